@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -59,6 +60,10 @@ func main() {
 			os.Exit(130) // 128 + SIGINT(2), standard exit code for Ctrl+C
 		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code) // e.g., partial vs total sync failure
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,2 @@
+// Package adaptive provides a concurrency limiter that adapts to rate limiting by the server.
+package adaptive
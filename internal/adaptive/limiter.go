@@ -0,0 +1,133 @@
+package adaptive
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultCooldown is how long after a decrease further rate limiting is attributed to the same burst.
+const defaultCooldown = time.Second
+
+// Limiter bounds the number of concurrent workers with additive increase, multiplicative decrease (AIMD):
+// the limit is halved when the server rate limits (see Throttle), and raised by one after every limit
+// completed requests without rate limiting, up to the maximum it started at.
+//
+// Without calls to Throttle, it is a plain semaphore of the maximum size.
+type Limiter struct {
+	mu        sync.Mutex
+	max       int
+	limit     int
+	active    int
+	completed int           // requests completed since the last limit change
+	changed   chan struct{} // closed when a slot may have become available
+	cooldown  time.Duration
+	lastCut   time.Time
+	onChange  func(limit int)
+	now       func() time.Time // for testing
+}
+
+// Option configures the Limiter.
+type Option func(*Limiter)
+
+// WithOnChange sets a function called with the new limit whenever it changes, e.g., for logging.
+// It is called with the limiter's lock held, so it must not call back into the limiter.
+func WithOnChange(fn func(limit int)) Option {
+	return func(l *Limiter) {
+		l.onChange = fn
+	}
+}
+
+// WithCooldown sets how long after a decrease further Throttle calls are ignored, since the requests
+// in flight when the server started rate limiting tend to be rate limited all at once.
+func WithCooldown(d time.Duration) Option {
+	return func(l *Limiter) {
+		l.cooldown = d
+	}
+}
+
+// NewLimiter creates a limiter allowing up to maxWorkers (at least 1) concurrent workers.
+func NewLimiter(maxWorkers int, opts ...Option) *Limiter {
+	maxWorkers = max(maxWorkers, 1)
+	l := &Limiter{
+		max:      maxWorkers,
+		limit:    maxWorkers,
+		changed:  make(chan struct{}),
+		cooldown: defaultCooldown,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Acquire blocks until a worker slot is free or the context is cancelled.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Release frees the slot taken by Acquire, counting the request as completed toward the next increase.
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.completed++
+	if l.limit < l.max && l.completed >= l.limit {
+		l.setLimit(l.limit + 1)
+	}
+	l.notify()
+}
+
+// Throttle reports that the server rate limited a request, halving the limit (to at least 1).
+// Safe to call from any goroutine, e.g., from an HTTP client's rate limit callback.
+func (l *Limiter) Throttle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if !l.lastCut.IsZero() && now.Sub(l.lastCut) < l.cooldown {
+		return
+	}
+	l.lastCut = now
+	l.setLimit(max(l.limit/2, 1))
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// setLimit changes the limit and restarts the count toward the next increase. Must be called with mu held.
+func (l *Limiter) setLimit(limit int) {
+	l.completed = 0
+	if limit == l.limit {
+		return
+	}
+	l.limit = limit
+	if l.onChange != nil {
+		l.onChange(limit)
+	}
+}
+
+// notify wakes the goroutines waiting in Acquire. Must be called with mu held.
+func (l *Limiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
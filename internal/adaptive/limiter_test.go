@@ -0,0 +1,103 @@
+package adaptive
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter_BoundsConcurrency(t *testing.T) {
+	l := NewLimiter(3)
+	var active, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Acquire(context.Background()); err != nil {
+				t.Errorf("Acquire() error: %v", err)
+				return
+			}
+			defer l.Release()
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", got)
+	}
+}
+
+func TestLimiter_AIMD(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var changes []int
+	l := NewLimiter(8, WithOnChange(func(limit int) { changes = append(changes, limit) }))
+	l.now = func() time.Time { return now }
+
+	l.Throttle()
+	if got := l.Limit(); got != 4 {
+		t.Fatalf("Limit() after Throttle = %d, want 4", got)
+	}
+	l.Throttle() // same burst, within the cooldown
+	if got := l.Limit(); got != 4 {
+		t.Errorf("Limit() after second Throttle within cooldown = %d, want 4", got)
+	}
+	now = now.Add(2 * time.Second)
+	l.Throttle()
+	if got := l.Limit(); got != 2 {
+		t.Errorf("Limit() after Throttle past cooldown = %d, want 2", got)
+	}
+
+	// every limit completions raise the limit by one
+	complete := func(n int) {
+		for range n {
+			if err := l.Acquire(context.Background()); err != nil {
+				t.Fatalf("Acquire() error: %v", err)
+			}
+			l.Release()
+		}
+	}
+	complete(2)
+	if got := l.Limit(); got != 3 {
+		t.Errorf("Limit() after 2 completions = %d, want 3", got)
+	}
+	complete(3 + 4 + 5 + 6 + 7)
+	if got := l.Limit(); got != 8 {
+		t.Errorf("Limit() after ramping up = %d, want the maximum 8", got)
+	}
+
+	if want := []int{4, 2, 3, 4, 5, 6, 7, 8}; !slices.Equal(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestLimiter_ThrottleFloor(t *testing.T) {
+	l := NewLimiter(1, WithCooldown(0))
+	l.Throttle()
+	if got := l.Limit(); got != 1 {
+		t.Errorf("Limit() = %d, want 1", got)
+	}
+}
+
+func TestLimiter_AcquireCancelled(t *testing.T) {
+	l := NewLimiter(1)
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx); err == nil {
+		t.Error("Acquire() on a full limiter expected error after cancellation, got nil")
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+// runADBPull reads Harmonic's bookmarks from a USB-connected Android device with adb and writes them
+// in the export format, so they can be piped into hnkeep instead of copying the export by hand.
+func runADBPull(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("adb-pull", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep adb-pull [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Read Harmonic's bookmarks from a USB-connected Android device (USB debugging enabled)\n")
+		_, _ = fmt.Fprintf(fs.Output(), "and write them as an export string, e.g., hnkeep adb-pull | hnkeep sync\n\n")
+		fs.PrintDefaults()
+	}
+	outputPath := fs.String("o", "", "Output file path (default: stdout)")
+	serial := fs.String("serial", "", "Serial of the device to use if several are connected (see adb devices)")
+	adbPath := fs.String("adb", "adb", "Path to the adb executable")
+	pkg := fs.String("package", harmonic.PackageName, "Application ID of Harmonic, e.g., for a debug build")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	adb, err := exec.LookPath(*adbPath)
+	if err != nil {
+		return fmt.Errorf("adb not found (install Android platform-tools or pass --adb): %w", err)
+	}
+	if _, err := runADB(ctx, adb, *serial, "get-state"); err != nil {
+		return fmt.Errorf("no device available: %w", err) // e.g., unauthorized or several connected
+	}
+	prefs, err := readHarmonicPrefs(ctx, adb, *serial, *pkg)
+	if err != nil {
+		return err
+	}
+	export, err := harmonic.ExtractBookmarks(prefs)
+	if err != nil {
+		return fmt.Errorf("reading Harmonic's preferences: %w", err)
+	}
+
+	if *outputPath == "" {
+		_, err = fmt.Fprintln(os.Stdout, export)
+		return err
+	}
+	if err := os.WriteFile(*outputPath, []byte(export+"\n"), 0o644); err != nil {
+		return err
+	}
+	bookmarks, _ := harmonic.Parse(export) // validated by ExtractBookmarks
+	fmt.Fprintf(os.Stderr, "Wrote %d bookmark(s) to %s\n", len(bookmarks), *outputPath)
+	return nil
+}
+
+// readHarmonicPrefs returns the contents of Harmonic's SharedPreferences file on the device.
+//
+// App data is private, so it is read with run-as, which works for debuggable builds, and
+// otherwise with su, which works on rooted devices. Store builds on unrooted devices can't be read.
+func readHarmonicPrefs(ctx context.Context, adb, serial, pkg string) ([]byte, error) {
+	prefsFile := "shared_prefs/" + pkg + "_preferences.xml"
+	attempts := []struct {
+		name string
+		cmd  string
+	}{
+		{"run-as", fmt.Sprintf("run-as %s cat %s", pkg, prefsFile)},
+		{"su", fmt.Sprintf("su -c 'cat /data/data/%s/%s'", pkg, prefsFile)},
+	}
+
+	var errs []error
+	for _, a := range attempts {
+		out, err := runADB(ctx, adb, serial, "exec-out", a.cmd)
+		if err == nil && bytes.Contains(out, []byte("<map")) {
+			return out, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			err = errors.New(orDefault(firstLine(out), "no output"))
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", a.name, err))
+	}
+	return nil, fmt.Errorf("reading Harmonic's data from the device failed, it requires a debuggable build "+
+		"or a rooted device (otherwise use Harmonic's export): %w", errors.Join(errs...))
+}
+
+// runADB runs adb with the arguments against the device and returns its stdout.
+// Shell commands are run with exec-out rather than shell so the output isn't mangled by a pseudo-terminal.
+func runADB(ctx context.Context, adb, serial string, args ...string) ([]byte, error) {
+	if serial != "" {
+		args = append([]string{"-s", serial}, args...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, adb, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := firstLine(stderr.Bytes()); msg != "" {
+			return nil, errors.New(msg) // e.g., "no devices/emulators found"
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// firstLine returns the first non-empty line of the output, trimmed, or "" if there is none.
+func firstLine(out []byte) string {
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
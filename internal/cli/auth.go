@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/internal/keyring"
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// keyringService is the service name the Karakeep API keys are stored under in the OS keyring.
+// Keys are stored per API URL, so each Karakeep server has its own.
+const keyringService = "hnkeep"
+
+// keyringAccount returns the keyring account of the API key for the given Karakeep API URL.
+func keyringAccount(apiURL string) string {
+	return strings.TrimRight(apiURL, "/")
+}
+
+// keyringAPIKey returns the API key stored for the Karakeep API URL, or "" if there is none
+// or the keyring is unavailable.
+func keyringAPIKey(apiURL string) string {
+	key, err := keyring.Get(keyringService, keyringAccount(apiURL))
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// runAuth dispatches the auth subcommands.
+func runAuth(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "login":
+			return runAuthLogin(ctx, args[1:])
+		case "logout":
+			return runAuthLogout(args[1:])
+		case "status":
+			return runAuthStatus(args[1:])
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Usage: hnkeep auth <command> -api-url <url>\n\n")
+	_, _ = fmt.Fprintf(os.Stderr, "Commands:\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  login   Store the Karakeep API key in the OS keyring\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  logout  Remove the stored API key\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  status  Show whether an API key is stored\n")
+	return errors.New("auth requires a subcommand")
+}
+
+// parseAuthFlags parses the arguments of an auth subcommand and returns the resolved API URL. With connect,
+// the subcommand talks to the server, so the connection flags are defined and their connection returned.
+func parseAuthFlags(name, description string, args []string, connect bool) (string, connection, error) {
+	fs := flag.NewFlagSet("auth "+name, flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep auth %s -api-url <url>\n\n", name)
+		_, _ = fmt.Fprintf(fs.Output(), "%s\n\n", description)
+		fs.PrintDefaults()
+	}
+	apiBaseURL := fs.String("api-url", "", "Karakeep API URL (env: KARAKEEP_API_URL)")
+	var conn *connFlags
+	if connect {
+		conn = addConnFlags(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", connection{}, err
+	}
+	apiURL := resolveAPIURL(*apiBaseURL)
+	if apiURL == "" {
+		return "", connection{}, fmt.Errorf("auth %s requires --api-url or KARAKEEP_API_URL to be set", name)
+	}
+	if conn == nil {
+		return apiURL, connection{}, nil
+	}
+	karakeepConn, err := conn.resolve()
+	if err != nil {
+		return "", connection{}, err
+	}
+	return apiURL, karakeepConn, nil
+}
+
+// runAuthLogin reads the API key from stdin, verifies it against the server, and stores it in the keyring.
+func runAuthLogin(ctx context.Context, args []string) error {
+	apiURL, conn, err := parseAuthFlags("login",
+		"Store the Karakeep API key in the OS keyring, so it isn't passed via a flag or env var.\n"+
+			"The key is read from stdin, e.g., typed at the prompt or piped from a password manager.", args, true)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	apiKey, err := readSecret("Karakeep API key: ")
+	if err != nil {
+		return fmt.Errorf("reading API key: %w", err)
+	}
+	if apiKey == "" {
+		return errors.New("no API key given")
+	}
+
+	client := karakeep.NewClient(apiURL, apiKey, conn.karakeepOptions()...)
+	phase := newPhaser(false).Start("Checking Karakeep API key")
+	user, err := client.CheckConnectivity(ctx)
+	if err != nil {
+		phase.Fail()
+		return fmt.Errorf("karakeep API check failed: %w", err)
+	}
+	phase.Done("ok (user: %s)", user)
+
+	if err := keyring.Set(keyringService, keyringAccount(apiURL), apiKey); err != nil {
+		return fmt.Errorf("storing API key: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Stored the API key for %s in the OS keyring\n", keyringAccount(apiURL))
+	return nil
+}
+
+// runAuthLogout removes the API key of the Karakeep server from the keyring.
+func runAuthLogout(args []string) error {
+	apiURL, _, err := parseAuthFlags("logout", "Remove the stored Karakeep API key from the OS keyring.", args, false)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if err := keyring.Delete(keyringService, keyringAccount(apiURL)); err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("no API key stored for %s", keyringAccount(apiURL))
+		}
+		return fmt.Errorf("removing API key: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Removed the API key for %s from the OS keyring\n", keyringAccount(apiURL))
+	return nil
+}
+
+// runAuthStatus reports whether an API key is stored for the Karakeep server.
+func runAuthStatus(args []string) error {
+	apiURL, _, err := parseAuthFlags("status", "Show whether a Karakeep API key is stored in the OS keyring.", args, false)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	key, err := keyring.Get(keyringService, keyringAccount(apiURL))
+	switch {
+	case errors.Is(err, keyring.ErrNotFound):
+		fmt.Printf("%s: no API key stored\n", keyringAccount(apiURL))
+	case err != nil:
+		return fmt.Errorf("reading API key: %w", err)
+	default:
+		fmt.Printf("%s: API key stored %s\n", keyringAccount(apiURL), redact(key))
+	}
+	return nil
+}
+
+// readSecret prompts on stderr and reads a line from stdin, hiding the typed characters on terminals.
+func readSecret(prompt string) (string, error) {
+	if logger.IsTTY(os.Stdin) {
+		fmt.Fprint(os.Stderr, prompt)
+		if err := setEcho(false); err == nil {
+			defer func() {
+				_ = setEcho(true) // best-effort, the terminal is unusable otherwise anyway
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
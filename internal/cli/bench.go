@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// benchConfig holds the configuration of the hidden bench command.
+type benchConfig struct {
+	N           int           // Number of synthetic bookmarks
+	Concurrency int           // Number of concurrent API calls
+	HNRateLimit float64       // Max HN API requests per second (0 = unlimited)
+	Latency     time.Duration // Simulated response latency of both fake servers
+	Cache       bool          // Fetch through a disk cache in a temporary directory
+	GzipCache   bool          // Gzip the cache entries
+	Sync        bool          // Sync the converted bookmarks to the fake Karakeep server
+}
+
+// benchPhase is the measurement of one pipeline phase.
+type benchPhase struct {
+	name     string
+	duration time.Duration
+	allocs   uint64 // heap objects allocated
+	bytes    uint64 // heap bytes allocated
+}
+
+// parseBenchFlags parses the bench command arguments.
+func parseBenchFlags(args []string) (*benchConfig, error) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep bench [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Run the pipeline on synthetic bookmarks against in-process fake HN and Karakeep servers,\n")
+		_, _ = fmt.Fprintf(fs.Output(), "and report the throughput and allocations of each phase. No network access is made.\n\n")
+		fs.PrintDefaults()
+	}
+
+	n := fs.Int("n", 10000, "Number of synthetic bookmarks")
+	concurrency := fs.Int("concurrency", 5, "Number of concurrent API calls")
+	hnRPS := fs.Float64("hn-rps", 0, "Max HN API requests per second (0 = unlimited)")
+	latency := fs.Duration("latency", 0, "Simulated response latency of the fake servers, e.g., 50ms")
+	cache := fs.Bool("cache", false, "Fetch through a disk cache in a temporary directory (fetched twice: cold, then warm)")
+	gzipCache := fs.Bool("cache-compress", false, "Gzip the cache entries (with -cache)")
+	sync := fs.Bool("sync", true, "Sync the converted bookmarks to the fake Karakeep server")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *n < 1 {
+		return nil, fmt.Errorf("-n must be at least 1, got %d", *n)
+	}
+	if *concurrency < 1 {
+		return nil, fmt.Errorf("-concurrency must be at least 1, got %d", *concurrency)
+	}
+	if *hnRPS < 0 {
+		return nil, fmt.Errorf("-hn-rps must not be negative, got %v", *hnRPS)
+	}
+
+	return &benchConfig{
+		N:           *n,
+		Concurrency: *concurrency,
+		HNRateLimit: *hnRPS,
+		Latency:     *latency,
+		Cache:       *cache,
+		GzipCache:   *gzipCache,
+		Sync:        *sync,
+	}, nil
+}
+
+// runBench runs the hidden bench command, a shared way to evaluate performance-oriented changes
+// (worker pools, rate limiters, cache backends) without touching the real APIs.
+func runBench(ctx context.Context, args []string) error {
+	cfg, err := parseBenchFlags(args)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	hnServer := httptest.NewServer(fakeHNHandler(cfg.Latency))
+	defer hnServer.Close()
+	kkServer := httptest.NewServer(newFakeKarakeep(cfg.Latency))
+	defer kkServer.Close()
+
+	bookmarks := syntheticBookmarks(cfg.N)
+	client := hackernews.NewClient(
+		hackernews.WithBaseURL(hnServer.URL),
+		hackernews.WithHTTPClient(hnServer.Client()),
+		hackernews.WithRateLimit(cfg.HNRateLimit),
+	)
+	var fetcher converter.ItemFetcher = client
+	if cfg.Cache {
+		cacheDir, err := os.MkdirTemp("", "hnkeep-bench-")
+		if err != nil {
+			return fmt.Errorf("creating cache dir: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(cacheDir) }()
+		if fetcher, err = hackernews.NewCachedClient(client, cacheDir, hackernews.WithCacheCompression(cfg.GzipCache)); err != nil {
+			return fmt.Errorf("creating cached client: %w", err)
+		}
+	}
+	conv := converter.New(
+		converter.WithFetcher(fetcher),
+		converter.WithConcurrency(cfg.Concurrency),
+		converter.WithLogger(logger.Noop()),
+	)
+
+	fmt.Fprintf(os.Stderr, "Benchmarking %d bookmark(s), concurrency %d, GOMAXPROCS %d\n",
+		cfg.N, cfg.Concurrency, runtime.GOMAXPROCS(0))
+
+	var phases []benchPhase
+	var items map[int]*hackernews.Item
+	fetch := func(name string) error {
+		phase, err := measure(name, func() (err error) {
+			items, err = conv.FetchItems(ctx, bookmarks)
+			return err
+		})
+		phases = append(phases, phase)
+		return err
+	}
+	if err := fetch("fetch"); err != nil {
+		return fmt.Errorf("fetching items: %w", err)
+	}
+	if cfg.Cache {
+		if err := fetch("fetch (warm)"); err != nil {
+			return fmt.Errorf("fetching items: %w", err)
+		}
+	}
+
+	var export converter.Schema
+	phase, _ := measure("convert", func() error {
+		export, _, _ = conv.Convert(bookmarks, items, converter.Options{
+			Tags:         []string{"hn"},
+			NoteTemplate: "{{smart_url}}",
+		})
+		return nil
+	})
+	phases = append(phases, phase)
+
+	phase, err = measure("encode", func() error {
+		return converter.WriteJSON(io.Discard, export)
+	})
+	phases = append(phases, phase)
+	if err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+
+	if cfg.Sync {
+		kkClient := karakeep.NewClient(kkServer.URL, "bench", karakeep.WithHTTPClient(kkServer.Client()))
+		var results map[syncer.SyncStatus]int
+		phase, _ = measure("sync", func() error {
+			results = syncer.New(syncer.NewKarakeepTarget(kkClient, nil), syncer.WithConcurrency(cfg.Concurrency)).Sync(ctx, export.Bookmarks)
+			return nil
+		})
+		phases = append(phases, phase)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if n := results[syncer.SyncFailed]; n > 0 {
+			return fmt.Errorf("%d bookmark(s) failed to sync against the fake server", n)
+		}
+	}
+
+	printBench(cfg.N, phases)
+	return nil
+}
+
+// measure runs fn and records its wall time and heap allocations.
+// Allocations of concurrently running goroutines, e.g., the fake servers, are included.
+func measure(name string, fn func() error) (benchPhase, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return benchPhase{
+		name:     name,
+		duration: elapsed,
+		allocs:   after.Mallocs - before.Mallocs,
+		bytes:    after.TotalAlloc - before.TotalAlloc,
+	}, err
+}
+
+// printBench prints the measurements of each phase to stdout.
+func printBench(n int, phases []benchPhase) {
+	out := os.Stdout
+	_, _ = fmt.Fprintf(out, "%-14s %10s %12s %12s %12s\n", "phase", "time", "items/s", "allocs/item", "bytes/item")
+	var total time.Duration
+	for _, p := range phases {
+		total += p.duration
+		_, _ = fmt.Fprintf(out, "%-14s %10s %12.0f %12d %12d\n", p.name, p.duration.Round(time.Millisecond),
+			float64(n)/p.duration.Seconds(), p.allocs/uint64(n), p.bytes/uint64(n))
+	}
+	_, _ = fmt.Fprintf(out, "%-14s %10s %12.0f\n", "total", total.Round(time.Millisecond), float64(n)/total.Seconds())
+}
+
+// syntheticBookmarks returns n bookmarks with consecutive IDs, one second apart.
+// Every tenth item shares the URL of the one before it, so deduplication is exercised too.
+func syntheticBookmarks(n int) []harmonic.Bookmark {
+	bookmarks := make([]harmonic.Bookmark, n)
+	for i := range bookmarks {
+		bookmarks[i] = harmonic.Bookmark{ID: i + 1, Timestamp: 1700000000 + int64(i)}
+	}
+	return bookmarks
+}
+
+// fakeHNHandler serves synthetic items for /item/<id>.json, like the HN Firebase API.
+func fakeHNHandler(latency time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(latency)
+		id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/item/"), ".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		urlID := id
+		if id%10 == 0 {
+			urlID = id - 1 // duplicate URL
+		}
+		_ = json.NewEncoder(w).Encode(hackernews.Item{
+			ID:          id,
+			Type:        "story",
+			By:          "bench",
+			Time:        1700000000 + int64(id),
+			URL:         fmt.Sprintf("https://example.com/articles/%d", urlID),
+			Title:       fmt.Sprintf("Synthetic story %d", id),
+			Score:       id % 500,
+			Descendants: id % 200,
+		})
+	})
+}
+
+// fakeKarakeep is an in-memory Karakeep API supporting what a sync uses.
+type fakeKarakeep struct {
+	latency time.Duration
+	nextID  atomic.Int64
+	mu      sync.Mutex
+	urls    map[string]string // url -> bookmark id
+}
+
+// newFakeKarakeep creates an empty fake Karakeep API.
+func newFakeKarakeep(latency time.Duration) *fakeKarakeep {
+	return &fakeKarakeep{latency: latency, urls: make(map[string]string)}
+}
+
+func (f *fakeKarakeep) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(f.latency)
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/bookmarks":
+		_ = json.NewEncoder(w).Encode(karakeep.ListBookmarksResponse{Bookmarks: []karakeep.ListBookmark{}})
+	case r.Method == http.MethodPost && r.URL.Path == "/bookmarks":
+		var req karakeep.CreateBookmarkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		id, exists := f.urls[req.URL]
+		if !exists {
+			id = strconv.FormatInt(f.nextID.Add(1), 10)
+			f.urls[req.URL] = id
+		}
+		f.mu.Unlock()
+		if exists {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_ = json.NewEncoder(w).Encode(karakeep.CreateBookmarkResponse{
+			ID:        id,
+			CreatedAt: req.CreatedAt,
+			Title:     req.Title,
+			Note:      req.Note,
+		})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/tags"),
+		r.Method == http.MethodPatch:
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte("{}"))
+	default:
+		http.NotFound(w, r)
+	}
+}
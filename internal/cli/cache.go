@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// runCache dispatches the cache subcommands.
+func runCache(args []string) error {
+	if len(args) == 0 || args[0] != "prune" {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: hnkeep cache prune [flags]\n\n")
+		_, _ = fmt.Fprintf(os.Stderr, "Commands:\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  prune  Delete selected HN cache entries (see hnkeep cache prune -h)\n")
+		return errors.New("cache requires a subcommand")
+	}
+	return runCachePrune(args[1:])
+}
+
+// runCachePrune deletes the cache entries selected by the flags.
+func runCachePrune(args []string) error {
+	fs := flag.NewFlagSet("cache prune", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep cache prune [-older-than <age>] [-negative-only] [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Delete a subset of HN cache entries. Use -clear-cache to delete everything.\n\n")
+		fs.PrintDefaults()
+	}
+
+	cacheDir := fs.String("cache-dir", getDefaultCacheDir(), "HN API responses cache directory path")
+	olderThan := fs.String("older-than", "", "Only delete entries older than this, e.g., 90d or 12h")
+	negativeOnly := fs.Bool("negative-only", false, "Only delete entries of deleted/dead items, so they are checked again")
+	dryRun := fs.Bool("dry-run", false, "Count matching entries without deleting them")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	opts := hackernews.PruneOptions{NegativeOnly: *negativeOnly, DryRun: *dryRun}
+	if *olderThan != "" {
+		ttl, err := parseTTL(*olderThan)
+		if err != nil {
+			return fmt.Errorf("parsing -older-than: %w", err)
+		}
+		opts.OlderThan = ttl
+	}
+	if opts.OlderThan == 0 && !opts.NegativeOnly {
+		return errors.New("cache prune requires -older-than and/or -negative-only (use -clear-cache to delete everything)")
+	}
+	if *cacheDir == "" {
+		return errors.New("cache directory unavailable")
+	}
+
+	// open without creating a missing cache dir
+	if _, err := os.Stat(*cacheDir); errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "No cache at %s, nothing to prune.\n", *cacheDir)
+		return nil
+	}
+	cached, err := hackernews.NewCachedClient(hackernews.NewClient(), *cacheDir)
+	if err != nil {
+		return fmt.Errorf("opening cache: %w", err)
+	}
+
+	n, err := cached.Prune(opts)
+	if err != nil {
+		return fmt.Errorf("pruning cache: %w", err)
+	}
+	if opts.DryRun {
+		fmt.Fprintf(os.Stderr, "Would delete %d cache entries from %s\n", n, *cacheDir)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Deleted %d cache entries from %s\n", n, *cacheDir)
+	return nil
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// checkpoint records converted bookmarks that were not synced yet,
+// so an interrupted or failed sync can be resumed without re-fetching from HN.
+type checkpoint struct {
+	APIURL    string                    `json:"apiUrl"`  // Karakeep instance the sync targeted
+	SavedAt   int64                     `json:"savedAt"` // Unix timestamp
+	Bookmarks converter.SchemaBookmarks `json:"bookmarks"`
+}
+
+// checkpointPath returns the checkpoint file path for the given state directory and Karakeep API URL.
+// Each server has its own, like the sync state, so a sync to one doesn't drop the checkpoint of another.
+func checkpointPath(stateDir, apiURL string) string {
+	return filepath.Join(stateDir, "sync-checkpoint-"+serverKey(apiURL)+".json")
+}
+
+// saveCheckpoint writes the unsynced bookmarks to the checkpoint file.
+// The file is written to a temporary path first so a crash never leaves a truncated checkpoint.
+func saveCheckpoint(path, apiURL string, bookmarks []converter.Bookmark) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(checkpoint{
+		APIURL:    apiURL,
+		SavedAt:   time.Now().Unix(),
+		Bookmarks: bookmarks,
+	})
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadCheckpoint reads the checkpoint file and verifies it targets the given Karakeep instance.
+func loadCheckpoint(path, apiURL string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no checkpoint found at %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("decoding checkpoint: %w", err)
+	}
+	if cp.APIURL != apiURL {
+		return nil, fmt.Errorf("checkpoint was saved for %s, not %s", cp.APIURL, apiURL)
+	}
+	return &cp, nil
+}
+
+// removeCheckpoint deletes the checkpoint file, ignoring a missing file.
+func removeCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// fetchCheckpointFile is the name of the fetch checkpoint file inside the state directory.
+const fetchCheckpointFile = "fetch-checkpoint.json"
+
+// fetchCheckpointInterval is how often the fetch checkpoint is saved during a fetch.
+const fetchCheckpointInterval = 30 * time.Second
+
+// fetchCheckpoint records the HN items fetched by an interrupted run. They are in the cache too,
+// but reading one file instead of one per item lets a resumed run of a huge import start within seconds.
+type fetchCheckpoint struct {
+	SavedAt int64              `json:"savedAt"` // Unix timestamp
+	Items   []*hackernews.Item `json:"items"`
+}
+
+// fetchCheckpointPath returns the fetch checkpoint file path for the given state directory.
+func fetchCheckpointPath(stateDir string) string {
+	return filepath.Join(stateDir, fetchCheckpointFile)
+}
+
+// saveFetchCheckpoint writes the fetched items to the fetch checkpoint file, atomically as saveCheckpoint.
+func saveFetchCheckpoint(path string, items map[int]*hackernews.Item) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	cp := fetchCheckpoint{SavedAt: time.Now().Unix(), Items: make([]*hackernews.Item, 0, len(items))}
+	for _, item := range items {
+		cp.Items = append(cp.Items, item)
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadFetchCheckpoint reads the fetch checkpoint file, returning nil if there is none. A checkpoint
+// saved longer than maxAge ago (0 = never too old) is removed instead, like an expired cache entry.
+// Stubs of deleted/dead items are dropped, since how they are handled may differ between runs.
+func loadFetchCheckpoint(path string, maxAge time.Duration) (map[int]*hackernews.Item, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp fetchCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("decoding fetch checkpoint: %w", err)
+	}
+	if maxAge > 0 && time.Since(time.Unix(cp.SavedAt, 0)) > maxAge {
+		return nil, removeCheckpoint(path)
+	}
+	items := make(map[int]*hackernews.Item, len(cp.Items))
+	for _, item := range cp.Items {
+		if item != nil && !item.Deleted && !item.Dead {
+			items[item.ID] = item
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestCheckpoint(t *testing.T) {
+	const serverA, serverB = "https://a.example.com/api/v1", "https://b.example.com/api/v1"
+	stateDir := t.TempDir()
+	pathA, pathB := checkpointPath(stateDir, serverA), checkpointPath(stateDir, serverB)
+	if pathA == pathB {
+		t.Fatalf("checkpointPath() = %s for both servers, want one per server", pathA)
+	}
+	if got := checkpointPath(stateDir, serverA+"/"); got != pathA {
+		t.Errorf("checkpointPath() with a trailing slash = %s, want %s", got, pathA)
+	}
+
+	bookmarks := []converter.Bookmark{{CreatedAt: 1700000000, Content: converter.NewBookmarkContent("https://example.com")}}
+	if err := saveCheckpoint(pathA, serverA, bookmarks); err != nil {
+		t.Fatalf("saveCheckpoint() error = %v", err)
+	}
+	// a completed sync to server B removes its own checkpoint only
+	if err := removeCheckpoint(pathB); err != nil {
+		t.Fatalf("removeCheckpoint() error = %v", err)
+	}
+
+	cp, err := loadCheckpoint(pathA, serverA)
+	if err != nil {
+		t.Fatalf("loadCheckpoint() error = %v", err)
+	}
+	if len(cp.Bookmarks) != 1 || cp.Bookmarks[0].Content.URL != "https://example.com" {
+		t.Errorf("loadCheckpoint() bookmarks = %+v, want the saved one", cp.Bookmarks)
+	}
+	if _, err := loadCheckpoint(pathB, serverB); err == nil || !strings.Contains(err.Error(), "no checkpoint found") {
+		t.Errorf("loadCheckpoint() of server B error = %v, want no checkpoint", err)
+	}
+	if _, err := loadCheckpoint(pathA, serverB); err == nil || !strings.Contains(err.Error(), "was saved for") {
+		t.Errorf("loadCheckpoint() for another server error = %v, want a mismatch", err)
+	}
+}
+
+func TestFetchCheckpoint_Expires(t *testing.T) {
+	path := fetchCheckpointPath(t.TempDir())
+	items := map[int]*hackernews.Item{1: {ID: 1, Title: "A Story"}}
+	if err := saveFetchCheckpoint(path, items); err != nil {
+		t.Fatalf("saveFetchCheckpoint() error = %v", err)
+	}
+
+	got, err := loadFetchCheckpoint(path, time.Hour)
+	if err != nil || got[1] == nil || got[1].Title != "A Story" {
+		t.Fatalf("loadFetchCheckpoint() = %v, %v, want the saved item", got, err)
+	}
+
+	// backdate the checkpoint past the TTL
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cp fetchCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		t.Fatal(err)
+	}
+	cp.SavedAt -= int64((2 * time.Hour).Seconds())
+	if data, err = json.Marshal(cp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := loadFetchCheckpoint(path, 0); err != nil || len(got) != 1 {
+		t.Errorf("loadFetchCheckpoint() without TTL = %v, %v, want the saved item", got, err)
+	}
+	if got, err := loadFetchCheckpoint(path, time.Hour); err != nil || got != nil {
+		t.Errorf("loadFetchCheckpoint() of an expired checkpoint = %v, %v, want none", got, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expired checkpoint stat error = %v, want it removed", err)
+	}
+}
@@ -2,22 +2,34 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/akhdanfadh/hnkeep/internal/adaptive"
 	"github.com/akhdanfadh/hnkeep/internal/converter"
-	"github.com/akhdanfadh/hnkeep/internal/hackernews"
 	"github.com/akhdanfadh/hnkeep/internal/harmonic"
-	"github.com/akhdanfadh/hnkeep/internal/karakeep"
+	"github.com/akhdanfadh/hnkeep/internal/hnlinks"
+	"github.com/akhdanfadh/hnkeep/internal/idmap"
 	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/internal/materialistic"
+	"github.com/akhdanfadh/hnkeep/internal/redirect"
 	"github.com/akhdanfadh/hnkeep/internal/syncer"
+	"github.com/akhdanfadh/hnkeep/internal/transform"
+	"github.com/akhdanfadh/hnkeep/internal/wayback"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
 )
 
 // readInput reads the input from the specified path or stdin if the path is empty.
+// The input is transcoded to UTF-8 if it was saved with a BOM or as UTF-16.
 func readInput(path string) (string, error) {
 	var r io.Reader = os.Stdin // fallback
 	if path != "" {
@@ -33,11 +45,83 @@ func readInput(path string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(data), nil
+	return decodeInput(data)
 }
 
-// writeOutput writes the output to the specified path or stdout if the path is empty.
-func writeOutput(path string, export converter.Schema) (err error) {
+// Supported input formats.
+const (
+	formatHarmonic      = "harmonic"
+	formatIDMap         = "idmap"
+	formatMaterialistic = "materialistic"
+	formatGlider        = "glider"
+	formatHews          = "hews"
+	formatIDList        = "idlist"
+)
+
+// inputFormats lists the supported input formats, for messages.
+var inputFormats = []string{formatHarmonic, formatIDMap, formatIDList, formatMaterialistic, formatGlider, formatHews}
+
+// checkInputFormat returns an error if the given input format is not supported.
+func checkInputFormat(format string) error {
+	if !slices.Contains(inputFormats, format) {
+		return fmt.Errorf("unknown input format %q (supported: %s)", format, strings.Join(inputFormats, ", "))
+	}
+	return nil
+}
+
+// Supported -dedupe-mode values, how a sync finds the bookmarks already in Karakeep.
+const (
+	dedupePrefetch = "prefetch" // list the whole library (or -prefetch-tag) once before syncing
+	dedupeSearch   = "search"   // search each bookmark's URL, for a few bookmarks into a large library
+	dedupeNone     = "none"     // treat every bookmark as new
+)
+
+// Supported push targets (see -target).
+const (
+	targetKarakeep = "karakeep"
+	targetWebhook  = "webhook"
+)
+
+// parseInput parses the input bookmarks in the given format.
+// Harmonic input may also be the app's shared preferences XML, e.g., from a backup.
+// Glider and Hews share bookmarks as lists of links without save times, so the HN links are picked out.
+func parseInput(input, format string) ([]harmonic.Bookmark, error) {
+	switch format {
+	case formatIDMap:
+		return idmap.Parse(input)
+	case formatMaterialistic:
+		return materialistic.Parse(input)
+	case formatGlider, formatHews:
+		return hnlinks.Parse(input, time.Now())
+	case formatIDList:
+		return hnlinks.ParseList(input, time.Now())
+	}
+	if strings.HasPrefix(strings.TrimSpace(input), "<") {
+		export, err := harmonic.ExtractBookmarks([]byte(input))
+		if err != nil {
+			return nil, err
+		}
+		input = export
+	}
+	return harmonic.Parse(input)
+}
+
+// writeOutput writes the output in the given registered format to opts.Path or stdout if the path
+// is empty. For directory formats such as obsidian, the path is the target directory.
+func writeOutput(format string, opts converter.ExportOptions, export converter.Schema) (err error) {
+	f, ok := converter.LookupFormat(format)
+	if !ok {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	path := opts.Path
+	exporter, err := f.New(opts)
+	if err != nil {
+		return err
+	}
+	if f.Directory {
+		return exporter.Export(nil, export)
+	}
+
 	var w io.Writer = os.Stdout // fallback
 	if path != "" {
 		f, createErr := os.Create(path)
@@ -52,9 +136,7 @@ func writeOutput(path string, export converter.Schema) (err error) {
 		w = f
 	}
 
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ") // pretty print
-	return encoder.Encode(export)
+	return exporter.Export(w, export)
 }
 
 // filterByDate filters bookmarks by before and after timestamps.
@@ -76,15 +158,198 @@ func filterByDate(bookmarks []harmonic.Bookmark, before, after int64) []harmonic
 	return filtered
 }
 
+// loadOverrides reads and parses the per-item overrides file. It is JSON only, as reading YAML would
+// take a third-party dependency, so a YAML file gets a hint instead of a JSON syntax error.
+func loadOverrides(path string) (converter.Overrides, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		return nil, fmt.Errorf("%s: overrides must be JSON, YAML is not supported (convert it, e.g., with yq -o json)", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading overrides: %w", err)
+	}
+	overrides, err := converter.ParseOverrides(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// loadExcludedIDs reads the -exclude-ids file, HN IDs or item URLs one per line.
+func loadExcludedIDs(path string) (map[int]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading excluded IDs: %w", err)
+	}
+	list, err := hnlinks.ParseList(string(data), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	excluded := make(map[int]bool, len(list))
+	for _, bm := range list {
+		excluded[bm.ID] = true
+	}
+	return excluded, nil
+}
+
+// filterBySkip drops the bookmarks whose override says to skip them.
+func filterBySkip(bookmarks []harmonic.Bookmark, overrides converter.Overrides) []harmonic.Bookmark {
+	filtered := make([]harmonic.Bookmark, 0, len(bookmarks))
+	for _, bm := range bookmarks {
+		if !overrides[bm.ID].Skip {
+			filtered = append(filtered, bm)
+		}
+	}
+	return filtered
+}
+
+// progress is a progresser whose display can be cleared once the stage is done.
+type progress interface {
+	logger.Progresser
+	Clear()
+}
+
+// heartbeatInterval is how often the fallback progresser prints a line.
+const heartbeatInterval = 2 * time.Second
+
+// newProgress returns a progress display for stderr, or nil if stderr is not a TTY or verbose is set.
+// Falls back to line-based heartbeat output if the terminal cannot interpret ANSI escape codes.
+func newProgress(verbose bool, format string) progress {
+	if verbose || !logger.IsStderrTTY() {
+		return nil
+	}
+	if !logger.EnableVirtualTerminal(os.Stderr) {
+		return logger.NewHeartbeatProgresser(os.Stderr, format, heartbeatInterval)
+	}
+	return logger.NewProgresser(os.Stderr, format)
+}
+
+// newStatusBoard returns a status area showing all sync stages at once, or nil if the stages are shown
+// one line at a time: outside of sync, in dry runs, verbose and interactive mode, and on terminals
+// without ANSI support.
+func newStatusBoard(cfg *Config) *logger.StatusBoard {
+	if !cfg.Sync || cfg.DryRun || cfg.Verbose || cfg.Interactive ||
+		!logger.IsStderrTTY() || !logger.EnableVirtualTerminal(os.Stderr) {
+		return nil
+	}
+	return logger.NewStatusBoard(os.Stderr)
+}
+
+// newStageProgress returns the progress display of a stage, as a line of the status board if there is one.
+func newStageProgress(board *logger.StatusBoard, verbose bool, format string) progress {
+	if board != nil {
+		return board.Stage(format)
+	}
+	return newProgress(verbose, format)
+}
+
+// newPhaser returns a phase reporter for stderr, shown in verbose mode or when stderr is a TTY.
+func newPhaser(verbose bool) *logger.Phaser {
+	return logger.NewPhaser(os.Stderr, !verbose && !logger.IsStderrTTY())
+}
+
+// Log files are rotated at this size, keeping this many backups (e.g., hnkeep.log.1 to hnkeep.log.3).
+const (
+	logFileMaxSize = 10 << 20 // 10 MiB
+	logFileBackups = 3
+)
+
+// newStderrLogger returns a logger writing to stderr (directly or through a status board drawn on it),
+// colored unless disabled or stderr is not a terminal.
+func newStderrLogger(out io.Writer, level slog.Level, noColor bool) *logger.StdLogger {
+	var opts []logger.StdLoggerOption
+	if !noColor && logger.ColorEnabled() {
+		opts = append(opts, logger.WithColor())
+	}
+	return logger.NewStdLogger(out, level, opts...)
+}
+
+// newLogger returns the logger for the run: timestamped lines to the rotating log file if set, else stderr.
+// With a status board, stderr lines are printed above the board. The returned close function must be
+// called once logging is done.
+func newLogger(cfg *Config, board *logger.StatusBoard) (*logger.StdLogger, func(), error) {
+	if cfg.LogFile == "" {
+		var out io.Writer = os.Stderr
+		if board != nil {
+			out = board
+		}
+		return newStderrLogger(out, cfg.LogLevel, cfg.NoColor), func() {}, nil
+	}
+	f, err := logger.OpenRotatingFile(cfg.LogFile, logFileMaxSize, logFileBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeFn := func() { _ = f.Close() } // nothing left to report a close error to
+	return logger.NewStdLogger(f, cfg.LogLevel, logger.WithTimestamps()), closeFn, nil
+}
+
 // Run executes the CLI with the provided CLI arguments.
 func Run(ctx context.Context) error {
 	var stats stats
 	stats.totalStart = time.Now()
 
-	cfg, err := parseFlags()
+	args := os.Args[1:]
+	retry, compare := false, ""
+	if len(args) > 0 {
+		switch args[0] {
+		case "env":
+			cfg, err := parseFlags(args[1:])
+			if err != nil {
+				return fmt.Errorf("parsing flags: %w", err)
+			}
+			printEnv(cfg)
+			return nil
+		case "sync":
+			args = append([]string{"-sync"}, args[1:]...)
+		case "watch":
+			args = append([]string{"-watch"}, args[1:]...)
+		case "retry":
+			retry, args = true, args[1:]
+		case "diff", "verify":
+			compare, args = args[0], args[1:]
+		case "prune":
+			return runPrune(ctx, args[1:])
+		case "state":
+			return runState(args[1:])
+		case "stats":
+			return runInsights(ctx, args[1:])
+		case "dedupe-remote":
+			return runDedupeRemote(ctx, args[1:])
+		case "templates":
+			return runTemplates(args[1:])
+		case "auth":
+			return runAuth(ctx, args[1:])
+		case "cache":
+			return runCache(args[1:])
+		case "adb-pull":
+			return runADBPull(ctx, args[1:])
+		case "bench": // hidden, for maintainers evaluating performance changes
+			return runBench(ctx, args[1:])
+		}
+	}
+
+	cfg, err := parseFlags(args)
 	if err != nil {
 		return fmt.Errorf("parsing flags: %w", err)
 	}
+	if retry && cfg.RetryReport == "" {
+		if cfg.StateDir == "" {
+			return fmt.Errorf("retry: state directory unavailable, pass -report")
+		}
+		cfg.RetryReport = failuresPath(cfg.StateDir)
+	}
+	if compare != "" {
+		if err := checkCompare(cfg, compare); err != nil {
+			return fmt.Errorf("parsing flags: %w", err)
+		}
+		cfg.Compare = compare
+	}
+
+	if cfg.Resume {
+		err := runResume(ctx, cfg, &stats)
+		reportFailures(cfg, stats.failures)
+		return err
+	}
 
 	// if no input data is given and stdin is a terminal, show usage and exit
 	if cfg.InputPath == "" && logger.IsTTY(os.Stdin) {
@@ -92,19 +357,65 @@ func Run(ctx context.Context) error {
 		return nil
 	}
 
-	input, err := readInput(cfg.InputPath)
-	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+	if cfg.Watch {
+		return runWatch(ctx, cfg)
 	}
 
-	// parse harmonic export
-	bookmarks, err := harmonic.Parse(input)
+	bookmarks, err := loadBookmarks(cfg)
 	if err != nil {
-		return fmt.Errorf("parsing input: %w", err)
+		return err
 	}
 	stats.found = len(bookmarks)
+	err = runPipeline(ctx, cfg, bookmarks, &stats)
+	reportFailures(cfg, stats.failures)
+	return err
+}
 
+// loadBookmarks reads and parses the input bookmarks.
+func loadBookmarks(cfg *Config) ([]harmonic.Bookmark, error) {
+	input, err := readInput(cfg.InputPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	bookmarks, err := parseInput(input, cfg.InputFormat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing input: %w", err)
+	}
+	return bookmarks, nil
+}
+
+// runPipeline filters the input bookmarks, fetches their HN items, converts them,
+// and writes the result to the output, Karakeep, or the webhook.
+func runPipeline(ctx context.Context, cfg *Config, bookmarks []harmonic.Bookmark, stats *stats) error {
 	// apply filters
+	if cfg.RetryReport != "" {
+		failures, err := loadFailures(cfg.RetryReport)
+		if err != nil {
+			return fmt.Errorf("reading failures report: %w", err)
+		}
+		retried := filterByFailures(bookmarks, failures)
+		stats.notRetried = len(bookmarks) - len(retried)
+		bookmarks = retried
+	}
+	var overrides converter.Overrides
+	if cfg.Overrides != "" {
+		var err error
+		if overrides, err = loadOverrides(cfg.Overrides); err != nil {
+			return err
+		}
+		kept := filterBySkip(bookmarks, overrides)
+		stats.overridden = len(bookmarks) - len(kept)
+		bookmarks = kept
+	}
+	if cfg.ExcludeIDs != "" {
+		excluded, err := loadExcludedIDs(cfg.ExcludeIDs)
+		if err != nil {
+			return err
+		}
+		kept := slices.DeleteFunc(slices.Clone(bookmarks), func(bm harmonic.Bookmark) bool { return excluded[bm.ID] })
+		stats.excluded = len(bookmarks) - len(kept)
+		bookmarks = kept
+	}
 	if cfg.Before > 0 || cfg.After > 0 {
 		bookmarks = filterByDate(bookmarks, cfg.Before, cfg.After)
 	}
@@ -121,40 +432,56 @@ func Run(ctx context.Context) error {
 	}
 
 	// pre-flight connectivity check for sync mode (includes dry-run)
-	var karakeepClient *karakeep.Client
 	if cfg.Sync {
-		karakeepClient = karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey,
-			karakeep.WithTimeout(cfg.APITimeout),
-		)
-
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "Checking Karakeep API connectivity... ")
-		}
-		if err := karakeepClient.CheckConnectivity(ctx); err != nil {
-			if cfg.Verbose {
-				fmt.Fprintf(os.Stderr, "failed\n")
-			}
-			return fmt.Errorf("karakeep API check failed: %w", err)
-		}
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "ok\n")
+		if err := preflight(ctx, cfg); err != nil {
+			return err
 		}
 	}
 
 	// dry run mode: give stats on the input and exit
-	if cfg.DryRun {
-		printDryRunMode(stats, bookmarks, cfg.Sync)
+	// sync dry run continues below to fetch items and build a sync plan (read-only)
+	if cfg.DryRun && !cfg.Sync {
+		printDryRunMode(*stats, bookmarks)
 		return nil
 	}
 
+	// warn before the fetch, as nothing may be printed past the status board while it is shown
+	if cfg.OutputPath != "" {
+		if cfg.Sync {
+			fmt.Fprintf(os.Stderr, "Warning: --output is ignored in sync mode\n")
+		} else if cfg.Target == targetWebhook {
+			fmt.Fprintf(os.Stderr, "Warning: --output is ignored with --target webhook\n")
+		} else if cfg.Compare != "" {
+			fmt.Fprintf(os.Stderr, "Warning: --output is ignored by hnkeep %s\n", cfg.Compare)
+		}
+	}
+
 	// configure logger and clients
-	log := logger.NewStdLogger(os.Stderr, !cfg.Verbose)
-	client := hackernews.NewClient(hackernews.WithLogger(log))
+	board := newStatusBoard(cfg)
+	if board != nil {
+		defer board.Close()
+	}
+	log, closeLog, err := newLogger(cfg, board)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	fetchLimiter := newLimiter(cfg.HNWorkers, log, "HN fetch")
+	client := hackernews.NewClient(
+		hackernews.WithLogger(log),
+		hackernews.WithRateLimit(cfg.HNRateLimit),
+		hackernews.WithRateLimitHook(fetchLimiter.Throttle),
+		hackernews.WithProxy(cfg.Proxy),
+	)
 	var fetcher converter.ItemFetcher = client
 
 	// use cached client if cache dir is set
 	if cfg.CacheDir != "" {
-		cachedClient, err := hackernews.NewCachedClient(client, cfg.CacheDir, hackernews.WithCacheLogger(log))
+		cachedClient, err := hackernews.NewCachedClient(client, cfg.CacheDir,
+			hackernews.WithCacheLogger(log),
+			hackernews.WithCacheTTL(cfg.CacheTTL),
+			hackernews.WithCacheCompression(cfg.GzipCache),
+		)
 		if err != nil {
 			return fmt.Errorf("creating cached client: %w", err)
 		}
@@ -166,17 +493,62 @@ func Run(ctx context.Context) error {
 		fetcher = cachedClient
 	}
 
-	// setup progress indicator if stderr is a TTY and not verbose (verbose has its own logging)
-	var progressFetch *logger.TTYProgresser
-	if !cfg.Verbose && logger.IsStderrTTY() {
-		progressFetch = logger.NewProgresser(os.Stderr, "Fetching: %d/%d")
+	// resume the fetch of an interrupted run, unless the user asked for fresh data: the checkpoint is
+	// removed by -clear-cache, skipped with -no-cache, and expires with -cache-ttl like the cache
+	var fetchCheckpoint string
+	var prefetched map[int]*hackernews.Item
+	if cfg.StateDir != "" {
+		fetchCheckpoint = fetchCheckpointPath(cfg.StateDir)
+		if cfg.ClearCache {
+			if err := removeCheckpoint(fetchCheckpoint); err != nil {
+				return fmt.Errorf("removing fetch checkpoint: %w", err)
+			}
+		}
+		if cfg.CacheDir != "" {
+			if prefetched, err = loadFetchCheckpoint(fetchCheckpoint, cfg.CacheTTL); err != nil {
+				log.Warn("ignoring fetch checkpoint: %v", err)
+			}
+		}
+		for _, bm := range bookmarks {
+			if _, ok := prefetched[bm.ID]; ok {
+				stats.reused++
+			}
+		}
+		if stats.reused > 0 {
+			fmt.Fprintf(os.Stderr, "Resuming fetch with %d item(s) from checkpoint\n", stats.reused)
+		}
 	}
 
+	// setup progress indicator if stderr is a TTY and not verbose (verbose has its own logging)
+	progressFetch := newStageProgress(board, cfg.Verbose, "Fetching: %d/%d")
+
 	// perform conversion
 	convOpts := []converter.Option{
 		converter.WithFetcher(fetcher),
-		converter.WithConcurrency(cfg.Concurrency),
+		converter.WithLimiter(fetchLimiter),
 		converter.WithLogger(log),
+		converter.WithDeadItems(converter.DeadItemsMode(cfg.DeadItems)),
+		converter.WithPrefetched(prefetched),
+		converter.WithTopComments(cfg.TopComments),
+		converter.WithOnFetchError(func(id int, err error) {
+			stats.failures = append(stats.failures, failure{
+				HNID: id, URL: hackernews.DiscussionURL(id), Stage: "fetch", Error: err.Error(),
+			})
+		}),
+	}
+	if cfg.Redirects {
+		convOpts = append(convOpts, converter.WithURLResolver(redirect.NewResolver()))
+	}
+	if cfg.CheckLinks != "" {
+		convOpts = append(convOpts, converter.WithDeadLinks(converter.DeadLinksMode(cfg.CheckLinks),
+			redirect.NewResolver(), wayback.NewClient()))
+	}
+	if fetchCheckpoint != "" {
+		convOpts = append(convOpts, converter.WithCheckpoint(fetchCheckpointInterval, func(items map[int]*hackernews.Item) {
+			if err := saveFetchCheckpoint(fetchCheckpoint, items); err != nil {
+				log.Warn("saving fetch checkpoint: %v", err)
+			}
+		}))
 	}
 	if progressFetch != nil {
 		convOpts = append(convOpts, converter.WithProgress(progressFetch))
@@ -192,91 +564,446 @@ func Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("fetching items: %w", err)
 	}
-	stats.skipped = stats.afterLimit - len(items)
+	if fetchCheckpoint != "" {
+		if err := removeCheckpoint(fetchCheckpoint); err != nil {
+			log.Warn("removing fetch checkpoint: %v", err)
+		}
+	}
+	filter := converter.ItemFilter{MinScore: cfg.MinScore, Kinds: cfg.Types, Excluded: cfg.Excluded, Only: cfg.OnlyDomains}
+	if cfg.Filter != "" {
+		// the expression is validated by parseFlags
+		if filter.Expr, err = converter.ParseFilterExpr(cfg.Filter); err != nil {
+			return fmt.Errorf("parsing filter: %w", err)
+		}
+	}
+	dropped := filter.Apply(items)
+
+	// count misses per bookmark rather than by map size, so a bug that drops bookmarks later doesn't cancel out
+	for _, bm := range bookmarks {
+		if _, ok := items[bm.ID]; !ok {
+			if dropped[bm.ID] {
+				stats.filtered++
+			} else {
+				stats.skipped++
+			}
+		}
+	}
 
 	if cc, ok := fetcher.(*hackernews.CachedClient); ok {
 		stats.cacheHits = cc.CacheHits()
 	}
 
-	export, dedupedCount := conv.Convert(bookmarks, items, converter.Options{
+	// rules are validated by parseFlags
+	tagRules, err := converter.ParseTagRules(cfg.TagRules)
+	if err != nil {
+		return fmt.Errorf("parsing tag rules: %w", err)
+	}
+	scoreRules, err := converter.ParseScoreRules(cfg.ScoreRules)
+	if err != nil {
+		return fmt.Errorf("parsing score rules: %w", err)
+	}
+	var transformer converter.Transformer
+	var script *transform.Script
+	if cfg.Transform != "" {
+		if script, err = transform.Load(cfg.Transform); err != nil {
+			return err
+		}
+		transformer = script
+	}
+	export, dedupedCount, err := conv.Convert(bookmarks, items, converter.Options{
 		Tags:         cfg.Tags,
+		TagRules:     tagRules,
+		TypeTags:     cfg.TypeTags,
+		StripPrefix:  cfg.StripPrefix,
+		DomainTags:   cfg.DomainTags,
+		IDTags:       cfg.IDTags,
+		Overrides:    overrides,
+		ScoreRules:   scoreRules,
 		NoteTemplate: cfg.NoteTemplate,
+		SummaryTmpl:  cfg.SummaryTmpl,
+		TitleTmpl:    cfg.TitleTmpl,
+		Lists:        cfg.Lists,
+		CommentText:  cfg.CommentText,
+		IncludeText:  cfg.IncludeText,
+		TextPosts:    cfg.TextPosts,
+		Highlights:   cfg.Highlights,
+		Archive:      cfg.Archive,
+		FavouriteAt:  cfg.FavouriteAt,
+		Transform:    transformer,
 	})
+	if err != nil {
+		return fmt.Errorf("converting bookmarks: %w", err)
+	}
+	if script != nil {
+		stats.filtered += script.Dropped()
+	}
 	stats.deduped = dedupedCount
 	stats.converted = len(export.Bookmarks)
 
+	if !stats.reconciled() {
+		err := fmt.Errorf("%d processed bookmark(s) but %d converted + %d filtered + %d skipped + %d deduplicated, some bookmarks went missing",
+			stats.afterLimit, stats.converted, stats.filtered, stats.skipped, stats.deduped)
+		if cfg.Strict {
+			return fmt.Errorf("reconciling counts: %w", err)
+		}
+		log.Warn("reconciling counts: %v", err)
+	}
+
+	switch cfg.Compare {
+	case "diff":
+		return runDiff(ctx, cfg, log, export.Bookmarks, stats)
+	case "verify":
+		return runVerify(ctx, cfg, log, export.Bookmarks, stats)
+	}
 	// sync mode: push directly to Karakeep API
 	if cfg.Sync {
-		if cfg.OutputPath != "" {
-			fmt.Fprintf(os.Stderr, "Warning: --output is ignored in sync mode\n")
-		}
+		return runSync(ctx, cfg, log, board, export.Bookmarks, stats)
+	}
+	if cfg.Target == targetWebhook {
+		return runWebhook(ctx, cfg, log, export.Bookmarks, stats)
+	}
 
-		// setup progress indicator for sync (same condition as fetch)
-		var progressSync *logger.TTYProgresser
-		if !cfg.Verbose && logger.IsStderrTTY() {
-			progressSync = logger.NewProgresser(os.Stderr, "Syncing: %d/%d")
+	// default mode: write to file/stdout
+	exportOpts := converter.ExportOptions{Path: cfg.OutputPath, GroupBy: cfg.GroupBy, Schema: cfg.SchemaVer}
+	if err := writeOutput(cfg.OutputFormat, exportOpts, export); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	printSummary(*stats)
+	return nil
+}
+
+// runResume syncs the bookmarks left over in the checkpoint of a previous interrupted or failed sync.
+// The HN fetch and conversion are skipped since the checkpoint holds already-converted bookmarks.
+func runResume(ctx context.Context, cfg *Config, stats *stats) error {
+	if cfg.StateDir == "" {
+		return fmt.Errorf("resuming sync: state directory unavailable")
+	}
+	cp, err := loadCheckpoint(checkpointPath(cfg.StateDir, cfg.APIBaseURL), cfg.APIBaseURL)
+	if err != nil {
+		return fmt.Errorf("resuming sync: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Resuming sync of %d bookmark(s) from checkpoint saved %s\n",
+		len(cp.Bookmarks), time.Unix(cp.SavedAt, 0).Format(time.DateTime))
+
+	// the checkpoint is the whole pipeline input, so all pipeline stages have the same count
+	stats.resumed = true
+	stats.found = len(cp.Bookmarks)
+	stats.afterFilter = stats.found
+	stats.afterLimit = stats.found
+	stats.converted = stats.found
+
+	if err := preflight(ctx, cfg); err != nil {
+		return err
+	}
+	board := newStatusBoard(cfg)
+	if board != nil {
+		defer board.Close()
+	}
+	log, closeLog, err := newLogger(cfg, board)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	return runSync(ctx, cfg, log, board, cp.Bookmarks, stats)
+}
+
+// preflight checks Karakeep API connectivity and write access before any work is done.
+func preflight(ctx context.Context, cfg *Config) error {
+	karakeepClient := karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey, cfg.karakeepOptions()...)
+
+	phaser := newPhaser(cfg.Verbose)
+
+	phase := phaser.Start("Checking Karakeep API connectivity")
+	user, err := karakeepClient.CheckConnectivity(ctx)
+	if err != nil {
+		phase.Fail()
+		return fmt.Errorf("karakeep API check failed: %w", err)
+	}
+	phase.Done("ok (user: %s)", user)
+
+	// an older server fails only some requests, so warn rather than refuse to run
+	if ok, err := karakeepClient.CheckCompatibility(ctx); err == nil && !ok {
+		fmt.Fprintf(os.Stderr, "Warning: the Karakeep server lacks endpoints of %s, which hnkeep is built against; "+
+			"requests may fail until it is updated\n", karakeep.SupportedVersion)
+	}
+
+	// catch read-only keys before any bookmark is pushed, not halfway through
+	phase = phaser.Start("Checking Karakeep API write access")
+	if err := karakeepClient.CheckWriteAccess(ctx); err != nil {
+		phase.Fail()
+		return fmt.Errorf("karakeep API write check failed: %w", err)
+	}
+	phase.Done("ok")
+	return nil
+}
+
+// runSync pushes the converted bookmarks to Karakeep (or prints the plan in dry-run mode).
+// Only one sync per Karakeep server may run at a time, guarded by a lockfile in the state dir.
+// On interruption or failure, the unsynced bookmarks are saved to a checkpoint for -resume.
+// With a status board, the pre-fetch, sync progress, and result counters are shown as its lines.
+func runSync(ctx context.Context, cfg *Config, log logger.Logger, board *logger.StatusBoard,
+	bookmarks []converter.Bookmark, stats *stats,
+) error {
+	// refuse concurrent syncs to the same server (dry run is read-only, so no lock needed)
+	if !cfg.DryRun && cfg.StateDir != "" {
+		lock, err := acquireLock(cfg.StateDir, cfg.APIBaseURL)
+		if err != nil {
+			return fmt.Errorf("locking sync: %w", err)
 		}
+		defer func() { _ = lock.release() }() // best-effort, a stale lock is detected by PID
+	}
 
-		// add logger to the existing client (created during connectivity check)
-		karakeepClient = karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey,
-			karakeep.WithTimeout(cfg.APITimeout),
-			karakeep.WithLogger(log),
-		)
+	syncLimiter := newLimiter(cfg.SyncWorkers, log, "sync")
+	clientOpts := append(cfg.karakeepOptions(),
+		karakeep.WithLogger(log),
+		karakeep.WithRateLimitHook(syncLimiter.Throttle),
+		karakeep.WithListConcurrency(listRanges(cfg)),
+	)
 
-		// pre-fetch existing bookmarks for client-side deduplication
-		var existingBookmarks map[string]karakeep.ExistingBookmark
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "Pre-fetching existing bookmarks... ")
+	// pre-fetch existing bookmarks for client-side deduplication
+	var existingBookmarks map[string]karakeep.ExistingBookmark
+	var karakeepClient *karakeep.Client
+	if cfg.DedupeMode != dedupePrefetch {
+		karakeepClient = karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey, clientOpts...)
+	} else {
+		var phase *logger.Phase
+		var prefetchLine *logger.StatusLine
+		prefetchStart := time.Now()
+		if board != nil {
+			prefetchLine = board.Line()
+			prefetchLine.Set("Pre-fetching existing bookmarks...")
+			clientOpts = append(clientOpts, karakeep.WithListProgress(func(page, listed int) {
+				prefetchLine.Set("Pre-fetching existing bookmarks... page %d, found %d", page, listed)
+			}))
+		} else {
+			phase = newPhaser(cfg.Verbose).Start("Pre-fetching existing bookmarks")
 		}
-		existingBookmarks, err = karakeepClient.ListBookmarks(ctx)
-		if err != nil {
-			if cfg.Verbose {
-				fmt.Fprintf(os.Stderr, "failed\n")
+		karakeepClient = karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey, clientOpts...)
+		var err error
+		if existingBookmarks, err = prefetch(ctx, cfg, log, karakeepClient); err != nil {
+			if board != nil {
+				prefetchLine.Set("Pre-fetching existing bookmarks... failed")
+			} else {
+				phase.Fail()
 			}
 			return fmt.Errorf("pre-fetching bookmarks: %w", err)
 		}
 		stats.prefetched = len(existingBookmarks)
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "found %d\n", stats.prefetched)
+		if board != nil {
+			prefetchLine.Set("Pre-fetching existing bookmarks... found %d (%.2fs)",
+				stats.prefetched, time.Since(prefetchStart).Seconds())
+		} else {
+			phase.Done("found %d", stats.prefetched)
 		}
+	}
 
-		syncOpts := []syncer.Option{
-			syncer.WithConcurrency(cfg.Concurrency),
-			syncer.WithLogger(log),
-			syncer.WithExistingBookmarks(existingBookmarks),
-		}
-		if progressSync != nil {
-			syncOpts = append(syncOpts, syncer.WithProgress(progressSync))
+	// load the sync state recording what was pushed to this server (see hnkeep state)
+	var state *syncer.State
+	if cfg.TwoWay && cfg.StateDir == "" {
+		return fmt.Errorf("two-way sync: state directory unavailable")
+	}
+	if cfg.StateDir != "" {
+		var err error
+		if state, err = syncer.LoadState(syncStatePath(cfg.StateDir, cfg.APIBaseURL)); err != nil {
+			return fmt.Errorf("loading sync state: %w", err)
 		}
-		sync := syncer.New(karakeepClient, syncOpts...)
+		state.Pull(existingBookmarks) // pull the current notes/tags of tracked bookmarks
+	}
+
+	target := syncer.NewKarakeepTarget(karakeepClient, existingBookmarks) // nil for none: all new
+	if cfg.DedupeMode == dedupeSearch {
+		target = syncer.NewKarakeepSearchTarget(karakeepClient)
+	}
+
+	// track successfully synced URLs so the rest can be checkpointed
+	synced := make(map[string]bool, len(bookmarks))
+	var counters *logger.StatusLine
+	counts := make(map[syncer.SyncStatus]int)
+	syncOpts := []syncer.Option{
+		syncer.WithLimiter(syncLimiter),
+		syncer.WithLogger(log),
+		syncer.WithMaxFailures(cfg.MaxFailures.of(len(bookmarks))),
+		syncer.WithOnResult(func(bm converter.Bookmark, status syncer.SyncStatus, err error) {
+			if status != syncer.SyncFailed {
+				synced[bm.Content.URL] = true
+			} else {
+				stats.failures = append(stats.failures, syncFailure(bm, "sync", err))
+			}
+			if counters != nil {
+				counts[status]++
+				setCounters(counters, counts)
+			}
+		}),
+	}
 
-		stats.syncStart = time.Now()
-		syncStatus := sync.Sync(ctx, export.Bookmarks)
-		stats.syncEnd = time.Now()
-		if progressSync != nil {
-			progressSync.Clear()
+	if state != nil {
+		syncOpts = append(syncOpts, syncer.WithState(state))
+	}
+	if cfg.TwoWay {
+		syncOpts = append(syncOpts, syncer.WithTwoWay())
+	}
+	if cfg.Interactive {
+		prompter := newConflictPrompter(os.Stdin, os.Stderr)
+		syncOpts = append(syncOpts, syncer.WithConflictResolver(prompter.resolve))
+	}
+	if len(cfg.RemoveTags) > 0 {
+		syncOpts = append(syncOpts, syncer.WithRemoveTags(cfg.RemoveTags))
+	}
+	if cfg.UpdateTitles {
+		syncOpts = append(syncOpts, syncer.WithUpdateTitles())
+	}
+	if cfg.TriggerCrawl {
+		syncOpts = append(syncOpts, syncer.WithTriggerCrawl())
+	}
+	if cfg.BatchCreate > 0 {
+		syncOpts = append(syncOpts, syncer.WithBatchCreate(cfg.BatchCreate))
+	}
+	syncOpts = append(syncOpts, syncer.WithNoteStrategy(syncer.NoteStrategy(cfg.NoteStrategy)))
+
+	// sync dry run: print what would happen without any writes
+	if cfg.DryRun {
+		plan := syncer.New(target, syncOpts...).Plan(ctx, bookmarks)
+		printSyncPlan(*stats, plan)
+		return nil
+	}
+
+	// setup progress indicator for sync (same condition as fetch)
+	// no progress display in interactive mode, it would overwrite the prompts
+	progressSync := newStageProgress(board, cfg.Verbose || cfg.Interactive, "Syncing: %d/%d")
+	if progressSync != nil {
+		syncOpts = append(syncOpts, syncer.WithProgress(progressSync))
+	}
+	if board != nil {
+		counters = board.Line()
+		setCounters(counters, counts)
+	}
+	sync := syncer.New(target, syncOpts...)
+
+	stats.syncStart = time.Now()
+	syncStatus := sync.Sync(ctx, bookmarks)
+	stats.syncEnd = time.Now()
+	if progressSync != nil {
+		progressSync.Clear()
+	}
+	if board != nil {
+		board.Close() // keep the final board above the summary
+	}
+
+	stats.syncCreated = syncStatus[syncer.SyncCreated]
+	stats.syncUpdated = syncStatus[syncer.SyncUpdated]
+	stats.syncSkipped = syncStatus[syncer.SyncSkipped]
+	stats.syncFailed = syncStatus[syncer.SyncFailed]
+
+	printSyncSummary(*stats)
+	updateCheckpoint(cfg, bookmarks, synced)
+	// the cached index no longer lists what this sync created or changed
+	if stats.syncCreated+stats.syncUpdated > 0 {
+		invalidateIndexCache(cfg.CacheDir, cfg.APIBaseURL)
+	}
+	if state != nil {
+		if err := state.Save(syncStatePath(cfg.StateDir, cfg.APIBaseURL)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: saving sync state: %v\n", err)
 		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err() // interrupted, unsynced bookmarks are checkpointed
+	}
+
+	// return error for non-zero exit code (details already logged inline)
+	return failureError(cfg.MaxFailures, len(bookmarks), stats.syncFailed, len(synced), "sync")
+}
 
-		stats.syncCreated = syncStatus[syncer.SyncCreated]
-		stats.syncUpdated = syncStatus[syncer.SyncUpdated]
-		stats.syncSkipped = syncStatus[syncer.SyncSkipped]
-		stats.syncFailed = syncStatus[syncer.SyncFailed]
+// setCounters shows the sync result counts on the status board line.
+func setCounters(line *logger.StatusLine, counts map[syncer.SyncStatus]int) {
+	line.Set("Created: %d  Updated: %d  Skipped: %d  Failed: %d", counts[syncer.SyncCreated],
+		counts[syncer.SyncUpdated], counts[syncer.SyncSkipped], counts[syncer.SyncFailed])
+}
 
-		printSyncSummary(stats)
+// listRanges returns the number of ranges to list the Karakeep library in (see karakeep.WithListConcurrency),
+// one per worker with -parallel-prefetch, else 1 for a single walk.
+func listRanges(cfg *Config) int {
+	if cfg.ParallelList {
+		return cfg.SyncWorkers
+	}
+	return 1
+}
 
-		// return error for non-zero exit code (details already logged inline)
-		if stats.syncFailed > 0 {
-			return fmt.Errorf("%d bookmark(s) failed to sync", stats.syncFailed)
+// prefetch lists the existing Karakeep bookmarks by URL: the ones with -prefetch-tag, if given, or else all.
+// Bookmarks without the tag are then only found when creating them, where Karakeep returns the existing one
+// of a link (see syncer.NewKarakeepTarget). With -karakeep-cache, a listing cached by an earlier run within
+// the window is used instead, and a new listing is cached.
+func prefetch(ctx context.Context, cfg *Config, log logger.Logger, client *karakeep.Client,
+) (map[string]karakeep.ExistingBookmark, error) {
+	cachePath := ""
+	if cfg.IndexCache > 0 {
+		cachePath = indexCachePath(cfg.CacheDir, cfg.APIBaseURL)
+		if existing, age, ok := loadIndexCache(cachePath, cfg.APIBaseURL, cfg.PrefetchTag, cfg.IndexCache); ok {
+			log.Debug("using the Karakeep bookmark index cached %s ago", age.Round(time.Second))
+			return existing, nil
 		}
+	}
 
-		return nil
+	var existing map[string]karakeep.ExistingBookmark
+	var err error
+	if cfg.PrefetchTag == "" {
+		existing, err = client.ListBookmarks(ctx)
+	} else {
+		existing, err = client.ListTaggedBookmarks(ctx, cfg.PrefetchTag)
+		if errors.Is(err, karakeep.ErrTagNotFound) {
+			existing, err = map[string]karakeep.ExistingBookmark{}, nil // nothing synced with the tag yet
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cachePath != "" {
+		if err := saveIndexCache(cachePath, cfg.APIBaseURL, cfg.PrefetchTag, existing); err != nil {
+			log.Warn("caching the Karakeep bookmark index: %v", err)
+		}
 	}
+	return existing, nil
+}
 
-	// default mode: write to file/stdout
-	if err := writeOutput(cfg.OutputPath, export); err != nil {
-		return fmt.Errorf("writing output: %w", err)
+// syncStatePath returns the sync state file path for the given Karakeep API URL.
+func syncStatePath(stateDir, apiURL string) string {
+	return filepath.Join(stateDir, "sync-state-"+serverKey(apiURL)+".json")
+}
+
+// updateCheckpoint saves the bookmarks not in synced to the checkpoint file,
+// or removes the checkpoint if everything was synced. Errors are reported as warnings.
+func updateCheckpoint(cfg *Config, bookmarks []converter.Bookmark, synced map[string]bool) {
+	if cfg.StateDir == "" {
+		return
 	}
+	path := checkpointPath(cfg.StateDir, cfg.APIBaseURL)
 
-	printSummary(stats)
-	return nil
+	var unsynced []converter.Bookmark
+	for _, bm := range bookmarks {
+		if !synced[bm.Content.URL] {
+			unsynced = append(unsynced, bm)
+		}
+	}
+
+	if len(unsynced) == 0 {
+		if err := removeCheckpoint(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: removing checkpoint: %v\n", err)
+		}
+		return
+	}
+	if err := saveCheckpoint(path, cfg.APIBaseURL, unsynced); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saving checkpoint: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\nSaved %d unsynced bookmark(s) to %s\n", len(unsynced), path)
+	fmt.Fprintf(os.Stderr, "Run with -sync -resume to continue.\n")
+}
+
+// newLimiter creates the limiter of parallel requests to one API, starting at n workers.
+// It is lowered when the API answers 429 and raised back while requests succeed, logging each change.
+func newLimiter(n int, log logger.Logger, name string) *adaptive.Limiter {
+	return adaptive.NewLimiter(n, adaptive.WithOnChange(func(limit int) {
+		log.Info("%s concurrency adjusted to %d", name, limit)
+	}))
 }
@@ -3,11 +3,20 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/cron"
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
+	"github.com/akhdanfadh/hnkeep/internal/transform"
 )
 
 var (
@@ -17,35 +26,114 @@ var (
 
 type Config struct {
 	InputPath    string        // Input file path (default: stdin)
+	InputFormat  string        // Input file format: harmonic or idmap
 	OutputPath   string        // Output file path (default: stdout)
+	OutputFormat string        // Output format, a name registered in the converter package
+	GroupBy      string        // Markdown grouping: month or tag
+	SchemaVer    int           // Structure of the Karakeep import file: 1 or 2
+	Lists        []string      // Lists to put all bookmarks in (v2 import file only)
 	Verbose      bool          // Show progress messages during fetch/sync
+	LogLevel     slog.Level    // Minimum level of log messages
+	LogFile      string        // Log file path (default: stderr)
+	NoColor      bool          // Disable colored log output
 	DryRun       bool          // Preview conversion without API calls
 	Before       int64         // Process only bookmarks before this timestamp (0 = all)
 	After        int64         // Process only bookmarks after this timestamp (0 = all)
 	Limit        int           // Process only first N bookmarks (0 = all)
-	Concurrency  int           // Number of concurrent API calls
+	MinScore     int           // Convert only items with at least this HN score (0 = all)
+	Types        []string      // Convert only items of these kinds, e.g., story, ask (empty = all)
+	Excluded     []string      // Drop items linking to these domains or their subdomains
+	ExcludeIDs   string        // File of HN IDs or item URLs to drop before fetching
+	OnlyDomains  []string      // Keep only items linking to these domains or their subdomains (empty = all)
+	Filter       string        // Keep only items matching this filter expression (empty = all)
+	HNWorkers    int           // Number of concurrent HN API calls
+	SyncWorkers  int           // Number of concurrent Karakeep (or webhook) API calls
+	HNRateLimit  float64       // Max HN API requests per second (0 = unlimited)
 	Tags         []string      // Tags to add to all imported bookmarks
+	RemoveTags   []string      // Tags to remove from existing bookmarks during sync
+	TagRules     []string      // Per-domain tag rules as domain=tag
+	TypeTags     bool          // Tag bookmarks by HN item type, e.g., hn:ask
+	StripPrefix  bool          // Strip "Ask HN:"-style title prefixes, tagging the kind instead
+	DomainTags   bool          // Tag bookmarks with the host of their URL as site:<host>
+	IDTags       bool          // Tag bookmarks with the HN ID they were converted from as hnkeep:id=<id>
+	Overrides    string        // Path of the per-item overrides file (tags, note, skip by HN ID)
+	Transform    string        // Path of the Starlark script that rewrites or drops each bookmark (empty = none)
+	ScoreRules   []string      // Score tag rules as score=tag
 	NoteTemplate string        // Template for note field in bookmarks
+	SummaryTmpl  string        // Template for summary field in bookmarks (empty = no summary)
+	TitleTmpl    string        // Template for bookmark titles (empty = the HN title)
+	CommentText  bool          // Include the text of bookmarked comments in the note
+	IncludeText  bool          // Append the text of text posts like Ask HN to the note
+	TextPosts    bool          // Convert text posts to text bookmarks instead of links
+	TopComments  int           // Number of top-level HN comments to quote in the note (0 = none)
+	Highlights   bool          // Add the top comments as highlights of new bookmarks instead of quoting them
+	Archive      bool          // Mark imported bookmarks as archived
+	FavouriteAt  int           // Favourite bookmarks with HN score above this (0 = disabled)
+	DeadItems    string        // How to handle deleted/dead HN items: skip, hn-link, or wayback
+	Redirects    bool          // Bookmark the final destination of redirecting story URLs
+	CheckLinks   string        // What to do with dead story URLs: note or replace with a snapshot (empty = don't check)
+	Strict       bool          // Fail if the bookmark counts don't reconcile after conversion
 	CacheDir     string        // HN API responses cache directory path
 	ClearCache   bool          // Clear the cache before running
+	CacheTTL     time.Duration // Refetch cache entries older than this (0 = never)
+	GzipCache    bool          // Gzip new cache entries
+	StateDir     string        // Directory for persistent state such as sync checkpoints
+	ConfigPath   string        // Config file path
+	Profile      string        // Config file profile in use (empty = none)
 	Sync         bool          // Export directly using Karakeep's API
+	Watch        bool          // Keep syncing new bookmarks whenever the input file changes
+	Interval     time.Duration // How often watch mode checks the input file
+	Schedule     string        // Cron expression of when watch mode checks the input file instead of Interval
+	Target       string        // Service to push to instead of writing output: webhook (karakeep sets Sync)
+	WebhookURL   string        // Endpoint to post bookmarks to with -target webhook
+	WebhookHdrs  []string      // Extra webhook request headers as "Name: value"
 	APIBaseURL   string        // Karakeep API URL for direct sync
 	APIKey       string        // Karakeep API key for direct sync
-	APITimeout   time.Duration // Karakeep API request timeout duration
+	Resume       bool          // Resume sync from the last checkpoint
+	RetryReport  string        // Failures report whose bookmarks to process, skipping the rest (see hnkeep retry)
+	Compare      string        // Compare the converted bookmarks with Karakeep instead of writing them: diff or verify
+	MaxFailures  failureLimit  // Failures tolerated before aborting a sync (unset = never abort, but exit non-zero)
+	TwoWay       bool          // Respect note/tag edits made in Karakeep using a local state file
+	UpdateTitles bool          // Update differing titles of existing bookmarks to the HN title
+	TriggerCrawl bool          // Ask Karakeep to crawl created link bookmarks right away
+	BatchCreate  int           // Create new bookmarks in batches of this size through Karakeep's tRPC API (0 = one by one)
+	NoteStrategy string        // How to update notes of existing bookmarks: merge, replace, keep-existing, or skip
+	PrefetchTag  string        // Pre-fetch only the Karakeep bookmarks with this tag (empty = all)
+	DedupeMode   string        // How to find existing bookmarks: prefetch, search, or none
+	IndexCache   time.Duration // Reuse the Karakeep bookmark index listed within this window (0 = off)
+	ParallelList bool          // Pre-fetch the Karakeep library as concurrent ranges of creation time
+	Interactive  bool          // Prompt how to resolve each sync conflict
+
+	connection // Connection to the Karakeep API, with the proxy also used for the HN API
 }
 
-// parseFlags parses command-line flags and returns a Config struct.
-func parseFlags() (*Config, error) {
+// parseFlags parses the given command-line arguments and returns a Config struct.
+func parseFlags(args []string) (*Config, error) {
 	showVersion := flag.Bool("version", false, "Show version information and exit")
 	flag.BoolVar(showVersion, "v", false, "alias for -version")
 
 	inputPath := flag.String("input", "", "Input file path, e.g., harmonic-export.txt (default to stdin)")
 	flag.StringVar(inputPath, "i", "", "alias for -input (default stdin)")
+	inputFormat := flag.String("input-format", formatHarmonic,
+		"Input format: harmonic (Harmonic-HN export or shared preferences XML), idmap (JSON object of item ID "+
+			"to ISO timestamp), idlist (HN item ID or URL per line), materialistic (Materialistic export or favorite table as CSV), or glider or hews "+
+			"(bookmarks shared by these apps)")
+	flag.StringVar(inputFormat, "source", formatHarmonic, "alias for -input-format")
 
 	outputPath := flag.String("output", "", "Output file path, e.g., karakeep-import.json (default stdout)")
 	flag.StringVar(outputPath, "o", "", "alias for -output (default stdout)")
+	outputFormat := flag.String("format", converter.FormatKarakeepJSON, "Output format: "+formatUsage())
+	groupBy := flag.String("group-by", converter.GroupByMonth, "Grouping of markdown output: month or tag")
+	schemaVersion := flag.Int("schema-version", converter.SchemaV1,
+		"Structure of the Karakeep import file: 1 (tag names, any Karakeep release) or 2 (tag objects and lists)")
+	var lists stringList
+	flag.Var(&lists, "list", "Put all bookmarks in the Karakeep list of this name, with -schema-version 2 (repeatable)")
 
 	verbose := flag.Bool("verbose", false, "Show progress messages during fetch/sync")
+	logLevel := flag.String("log-level", "",
+		"Minimum log level: debug, info, warn, or error (default: info with -verbose or -log-file, else warn)")
+	noColor := flag.Bool("no-color", false, "Disable colored log output (also via the NO_COLOR env var)")
+	logFile := flag.String("log-file", "", "Write log messages to this file (rotated at 10 MiB) instead of stderr")
 
 	dryRun := flag.Bool("dry-run", false, "Preview conversion without API calls")
 
@@ -53,36 +141,165 @@ func parseFlags() (*Config, error) {
 	after := flag.String("after", "", "Only include Harmonic bookmarks after this timestamp")
 	limit := flag.Int("limit", 0, "Number of bookmarks to process (0 = all)")
 	flag.IntVar(limit, "n", 0, "alias for -limit")
+	excludeIDs := flag.String("exclude-ids", "",
+		"File of HN IDs or item URLs to leave out before fetching, one per line (# for comments)")
+	excludeDomains := flag.String("exclude-domains", "",
+		"Comma-separated domains whose items to leave out (with subdomains), applied after fetching, e.g., reddit.com,twitter.com")
+	onlyDomains := flag.String("only-domains", "",
+		"Comma-separated domains whose items to keep, dropping all others (with subdomains), applied after fetching, e.g., github.com,arxiv.org")
+	minScore := flag.Int("min-score", 0,
+		"Convert only items with at least this HN score, applied after fetching (0 = all)")
+	types := flag.String("types", "",
+		"Comma-separated list of item kinds to convert, applied after fetching: "+
+			strings.Join(converter.ItemKinds, ", ")+" (default all)")
+
+	filterExpr := flag.String("filter", "",
+		"Convert only items matching this expression, applied after fetching, e.g., "+
+			"'score > 100 && type == \"story\" && !contains(domain, \"medium.com\")' (see README)")
 
-	concurrency := flag.Int("concurrency", 5, "Number of concurrent API calls.")
+	concurrency := flag.Int("concurrency", 5, "Number of concurrent API calls, for both HN and Karakeep.")
 	flag.IntVar(concurrency, "c", 5, "alias for -concurrency")
+	hnConcurrency := flag.Int("hn-concurrency", 0, "Number of concurrent HN API calls (0 = -concurrency)")
+	kkConcurrency := flag.Int("karakeep-concurrency", 0,
+		"Number of concurrent Karakeep or webhook API calls (0 = -concurrency)")
+	hnRPS := flag.Float64("hn-rps", 10, "Max HN API requests per second across all workers (0 = unlimited)")
 
 	defaultTags := "src:hackernews,hnkeep:" + time.Now().Format("20060102")
 	tags := flag.String("tags", defaultTags, "Comma-separated list of tags to add to all imported bookmarks")
 	flag.StringVar(tags, "t", defaultTags, "alias for -tags")
+	removeTags := flag.String("remove-tags", "",
+		"Comma-separated list of tags to remove from existing bookmarks during sync, e.g., old tags of previous imports")
+	var tagRules stringList
+	flag.Var(&tagRules, "tag-rule",
+		"Tag bookmarks of a domain and its subdomains as domain=tag, e.g., github.com=code (repeatable)")
+	var scoreRules stringList
+	flag.Var(&scoreRules, "tag-if-score",
+		"Tag bookmarks with an HN score of at least the given one as score=tag, e.g., 500=hn:popular (repeatable)")
+	overrides := flag.String("overrides", "",
+		"JSON file (not YAML) mapping HN IDs to extra tags, a custom note, or skip, e.g., {\"3742902\": {\"tags\": [\"classic\"]}}")
+	transformScript := flag.String("transform", "",
+		"Starlark script whose transform(bookmark, item) function rewrites or drops each bookmark, e.g., transform.star (see README)")
+	domainTags := flag.Bool("domain-tags", false, "Tag bookmarks with the host of their URL, e.g., site:example.com")
+	idTags := flag.Bool("id-tags", false,
+		"Tag bookmarks with the HN ID they were converted from, e.g., hnkeep:id=3742902, so diff and verify find them after URL changes")
+	stripPrefix := flag.Bool("strip-title-prefix", false,
+		"Strip \"Ask HN:\", \"Show HN:\", \"Tell HN:\", and \"Launch HN:\" from titles and tag the kind instead, e.g., hn:ask")
+	typeTags := flag.Bool("type-tags", false,
+		"Tag bookmarks by HN item type: hn:story, hn:ask, hn:show, hn:tell, hn:launch, hn:job, hn:poll, hn:comment")
 
 	noteTemplate := flag.String("note-template", "{{smart_url}}",
 		"Template for note field in bookmarks (empty = no note). "+
 			"Variables: {{smart_url}}, {{item_url}}, {{hn_url}}, "+
-			"{{id}}, {{title}}, {{author}}, {{date}}")
+			"{{id}}, {{title}}, {{author}}, {{date}}, {{year}}, {{score}}, {{comments}}, {{type}}, {{domain}}, {{text}}")
+	summaryTmpl := flag.String("summary-template", "",
+		"Template for the summary field shown on Karakeep cards apart from the note, with the note's variables, "+
+			`e.g., "{{score}} points, {{comments}} comments on HN ({{date}})" (sync only)`)
+	titleTmpl := flag.String("title-template", "",
+		`Template for bookmark titles with the note's variables, e.g., "{{title}} ({{score}}↑)" (default the HN title)`)
+	notePreset := flag.String("note-preset", "",
+		"Named note template instead of -note-template: minimal, discussion-only, full, stats (see hnkeep templates list)")
+	includeText := flag.Bool("include-text", false,
+		"Append the text of text posts like Ask HN to the note (same as {{text}} at the end of the template)")
+	textPosts := flag.Bool("text-posts", false,
+		"Convert text posts like Ask HN to Karakeep text bookmarks of the post instead of links to the discussion")
+	commentText := flag.Bool("comment-text", false,
+		"Include the text of bookmarked comments in the note, below the comment link")
+	topComments := flag.Int("comments-in-note", 0,
+		"Fetch the top N top-level HN comments of each story and quote them in the note (0 = none)")
+	highlightComments := flag.Int("comment-highlights", 0,
+		"Fetch the top N top-level HN comments of each story and add them as highlights of new bookmarks (sync only)")
+
+	resolveRedirects := flag.Bool("resolve-redirects", false,
+		"Follow the redirects of story URLs, e.g., URL shorteners or moved pages, and bookmark the final destination")
+	checkLinks := flag.String("check-links", "",
+		"Check story URLs for dead links (404, 410, or gone domain) and add their Wayback Machine snapshot to the note (note) or bookmark it instead (replace)")
+	deadItems := flag.String("dead-items", string(converter.DeadItemsSkip),
+		"Deleted/dead HN items: skip, hn-link (bookmark the HN discussion), or wayback (Wayback Machine snapshot of it)")
+	strict := flag.Bool("strict", false,
+		"Fail instead of warning if converted + filtered + skipped + deduplicated bookmarks don't add up to the processed count")
+	archive := flag.Bool("archive", false, "Mark imported bookmarks as archived in Karakeep")
+	favouriteAt := flag.Int("favourite-above-score", 0,
+		"Mark bookmarks as favourited if the HN score exceeds this (0 = disabled)")
 
 	defaultCacheDir := getDefaultCacheDir()
 	cacheDir := flag.String("cache-dir", defaultCacheDir, "HN API responses cache directory path")
 	noCache := flag.Bool("no-cache", false, "Disable caching of HN API responses")
 	clearCache := flag.Bool("clear-cache", false, "Clear the cache before running")
+	gzipCache := flag.Bool("cache-compress", false,
+		"Gzip new cache entries to save disk space (existing entries are read either way)")
+	cacheTTL := flag.String("cache-ttl", "", "Refetch cache entries older than this, e.g., 30d or 12h (default never)")
 
 	sync := flag.Bool("sync", false, "Enable sync mode (push to Karakeep API directly)")
+	target := flag.String("target", "",
+		"Push bookmarks to a service instead of writing output: karakeep (same as -sync) or webhook")
+	webhookURL := flag.String("webhook-url", "", "Endpoint to POST each bookmark to as JSON with -target webhook")
+	var webhookHeaders stringList
+	flag.Var(&webhookHeaders, "webhook-header",
+		`Header sent with webhook requests as "Name: value", e.g., for authentication (repeatable)`)
+	watch := flag.Bool("watch", false, "Keep running and sync new bookmarks whenever the input file changes")
+	interval := flag.Duration("interval", time.Hour, "How often -watch checks the input file for changes")
+	schedule := flag.String("schedule", "",
+		`Check the input file on this cron schedule instead of every -interval, e.g., "0 3 * * *" (with -watch)`)
 	apiBaseURL := flag.String("api-url", "", "Karakeep API URL (env: KARAKEEP_API_URL)")
-	apiKey := flag.String("api-key", "", "Karakeep API key (env: KARAKEEP_API_KEY)")
-	apiTimeout := flag.Duration("api-timeout", 30*time.Second, "Karakeep API request timeout duration")
+	apiKey := flag.String("api-key", "", "Karakeep API key (env: KARAKEEP_API_KEY, or stored with hnkeep auth login)")
+	conn := addConnFlags(flag.CommandLine)
+	resume := flag.Bool("resume", false, "Resume an interrupted or failed sync from its checkpoint")
+	retryReport := flag.String("report", "",
+		"Process only the bookmarks listed in this failures report of an earlier run (see hnkeep retry)")
+	maxFailures := flag.String("max-failures", "",
+		"Abort the sync once more than N bookmarks, or N% of them, failed; fewer failures exit with 0")
+	interactive := flag.Bool("interactive-conflicts", false,
+		"Ask whether to merge, replace, or skip each existing bookmark with a different note or timestamp")
+	noteStrategy := flag.String("note-strategy", string(syncer.NotesMerge),
+		"Notes of existing bookmarks: merge (append), replace, keep-existing (only fill in missing notes), or skip (requires -sync)")
+	prefetchTag := flag.String("prefetch-tag", "",
+		"Pre-fetch only the existing Karakeep bookmarks with this tag, e.g., src:hackernews, instead of the whole library (sync, diff, verify)")
+	dedupeMode := flag.String("dedupe-mode", dedupePrefetch,
+		"Find existing bookmarks by prefetch (list the library once), search (one url: query per bookmark), or none (requires -sync)")
+	indexCache := flag.Duration("karakeep-cache", 0,
+		"Reuse the existing Karakeep bookmarks listed by a run within this window, e.g., 10m, instead of listing them again (0 = off)")
+	parallelList := flag.Bool("parallel-prefetch", false,
+		"Pre-fetch the Karakeep library as -karakeep-concurrency ranges of creation time at once instead of page by page (relies on Karakeep's undocumented cursor format)")
+	updateTitles := flag.Bool("update-titles", false,
+		"Update the title of existing bookmarks that differs from the HN title, e.g., a generic page title crawled by Karakeep (requires -sync)")
+	triggerCrawl := flag.Bool("trigger-crawl", false,
+		"Ask Karakeep to crawl each created link bookmark right away, like its refresh button (requires -sync)")
+	batchCreate := flag.Int("batch-create", 0,
+		"Create new bookmarks in batches of this size through Karakeep's tRPC API instead of one request each, e.g., 50 (0 = off, requires -sync)")
+	twoWay := flag.Bool("two-way", false,
+		"Track synced notes/tags in a state file and don't re-push ones removed in Karakeep")
+
+	profile := flag.String("profile", "", "Use the flag values of this named profile in the config file")
+	configPath := flag.String("config", getDefaultConfigPath(), "Config file path")
 
-	flag.Parse()
+	flag.Usage = usage
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return nil, err
+	}
+	// checks of flags given on the command line ignore the ones set by the profile, which may apply to
+	// other modes, e.g., -interval for -watch
+	cmdLine := givenFlags(flag.CommandLine)
+	isFlagSet := func(name string) bool { return cmdLine[name] }
+	if *profile != "" {
+		if err := applyProfile(flag.CommandLine, *configPath, *profile, false); err != nil {
+			return nil, err
+		}
+	}
 
 	if *showVersion {
 		_, _ = fmt.Fprintf(os.Stdout, "hnkeep %s, build %s\n", Version, Commit)
 		os.Exit(0)
 	}
 
+	// -concurrency applies to both APIs unless overridden per API
+	hnWorkers, syncWorkers := *hnConcurrency, *kkConcurrency
+	if hnWorkers == 0 {
+		hnWorkers = *concurrency
+	}
+	if syncWorkers == 0 {
+		syncWorkers = *concurrency
+	}
+
 	// parse date filters
 	var beforeTS, afterTS int64
 	if *before != "" {
@@ -100,13 +317,236 @@ func parseFlags() (*Config, error) {
 		afterTS = t.Unix()
 	}
 
-	// parse tags
-	var tagsSlice []string
-	if *tags != "" {
-		for split := range strings.SplitSeq(*tags, ",") {
-			if tag := strings.TrimSpace(split); tag != "" {
-				tagsSlice = append(tagsSlice, tag)
+	if *minScore < 0 {
+		return nil, fmt.Errorf("--min-score must not be negative")
+	}
+	if *favouriteAt < 0 {
+		return nil, fmt.Errorf("--favourite-above-score must not be negative (0 = disabled)")
+	}
+	if *topComments < 0 || *highlightComments < 0 {
+		return nil, fmt.Errorf("--comments-in-note and --comment-highlights must not be negative")
+	}
+	if *highlightComments > 0 {
+		if *topComments > 0 {
+			return nil, fmt.Errorf("--comments-in-note and --comment-highlights are mutually exclusive")
+		}
+		if !*sync {
+			return nil, fmt.Errorf("--comment-highlights requires --sync")
+		}
+		*topComments = *highlightComments
+	}
+	excludedDomains, err := parseDomains("exclude-domains", *excludeDomains)
+	if err != nil {
+		return nil, err
+	}
+	onlyDomainsSlice, err := parseDomains("only-domains", *onlyDomains)
+	if err != nil {
+		return nil, err
+	}
+	typesSlice := splitTags(*types)
+	for _, kind := range typesSlice {
+		if !slices.Contains(converter.ItemKinds, kind) {
+			return nil, fmt.Errorf("unknown --types kind %q (supported: %s)", kind, strings.Join(converter.ItemKinds, ", "))
+		}
+	}
+	if *filterExpr != "" {
+		if _, err := converter.ParseFilterExpr(*filterExpr); err != nil {
+			return nil, fmt.Errorf("invalid --filter: %w", err)
+		}
+	}
+	if *hnRPS < 0 {
+		return nil, fmt.Errorf("--hn-rps must not be negative")
+	}
+	if *concurrency < 1 {
+		return nil, fmt.Errorf("--concurrency must be at least 1")
+	}
+	if *hnConcurrency < 0 || *kkConcurrency < 0 {
+		return nil, fmt.Errorf("--hn-concurrency and --karakeep-concurrency must not be negative (0 = --concurrency)")
+	}
+
+	if err := checkInputFormat(*inputFormat); err != nil {
+		return nil, err
+	}
+
+	// resolve push target, -target karakeep being the long form of -sync
+	switch *target {
+	case "":
+	case targetKarakeep:
+		*sync = true
+	case targetWebhook:
+		if *sync {
+			return nil, fmt.Errorf("--target webhook and --sync are mutually exclusive")
+		}
+		if *webhookURL == "" {
+			return nil, fmt.Errorf("--target webhook requires --webhook-url")
+		}
+		if u, err := url.Parse(*webhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid --webhook-url %q, expected an http(s) URL", *webhookURL)
+		}
+		if isFlagSet("format") {
+			return nil, fmt.Errorf("--format cannot be used with --target webhook")
+		}
+	default:
+		return nil, fmt.Errorf("unknown --target %q (supported: %s, %s)", *target, targetKarakeep, targetWebhook)
+	}
+	// watch mode pushes to Karakeep unless another target is given
+	if *watch {
+		if *inputPath == "" {
+			return nil, fmt.Errorf("--watch requires --input, the file to watch")
+		}
+		if *interval <= 0 {
+			return nil, fmt.Errorf("--interval must be positive")
+		}
+		if *schedule != "" {
+			if isFlagSet("interval") {
+				return nil, fmt.Errorf("--schedule and --interval cannot be combined")
 			}
+			if _, err := cron.Parse(*schedule); err != nil {
+				return nil, fmt.Errorf("invalid --schedule: %w", err)
+			}
+		}
+		if *dryRun || *resume || *retryReport != "" {
+			return nil, fmt.Errorf("--watch cannot be combined with --dry-run, --resume, or --report")
+		}
+		if *target != targetWebhook {
+			*sync = true
+		}
+	} else if isFlagSet("interval") || isFlagSet("schedule") {
+		return nil, fmt.Errorf("--interval and --schedule require --watch")
+	}
+	if *target != targetWebhook && (*webhookURL != "" || len(webhookHeaders) > 0) {
+		return nil, fmt.Errorf("--webhook-url and --webhook-header require --target webhook")
+	}
+	if err := checkHeaders("webhook-header", webhookHeaders); err != nil {
+		return nil, err
+	}
+
+	if *resume && *retryReport != "" {
+		return nil, fmt.Errorf("--resume and --report are mutually exclusive")
+	}
+	var failureLimitArg failureLimit
+	if *maxFailures != "" {
+		if !*sync && *target != targetWebhook {
+			return nil, fmt.Errorf("--max-failures requires --sync or --target webhook")
+		}
+		var err error
+		if failureLimitArg, err = parseFailureLimit(*maxFailures); err != nil {
+			return nil, fmt.Errorf("invalid --max-failures: %w", err)
+		}
+	}
+
+	format, ok := converter.LookupFormat(*outputFormat)
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (supported: %s)",
+			*outputFormat, strings.Join(converter.FormatNames(), ", "))
+	}
+	*outputFormat = format.Name // resolve aliases
+	if format.Name != converter.FormatKarakeepJSON && *sync {
+		return nil, fmt.Errorf("--format %s cannot be used with --sync", format.Name)
+	}
+	if format.Name != converter.FormatMarkdown && isFlagSet("group-by") {
+		return nil, fmt.Errorf("--group-by requires --format markdown")
+	}
+	switch {
+	case *schemaVersion != converter.SchemaV1 && *schemaVersion != converter.SchemaV2:
+		return nil, fmt.Errorf("unknown --schema-version %d (supported: %d, %d)", *schemaVersion, converter.SchemaV1, converter.SchemaV2)
+	case isFlagSet("schema-version") && format.Name != converter.FormatKarakeepJSON:
+		return nil, fmt.Errorf("--schema-version requires --format %s", converter.FormatKarakeepJSON)
+	case isFlagSet("schema-version") && *sync:
+		return nil, fmt.Errorf("--schema-version cannot be used with --sync, it only applies to the import file")
+	case len(lists) > 0 && *schemaVersion != converter.SchemaV2:
+		return nil, fmt.Errorf("--list requires --schema-version %d, the older import file has no lists", converter.SchemaV2)
+	}
+	for _, name := range lists {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("--list must not be empty")
+		}
+	}
+	if format.Directory && *outputPath == "" {
+		return nil, fmt.Errorf("--format %s requires --output, the target directory", format.Name)
+	}
+	if format.Name == converter.FormatMarkdown && *groupBy != converter.GroupByMonth && *groupBy != converter.GroupByTag {
+		return nil, fmt.Errorf("unknown --group-by %q (supported: %s, %s)", *groupBy, converter.GroupByMonth, converter.GroupByTag)
+	}
+
+	switch converter.DeadItemsMode(*deadItems) {
+	case converter.DeadItemsSkip, converter.DeadItemsHNLink, converter.DeadItemsWayback:
+	default:
+		return nil, fmt.Errorf("unknown --dead-items mode %q (supported: skip, hn-link, wayback)", *deadItems)
+	}
+	switch converter.DeadLinksMode(*checkLinks) {
+	case "", converter.DeadLinksNote, converter.DeadLinksReplace:
+	default:
+		return nil, fmt.Errorf("unknown --check-links mode %q (supported: note, replace)", *checkLinks)
+	}
+
+	// resolve note preset, which must not be combined with an explicit template
+	if *notePreset != "" {
+		preset, ok := converter.LookupNotePreset(*notePreset)
+		if !ok {
+			return nil, fmt.Errorf("unknown note preset %q (see hnkeep templates list)", *notePreset)
+		}
+		if isFlagSet("note-template") {
+			return nil, fmt.Errorf("--note-preset and --note-template are mutually exclusive")
+		}
+		*noteTemplate = preset.Template
+	}
+
+	// parse tags
+	tagsSlice := splitTags(*tags)
+	removeTagsSlice := splitTags(*removeTags)
+	// bookmarks synced now must carry the tag to be pre-fetched on the next sync
+	if *prefetchTag != "" && !slices.Contains(tagsSlice, *prefetchTag) {
+		return nil, fmt.Errorf("--prefetch-tag %q must be one of --tags", *prefetchTag)
+	}
+	if len(removeTagsSlice) > 0 && !*sync {
+		return nil, fmt.Errorf("--remove-tags requires --sync")
+	}
+	if *transformScript != "" {
+		if _, err := transform.Load(*transformScript); err != nil {
+			return nil, fmt.Errorf("invalid --transform: %w", err)
+		}
+	}
+	if *summaryTmpl != "" && !*sync {
+		return nil, fmt.Errorf("--summary-template requires --sync, Karakeep's import format has no summary")
+	}
+	for _, tag := range removeTagsSlice {
+		if slices.Contains(tagsSlice, tag) {
+			return nil, fmt.Errorf("tag %q is both added (--tags) and removed (--remove-tags)", tag)
+		}
+	}
+
+	rules, err := converter.ParseTagRules(tagRules)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --tag-rule: %w", err)
+	}
+	for _, rule := range rules {
+		if slices.Contains(removeTagsSlice, rule.Tag) {
+			return nil, fmt.Errorf("tag %q is both added (--tag-rule) and removed (--remove-tags)", rule.Tag)
+		}
+	}
+	scoreRulesParsed, err := converter.ParseScoreRules(scoreRules)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --tag-if-score: %w", err)
+	}
+	for _, rule := range scoreRulesParsed {
+		if slices.Contains(removeTagsSlice, rule.Tag) {
+			return nil, fmt.Errorf("tag %q is both added (--tag-if-score) and removed (--remove-tags)", rule.Tag)
+		}
+	}
+
+	// a log file keeps the info messages that would otherwise replace the progress bar on stderr
+	level, err := resolveLogLevel(*logLevel, *verbose || *logFile != "")
+	if err != nil {
+		return nil, err
+	}
+
+	// parse cache ttl
+	var ttl time.Duration
+	if *cacheTTL != "" {
+		var err error
+		if ttl, err = parseTTL(*cacheTTL); err != nil {
+			return nil, fmt.Errorf("parsing -cache-ttl: %w", err)
 		}
 	}
 
@@ -117,43 +557,220 @@ func parseFlags() (*Config, error) {
 	}
 
 	// handle sync env vars
-	resolvedAPIBaseURL := *apiBaseURL
-	if resolvedAPIBaseURL == "" {
-		resolvedAPIBaseURL = os.Getenv("KARAKEEP_API_URL")
+	resolvedAPIBaseURL, resolvedAPIKey := resolveAPI(*apiBaseURL, *apiKey)
+	if *sync {
+		if err := requireAPI("--sync", resolvedAPIBaseURL, resolvedAPIKey); err != nil {
+			return nil, err
+		}
 	}
-	resolvedAPIKey := *apiKey
-	if resolvedAPIKey == "" {
-		resolvedAPIKey = os.Getenv("KARAKEEP_API_KEY")
+	if *resume && !*sync {
+		return nil, fmt.Errorf("--resume requires --sync")
 	}
-	if *sync {
-		if resolvedAPIBaseURL == "" {
-			return nil, fmt.Errorf("--sync requires --api-url or KARAKEEP_API_URL to be set")
+	switch syncer.NoteStrategy(*noteStrategy) {
+	case syncer.NotesMerge, syncer.NotesReplace, syncer.NotesKeepExisting, syncer.NotesSkip:
+	default:
+		return nil, fmt.Errorf("unknown --note-strategy %q (supported: merge, replace, keep-existing, skip)", *noteStrategy)
+	}
+	if isFlagSet("note-strategy") && !*sync {
+		return nil, fmt.Errorf("--note-strategy requires --sync")
+	}
+	switch *dedupeMode {
+	case dedupePrefetch, dedupeSearch, dedupeNone:
+	default:
+		return nil, fmt.Errorf("unknown --dedupe-mode %q (supported: prefetch, search, none)", *dedupeMode)
+	}
+	if isFlagSet("dedupe-mode") && !*sync {
+		return nil, fmt.Errorf("--dedupe-mode requires --sync")
+	}
+	if *dedupeMode != dedupePrefetch && (*twoWay || *prefetchTag != "") {
+		return nil, fmt.Errorf("--two-way and --prefetch-tag require --dedupe-mode prefetch")
+	}
+	karakeepConn, err := conn.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if *indexCache < 0 {
+		return nil, fmt.Errorf("--karakeep-cache must not be negative")
+	}
+	if *indexCache > 0 && resolvedCacheDir == "" {
+		return nil, fmt.Errorf("--karakeep-cache requires a cache directory, it cannot be used with --no-cache")
+	}
+	if *updateTitles && !*sync {
+		return nil, fmt.Errorf("--update-titles requires --sync")
+	}
+	if *triggerCrawl && !*sync {
+		return nil, fmt.Errorf("--trigger-crawl requires --sync")
+	}
+	if *batchCreate < 0 {
+		return nil, fmt.Errorf("--batch-create must not be negative (0 = off)")
+	}
+	if *batchCreate > 0 && !*sync {
+		return nil, fmt.Errorf("--batch-create requires --sync")
+	}
+	if *batchCreate > 0 && *dedupeMode == dedupeSearch {
+		return nil, fmt.Errorf("--batch-create does not work with --dedupe-mode search, which looks up each bookmark on its own")
+	}
+	if *twoWay && !*sync {
+		return nil, fmt.Errorf("--two-way requires --sync")
+	}
+	if *interactive {
+		if !*sync {
+			return nil, fmt.Errorf("--interactive-conflicts requires --sync")
 		}
-		if resolvedAPIKey == "" {
-			return nil, fmt.Errorf("--sync requires --api-key or KARAKEEP_API_KEY to be set")
+		if (*inputPath == "" && !*resume) || !logger.IsTTY(os.Stdin) {
+			return nil, fmt.Errorf("--interactive-conflicts reads answers from the terminal, so pass the input with -i")
 		}
 	}
 
 	return &Config{
 		InputPath:    *inputPath,
+		InputFormat:  *inputFormat,
 		OutputPath:   *outputPath,
-		Verbose:      *verbose,
+		OutputFormat: *outputFormat,
+		GroupBy:      *groupBy,
+		SchemaVer:    *schemaVersion,
+		Lists:        lists,
+		Verbose:      *verbose || (level <= slog.LevelInfo && *logFile == ""),
+		LogLevel:     level,
+		LogFile:      *logFile,
+		NoColor:      *noColor,
 		DryRun:       *dryRun,
 		Before:       beforeTS,
 		After:        afterTS,
 		Limit:        *limit,
-		Concurrency:  *concurrency,
+		MinScore:     *minScore,
+		Types:        typesSlice,
+		Excluded:     excludedDomains,
+		ExcludeIDs:   *excludeIDs,
+		OnlyDomains:  onlyDomainsSlice,
+		Filter:       *filterExpr,
+		HNWorkers:    hnWorkers,
+		SyncWorkers:  syncWorkers,
+		HNRateLimit:  *hnRPS,
 		Tags:         tagsSlice,
+		RemoveTags:   removeTagsSlice,
+		TagRules:     tagRules,
+		TypeTags:     *typeTags,
+		StripPrefix:  *stripPrefix,
+		DomainTags:   *domainTags,
+		IDTags:       *idTags,
+		Overrides:    *overrides,
+		Transform:    *transformScript,
+		ScoreRules:   scoreRules,
 		NoteTemplate: *noteTemplate,
+		SummaryTmpl:  *summaryTmpl,
+		TitleTmpl:    *titleTmpl,
+		CommentText:  *commentText,
+		IncludeText:  *includeText,
+		TextPosts:    *textPosts,
+		TopComments:  *topComments,
+		Highlights:   *highlightComments > 0,
+		Archive:      *archive,
+		FavouriteAt:  *favouriteAt,
+		DeadItems:    *deadItems,
+		Redirects:    *resolveRedirects,
+		CheckLinks:   *checkLinks,
+		Strict:       *strict,
 		CacheDir:     resolvedCacheDir,
 		ClearCache:   *clearCache,
+		GzipCache:    *gzipCache,
+		CacheTTL:     ttl,
+		StateDir:     getDefaultStateDir(),
+		ConfigPath:   *configPath,
+		Profile:      *profile,
 		Sync:         *sync,
+		Watch:        *watch,
+		Interval:     *interval,
+		Schedule:     *schedule,
+		Target:       *target,
+		WebhookURL:   *webhookURL,
+		WebhookHdrs:  webhookHeaders,
 		APIBaseURL:   resolvedAPIBaseURL,
 		APIKey:       resolvedAPIKey,
-		APITimeout:   *apiTimeout,
+		Resume:       *resume,
+		RetryReport:  *retryReport,
+		MaxFailures:  failureLimitArg,
+		TwoWay:       *twoWay,
+		UpdateTitles: *updateTitles,
+		TriggerCrawl: *triggerCrawl,
+		BatchCreate:  *batchCreate,
+		NoteStrategy: *noteStrategy,
+		PrefetchTag:  *prefetchTag,
+		DedupeMode:   *dedupeMode,
+		IndexCache:   *indexCache,
+		ParallelList: *parallelList,
+		Interactive:  *interactive,
+		connection:   karakeepConn,
 	}, nil
 }
 
+// resolveAPI returns the Karakeep API URL and key, falling back to environment variables
+// when the corresponding flag is empty, and for the key then to the OS keyring (see hnkeep auth login).
+func resolveAPI(flagURL, flagKey string) (apiURL, apiKey string) {
+	apiURL, apiKey = resolveAPIURL(flagURL), flagKey
+	if apiKey == "" {
+		apiKey = os.Getenv("KARAKEEP_API_KEY")
+	}
+	if apiKey == "" && apiURL != "" {
+		apiKey = keyringAPIKey(apiURL)
+	}
+	return apiURL, apiKey
+}
+
+// resolveAPIURL returns the Karakeep API URL, falling back to the environment variable when the flag is empty.
+func resolveAPIURL(flagURL string) string {
+	if flagURL == "" {
+		return os.Getenv("KARAKEEP_API_URL")
+	}
+	return flagURL
+}
+
+// requireAPI returns an error if the Karakeep API URL or key needed by the given mode is missing.
+func requireAPI(mode, apiURL, apiKey string) error {
+	if apiURL == "" {
+		return fmt.Errorf("%s requires --api-url or KARAKEEP_API_URL to be set", mode)
+	}
+	if apiKey == "" {
+		return fmt.Errorf("%s requires --api-key or KARAKEEP_API_KEY to be set, or a key stored with hnkeep auth login", mode)
+	}
+	return nil
+}
+
+// usage prints the command-line usage, including the available commands.
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintf(out, "Usage: hnkeep [command] [flags]\n\n")
+	_, _ = fmt.Fprintf(out, "Commands:\n")
+	_, _ = fmt.Fprintf(out, "  sync           Same as -sync, e.g., hnkeep sync -resume\n")
+	_, _ = fmt.Fprintf(out, "  retry          Process only the failures of the last run, e.g., hnkeep retry -i export.txt -sync\n")
+	_, _ = fmt.Fprintf(out, "  diff           Compare the converted bookmarks with Karakeep, e.g., hnkeep diff -i export.txt\n")
+	_, _ = fmt.Fprintf(out, "  verify         Check that every converted bookmark is in Karakeep with its tags, e.g., after a sync\n")
+	_, _ = fmt.Fprintf(out, "  watch          Same as -watch, e.g., hnkeep watch -i export.txt -schedule \"0 3 * * *\"\n")
+	_, _ = fmt.Fprintf(out, "  prune          Delete bookmarks of an import batch by tag (see hnkeep prune -h)\n")
+	_, _ = fmt.Fprintf(out, "  stats          Report top domains, authors, scores, and saves per month (hnkeep stats -i export.txt)\n")
+	_, _ = fmt.Fprintf(out, "  dedupe-remote  Report (or -merge) duplicate bookmarks in Karakeep\n")
+	_, _ = fmt.Fprintf(out, "  state          Inspect the sync state, e.g., hnkeep state pending -i export.txt\n")
+	_, _ = fmt.Fprintf(out, "  templates      List note template presets and variables (hnkeep templates list)\n")
+	_, _ = fmt.Fprintf(out, "  cache          Delete selected HN cache entries (see hnkeep cache prune -h)\n")
+	_, _ = fmt.Fprintf(out, "  auth           Store the Karakeep API key in the OS keyring (hnkeep auth login)\n")
+	_, _ = fmt.Fprintf(out, "  adb-pull       Read Harmonic's bookmarks from a USB-connected Android device\n")
+	_, _ = fmt.Fprintf(out, "  env            Print the resolved configuration and environment\n\n")
+	_, _ = fmt.Fprintf(out, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+// getDefaultStateDir returns the default state directory following platform conventions.
+// Returns empty string if home directory cannot be determined.
+func getDefaultStateDir() string {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "hnkeep")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", "hnkeep")
+	}
+	return ""
+}
+
 // getDefaultCacheDir returns the default cache directory following platform conventions.
 // Returns empty string if home directory cannot be determined.
 func getDefaultCacheDir() string {
@@ -166,6 +783,84 @@ func getDefaultCacheDir() string {
 	return ""
 }
 
+// checkHeaders returns an error if one of the headers given to the named flag is not "Name: value".
+func checkHeaders(flagName string, headers []string) error {
+	for _, h := range headers {
+		if name, _, ok := strings.Cut(h, ":"); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid --%s %q, expected \"Name: value\"", flagName, h)
+		}
+	}
+	return nil
+}
+
+// formatUsage describes the registered output formats for the -format usage message.
+func formatUsage() string {
+	var parts []string
+	for _, f := range converter.Formats() {
+		desc := f.Description
+		if len(f.Aliases) > 0 {
+			desc += ", alias " + strings.Join(f.Aliases, ", ")
+		}
+		parts = append(parts, f.Name+" ("+desc+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// stringList is a flag.Value collecting the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// splitTags splits a comma-separated list of tags, dropping empty entries.
+func splitTags(s string) []string {
+	var tags []string
+	for split := range strings.SplitSeq(s, ",") {
+		if tag := strings.TrimSpace(split); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseDomains splits the comma-separated domains of the given flag, lowercased and without "www.".
+func parseDomains(name, s string) ([]string, error) {
+	domains := splitTags(s)
+	for i, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+		if strings.ContainsAny(domain, "/:") {
+			return nil, fmt.Errorf("invalid --%s domain %q, expected a domain like example.com, not a URL", name, domain)
+		}
+		domains[i] = domain
+	}
+	return domains, nil
+}
+
+// parseTTL parses a duration like time.ParseDuration, additionally accepting whole days, e.g., "30d".
+func parseTTL(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration %q must not be negative", s)
+	}
+	return d, nil
+}
+
 // parseDate attempts to parse a date string in various formats.
 // Supported formats are "2006-01-02", RFC3339, and Unix timestamp (seconds since epoch).
 func parseDate(s string) (time.Time, error) {
@@ -185,3 +880,18 @@ func parseDate(s string) (time.Time, error) {
 	}
 	return time.Time{}, fmt.Errorf("invalid date format: %s", s)
 }
+
+// resolveLogLevel returns the level given with --log-level, defaulting to info in verbose mode and warn otherwise.
+func resolveLogLevel(value string, verbose bool) (slog.Level, error) {
+	if value == "" {
+		if verbose {
+			return slog.LevelInfo, nil
+		}
+		return slog.LevelWarn, nil
+	}
+	level, err := logger.ParseLevel(value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing --log-level: %w", err)
+	}
+	return level, nil
+}
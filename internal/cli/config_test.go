@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+// parseTestFlags runs parseFlags on the given arguments with fresh global flags and no config file.
+func parseTestFlags(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	orig := flag.CommandLine
+	t.Cleanup(func() { flag.CommandLine = orig })
+	flag.CommandLine = flag.NewFlagSet("hnkeep", flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+	return parseFlags(args)
+}
+
+func TestParseFlags_Concurrency(t *testing.T) {
+	tests := map[string]struct {
+		args             []string
+		wantHN, wantSync int
+		wantErr          string
+	}{
+		"default":           {wantHN: 5, wantSync: 5},
+		"shared":            {args: []string{"-c", "8"}, wantHN: 8, wantSync: 8},
+		"per api":           {args: []string{"-c", "8", "-karakeep-concurrency", "2"}, wantHN: 8, wantSync: 2},
+		"zero":              {args: []string{"-concurrency", "0"}, wantErr: "--concurrency must be at least 1"},
+		"negative":          {args: []string{"-c", "-3"}, wantErr: "--concurrency must be at least 1"},
+		"negative hn":       {args: []string{"-hn-concurrency", "-1"}, wantErr: "must not be negative"},
+		"negative karakeep": {args: []string{"-karakeep-concurrency", "-1"}, wantErr: "must not be negative"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := parseTestFlags(t, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("parseFlags() error = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFlags() unexpected error: %v", err)
+			}
+			if cfg.HNWorkers != tc.wantHN || cfg.SyncWorkers != tc.wantSync {
+				t.Errorf("workers = %d HN, %d sync, want %d, %d", cfg.HNWorkers, cfg.SyncWorkers, tc.wantHN, tc.wantSync)
+			}
+		})
+	}
+}
+
+func TestParseFlags_FavouriteAboveScore(t *testing.T) {
+	cfg, err := parseTestFlags(t, "-favourite-above-score", "100")
+	if err != nil || cfg.FavouriteAt != 100 {
+		t.Errorf("parseFlags() = %v, %v, want FavouriteAt 100", cfg, err)
+	}
+	if _, err := parseTestFlags(t, "-favourite-above-score", "-1"); err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Errorf("parseFlags() error = %v, want a negative score rejected", err)
+	}
+}
+
+func TestParseFlags_BatchCreate(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		want    int
+		wantErr string
+	}{
+		"default":       {args: []string{"-sync"}},
+		"set":           {args: []string{"-sync", "-batch-create", "50"}, want: 50},
+		"negative":      {args: []string{"-sync", "-batch-create", "-1"}, wantErr: "must not be negative"},
+		"without sync":  {args: []string{"-batch-create", "50"}, wantErr: "requires --sync"},
+		"search lookup": {args: []string{"-sync", "-batch-create", "50", "-dedupe-mode", "search"}, wantErr: "--dedupe-mode search"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("KARAKEEP_API_URL", "https://karakeep.example.com/api/v1")
+			t.Setenv("KARAKEEP_API_KEY", "test-key")
+			cfg, err := parseTestFlags(t, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("parseFlags() error = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFlags() unexpected error: %v", err)
+			}
+			if cfg.BatchCreate != tc.want {
+				t.Errorf("BatchCreate = %d, want %d", cfg.BatchCreate, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
+)
+
+// maxConflictNoteLength is the number of runes of a note shown in a conflict prompt.
+const maxConflictNoteLength = 80
+
+// conflictPrompter asks how to resolve each sync conflict (see syncer.Conflict),
+// writing prompts to out and reading answers from in.
+type conflictPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	all *syncer.Resolution // answer for all remaining conflicts, once given with "!"
+}
+
+// newConflictPrompter creates a conflictPrompter reading from in and writing to out.
+func newConflictPrompter(in io.Reader, out io.Writer) *conflictPrompter {
+	return &conflictPrompter{in: bufio.NewReader(in), out: out}
+}
+
+// resolve prompts until a valid answer is given. Input ending (e.g., Ctrl-D) merges this and all remaining ones.
+func (p *conflictPrompter) resolve(c syncer.Conflict) syncer.Resolution {
+	if p.all != nil {
+		return *p.all
+	}
+
+	_, _ = fmt.Fprintf(p.out, "\nConflict: %s\n", c.Bookmark.Content.URL)
+	_, _ = fmt.Fprintf(p.out, "  Existing : saved %s, note %s\n", formatUnix(c.Remote.CreatedAt), notePreview(c.Remote.Note))
+	_, _ = fmt.Fprintf(p.out, "  Incoming : saved %s, note %s\n", formatUnix(c.Bookmark.CreatedAt), notePreview(c.Bookmark.Note))
+	for {
+		_, _ = fmt.Fprintf(p.out, "[m]erge, [r]eplace, or [s]kip? Add ! to apply to all remaining (default m): ")
+		line, err := p.in.ReadString('\n')
+		if err != nil && line == "" {
+			_, _ = fmt.Fprintln(p.out)
+			merge := syncer.ResolveMerge
+			p.all = &merge
+			return merge
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		answer, forAll := strings.CutSuffix(answer, "!")
+
+		var res syncer.Resolution
+		switch answer {
+		case "", "m", "merge":
+			res = syncer.ResolveMerge
+		case "r", "replace":
+			res = syncer.ResolveReplace
+		case "s", "skip":
+			res = syncer.ResolveSkip
+		default:
+			continue
+		}
+		if forAll {
+			p.all = &res
+		}
+		return res
+	}
+}
+
+// formatUnix formats a Unix timestamp as local date and time.
+func formatUnix(ts int64) string {
+	return time.Unix(ts, 0).Format("2006-01-02 15:04")
+}
+
+// notePreview returns the note quoted and shortened for a single prompt line.
+func notePreview(note *string) string {
+	if note == nil || *note == "" {
+		return "(empty)"
+	}
+	runes := []rune(*note)
+	if len(runes) > maxConflictNoteLength {
+		return strconv.Quote(string(runes[:maxConflictNoteLength])) + "…"
+	}
+	return strconv.Quote(*note)
+}
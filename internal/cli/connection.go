@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// connection holds the settings of the connection to the Karakeep API, shared by the commands talking to it.
+type connection struct {
+	APITimeout  time.Duration // Karakeep API request timeout duration
+	Proxy       *url.URL      // Proxy for the API requests (nil = environment)
+	KarakeepTLS *tls.Config   // TLS config of the Karakeep API requests (nil = default)
+	UserAgent   string        // User-Agent of the Karakeep API requests
+	Headers     []string      // Extra Karakeep API request headers as "Name: value"
+}
+
+// connFlags are the flag values of a connection, see addConnFlags.
+type connFlags struct {
+	apiTimeout  *time.Duration
+	caCert      *string
+	clientCert  *string
+	clientKey   *string
+	insecureTLS *bool
+	userAgent   *string
+	headers     stringList
+	proxy       *string
+}
+
+// addConnFlags defines the flags of the connection to the Karakeep API on fs. Every command talking to
+// Karakeep defines them, so a Karakeep behind a proxy or requiring mutual TLS is reachable by each,
+// and each picks them from a profile.
+func addConnFlags(fs *flag.FlagSet) *connFlags {
+	f := &connFlags{}
+	f.apiTimeout = fs.Duration("api-timeout", 30*time.Second, "Karakeep API request timeout duration")
+	f.caCert = fs.String("ca-cert", "",
+		"PEM file of CA certificates to trust for the Karakeep API, e.g., the private CA of a self-hosted instance")
+	f.clientCert = fs.String("client-cert", "",
+		"PEM client certificate for the Karakeep API, for a reverse proxy requiring mutual TLS (with -client-key)")
+	f.clientKey = fs.String("client-key", "", "PEM private key of -client-cert")
+	f.insecureTLS = fs.Bool("insecure-skip-verify", false,
+		"Do not verify the TLS certificate of the Karakeep API (insecure, e.g., for a self-signed certificate)")
+	f.userAgent = fs.String("user-agent", "hnkeep/"+Version, "User-Agent header of Karakeep API requests")
+	fs.Var(&f.headers, "header",
+		`Header sent with Karakeep API requests as "Name: value", e.g., for Cloudflare Access (repeatable)`)
+	f.proxy = fs.String("proxy", "",
+		"Proxy URL for API requests, e.g., http://proxy:3128 or socks5://127.0.0.1:1080 (default: env HTTPS_PROXY, HTTP_PROXY)")
+	return f
+}
+
+// resolve validates the flag values and returns the connection they describe.
+func (f *connFlags) resolve() (connection, error) {
+	if err := checkHeaders("header", f.headers); err != nil {
+		return connection{}, err
+	}
+	var proxyURL *url.URL
+	if *f.proxy != "" {
+		u, err := url.Parse(*f.proxy)
+		if err != nil || u.Host == "" || !slices.Contains([]string{"http", "https", "socks5", "socks5h"}, u.Scheme) {
+			return connection{}, fmt.Errorf("invalid --proxy %q: want a URL like http://host:port or socks5://host:port", *f.proxy)
+		}
+		proxyURL = u
+	}
+	karakeepTLS, err := loadTLSConfig(*f.caCert, *f.clientCert, *f.clientKey, *f.insecureTLS)
+	if err != nil {
+		return connection{}, err
+	}
+	return connection{
+		APITimeout:  *f.apiTimeout,
+		Proxy:       proxyURL,
+		KarakeepTLS: karakeepTLS,
+		UserAgent:   *f.userAgent,
+		Headers:     f.headers,
+	}, nil
+}
+
+// karakeepOptions returns the Karakeep client options of the connection.
+func (c connection) karakeepOptions() []karakeep.ClientOption {
+	opts := []karakeep.ClientOption{
+		karakeep.WithTimeout(c.APITimeout),
+		karakeep.WithProxy(c.Proxy),
+		karakeep.WithTLSConfig(c.KarakeepTLS),
+		karakeep.WithUserAgent(c.UserAgent),
+	}
+	for _, h := range c.Headers {
+		name, value, _ := strings.Cut(h, ":") // validated by resolve
+		opts = append(opts, karakeep.WithHeader(strings.TrimSpace(name), strings.TrimSpace(value)))
+	}
+	return opts
+}
+
+// loadTLSConfig returns the TLS config of -ca-cert, -client-cert, -client-key, and -insecure-skip-verify,
+// or nil for the default one. The certificates of the CA file are trusted in addition to the system ones.
+func loadTLSConfig(caCert, clientCert, clientKey string, insecure bool) (*tls.Config, error) {
+	if (clientCert == "") != (clientKey == "") {
+		return nil, fmt.Errorf("--client-cert and --client-key must be given together")
+	}
+	if caCert == "" && clientCert == "" && !insecure {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: insecure} // explicitly requested with -insecure-skip-verify
+	if caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("reading --ca-cert: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool() // e.g., on platforms without a readable system pool
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("--ca-cert %s contains no PEM certificate", caCert)
+		}
+		config.RootCAs = roots
+	}
+	if clientCert != "" {
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading --client-cert: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnFlags_Resolve(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"defaults":           {},
+		"socks proxy":        {args: []string{"-proxy", "socks5://127.0.0.1:1080"}},
+		"unsupported proxy":  {args: []string{"-proxy", "ftp://proxy:21"}, wantErr: "invalid --proxy"},
+		"proxy without host": {args: []string{"-proxy", "http://"}, wantErr: "invalid --proxy"},
+		"invalid header":     {args: []string{"-header", "no colon"}, wantErr: "invalid --header"},
+		"key without cert":   {args: []string{"-client-key", "key.pem"}, wantErr: "must be given together"},
+		"missing ca cert":    {args: []string{"-ca-cert", "/nonexistent/ca.pem"}, wantErr: "reading --ca-cert"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			conn := addConnFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			_, err := conn.resolve()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("resolve() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("resolve() error = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubcommandConnection(t *testing.T) {
+	t.Setenv("KARAKEEP_API_URL", "https://karakeep.example.com/api/v1")
+	t.Setenv("KARAKEEP_API_KEY", "secret")
+	path := writeConfig(t, `{"profiles": {"w": {
+		"proxy": "http://proxy:3128", "header": ["X-Auth: a"], "api-timeout": "5s", "tags": "ignored"
+	}}}`)
+	args := []string{"-config", path, "-profile", "w", "-user-agent", "custom"}
+
+	check := func(t *testing.T, conn connection) {
+		t.Helper()
+		if conn.Proxy == nil || conn.Proxy.Host != "proxy:3128" {
+			t.Errorf("Proxy = %v, want proxy:3128 from the profile", conn.Proxy)
+		}
+		if !slices.Equal(conn.Headers, []string{"X-Auth: a"}) {
+			t.Errorf("Headers = %v, want the profile's", conn.Headers)
+		}
+		if conn.APITimeout != 5*time.Second || conn.UserAgent != "custom" {
+			t.Errorf("APITimeout, UserAgent = %s, %q, want 5s, custom", conn.APITimeout, conn.UserAgent)
+		}
+	}
+
+	t.Run("prune", func(t *testing.T) {
+		cfg, err := parsePruneFlags(append([]string{"-tag", "hnkeep:20260101"}, args...))
+		if err != nil {
+			t.Fatalf("parsePruneFlags() error = %v", err)
+		}
+		check(t, cfg.connection)
+	})
+	t.Run("dedupe-remote", func(t *testing.T) {
+		cfg, err := parseDedupeFlags(args)
+		if err != nil {
+			t.Fatalf("parseDedupeFlags() error = %v", err)
+		}
+		check(t, cfg.connection)
+	})
+	t.Run("auth login", func(t *testing.T) {
+		_, conn, err := parseAuthFlags("login", "", []string{"-proxy", "http://proxy:3128", "-header", "X-Auth: a",
+			"-api-timeout", "5s", "-user-agent", "custom"}, true)
+		if err != nil {
+			t.Fatalf("parseAuthFlags() error = %v", err)
+		}
+		check(t, conn)
+	})
+}
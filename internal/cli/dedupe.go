@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// dedupeNoteSeparator separates notes merged from duplicates, same as the converter and syncer.
+const dedupeNoteSeparator = "\n\n---\n\n"
+
+// dedupeConfig holds the configuration of the dedupe-remote command.
+type dedupeConfig struct {
+	Merge      bool       // Merge duplicates into the oldest bookmark and delete the rest
+	Yes        bool       // Skip the confirmation prompt
+	Verbose    bool       // Show per-bookmark messages
+	LogLevel   slog.Level // Minimum level of log messages
+	NoColor    bool       // Disable colored log output
+	APIBaseURL string     // Karakeep API URL
+	APIKey     string     // Karakeep API key
+	CacheDir   string     // Cache directory whose Karakeep bookmark index is cleared after changes
+
+	connection // Connection to the Karakeep API
+}
+
+// duplicateGroup is a set of bookmarks whose URLs normalize to the same key.
+// Bookmarks are ordered oldest first; the first one is kept when merging.
+type duplicateGroup struct {
+	Key       string
+	Bookmarks []karakeep.ListBookmark
+}
+
+// parseDedupeFlags parses the dedupe-remote command arguments.
+func parseDedupeFlags(args []string) (*dedupeConfig, error) {
+	fs := flag.NewFlagSet("dedupe-remote", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep dedupe-remote [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Report Karakeep bookmarks whose URLs differ only cosmetically (case, www., trailing slash,\n")
+		_, _ = fmt.Fprintf(fs.Output(), "utm_* and click ID parameters, ...), and optionally merge them into the oldest one.\n\n")
+		fs.PrintDefaults()
+	}
+
+	merge := fs.Bool("merge", false,
+		"Merge notes, tags, and archived/favourited state into the oldest bookmark, then delete the others")
+	yes := fs.Bool("yes", false, "Merge without asking for confirmation")
+	verbose := fs.Bool("verbose", false, "Show a message for every merged and deleted bookmark")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn, or error (default: info with -verbose, else warn)")
+	noColor := fs.Bool("no-color", false, "Disable colored log output (also via the NO_COLOR env var)")
+	apiBaseURL := fs.String("api-url", "", "Karakeep API URL (env: KARAKEEP_API_URL)")
+	apiKey := fs.String("api-key", "", "Karakeep API key (env: KARAKEEP_API_KEY, or stored with hnkeep auth login)")
+	conn := addConnFlags(fs)
+	cacheDir := fs.String("cache-dir", getDefaultCacheDir(),
+		"Cache directory whose Karakeep bookmark index (see -karakeep-cache) is cleared after changing bookmarks")
+
+	profile := fs.String("profile", "", "Use the flag values of this named profile in the config file (others are ignored)")
+	configPath := fs.String("config", getDefaultConfigPath(), "Config file path")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *profile != "" {
+		if err := applyProfile(fs, *configPath, *profile, true); err != nil {
+			return nil, err
+		}
+	}
+
+	resolvedAPIBaseURL, resolvedAPIKey := resolveAPI(*apiBaseURL, *apiKey)
+	if err := requireAPI("dedupe-remote", resolvedAPIBaseURL, resolvedAPIKey); err != nil {
+		return nil, err
+	}
+
+	level, err := resolveLogLevel(*logLevel, *verbose)
+	if err != nil {
+		return nil, err
+	}
+	karakeepConn, err := conn.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dedupeConfig{
+		Merge:      *merge,
+		Yes:        *yes,
+		Verbose:    *verbose || level <= slog.LevelInfo,
+		LogLevel:   level,
+		NoColor:    *noColor,
+		APIBaseURL: resolvedAPIBaseURL,
+		APIKey:     resolvedAPIKey,
+		CacheDir:   *cacheDir,
+		connection: karakeepConn,
+	}, nil
+}
+
+// runDedupeRemote finds bookmarks in Karakeep that point to the same page under different URLs,
+// e.g., created by earlier imports that didn't normalize URLs. It only reports them unless -merge is given.
+func runDedupeRemote(ctx context.Context, args []string) error {
+	cfg, err := parseDedupeFlags(args)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log := newStderrLogger(os.Stderr, cfg.LogLevel, cfg.NoColor)
+	client := karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey, append(cfg.karakeepOptions(),
+		karakeep.WithLogger(log),
+	)...)
+
+	phase := newPhaser(cfg.Verbose).Start("Listing bookmarks")
+	bookmarks, err := client.ListAllBookmarks(ctx)
+	if err != nil {
+		phase.Fail()
+		return fmt.Errorf("listing bookmarks: %w", err)
+	}
+	phase.Done("found %d", len(bookmarks))
+
+	groups := findDuplicates(bookmarks)
+	redundant := 0
+	for _, g := range groups {
+		redundant += len(g.Bookmarks) - 1
+		fmt.Fprintf(os.Stderr, "%s\n", g.Key)
+		for i, bm := range g.Bookmarks {
+			action := "duplicate"
+			if i == 0 {
+				action = "keep     "
+			}
+			fmt.Fprintf(os.Stderr, "  %s  %s  %s\n", action, bm.CreatedAt, bookmarkLabel(bm))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\nBookmarks       : %d\n", len(bookmarks))
+	fmt.Fprintf(os.Stderr, "Duplicate groups: %d\n", len(groups))
+	fmt.Fprintf(os.Stderr, "  Redundant     : %d\n", redundant)
+
+	if len(groups) == 0 {
+		return nil
+	}
+	if !cfg.Merge {
+		fmt.Fprintf(os.Stderr, "\nNo changes made to Karakeep, use -merge to merge duplicates into the kept bookmark.\n")
+		return nil
+	}
+
+	if !cfg.Yes {
+		if !logger.IsTTY(os.Stdin) {
+			return errors.New("refusing to merge without confirmation, pass -yes to skip the prompt")
+		}
+		if !confirm(fmt.Sprintf("Merge and permanently delete %d duplicate bookmark(s)?", redundant)) {
+			fmt.Fprintf(os.Stderr, "Aborted.\n")
+			return nil
+		}
+	}
+
+	// merging while a sync is running could leave the sync updating deleted bookmarks
+	if stateDir := getDefaultStateDir(); stateDir != "" {
+		lock, err := acquireLock(stateDir, cfg.APIBaseURL)
+		if err != nil {
+			return fmt.Errorf("locking dedupe: %w", err)
+		}
+		defer func() { _ = lock.release() }()
+	}
+
+	merged, deleted, failed := 0, 0, 0
+	defer func() {
+		if merged > 0 { // also when interrupted
+			invalidateIndexCache(cfg.CacheDir, cfg.APIBaseURL)
+		}
+	}()
+	for _, g := range groups {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		keep, dups := g.Bookmarks[0], g.Bookmarks[1:]
+
+		// merge first, so a failure never loses data of a deleted duplicate
+		if err := mergeDuplicates(ctx, client, keep, dups); err != nil {
+			failed++
+			log.Warn("failed to merge into %s: %v", bookmarkLabel(keep), err)
+			continue
+		}
+		merged++
+
+		for _, bm := range dups {
+			if err := client.DeleteBookmark(ctx, bm.ID); err != nil && !errors.Is(err, karakeep.ErrBookmarkNotFound) {
+				failed++
+				log.Warn("failed to delete %s: %v", bookmarkLabel(bm), err)
+				continue
+			}
+			deleted++
+			log.Info("deleted duplicate %s of %s", bm.ID, bookmarkLabel(keep))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\n=== Dedupe Summary ===\n")
+	fmt.Fprintf(os.Stderr, "Merged groups   : %d\n", merged)
+	fmt.Fprintf(os.Stderr, "Deleted         : %d\n", deleted)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Failed          : %d\n", failed)
+		return fmt.Errorf("%d duplicate(s) failed to merge or delete", failed)
+	}
+	return nil
+}
+
+// findDuplicates groups link bookmarks by normalized URL and returns the groups with more than one bookmark,
+// in order of first appearance. Within a group, bookmarks are sorted oldest first.
+func findDuplicates(bookmarks []karakeep.ListBookmark) []duplicateGroup {
+	index := make(map[string]int) // normalized url -> index in groups
+	var groups []duplicateGroup
+	for _, bm := range bookmarks {
+		bmURL := bm.Content.GetURL()
+		if bmURL == "" {
+			continue // text bookmarks have no URL to compare
+		}
+		key := converter.NormalizeURL(bmURL)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, duplicateGroup{Key: key})
+		}
+		groups[i].Bookmarks = append(groups[i].Bookmarks, bm)
+	}
+
+	dups := groups[:0]
+	for _, g := range groups {
+		if len(g.Bookmarks) < 2 {
+			continue
+		}
+		// createdAt is ISO 8601 in UTC, so string order is chronological
+		slices.SortStableFunc(g.Bookmarks, func(a, b karakeep.ListBookmark) int {
+			return strings.Compare(a.CreatedAt, b.CreatedAt)
+		})
+		dups = append(dups, g)
+	}
+	return dups
+}
+
+// mergeDuplicates carries the notes, tags, and archived/favourited state of the duplicates over to keep.
+// Notes already contained in the kept note are not appended again.
+func mergeDuplicates(ctx context.Context, client *karakeep.Client, keep karakeep.ListBookmark, dups []karakeep.ListBookmark) error {
+	note := ""
+	if keep.Note != nil {
+		note = *keep.Note
+	}
+	var tags []string
+	var update karakeep.UpdateBookmarkRequest
+	for _, bm := range dups {
+		if bm.Note != nil && strings.TrimSpace(*bm.Note) != "" && !strings.Contains(note, *bm.Note) {
+			note = strings.TrimSpace(note + dedupeNoteSeparator + *bm.Note)
+		}
+		for _, tag := range bm.Tags {
+			if !slices.ContainsFunc(keep.Tags, func(t karakeep.ListBookmarkTag) bool { return t.Name == tag.Name }) &&
+				!slices.Contains(tags, tag.Name) {
+				tags = append(tags, tag.Name)
+			}
+		}
+		if bm.Archived && !keep.Archived {
+			update.Archived = &bm.Archived
+		}
+		if bm.Favourited && !keep.Favourited {
+			update.Favourited = &bm.Favourited
+		}
+	}
+	if keep.Note == nil || note != *keep.Note {
+		if note != "" {
+			update.Note = &note
+		}
+	}
+
+	if update.Note != nil || update.Archived != nil || update.Favourited != nil {
+		if err := client.UpdateBookmark(ctx, keep.ID, update); err != nil {
+			return fmt.Errorf("updating: %w", err)
+		}
+	}
+	if len(tags) > 0 {
+		if err := client.AttachTags(ctx, keep.ID, tags); err != nil {
+			return fmt.Errorf("attaching tags: %w", err)
+		}
+	}
+	return nil
+}
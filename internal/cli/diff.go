@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// batchTagPrefix is the prefix of the dated batch tag in the default -tags, e.g., hnkeep:20260117.
+const batchTagPrefix = "hnkeep:"
+
+// checkCompare validates the flags given to hnkeep diff or verify, which only read from Karakeep.
+func checkCompare(cfg *Config, cmd string) error {
+	switch {
+	case cfg.Sync || cfg.Target != "":
+		return fmt.Errorf("hnkeep %s cannot be used with --sync or --target", cmd)
+	case cfg.Watch || cfg.Resume:
+		return fmt.Errorf("hnkeep %s cannot be used with --watch or --resume", cmd)
+	case cfg.DryRun:
+		return fmt.Errorf("hnkeep %s is read-only, --dry-run is not needed", cmd)
+	}
+	return requireAPI("hnkeep "+cmd, cfg.APIBaseURL, cfg.APIKey)
+}
+
+// listExisting lists the bookmarks in Karakeep by URL, for hnkeep diff and verify.
+func listExisting(ctx context.Context, cfg *Config, log logger.Logger) (map[string]karakeep.ExistingBookmark, error) {
+	client := karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey, append(cfg.karakeepOptions(),
+		karakeep.WithLogger(log),
+		karakeep.WithListConcurrency(listRanges(cfg)),
+	)...)
+	phase := newPhaser(cfg.Verbose).Start("Listing Karakeep bookmarks")
+	existing, err := prefetch(ctx, cfg, log, client)
+	if err != nil {
+		phase.Fail()
+		return nil, fmt.Errorf("listing bookmarks: %w", err)
+	}
+	phase.Done("found %d", len(existing))
+	return existing, nil
+}
+
+// runDiff compares the converted bookmarks with the bookmarks in Karakeep, without writing anything.
+// Differences are printed to stdout, one per line: missing, diverged (with the differing fields),
+// or stale for imports no longer in the export. Bookmarks count as imports if the sync state of
+// the server records them, or if they carry one of -tags or a batch tag of an earlier run.
+func runDiff(ctx context.Context, cfg *Config, log logger.Logger, bookmarks []converter.Bookmark, stats *stats) error {
+	existing, err := listExisting(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	var state *syncer.State
+	if cfg.StateDir != "" {
+		if state, err = syncer.LoadState(syncStatePath(cfg.StateDir, cfg.APIBaseURL)); err != nil {
+			return fmt.Errorf("loading sync state: %w", err)
+		}
+	}
+	imported := func(url string, remote karakeep.ExistingBookmark) bool {
+		if state != nil && state.Synced(url) {
+			return true
+		}
+		return slices.ContainsFunc(remote.Tags, func(tag string) bool {
+			return strings.HasPrefix(tag, batchTagPrefix) || slices.Contains(cfg.Tags, tag)
+		})
+	}
+
+	diff := syncer.DiffBookmarks(bookmarks, existing, imported)
+	for _, bm := range diff.Missing {
+		fmt.Printf("missing\t%s\t%s\n", bm.Content.URL, bookmarkTitle(bm))
+	}
+	for _, d := range diff.Diverged {
+		line := fmt.Sprintf("diverged\t%s\t%s", d.Bookmark.Content.URL, strings.Join(d.Fields, ","))
+		if d.RemoteURL != d.Bookmark.Content.URL {
+			line += "\t" + d.RemoteURL // found by provenance tag
+		}
+		fmt.Println(line)
+	}
+	for _, url := range diff.Stale {
+		fmt.Printf("stale\t%s\n", url)
+	}
+
+	printSummary(*stats)
+	fmt.Fprintf(os.Stderr, "\n=== Diff ===\n")
+	fmt.Fprintf(os.Stderr, "Karakeep        : %d   (existing bookmarks)\n", len(existing))
+	fmt.Fprintf(os.Stderr, "In sync         : %d\n", diff.Matching)
+	fmt.Fprintf(os.Stderr, "Missing         : %d   (not in Karakeep)\n", len(diff.Missing))
+	fmt.Fprintf(os.Stderr, "Diverged        : %d   (URL, note, or timestamp)\n", len(diff.Diverged))
+	fmt.Fprintf(os.Stderr, "Stale           : %d   (imports not in the export)\n", len(diff.Stale))
+	return nil
+}
+
+// runVerify checks that every converted bookmark is in Karakeep with its tags, e.g., after a sync.
+// Discrepancies are printed to stdout, one per line: missing, or tags with the tags Karakeep lacks.
+// Any discrepancy fails the command, so scripts can tell an incomplete import.
+func runVerify(ctx context.Context, cfg *Config, log logger.Logger, bookmarks []converter.Bookmark, stats *stats) error {
+	existing, err := listExisting(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	var missing, untagged int
+	discrepancies := syncer.VerifyBookmarks(bookmarks, existing)
+	for _, d := range discrepancies {
+		if d.Missing {
+			missing++
+			fmt.Printf("missing\t%s\t%s\n", d.Bookmark.Content.URL, bookmarkTitle(d.Bookmark))
+			continue
+		}
+		untagged++
+		fmt.Printf("tags\t%s\t%s\n", d.RemoteURL, strings.Join(d.MissingTags, ","))
+	}
+
+	printSummary(*stats)
+	fmt.Fprintf(os.Stderr, "\n=== Verify ===\n")
+	fmt.Fprintf(os.Stderr, "Verified        : %d\n", len(bookmarks)-len(discrepancies))
+	fmt.Fprintf(os.Stderr, "Missing         : %d   (not in Karakeep)\n", missing)
+	fmt.Fprintf(os.Stderr, "Missing tags    : %d\n", untagged)
+
+	if len(discrepancies) > 0 {
+		return fmt.Errorf("%d of %d bookmark(s) not in Karakeep as converted", len(discrepancies), len(bookmarks))
+	}
+	return nil
+}
+
+// bookmarkTitle returns the title of the converted bookmark, or "" if it has none.
+func bookmarkTitle(bm converter.Bookmark) string {
+	if bm.Title == nil {
+		return ""
+	}
+	return *bm.Title
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setEcho turns the echo of typed characters on the stdin terminal on or off.
+func setEcho(on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = os.Stdin // stty acts on the terminal of its stdin
+	return cmd.Run()
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// sqliteHeader starts every SQLite database file, see decodeInput.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// Byte order marks for the encodings we detect.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeInput detects the encoding of raw input data and returns it as a UTF-8 string.
+//
+// Supported encodings are UTF-8 (with or without BOM) and UTF-16 LE/BE (with BOM, or without BOM
+// when every character is ASCII or Latin-1, which is always the case for Harmonic exports). Other
+// input, e.g., an export re-saved by a Windows tool, is read as Windows-1252 with a warning.
+func decodeInput(data []byte) (string, error) {
+	if bytes.HasPrefix(data, sqliteHeader) { // e.g., an app database instead of its export
+		return "", errors.New(`input is a SQLite database, not text (for Materialistic, dump it with ` +
+			`sqlite3 -header -csv Materialistic.db "SELECT * FROM favorite")`)
+	}
+
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		data = data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], binary.LittleEndian)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], binary.BigEndian)
+	default:
+		if order, ok := guessUTF16(data); ok {
+			return decodeUTF16(data, order)
+		}
+	}
+
+	if !utf8.Valid(data) {
+		fmt.Fprintf(os.Stderr, "Warning: input is not valid UTF-8, reading it as Windows-1252\n")
+		return decodeWindows1252(data), nil
+	}
+	return string(data), nil
+}
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 to their characters. The bytes undefined
+// in it are kept as the C1 control characters, like the rest of the bytes map to Latin-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// decodeWindows1252 transcodes Windows-1252 data to a UTF-8 string.
+func decodeWindows1252(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		if c >= 0x80 && c < 0xA0 {
+			b.WriteRune(windows1252[c-0x80])
+		} else {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// decodeUTF16 transcodes UTF-16 data in the given byte order to a UTF-8 string.
+func decodeUTF16(data []byte, order binary.ByteOrder) (string, error) {
+	if len(data)%2 != 0 {
+		return "", errors.New("input has odd length for UTF-16 text")
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// guessUTF16 detects BOM-less UTF-16 of ASCII or Latin-1 text, whose every other byte is NUL:
+// every odd byte for LE, every even byte for BE. Any other NUL byte rules UTF-16 out, as does a
+// character outside Latin-1, so other BOM-less UTF-16 is not detected.
+func guessUTF16(data []byte) (binary.ByteOrder, bool) {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return nil, false
+	}
+	var evenZeros, oddZeros int
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	half := len(data) / 2
+	switch {
+	case oddZeros == half && evenZeros == 0:
+		return binary.LittleEndian, true
+	case evenZeros == half && oddZeros == 0:
+		return binary.BigEndian, true
+	}
+	return nil, false
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16Bytes encodes s as UTF-16 in the given byte order, without BOM.
+func utf16Bytes(s string, order binary.ByteOrder) []byte {
+	units := utf16.Encode([]rune(s))
+	data := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(data[2*i:], unit)
+	}
+	return data
+}
+
+func TestDecodeInput(t *testing.T) {
+	const text = "1,Show HN: café,1700000000"
+	const ascii = "1,Show HN,1700000000"
+	tests := map[string]struct {
+		data    []byte
+		want    string
+		wantErr string
+	}{
+		"utf-8":                 {data: []byte(text), want: text},
+		"utf-8 with bom":        {data: append([]byte{0xEF, 0xBB, 0xBF}, text...), want: text},
+		"utf-16 le with bom":    {data: append([]byte{0xFF, 0xFE}, utf16Bytes(text, binary.LittleEndian)...), want: text},
+		"utf-16 be with bom":    {data: append([]byte{0xFE, 0xFF}, utf16Bytes(text, binary.BigEndian)...), want: text},
+		"utf-16 le without bom": {data: utf16Bytes(ascii, binary.LittleEndian), want: ascii},
+		"utf-16 be without bom": {data: utf16Bytes(ascii, binary.BigEndian), want: ascii},
+		"empty":                 {data: nil, want: ""},
+		"windows-1252":          {data: []byte{'c', 'a', 'f', 0xE9, ' ', 0x80, '5', ' ', 0x93, 'x', 0x94}, want: "café €5 “x”"},
+		"odd-length utf-16":     {data: []byte{0xFF, 0xFE, 'a', 0, 'b'}, wantErr: "odd length"},
+		"sqlite database":       {data: append([]byte("SQLite format 3\x00"), 0x10, 0x00), wantErr: "SQLite database"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeInput(tc.data)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("decodeInput() error = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeInput() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("decodeInput() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGuessUTF16(t *testing.T) {
+	tests := map[string]struct {
+		data      []byte
+		wantOrder binary.ByteOrder
+		wantOK    bool
+	}{
+		"ascii le":       {data: utf16Bytes("hn", binary.LittleEndian), wantOrder: binary.LittleEndian, wantOK: true},
+		"ascii be":       {data: utf16Bytes("hn", binary.BigEndian), wantOrder: binary.BigEndian, wantOK: true},
+		"utf-8":          {data: []byte("hnkeep"), wantOK: false},
+		"latin-1 le":     {data: utf16Bytes("hé", binary.LittleEndian), wantOrder: binary.LittleEndian, wantOK: true},
+		"non-latin-1 le": {data: utf16Bytes("h€", binary.LittleEndian), wantOK: false},
+		"stray nul":      {data: []byte{'h', 0, 'n', 'k'}, wantOK: false},
+		"odd length":     {data: []byte{'h', 0, 'n'}, wantOK: false},
+		"too short":      {data: []byte{'h'}, wantOK: false},
+		"all nul bytes":  {data: []byte{0, 0}, wantOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			order, ok := guessUTF16(tc.data)
+			if ok != tc.wantOK || order != tc.wantOrder {
+				t.Errorf("guessUTF16() = %v, %v, want %v, %v", order, ok, tc.wantOrder, tc.wantOK)
+			}
+		})
+	}
+}
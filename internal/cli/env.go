@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+)
+
+// printEnv prints the resolved configuration and detected environment to stdout.
+// Secrets are redacted so the output can be pasted into support requests as is.
+func printEnv(cfg *Config) {
+	out := os.Stdout
+	_, _ = fmt.Fprintf(out, "Version         : hnkeep %s, build %s\n", Version, Commit)
+	_, _ = fmt.Fprintf(out, "Platform        : %s/%s (%s)\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+
+	_, _ = fmt.Fprintf(out, "\nPaths:\n")
+	_, _ = fmt.Fprintf(out, "  Cache dir     : %s\n", orDefault(cfg.CacheDir, "(disabled)"))
+	_, _ = fmt.Fprintf(out, "  State dir     : %s\n", orDefault(cfg.StateDir, "(unavailable)"))
+	_, _ = fmt.Fprintf(out, "  Log file      : %s\n", orDefault(cfg.LogFile, "(stderr)"))
+	_, _ = fmt.Fprintf(out, "  Config file   : %s\n", configFileStatus(cfg.ConfigPath))
+	_, _ = fmt.Fprintf(out, "  Profile       : %s\n", orDefault(cfg.Profile, "(none)"))
+
+	_, _ = fmt.Fprintf(out, "\nKarakeep:\n")
+	_, _ = fmt.Fprintf(out, "  API URL       : %s\n", orDefault(cfg.APIBaseURL, "(not set)"))
+	_, _ = fmt.Fprintf(out, "  API key       : %s\n", redact(cfg.APIKey))
+	_, _ = fmt.Fprintf(out, "  API timeout   : %s\n", cfg.APITimeout)
+
+	_, _ = fmt.Fprintf(out, "\nTerminal:\n")
+	_, _ = fmt.Fprintf(out, "  Stdin TTY     : %t\n", logger.IsTTY(os.Stdin))
+	_, _ = fmt.Fprintf(out, "  Stderr TTY    : %t\n", logger.IsStderrTTY())
+	_, _ = fmt.Fprintf(out, "  ANSI escapes  : %t\n", logger.IsStderrTTY() && logger.EnableVirtualTerminal(os.Stderr))
+	_, _ = fmt.Fprintf(out, "  Colored logs  : %t\n", !cfg.NoColor && logger.ColorEnabled())
+}
+
+// configFileStatus returns the config file path, noting if the file does not exist.
+func configFileStatus(path string) string {
+	if path == "" {
+		return "(unavailable)"
+	}
+	if _, err := os.Stat(path); err != nil {
+		return path + " (not found)"
+	}
+	return path
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// redact hides a secret while still showing whether it is set.
+func redact(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	return fmt.Sprintf("(set, %d chars, redacted)", len(secret))
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of failed syncs, so scripts can tell them apart from other errors (exit code 1).
+// Exit code 2 is taken by the flag package for usage errors.
+const (
+	ExitPartialFailure = 3 // some bookmarks failed to sync, the others were synced
+	ExitTotalFailure   = 4 // no bookmark was synced
+)
+
+// ExitError is an error to exit the program with the given code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// failureLimit is the -max-failures threshold, either a number of bookmarks or a percentage of them.
+type failureLimit struct {
+	set     bool
+	n       int
+	percent float64
+}
+
+// parseFailureLimit parses a -max-failures value, e.g., "10" or "5%".
+func parseFailureLimit(s string) (failureLimit, error) {
+	if num, ok := strings.CutSuffix(s, "%"); ok {
+		p, err := strconv.ParseFloat(num, 64)
+		if err != nil || p < 0 || p > 100 {
+			return failureLimit{}, fmt.Errorf("invalid percentage %q, expected 0%% to 100%%", s)
+		}
+		return failureLimit{set: true, percent: p}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return failureLimit{}, fmt.Errorf("invalid value %q, expected a non-negative number or a percentage", s)
+	}
+	return failureLimit{set: true, n: n}, nil
+}
+
+// of returns the number of failures tolerated out of total bookmarks, or -1 if unlimited.
+func (l failureLimit) of(total int) int {
+	switch {
+	case !l.set:
+		return -1
+	case l.percent > 0:
+		return int(l.percent * float64(total) / 100)
+	default:
+		return l.n
+	}
+}
+
+// failureError returns the error of a sync in which failed bookmarks out of total failed and
+// synced ones succeeded, or nil if nothing failed or the failures are within -max-failures.
+// The exit code tells a partial failure from a total one.
+func failureError(limit failureLimit, total, failed, synced int, verb string) error {
+	if failed == 0 {
+		return nil
+	}
+	tolerated := limit.of(total)
+	if tolerated >= 0 && failed <= tolerated {
+		fmt.Fprintf(os.Stderr, "Warning: %d bookmark(s) failed to %s, within -max-failures %d\n", failed, verb, tolerated)
+		return nil
+	}
+
+	err := fmt.Errorf("%d bookmark(s) failed to %s", failed, verb)
+	if tolerated >= 0 {
+		err = fmt.Errorf("aborted after %d bookmark(s) failed to %s, more than -max-failures %d", failed, verb, tolerated)
+	}
+	code := ExitPartialFailure
+	if synced == 0 {
+		code = ExitTotalFailure
+	}
+	return &ExitError{Code: code, Err: err}
+}
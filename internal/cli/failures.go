@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+// failuresFile is the name of the failures report inside the state directory.
+const failuresFile = "failures.json"
+
+// failure is a bookmark that failed at some stage of the pipeline, as listed in the failures report.
+type failure struct {
+	HNID  int    `json:"hnId,omitempty"` // unknown for bookmarks resumed from a checkpoint
+	URL   string `json:"url"`
+	Stage string `json:"stage"` // fetch, sync, or post
+	Error string `json:"error"`
+}
+
+// failuresPath returns the failures report path for the given state directory.
+func failuresPath(stateDir string) string {
+	return filepath.Join(stateDir, failuresFile)
+}
+
+// reportFailures writes the failures of the run to the failures report, so scripts can act on them,
+// or removes the report of an earlier run if nothing failed. Errors are reported as warnings.
+func reportFailures(cfg *Config, failures []failure) {
+	if cfg.StateDir == "" || cfg.DryRun {
+		return
+	}
+	path := failuresPath(cfg.StateDir)
+
+	if len(failures) == 0 {
+		if err := removeCheckpoint(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: removing failures report: %v\n", err)
+		}
+		return
+	}
+	if err := saveFailures(path, failures); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saving failures report: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Saved %d failure(s) to %s\n", len(failures), path)
+}
+
+// syncFailure returns the failure of a bookmark that failed to push at the given stage.
+func syncFailure(bm converter.Bookmark, stage string, err error) failure {
+	return failure{HNID: bm.Origin.ID, URL: bm.Content.URL, Stage: stage, Error: err.Error()}
+}
+
+// loadFailures reads a failures report written by saveFailures.
+func loadFailures(path string) ([]failure, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no failures report at %s, nothing to retry", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var failures []failure
+	if err := json.Unmarshal(data, &failures); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return failures, nil
+}
+
+// filterByFailures keeps the bookmarks listed in the failures report, matched by HN ID.
+// Failures that match no bookmark, e.g., the story of a bookmarked comment or a bookmark
+// resumed from a checkpoint, are counted in a warning as they can't be retried this way.
+func filterByFailures(bookmarks []harmonic.Bookmark, failures []failure) []harmonic.Bookmark {
+	failed := make(map[int]bool, len(failures))
+	for _, f := range failures {
+		if f.HNID != 0 {
+			failed[f.HNID] = true
+		}
+	}
+
+	filtered := make([]harmonic.Bookmark, 0, len(failed))
+	matched := make(map[int]bool, len(failed))
+	for _, bm := range bookmarks {
+		if failed[bm.ID] {
+			filtered = append(filtered, bm)
+			matched[bm.ID] = true
+		}
+	}
+	unmatched := 0
+	for _, f := range failures {
+		if !matched[f.HNID] {
+			unmatched++
+		}
+	}
+	if unmatched > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d failure(s) in the report match no input bookmark and are not retried "+
+			"(bookmarked comments are reported by their story; use -resume for bookmarks of a failed sync)\n", unmatched)
+	}
+	return filtered
+}
+
+// saveFailures writes the failures report as a JSON array, through a temporary file like saveCheckpoint.
+func saveFailures(path string, failures []failure) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
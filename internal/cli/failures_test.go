@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+func TestFailures(t *testing.T) {
+	path := failuresPath(filepath.Join(t.TempDir(), "state"))
+	if _, err := loadFailures(path); err == nil || !strings.Contains(err.Error(), "nothing to retry") {
+		t.Errorf("loadFailures() without a report error = %v, want nothing to retry", err)
+	}
+
+	failures := []failure{
+		{HNID: 1, URL: "https://example.com/1", Stage: "fetch", Error: "timeout"},
+		{HNID: 3, URL: "https://example.com/3", Stage: "sync", Error: "HTTP 500"},
+		{URL: "https://example.com/resumed", Stage: "sync", Error: "HTTP 500"}, // from a checkpoint
+	}
+	if err := saveFailures(path, failures); err != nil {
+		t.Fatalf("saveFailures() error = %v", err)
+	}
+	got, err := loadFailures(path)
+	if err != nil {
+		t.Fatalf("loadFailures() error = %v", err)
+	}
+	if !slices.Equal(got, failures) {
+		t.Errorf("loadFailures() = %+v, want %+v", got, failures)
+	}
+
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}, {ID: 3}}
+	if retried := filterByFailures(bookmarks, got); !slices.Equal(retried, []harmonic.Bookmark{{ID: 1}, {ID: 3}}) {
+		t.Errorf("filterByFailures() = %v, want bookmarks 1 and 3", retried)
+	}
+}
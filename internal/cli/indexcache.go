@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// indexCache is the pre-fetched Karakeep bookmark index saved for -karakeep-cache.
+type indexCache struct {
+	APIURL      string                               `json:"apiUrl"`      // Karakeep instance listed
+	PrefetchTag string                               `json:"prefetchTag"` // -prefetch-tag of the listing, empty for all
+	SavedAt     int64                                `json:"savedAt"`     // Unix timestamp
+	Bookmarks   map[string]karakeep.ExistingBookmark `json:"bookmarks"`
+}
+
+// indexCachePath returns the bookmark index cache file path for the given Karakeep API URL.
+func indexCachePath(cacheDir, apiURL string) string {
+	return filepath.Join(cacheDir, "karakeep-index-"+serverKey(apiURL)+".json")
+}
+
+// loadIndexCache returns the cached bookmark index if it lists the given instance and tag and was
+// saved less than maxAge ago, with its age. A missing, stale, or unreadable cache is a miss.
+func loadIndexCache(path, apiURL, prefetchTag string, maxAge time.Duration) (map[string]karakeep.ExistingBookmark, time.Duration, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	var cache indexCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Bookmarks == nil {
+		return nil, 0, false
+	}
+	age := time.Since(time.Unix(cache.SavedAt, 0))
+	if cache.APIURL != apiURL || cache.PrefetchTag != prefetchTag || age < 0 || age >= maxAge {
+		return nil, 0, false
+	}
+	return cache.Bookmarks, age, true
+}
+
+// saveIndexCache writes the bookmark index to the cache file, through a temporary file like
+// saveCheckpoint. The file is private to the user as it holds the notes of the bookmarks.
+func saveIndexCache(path, apiURL, prefetchTag string, existing map[string]karakeep.ExistingBookmark) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(indexCache{
+		APIURL:      apiURL,
+		PrefetchTag: prefetchTag,
+		SavedAt:     time.Now().Unix(),
+		Bookmarks:   existing,
+	})
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// invalidateIndexCache deletes the bookmark index cached for the Karakeep instance once a command changed
+// its bookmarks, whether or not that run used -karakeep-cache, so a later run within the window neither
+// skips deleted bookmarks as existing nor misses created ones. A failure is only a warning.
+func invalidateIndexCache(cacheDir, apiURL string) {
+	if cacheDir == "" {
+		return
+	}
+	if err := removeIndexCache(indexCachePath(cacheDir, apiURL)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: removing the Karakeep bookmark index cache: %v\n", err)
+	}
+}
+
+// removeIndexCache deletes the cache file.
+func removeIndexCache(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
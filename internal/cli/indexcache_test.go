@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+func TestIndexCache(t *testing.T) {
+	const apiURL = "https://karakeep.example.com/api/v1"
+	path := indexCachePath(t.TempDir(), apiURL)
+	existing := map[string]karakeep.ExistingBookmark{"https://example.com": {ID: "bm-1"}}
+	if err := saveIndexCache(path, apiURL, "", existing); err != nil {
+		t.Fatalf("saveIndexCache() error = %v", err)
+	}
+
+	if got, _, ok := loadIndexCache(path, apiURL, "", time.Minute); !ok || got["https://example.com"].ID != "bm-1" {
+		t.Errorf("loadIndexCache() = %v, %v, want the saved index", got, ok)
+	}
+	misses := map[string]struct {
+		apiURL, tag string
+		maxAge      time.Duration
+	}{
+		"other instance": {"https://other.example.com/api/v1", "", time.Minute},
+		"other tag":      {apiURL, "src:hackernews", time.Minute},
+		"stale":          {apiURL, "", 0},
+	}
+	for name, tc := range misses {
+		t.Run(name, func(t *testing.T) {
+			if _, _, ok := loadIndexCache(path, tc.apiURL, tc.tag, tc.maxAge); ok {
+				t.Error("loadIndexCache() hit, want a miss")
+			}
+		})
+	}
+
+	invalidateIndexCache(filepath.Dir(path), apiURL)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cache file after invalidateIndexCache() stat error = %v, want it removed", err)
+	}
+	invalidateIndexCache(filepath.Dir(path), apiURL) // a missing cache is fine
+}
+
+func TestRunPrune_InvalidatesIndexCache(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/tags", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(karakeep.ListTagsResponse{Tags: []karakeep.Tag{{ID: "tag-1", Name: "hnkeep:20260101"}}})
+	})
+	mux.HandleFunc("GET /api/v1/tags/tag-1/bookmarks", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"bookmarks": [{"id": "bm-1", "createdAt": "2026-01-01T00:00:00.000Z", "source": "api",
+			"content": {"type": "link", "url": "https://example.com"}}], "nextCursor": null}`))
+	})
+	mux.HandleFunc("DELETE /api/v1/bookmarks/bm-1", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	apiURL := server.URL + "/api/v1"
+
+	t.Setenv("XDG_STATE_HOME", t.TempDir()) // for the lock
+	cacheDir := t.TempDir()
+	path := indexCachePath(cacheDir, apiURL)
+	existing := map[string]karakeep.ExistingBookmark{"https://example.com": {ID: "bm-1"}}
+	if err := saveIndexCache(path, apiURL, "", existing); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runPrune(context.Background(), []string{"-tag", "hnkeep:20260101", "-yes",
+		"-api-url", apiURL, "-api-key", "secret", "-cache-dir", cacheDir})
+	if err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if _, _, ok := loadIndexCache(path, apiURL, "", time.Hour); ok {
+		t.Error("index cache still lists the pruned bookmark, want it cleared")
+	}
+}
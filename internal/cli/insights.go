@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// histogramWidth is the width of the longest bar of the stats histograms, in characters.
+const histogramWidth = 40
+
+// scoreBuckets are the lower bounds of the score distribution buckets.
+var scoreBuckets = []int{0, 10, 50, 100, 250, 500, 1000}
+
+// insightsConfig holds the configuration of the stats command.
+type insightsConfig struct {
+	InputPath   string     // Path to input file
+	InputFormat string     // Input file format
+	Before      int64      // Only include bookmarks before this timestamp
+	After       int64      // Only include bookmarks after this timestamp
+	Top         int        // Number of domains and authors listed
+	Concurrency int        // Number of concurrent HN API calls
+	HNRateLimit float64    // Max HN API requests per second (0 = unlimited)
+	CacheDir    string     // HN API responses cache directory path, empty to disable caching
+	Verbose     bool       // Show per-item messages
+	LogLevel    slog.Level // Minimum level of log messages
+	NoColor     bool       // Disable colored log output
+}
+
+// counted is a value of a top list and its number of bookmarks.
+type counted struct {
+	value string
+	n     int
+}
+
+// parseInsightsFlags parses the stats command arguments.
+func parseInsightsFlags(args []string) (*insightsConfig, error) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep stats -i <export.txt> [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Fetch the HN items of the input bookmarks (through the cache) and report the top domains,\n")
+		_, _ = fmt.Fprintf(fs.Output(), "top authors, score distribution, and saves per month. Nothing is converted or synced.\n\n")
+		fs.PrintDefaults()
+	}
+
+	inputPath := fs.String("input", "", "Input file path (default: stdin)")
+	fs.StringVar(inputPath, "i", "", "alias for -input")
+	inputFormat := fs.String("input-format", formatHarmonic, "Input format: harmonic, idmap, idlist, materialistic, glider, or hews")
+	fs.StringVar(inputFormat, "source", formatHarmonic, "alias for -input-format")
+	before := fs.String("before", "", "Only include Harmonic bookmarks before this timestamp")
+	after := fs.String("after", "", "Only include Harmonic bookmarks after this timestamp")
+	top := fs.Int("top", 10, "Number of domains and authors to list")
+	concurrency := fs.Int("concurrency", 5, "Number of concurrent HN API calls")
+	hnRPS := fs.Float64("hn-rps", 10, "Max HN API requests per second across all workers (0 = unlimited)")
+	cacheDir := fs.String("cache-dir", getDefaultCacheDir(), "HN API responses cache directory path")
+	noCache := fs.Bool("no-cache", false, "Disable caching of HN API responses")
+	verbose := fs.Bool("verbose", false, "Show a message for every fetched item")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn, or error (default: info with -verbose, else warn)")
+	noColor := fs.Bool("no-color", false, "Disable colored log output (also via the NO_COLOR env var)")
+
+	profile := fs.String("profile", "", "Use the flag values of this named profile in the config file (others are ignored)")
+	configPath := fs.String("config", getDefaultConfigPath(), "Config file path")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *profile != "" {
+		if err := applyProfile(fs, *configPath, *profile, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkInputFormat(*inputFormat); err != nil {
+		return nil, err
+	}
+	var beforeTS, afterTS int64
+	if *before != "" {
+		t, err := parseDate(*before)
+		if err != nil {
+			return nil, fmt.Errorf("parsing -before date: %w", err)
+		}
+		beforeTS = t.Unix()
+	}
+	if *after != "" {
+		t, err := parseDate(*after)
+		if err != nil {
+			return nil, fmt.Errorf("parsing -after date: %w", err)
+		}
+		afterTS = t.Unix()
+	}
+	if *top < 1 {
+		return nil, fmt.Errorf("-top must be at least 1, got %d", *top)
+	}
+	if *concurrency < 1 {
+		return nil, fmt.Errorf("-concurrency must be at least 1, got %d", *concurrency)
+	}
+	if *hnRPS < 0 {
+		return nil, errors.New("-hn-rps must not be negative")
+	}
+	if *noCache {
+		*cacheDir = ""
+	}
+
+	level, err := resolveLogLevel(*logLevel, *verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return &insightsConfig{
+		InputPath:   *inputPath,
+		InputFormat: *inputFormat,
+		Before:      beforeTS,
+		After:       afterTS,
+		Top:         *top,
+		Concurrency: *concurrency,
+		HNRateLimit: *hnRPS,
+		CacheDir:    *cacheDir,
+		Verbose:     *verbose || level <= slog.LevelInfo,
+		LogLevel:    level,
+		NoColor:     *noColor,
+	}, nil
+}
+
+// runInsights runs the stats command, a read-only look at the input bookmarks. Items are fetched
+// like a conversion would, so a later conversion finds them in the cache.
+func runInsights(ctx context.Context, args []string) error {
+	cfg, err := parseInsightsFlags(args)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	input, err := readInput(cfg.InputPath)
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+	bookmarks, err := parseInput(input, cfg.InputFormat)
+	if err != nil {
+		return fmt.Errorf("parsing input: %w", err)
+	}
+	bookmarks = filterByDate(bookmarks, cfg.Before, cfg.After)
+
+	log := newStderrLogger(os.Stderr, cfg.LogLevel, cfg.NoColor)
+	limiter := newLimiter(cfg.Concurrency, log, "HN fetch")
+	client := hackernews.NewClient(
+		hackernews.WithLogger(log),
+		hackernews.WithRateLimit(cfg.HNRateLimit),
+		hackernews.WithRateLimitHook(limiter.Throttle),
+	)
+	var fetcher converter.ItemFetcher = client
+	if cfg.CacheDir != "" {
+		if fetcher, err = hackernews.NewCachedClient(client, cfg.CacheDir, hackernews.WithCacheLogger(log)); err != nil {
+			return fmt.Errorf("creating cached client: %w", err)
+		}
+	}
+	conv := converter.New(
+		converter.WithFetcher(fetcher),
+		converter.WithLimiter(limiter),
+		converter.WithLogger(log),
+	)
+
+	phase := newPhaser(cfg.Verbose).Start("Fetching items")
+	items, err := conv.FetchItems(ctx, bookmarks)
+	if err != nil {
+		phase.Fail()
+		return fmt.Errorf("fetching items: %w", err)
+	}
+	phase.Done("fetched %d of %d", len(items), len(bookmarks))
+
+	printInsights(os.Stdout, bookmarks, items, cfg.Top)
+	return nil
+}
+
+// printInsights writes the stats report of the bookmarks and their fetched items. Bookmarks whose
+// item couldn't be fetched still count in the saves per month, which only need the input.
+func printInsights(w io.Writer, bookmarks []harmonic.Bookmark, items map[int]*hackernews.Item, top int) {
+	domains := make(map[string]int)
+	authors := make(map[string]int)
+	scores := make([]int, len(scoreBuckets))
+	months := make(map[string]int)
+	var noLink, undated int
+
+	for _, bm := range bookmarks {
+		if bm.Timestamp > 0 {
+			months[time.Unix(bm.Timestamp, 0).Format("2006-01")]++
+		} else {
+			undated++
+		}
+		item, ok := items[bm.ID]
+		if !ok {
+			continue
+		}
+		if item.By != "" {
+			authors[item.By]++
+		}
+		if host := insightsHost(item.URL); host != "" {
+			domains[host]++
+		} else {
+			noLink++
+		}
+		if item.Type != "comment" { // comments have no public score
+			scores[scoreBucket(item.Score)]++
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "Bookmarks: %d (%d fetched)\n", len(bookmarks), len(items))
+
+	_, _ = fmt.Fprintf(w, "\nTop domains")
+	if noLink > 0 {
+		_, _ = fmt.Fprintf(w, " (%d without a link, e.g., Ask HN or comments)", noLink)
+	}
+	_, _ = fmt.Fprintln(w, ":")
+	printTop(w, domains, top)
+
+	_, _ = fmt.Fprintln(w, "\nTop authors:")
+	printTop(w, authors, top)
+
+	_, _ = fmt.Fprintln(w, "\nScore distribution:")
+	labels := make([]string, len(scoreBuckets))
+	for i, lower := range scoreBuckets {
+		if i == len(scoreBuckets)-1 {
+			labels[i] = fmt.Sprintf("%d+", lower)
+		} else {
+			labels[i] = fmt.Sprintf("%d-%d", lower, scoreBuckets[i+1]-1)
+		}
+	}
+	printHistogram(w, labels, scores)
+
+	_, _ = fmt.Fprintf(w, "\nSaves per month")
+	if undated > 0 {
+		_, _ = fmt.Fprintf(w, " (%d without a save date)", undated)
+	}
+	_, _ = fmt.Fprintln(w, ":")
+	labels, counts := monthRange(months)
+	printHistogram(w, labels, counts)
+}
+
+// insightsHost returns the host of rawURL without "www.", or "" if it has none.
+func insightsHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// scoreBucket returns the index of the scoreBuckets bucket of the score.
+func scoreBucket(score int) int {
+	i := len(scoreBuckets) - 1
+	for i > 0 && score < scoreBuckets[i] {
+		i--
+	}
+	return i
+}
+
+// monthRange returns every month from the earliest to the latest of months, including the months
+// without saves so gaps show in the histogram, with their number of saves.
+func monthRange(months map[string]int) ([]string, []int) {
+	if len(months) == 0 {
+		return nil, nil
+	}
+	keys := slices.Sorted(maps.Keys(months))
+	first, _ := time.Parse("2006-01", keys[0])
+	last, _ := time.Parse("2006-01", keys[len(keys)-1])
+
+	var labels []string
+	var counts []int
+	for m := first; !m.After(last); m = m.AddDate(0, 1, 0) {
+		label := m.Format("2006-01")
+		labels = append(labels, label)
+		counts = append(counts, months[label])
+	}
+	return labels, counts
+}
+
+// printTop writes the n most frequent values of counts, ties broken alphabetically.
+func printTop(w io.Writer, counts map[string]int, n int) {
+	if len(counts) == 0 {
+		_, _ = fmt.Fprintln(w, "  (none)")
+		return
+	}
+	list := make([]counted, 0, len(counts))
+	for value, c := range counts {
+		list = append(list, counted{value, c})
+	}
+	slices.SortFunc(list, func(a, b counted) int {
+		return cmp.Or(cmp.Compare(b.n, a.n), strings.Compare(a.value, b.value))
+	})
+	for _, c := range list[:min(n, len(list))] {
+		_, _ = fmt.Fprintf(w, "  %6d  %s\n", c.n, c.value)
+	}
+}
+
+// printHistogram writes a bar per label, scaled so the largest count spans histogramWidth.
+func printHistogram(w io.Writer, labels []string, counts []int) {
+	if len(labels) == 0 {
+		_, _ = fmt.Fprintln(w, "  (none)")
+		return
+	}
+	width := 0
+	for _, label := range labels {
+		width = max(width, len(label))
+	}
+	largest := slices.Max(counts)
+	for i, label := range labels {
+		bar := 0
+		if largest > 0 {
+			bar = (counts[i]*histogramWidth + largest - 1) / largest // any non-zero count gets a bar
+		}
+		line := fmt.Sprintf("  %-*s %6d  %s", width, label, counts[i], strings.Repeat("#", bar))
+		_, _ = fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
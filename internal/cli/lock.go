@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runLock is an exclusive lockfile preventing concurrent syncs to the same Karakeep server.
+// Two processes syncing the same bookmarks would both see them as missing in their
+// pre-fetch and race on create/merge, producing duplicated notes.
+type runLock struct {
+	path string
+}
+
+// serverKey returns a short, file-name-safe key identifying the given Karakeep API URL.
+func serverKey(apiURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimRight(apiURL, "/")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// lockPath returns the lockfile path for the given Karakeep API URL.
+func lockPath(stateDir, apiURL string) string {
+	return filepath.Join(stateDir, "sync-"+serverKey(apiURL)+".lock")
+}
+
+// acquireLock creates the lockfile for the given API URL, failing if another live process holds it.
+// A lockfile left behind by a crashed process (dead PID) is taken over.
+func acquireLock(stateDir, apiURL string) (*runLock, error) {
+	path := lockPath(stateDir, apiURL)
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	for range 2 { // second attempt after removing a stale lock
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, werr := fmt.Fprintf(f, "%d\n%s\n%s\n", os.Getpid(), apiURL, time.Now().Format(time.RFC3339))
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				_ = os.Remove(path)
+				return nil, werr
+			}
+			return &runLock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		pid, started := readLock(path)
+		if pid > 0 && processAlive(pid) {
+			return nil, fmt.Errorf("another hnkeep process (PID %d, started %s) is syncing to %s; "+
+				"remove %s if this is wrong", pid, started, apiURL, path)
+		}
+		_ = os.Remove(path) // stale lock from a crashed run
+	}
+	return nil, fmt.Errorf("could not acquire lock %s", path)
+}
+
+// readLock returns the PID and start time recorded in the lockfile, or zero values if unreadable.
+func readLock(path string) (int, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, ""
+	}
+	lines := strings.Split(string(data), "\n")
+	pid, _ := strconv.Atoi(strings.TrimSpace(lines[0]))
+	started := ""
+	if len(lines) > 2 {
+		started = strings.TrimSpace(lines[2])
+	}
+	return pid, started
+}
+
+// release removes the lockfile.
+func (l *runLock) release() error {
+	return os.Remove(l.path)
+}
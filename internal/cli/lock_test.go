@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	const apiURL = "https://karakeep.example.com/api/v1"
+	stateDir := t.TempDir()
+
+	lock, err := acquireLock(stateDir, apiURL)
+	if err != nil {
+		t.Fatalf("acquireLock() error = %v", err)
+	}
+	if _, err := acquireLock(stateDir, apiURL); err == nil || !strings.Contains(err.Error(), "another hnkeep process") {
+		t.Errorf("second acquireLock() error = %v, want the lock held by this process", err)
+	}
+	other, err := acquireLock(stateDir, "https://other.example.com/api/v1")
+	if err != nil {
+		t.Fatalf("acquireLock() of another server error = %v, want a lock of its own", err)
+	}
+	_ = other.release()
+
+	if err := lock.release(); err != nil {
+		t.Fatalf("release() error = %v", err)
+	}
+	lock, err = acquireLock(stateDir, apiURL)
+	if err != nil {
+		t.Fatalf("acquireLock() after release error = %v", err)
+	}
+	_ = lock.release()
+}
+
+func TestAcquireLock_Stale(t *testing.T) {
+	const apiURL = "https://karakeep.example.com/api/v1"
+	stateDir := t.TempDir()
+	// a lockfile without a live PID, e.g., cut short by a crash
+	if err := os.WriteFile(lockPath(stateDir, apiURL), []byte("garbage\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := acquireLock(stateDir, apiURL)
+	if err != nil {
+		t.Fatalf("acquireLock() error = %v, want the stale lock taken over", err)
+	}
+	if pid, _ := readLock(lock.path); pid != os.Getpid() {
+		t.Errorf("lock PID = %d, want this process %d", pid, os.Getpid())
+	}
+	_ = lock.release()
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package cli
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	processQueryLimitedInformation = 0x1000 // PROCESS_QUERY_LIMITED_INFORMATION
+	stillActive                    = 259    // STILL_ACTIVE, the exit code of a running process
+)
+
+// processAlive reports whether a process with the given PID is running.
+// Opening the process succeeds as long as a handle to it is held somewhere, even after it exited,
+// so its exit code is checked; access denied means the process exists but belongs to another user.
+func processAlive(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true // can't tell, so don't take over the lock
+	}
+	return code == stillActive
+}
@@ -3,26 +3,34 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
 )
 
 // stats tracks bookmark counts at each pipeline stage and timing statistics.
 type stats struct {
 	// converter stats
 	found       int
+	notRetried  int // not listed in the failures report of -report
+	overridden  int // skipped by -overrides
+	excluded    int // dropped by -exclude-ids
 	afterFilter int
 	afterLimit  int
 	skipped     int
+	filtered    int // dropped by item filters such as -min-score, or by -transform
 	converted   int
 	deduped     int
 	cacheHits   int
+	reused      int // items reused from the fetch checkpoint of an interrupted run
 	totalStart  time.Time
 	fetchStart  time.Time
 	fetchEnd    time.Time
 
 	// sync stats
+	resumed     bool // synced from checkpoint, so no fetch/convert stats
 	prefetched  int
 	syncCreated int
 	syncUpdated int
@@ -30,6 +38,8 @@ type stats struct {
 	syncFailed  int
 	syncStart   time.Time
 	syncEnd     time.Time
+
+	failures []failure // bookmarks that failed to fetch or sync, for the failures report
 }
 
 func (s *stats) totalDuration() time.Duration {
@@ -51,11 +61,43 @@ func (s *stats) syncDuration() time.Duration {
 	return s.syncEnd.Sub(s.syncStart)
 }
 
+// reconciled reports whether every processed input bookmark is accounted for
+// as either converted, filtered, skipped, or deduplicated.
+func (s *stats) reconciled() bool {
+	return s.accounted() == s.afterLimit
+}
+
+// accounted returns the number of processed input bookmarks with a known outcome.
+func (s *stats) accounted() int {
+	return s.converted + s.filtered + s.skipped + s.deduped
+}
+
+// printReconciliation prints how the processed input bookmarks are accounted for.
+func printReconciliation(stats stats) {
+	mark := "ok"
+	if !stats.reconciled() {
+		mark = fmt.Sprintf("MISMATCH, %d unaccounted", stats.afterLimit-stats.accounted())
+	}
+	fmt.Fprintf(os.Stderr, "  Reconciled    : %d = %d converted + %d filtered + %d skipped + %d deduplicated (%s)\n",
+		stats.afterLimit, stats.converted, stats.filtered, stats.skipped, stats.deduped, mark)
+}
+
 // printPipelineStats prints the common pipeline statistics (found, filtered, limited)
 func printPipelineStats(stats stats) {
 	fmt.Fprintf(os.Stderr, "Bookmarks found : %d\n", stats.found)
 
-	dateFiltered := stats.found - stats.afterFilter
+	if stats.notRetried > 0 {
+		fmt.Fprintf(os.Stderr, "  Not retried   : -%d   (not in -report)\n", stats.notRetried)
+	}
+	if stats.overridden > 0 {
+		fmt.Fprintf(os.Stderr, "  Overridden    : -%d   (skip in -overrides)\n", stats.overridden)
+	}
+
+	if stats.excluded > 0 {
+		fmt.Fprintf(os.Stderr, "  Excluded      : -%d   (-exclude-ids)\n", stats.excluded)
+	}
+
+	dateFiltered := stats.found - stats.notRetried - stats.overridden - stats.excluded - stats.afterFilter
 	if dateFiltered > 0 {
 		fmt.Fprintf(os.Stderr, "  Date filtered : -%d\n", dateFiltered)
 	}
@@ -74,15 +116,22 @@ func printSummary(stats stats) {
 	if stats.skipped > 0 {
 		fmt.Fprintf(os.Stderr, "  Fetch skipped : -%d   (deleted/dead/not found)\n", stats.skipped)
 	}
+	if stats.filtered > 0 {
+		fmt.Fprintf(os.Stderr, "  Item filtered : -%d   (-min-score/-types/-filter/domain filters, -transform)\n", stats.filtered)
+	}
 
 	if stats.deduped > 0 {
 		fmt.Fprintf(os.Stderr, "  Deduplicated  : -%d   (merged duplicate URLs)\n", stats.deduped)
 	}
 
 	fmt.Fprintf(os.Stderr, "Converted       : %d\n", stats.converted)
+	printReconciliation(stats)
 
 	if stats.cacheHits > 0 || stats.afterLimit > stats.cacheHits {
-		fromAPI := stats.afterLimit - stats.cacheHits
+		fromAPI := stats.afterLimit - stats.cacheHits - stats.reused
+		if stats.reused > 0 {
+			fmt.Fprintf(os.Stderr, "  From resume   : %d   (fetch checkpoint)\n", stats.reused)
+		}
 		fmt.Fprintf(os.Stderr, "  From cache    : %d\n", stats.cacheHits)
 		fmt.Fprintf(os.Stderr, "  From API      : %d\n", fromAPI)
 	}
@@ -103,15 +152,24 @@ func printSyncSummary(stats stats) {
 	if stats.skipped > 0 {
 		fmt.Fprintf(os.Stderr, "  Fetch skipped : -%d   (deleted/dead/not found)\n", stats.skipped)
 	}
+	if stats.filtered > 0 {
+		fmt.Fprintf(os.Stderr, "  Item filtered : -%d   (-min-score/-types/-filter/domain filters, -transform)\n", stats.filtered)
+	}
 
 	if stats.deduped > 0 {
 		fmt.Fprintf(os.Stderr, "  Deduplicated  : -%d   (merged duplicate URLs)\n", stats.deduped)
 	}
 
 	fmt.Fprintf(os.Stderr, "Converted       : %d\n", stats.converted)
+	if !stats.resumed {
+		printReconciliation(stats)
+	}
 
-	if stats.cacheHits > 0 || stats.afterLimit > stats.cacheHits {
-		fromAPI := stats.afterLimit - stats.cacheHits
+	if !stats.resumed && (stats.cacheHits > 0 || stats.afterLimit > stats.cacheHits) {
+		fromAPI := stats.afterLimit - stats.cacheHits - stats.reused
+		if stats.reused > 0 {
+			fmt.Fprintf(os.Stderr, "  From resume   : %d   (fetch checkpoint)\n", stats.reused)
+		}
 		fmt.Fprintf(os.Stderr, "  From cache    : %d\n", stats.cacheHits)
 		fmt.Fprintf(os.Stderr, "  From API      : %d\n", fromAPI)
 	}
@@ -129,12 +187,14 @@ func printSyncSummary(stats stats) {
 
 	fmt.Fprintf(os.Stderr, "\nTiming:\n")
 	fmt.Fprintf(os.Stderr, "  Total time    : %.2fs\n", stats.totalDuration().Seconds())
-	fmt.Fprintf(os.Stderr, "  Fetch time    : %.2fs\n", stats.fetchDuration().Seconds())
+	if !stats.resumed {
+		fmt.Fprintf(os.Stderr, "  Fetch time    : %.2fs\n", stats.fetchDuration().Seconds())
+	}
 	fmt.Fprintf(os.Stderr, "  Sync time     : %.2fs\n", stats.syncDuration().Seconds())
 }
 
 // printDryRunMode prints statistics about the bookmarks without making any API calls.
-func printDryRunMode(stats stats, bookmarks []harmonic.Bookmark, syncMode bool) {
+func printDryRunMode(stats stats, bookmarks []harmonic.Bookmark) {
 	fmt.Fprintf(os.Stderr, "=== Dry Run ===\n")
 	printPipelineStats(stats)
 	fmt.Fprintf(os.Stderr, "To process      : %d\n", stats.afterLimit)
@@ -156,9 +216,49 @@ func printDryRunMode(stats stats, bookmarks []harmonic.Bookmark, syncMode bool)
 		fmt.Fprintf(os.Stderr, "  Newest        : %s\n", time.Unix(maxTS, 0).UTC().Format("2006-01-02"))
 	}
 
-	if syncMode {
-		fmt.Fprintf(os.Stderr, "\nWould sync %d bookmarks to Karakeep.\n", stats.afterLimit)
-	} else {
-		fmt.Fprintf(os.Stderr, "\nNo API calls made.\n")
+	fmt.Fprintf(os.Stderr, "\nNo API calls made.\n")
+}
+
+// printSyncPlan prints the per-bookmark actions a sync would take, followed by a summary.
+func printSyncPlan(stats stats, plan []syncer.PlanEntry) {
+	fmt.Fprintf(os.Stderr, "=== Sync Plan (dry run) ===\n")
+	counts := make(map[syncer.SyncStatus]int)
+	for _, entry := range plan {
+		counts[entry.Status]++
+		switch entry.Status {
+		case syncer.SyncCreated:
+			fmt.Fprintf(os.Stderr, "  would create : %s\n", entry.URL)
+		case syncer.SyncUpdated:
+			fmt.Fprintf(os.Stderr, "  would update : %s (%s)\n", entry.URL, strings.Join(entry.Changes, ", "))
+		case syncer.SyncSkipped:
+			fmt.Fprintf(os.Stderr, "  would skip   : %s\n", entry.URL)
+		default:
+			fmt.Fprintf(os.Stderr, "  would fail   : %s (%v)\n", entry.URL, entry.Err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\n")
+	printPipelineStats(stats)
+	if stats.skipped > 0 {
+		fmt.Fprintf(os.Stderr, "  Fetch skipped : -%d   (deleted/dead/not found)\n", stats.skipped)
+	}
+	if stats.filtered > 0 {
+		fmt.Fprintf(os.Stderr, "  Item filtered : -%d   (-min-score/-types/-filter/domain filters, -transform)\n", stats.filtered)
+	}
+	if stats.deduped > 0 {
+		fmt.Fprintf(os.Stderr, "  Deduplicated  : -%d   (merged duplicate URLs)\n", stats.deduped)
+	}
+	fmt.Fprintf(os.Stderr, "Converted       : %d\n", stats.converted)
+	printReconciliation(stats)
+
+	fmt.Fprintf(os.Stderr, "\nPlanned actions:\n")
+	fmt.Fprintf(os.Stderr, "  Pre-fetched   : %d   (existing bookmarks)\n", stats.prefetched)
+	fmt.Fprintf(os.Stderr, "  Create        : %d\n", counts[syncer.SyncCreated])
+	fmt.Fprintf(os.Stderr, "  Update        : %d\n", counts[syncer.SyncUpdated])
+	fmt.Fprintf(os.Stderr, "  Skip          : %d   (already up-to-date)\n", counts[syncer.SyncSkipped])
+	if counts[syncer.SyncFailed] > 0 {
+		fmt.Fprintf(os.Stderr, "  Fail          : %d\n", counts[syncer.SyncFailed])
 	}
+
+	fmt.Fprintf(os.Stderr, "\nNo changes made to Karakeep.\n")
 }
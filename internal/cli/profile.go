@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// configFile is the hnkeep config file. Each profile is a named block of flag values,
+// e.g., {"profiles": {"work": {"api-url": "https://karakeep.example.com/api/v1", "tags": "hn,work"}}}.
+type configFile struct {
+	Profiles map[string]map[string]any `json:"profiles"`
+}
+
+// getDefaultConfigPath returns the default config file path following platform conventions.
+// Returns empty string if home directory cannot be determined.
+func getDefaultConfigPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "hnkeep", "config.json")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", "hnkeep", "config.json")
+	}
+	return ""
+}
+
+// profileExclusive lists the flags that a flag given on the command line also overrides in a profile,
+// because the two are mutually exclusive. Aliases, e.g., -t and -tags, are found by flagAliases.
+var profileExclusive = map[string][]string{
+	"note-preset":   {"note-template"},
+	"note-template": {"note-preset"},
+}
+
+// applyProfile sets the flags of the named profile in the config file on fs, except flags given on the
+// command line, which take precedence. Unless ignoreUnknown is set, profile keys that are not flags of fs
+// are an error; subcommands set it to pick only the flags they share with the main command.
+func applyProfile(fs *flag.FlagSet, path, name string, ignoreUnknown bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	var cfg configFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(cfg.Profiles))
+		for n := range cfg.Profiles {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		return fmt.Errorf("profile %q not found in %s (available: %s)", name, path, strings.Join(names, ", "))
+	}
+
+	given := givenFlags(fs)
+	for f := range given {
+		for _, other := range profileExclusive[f] {
+			given[other] = true
+		}
+	}
+
+	keys := make([]string, 0, len(profile))
+	for key := range profile {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys) // deterministic order for error messages
+	for _, key := range keys {
+		if key == "profile" || key == "config" {
+			return fmt.Errorf("profile %q: %q cannot be set in a profile", name, key)
+		}
+		if fs.Lookup(key) == nil {
+			if ignoreUnknown {
+				continue
+			}
+			return fmt.Errorf("profile %q: unknown flag %q", name, key)
+		}
+		if given[key] {
+			continue
+		}
+		for _, other := range profileExclusive[key] {
+			if _, ok := profile[other]; ok && !given[other] && key < other {
+				return fmt.Errorf("profile %q: %q and %q are mutually exclusive", name, key, other)
+			}
+		}
+		values, err := profileValues(profile[key])
+		if err != nil {
+			return fmt.Errorf("profile %q: flag %q: %w", name, key, err)
+		}
+		for _, v := range values {
+			if err := fs.Set(key, v); err != nil {
+				return fmt.Errorf("profile %q: flag %q: %w", name, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// givenFlags returns the flags of fs given on the command line, including the aliases of each, so a
+// short alias like -t keeps a profile from setting its long form -tags.
+func givenFlags(fs *flag.FlagSet) map[string]bool {
+	aliases := flagAliases(fs)
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+		for _, alias := range aliases[f.Name] {
+			given[alias] = true
+		}
+	})
+	return given
+}
+
+// flagAliases maps each flag of fs to the other flags sharing its flag.Value, i.e., its aliases.
+func flagAliases(fs *flag.FlagSet) map[string][]string {
+	byValue := make(map[flag.Value][]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		// Values of non-comparable types cannot be map keys, and no alias shares one
+		if !reflect.TypeOf(f.Value).Comparable() {
+			return
+		}
+		byValue[f.Value] = append(byValue[f.Value], f.Name)
+	})
+	aliases := make(map[string][]string)
+	for _, names := range byValue {
+		if len(names) < 2 {
+			continue
+		}
+		for _, name := range names {
+			for _, other := range names {
+				if other != name {
+					aliases[name] = append(aliases[name], other)
+				}
+			}
+		}
+	}
+	return aliases
+}
+
+// profileValues converts a JSON profile value to flag values. Arrays set a repeatable flag once per element.
+func profileValues(v any) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []any:
+		var values []string
+		for _, elem := range v {
+			if _, nested := elem.([]any); nested {
+				return nil, errors.New("nested arrays are not supported")
+			}
+			vs, err := profileValues(elem)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, vs...)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported value %v", v)
+}
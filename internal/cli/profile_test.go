@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newProfileFlagSet returns a FlagSet with a long flag and its short alias, a repeatable flag,
+// and the mutually exclusive note flags, parsed from args.
+func newProfileFlagSet(t *testing.T, args ...string) (*flag.FlagSet, map[string]*string, *stringList) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	values := map[string]*string{
+		"tags":          fs.String("tags", "default", ""),
+		"note-template": fs.String("note-template", "{{smart_url}}", ""),
+		"note-preset":   fs.String("note-preset", "", ""),
+	}
+	fs.StringVar(values["tags"], "t", "default", "")
+	limit := fs.Int("limit", 0, "")
+	fs.IntVar(limit, "n", 0, "")
+	var lists stringList
+	fs.Var(&lists, "list", "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parsing %v: %v", args, err)
+	}
+	return fs, values, &lists
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := map[string]struct {
+		profile string
+		args    []string
+		want    map[string]string
+	}{
+		"profile sets unset flag": {
+			profile: `{"tags": "a"}`,
+			want:    map[string]string{"tags": "a"},
+		},
+		"command line wins": {
+			profile: `{"tags": "a"}`,
+			args:    []string{"-tags", "b"},
+			want:    map[string]string{"tags": "b"},
+		},
+		"short alias on command line wins": {
+			profile: `{"tags": "a"}`,
+			args:    []string{"-t", "b"},
+			want:    map[string]string{"tags": "b"},
+		},
+		"long form on command line wins over profile alias": {
+			profile: `{"t": "a"}`,
+			args:    []string{"-tags", "b"},
+			want:    map[string]string{"tags": "b"},
+		},
+		"exclusive flag on command line overrides profile": {
+			profile: `{"note-template": "{{title}}"}`,
+			args:    []string{"-note-preset", "minimal"},
+			want:    map[string]string{"note-template": "{{smart_url}}", "note-preset": "minimal"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, `{"profiles": {"w": `+tc.profile+`}}`)
+			fs, values, _ := newProfileFlagSet(t, tc.args...)
+			if err := applyProfile(fs, path, "w", false); err != nil {
+				t.Fatalf("applyProfile() error = %v", err)
+			}
+			for flagName, want := range tc.want {
+				if got := *values[flagName]; got != want {
+					t.Errorf("-%s = %q, want %q", flagName, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("short alias of a non-string flag on command line wins", func(t *testing.T) {
+		path := writeConfig(t, `{"profiles": {"w": {"limit": 10}}}`)
+		fs, _, _ := newProfileFlagSet(t, "-n", "3")
+		if err := applyProfile(fs, path, "w", false); err != nil {
+			t.Fatalf("applyProfile() error = %v", err)
+		}
+		if got := fs.Lookup("limit").Value.String(); got != "3" {
+			t.Errorf("-limit = %s, want 3", got)
+		}
+	})
+
+	t.Run("arrays set a repeatable flag once per element", func(t *testing.T) {
+		path := writeConfig(t, `{"profiles": {"w": {"list": ["a", "b"]}}}`)
+		fs, _, lists := newProfileFlagSet(t)
+		if err := applyProfile(fs, path, "w", false); err != nil {
+			t.Fatalf("applyProfile() error = %v", err)
+		}
+		if got := strings.Join(*lists, ","); got != "a,b" {
+			t.Errorf("-list = %q, want a,b", got)
+		}
+	})
+
+	errTests := map[string]struct {
+		config  string
+		name    string
+		wantErr string
+	}{
+		"missing profile":      {`{"profiles": {"w": {}}}`, "x", `profile "x" not found`},
+		"unknown flag":         {`{"profiles": {"w": {"nope": "a"}}}`, "w", `unknown flag "nope"`},
+		"reserved key":         {`{"profiles": {"w": {"profile": "a"}}}`, "w", "cannot be set in a profile"},
+		"nested array":         {`{"profiles": {"w": {"list": [["a"]]}}}`, "w", "nested arrays"},
+		"exclusive in profile": {`{"profiles": {"w": {"note-preset": "minimal", "note-template": "x"}}}`, "w", "mutually exclusive"},
+	}
+	for name, tc := range errTests {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, tc.config)
+			fs, _, _ := newProfileFlagSet(t)
+			err := applyProfile(fs, path, tc.name, false)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("applyProfile() error = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	t.Run("ignoreUnknown skips flags of other commands", func(t *testing.T) {
+		path := writeConfig(t, `{"profiles": {"w": {"nope": "a", "tags": "x"}}}`)
+		fs, values, _ := newProfileFlagSet(t)
+		if err := applyProfile(fs, path, "w", true); err != nil {
+			t.Fatalf("applyProfile() error = %v", err)
+		}
+		if *values["tags"] != "x" {
+			t.Errorf("-tags = %q, want x", *values["tags"])
+		}
+	})
+}
+
+func TestGivenFlags(t *testing.T) {
+	fs, _, _ := newProfileFlagSet(t, "-t", "b")
+	given := givenFlags(fs)
+	if !given["t"] || !given["tags"] {
+		t.Errorf("givenFlags() = %v, want t and its alias tags", given)
+	}
+	if given["limit"] || given["n"] {
+		t.Errorf("givenFlags() = %v, want no limit", given)
+	}
+}
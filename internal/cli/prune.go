@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// pruneConfig holds the configuration of the prune command.
+type pruneConfig struct {
+	Tag        string     // Tag identifying the import batch to delete
+	DryRun     bool       // List matching bookmarks without deleting
+	Yes        bool       // Skip the confirmation prompt
+	AllSources bool       // Also delete bookmarks not created via API/import
+	Verbose    bool       // Show per-bookmark messages
+	LogLevel   slog.Level // Minimum level of log messages
+	NoColor    bool       // Disable colored log output
+	APIBaseURL string     // Karakeep API URL
+	APIKey     string     // Karakeep API key
+	CacheDir   string     // Cache directory whose Karakeep bookmark index is cleared after changes
+
+	connection // Connection to the Karakeep API
+}
+
+// hnkeepSources are the Karakeep bookmark sources hnkeep creates bookmarks with:
+// "api" for sync mode and "import" for the JSON file import.
+var hnkeepSources = map[string]bool{"api": true, "import": true}
+
+// parsePruneFlags parses the prune command arguments.
+func parsePruneFlags(args []string) (*pruneConfig, error) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep prune -tag <tag> [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Delete bookmarks of a previous import batch, e.g., -tag hnkeep:20260117.\n\n")
+		fs.PrintDefaults()
+	}
+
+	tag := fs.String("tag", "", "Tag of the import batch to delete (required), e.g., hnkeep:YYYYMMDD")
+	dryRun := fs.Bool("dry-run", false, "List bookmarks that would be deleted without deleting")
+	yes := fs.Bool("yes", false, "Delete without asking for confirmation")
+	allSources := fs.Bool("all-sources", false,
+		"Also delete tagged bookmarks not created by hnkeep (e.g., saved manually before the import)")
+	verbose := fs.Bool("verbose", false, "Show a message for every deleted bookmark")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn, or error (default: info with -verbose, else warn)")
+	noColor := fs.Bool("no-color", false, "Disable colored log output (also via the NO_COLOR env var)")
+	apiBaseURL := fs.String("api-url", "", "Karakeep API URL (env: KARAKEEP_API_URL)")
+	apiKey := fs.String("api-key", "", "Karakeep API key (env: KARAKEEP_API_KEY, or stored with hnkeep auth login)")
+	conn := addConnFlags(fs)
+	cacheDir := fs.String("cache-dir", getDefaultCacheDir(),
+		"Cache directory whose Karakeep bookmark index (see -karakeep-cache) is cleared after changing bookmarks")
+
+	profile := fs.String("profile", "", "Use the flag values of this named profile in the config file (others are ignored)")
+	configPath := fs.String("config", getDefaultConfigPath(), "Config file path")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *profile != "" {
+		if err := applyProfile(fs, *configPath, *profile, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(*tag) == "" {
+		return nil, errors.New("prune requires --tag")
+	}
+	resolvedAPIBaseURL, resolvedAPIKey := resolveAPI(*apiBaseURL, *apiKey)
+	if err := requireAPI("prune", resolvedAPIBaseURL, resolvedAPIKey); err != nil {
+		return nil, err
+	}
+
+	level, err := resolveLogLevel(*logLevel, *verbose)
+	if err != nil {
+		return nil, err
+	}
+	karakeepConn, err := conn.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pruneConfig{
+		Tag:        strings.TrimSpace(*tag),
+		DryRun:     *dryRun,
+		Yes:        *yes,
+		AllSources: *allSources,
+		Verbose:    *verbose || level <= slog.LevelInfo,
+		LogLevel:   level,
+		NoColor:    *noColor,
+		APIBaseURL: resolvedAPIBaseURL,
+		APIKey:     resolvedAPIKey,
+		CacheDir:   *cacheDir,
+		connection: karakeepConn,
+	}, nil
+}
+
+// runPrune deletes the bookmarks carrying the given import batch tag.
+//
+// hnkeep also attaches its tags to bookmarks that already existed in Karakeep before the import,
+// so by default only bookmarks created through the API or file import are deleted. The rest are
+// listed as kept and only deleted with -all-sources.
+func runPrune(ctx context.Context, args []string) error {
+	cfg, err := parsePruneFlags(args)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	log := newStderrLogger(os.Stderr, cfg.LogLevel, cfg.NoColor)
+	client := karakeep.NewClient(cfg.APIBaseURL, cfg.APIKey, append(cfg.karakeepOptions(),
+		karakeep.WithLogger(log),
+	)...)
+
+	phase := newPhaser(cfg.Verbose).Start("Listing tagged bookmarks")
+	tagged, err := client.ListBookmarksByTag(ctx, cfg.Tag)
+	if errors.Is(err, karakeep.ErrTagNotFound) {
+		phase.Done("none")
+		fmt.Fprintf(os.Stderr, "No tag named %q, nothing to prune.\n", cfg.Tag)
+		return nil
+	}
+	if err != nil {
+		phase.Fail()
+		return fmt.Errorf("listing bookmarks tagged %q: %w", cfg.Tag, err)
+	}
+	phase.Done("found %d", len(tagged))
+
+	var toDelete []karakeep.ListBookmark
+	kept := 0
+	for _, bm := range tagged {
+		if cfg.AllSources || (bm.Source != nil && hnkeepSources[*bm.Source]) {
+			toDelete = append(toDelete, bm)
+			continue
+		}
+		kept++
+		log.Info("keeping %s (not created by hnkeep)", bookmarkLabel(bm))
+	}
+
+	fmt.Fprintf(os.Stderr, "Tagged          : %d   (%s)\n", len(tagged), cfg.Tag)
+	if kept > 0 {
+		fmt.Fprintf(os.Stderr, "  Kept          : %d   (not created by hnkeep, use -all-sources to include)\n", kept)
+	}
+	fmt.Fprintf(os.Stderr, "To delete       : %d\n", len(toDelete))
+
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	if cfg.DryRun {
+		for _, bm := range toDelete {
+			fmt.Fprintf(os.Stderr, "  would delete : %s\n", bookmarkLabel(bm))
+		}
+		fmt.Fprintf(os.Stderr, "\nNo changes made to Karakeep.\n")
+		return nil
+	}
+
+	if !cfg.Yes {
+		if !logger.IsTTY(os.Stdin) {
+			return errors.New("refusing to delete without confirmation, pass -yes to skip the prompt")
+		}
+		if !confirm(fmt.Sprintf("Permanently delete %d bookmark(s)?", len(toDelete))) {
+			fmt.Fprintf(os.Stderr, "Aborted.\n")
+			return nil
+		}
+	}
+
+	// deleting while a sync is running could leave the sync updating deleted bookmarks
+	if stateDir := getDefaultStateDir(); stateDir != "" {
+		lock, err := acquireLock(stateDir, cfg.APIBaseURL)
+		if err != nil {
+			return fmt.Errorf("locking prune: %w", err)
+		}
+		defer func() { _ = lock.release() }()
+	}
+
+	deleted, failed := 0, 0
+	defer func() {
+		if deleted > 0 { // also when interrupted
+			invalidateIndexCache(cfg.CacheDir, cfg.APIBaseURL)
+		}
+	}()
+	for _, bm := range toDelete {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := client.DeleteBookmark(ctx, bm.ID); err != nil && !errors.Is(err, karakeep.ErrBookmarkNotFound) {
+			failed++
+			log.Warn("failed to delete %s: %v", bookmarkLabel(bm), err)
+			continue
+		}
+		deleted++
+		log.Info("deleted: %s", bookmarkLabel(bm))
+	}
+
+	fmt.Fprintf(os.Stderr, "\n=== Prune Summary ===\n")
+	fmt.Fprintf(os.Stderr, "Deleted         : %d\n", deleted)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Failed          : %d\n", failed)
+		return fmt.Errorf("%d bookmark(s) failed to delete", failed)
+	}
+	return nil
+}
+
+// bookmarkLabel returns the URL of the bookmark, or its ID for non-link bookmarks.
+func bookmarkLabel(bm karakeep.ListBookmark) string {
+	if u := bm.Content.GetURL(); u != "" {
+		return u
+	}
+	return bm.ID
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin (default no).
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// pendingConfig holds the configuration of the state pending command.
+type pendingConfig struct {
+	InputPath   string // Path to input file
+	InputFormat string // Input file format
+	Before      int64  // Only include bookmarks before this timestamp
+	After       int64  // Only include bookmarks after this timestamp
+	CacheDir    string // HN API responses cache directory path
+	StateDir    string // Directory for the sync state
+	APIBaseURL  string // Karakeep API URL identifying the sync state
+}
+
+// runState dispatches the state subcommands.
+func runState(args []string) error {
+	if len(args) == 0 || args[0] != "pending" {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: hnkeep state pending -i <export.txt> [flags]\n\n")
+		_, _ = fmt.Fprintf(os.Stderr, "Commands:\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  pending  List input bookmarks not yet synced to Karakeep\n")
+		return errors.New("state requires a subcommand")
+	}
+	return runPending(args[1:])
+}
+
+// parsePendingFlags parses the state pending command arguments.
+func parsePendingFlags(args []string) (*pendingConfig, error) {
+	fs := flag.NewFlagSet("state pending", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: hnkeep state pending -i <export.txt> [flags]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "List input bookmarks not yet synced to the Karakeep server (no API calls).\n\n")
+		fs.PrintDefaults()
+	}
+
+	inputPath := fs.String("input", "", "Input file path (default: stdin)")
+	fs.StringVar(inputPath, "i", "", "alias for -input")
+	inputFormat := fs.String("input-format", formatHarmonic, "Input format: harmonic, idmap, idlist, materialistic, glider, or hews")
+	fs.StringVar(inputFormat, "source", formatHarmonic, "alias for -input-format")
+	before := fs.String("before", "", "Only include Harmonic bookmarks before this timestamp")
+	after := fs.String("after", "", "Only include Harmonic bookmarks after this timestamp")
+	cacheDir := fs.String("cache-dir", getDefaultCacheDir(), "HN API responses cache directory path")
+	apiBaseURL := fs.String("api-url", "", "Karakeep API URL (env: KARAKEEP_API_URL)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := checkInputFormat(*inputFormat); err != nil {
+		return nil, err
+	}
+
+	var beforeTS, afterTS int64
+	if *before != "" {
+		t, err := parseDate(*before)
+		if err != nil {
+			return nil, fmt.Errorf("parsing -before date: %w", err)
+		}
+		beforeTS = t.Unix()
+	}
+	if *after != "" {
+		t, err := parseDate(*after)
+		if err != nil {
+			return nil, fmt.Errorf("parsing -after date: %w", err)
+		}
+		afterTS = t.Unix()
+	}
+
+	// only the URL is needed to locate the state, the key is never used
+	resolvedAPIBaseURL, _ := resolveAPI(*apiBaseURL, "")
+	if resolvedAPIBaseURL == "" {
+		return nil, errors.New("state pending requires --api-url or KARAKEEP_API_URL")
+	}
+	stateDir := getDefaultStateDir()
+	if stateDir == "" {
+		return nil, errors.New("state directory unavailable")
+	}
+
+	return &pendingConfig{
+		InputPath:   *inputPath,
+		InputFormat: *inputFormat,
+		Before:      beforeTS,
+		After:       afterTS,
+		CacheDir:    *cacheDir,
+		StateDir:    stateDir,
+		APIBaseURL:  resolvedAPIBaseURL,
+	}, nil
+}
+
+// runPending lists the input bookmarks that the next sync would touch, i.e., not recorded
+// in the sync state of the server. Titles and URLs come from the HN cache, so nothing is fetched;
+// items not cached yet are listed as pending without a title.
+func runPending(args []string) error {
+	cfg, err := parsePendingFlags(args)
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	input, err := readInput(cfg.InputPath)
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+	bookmarks, err := parseInput(input, cfg.InputFormat)
+	if err != nil {
+		return fmt.Errorf("parsing input: %w", err)
+	}
+	bookmarks = filterByDate(bookmarks, cfg.Before, cfg.After)
+
+	state, err := syncer.LoadState(syncStatePath(cfg.StateDir, cfg.APIBaseURL))
+	if err != nil {
+		return fmt.Errorf("loading sync state: %w", err)
+	}
+
+	var cache *hackernews.CachedClient
+	if cfg.CacheDir != "" {
+		cache, err = hackernews.NewCachedClient(hackernews.NewClient(), cfg.CacheDir)
+		if err != nil {
+			return fmt.Errorf("opening cache: %w", err)
+		}
+	}
+
+	var pending, synced, unavailable, uncached int
+	for _, bm := range bookmarks {
+		var item *hackernews.Item
+		var itemErr error
+		if cache != nil {
+			item, itemErr = cache.CachedItem(bm.ID)
+		}
+		switch {
+		case errors.Is(itemErr, hackernews.ErrItemDeleted) || errors.Is(itemErr, hackernews.ErrItemDead):
+			unavailable++ // never synced, the next run skips it too
+			continue
+		case item == nil:
+			uncached++
+			pending++
+			fmt.Printf("%d\t%s\t(not fetched yet)\n", bm.ID, hackernews.DiscussionURL(bm.ID))
+			continue
+		}
+
+		// same URL resolution as the converter
+		url := item.URL
+		if url == "" {
+			url = hackernews.DiscussionURL(item.ID)
+		}
+		if state.Synced(url) {
+			synced++
+			continue
+		}
+		pending++
+		fmt.Printf("%d\t%s\t%s\n", bm.ID, url, item.Title)
+	}
+
+	fmt.Fprintf(os.Stderr, "Bookmarks       : %d\n", len(bookmarks))
+	fmt.Fprintf(os.Stderr, "  Synced        : %d\n", synced)
+	fmt.Fprintf(os.Stderr, "  Pending       : %d", pending)
+	if uncached > 0 {
+		fmt.Fprintf(os.Stderr, "   (%d not fetched yet)", uncached)
+	}
+	fmt.Fprintln(os.Stderr)
+	if unavailable > 0 {
+		fmt.Fprintf(os.Stderr, "  Unavailable   : %d   (deleted or dead on HN)\n", unavailable)
+	}
+	return nil
+}
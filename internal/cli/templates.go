@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+)
+
+// runTemplates dispatches the templates subcommands.
+func runTemplates(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: hnkeep templates list\n\n")
+		_, _ = fmt.Fprintf(os.Stderr, "Commands:\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  list  Show note template presets and the variables they use\n")
+		return errors.New("templates requires a subcommand")
+	}
+	printTemplates()
+	return nil
+}
+
+// printTemplates prints the note presets, with the variables each uses, and all available variables to stdout.
+func printTemplates() {
+	out := os.Stdout
+	_, _ = fmt.Fprintf(out, "Note presets (use with -note-preset):\n")
+	for _, p := range converter.NotePresets {
+		_, _ = fmt.Fprintf(out, "\n  %s\n", p.Name)
+		_, _ = fmt.Fprintf(out, "    %s\n", p.Description)
+		_, _ = fmt.Fprintf(out, "    Template  : %s\n", strconv.Quote(p.Template))
+		_, _ = fmt.Fprintf(out, "    Variables : %s\n", strings.Join(converter.UsedVariables(p.Template), ", "))
+	}
+
+	_, _ = fmt.Fprintf(out, "\nVariables (use in -note-template):\n")
+	for _, v := range converter.NoteVariables {
+		_, _ = fmt.Fprintf(out, "  %-14s %s\n", v.Name, v.Description)
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/cron"
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+// runWatch checks the input file every interval, or at the times of the cron schedule if one is given,
+// and whenever it changed, runs the pipeline on the bookmarks not pushed by an earlier pass,
+// until stopped (e.g., Ctrl-C or SIGTERM from a service manager).
+//
+// The file is polled rather than watched for change notifications, since exports are usually
+// replaced by a sync tool or rewritten whole, and an interval of minutes to hours is plenty.
+// A failed pass is retried on the next check even if the file didn't change.
+//
+// Unlike with an interval, a schedule doesn't run a pass on start, like an external cron job would.
+func runWatch(ctx context.Context, cfg *Config) error {
+	w := &watcher{cfg: cfg, pushed: make(map[int]bool)}
+
+	var schedule *cron.Schedule
+	if cfg.Schedule != "" {
+		var err error
+		if schedule, err = cron.Parse(cfg.Schedule); err != nil {
+			return err // already validated in parseFlags
+		}
+		fmt.Fprintf(os.Stderr, "Watching %s on schedule %q\n", cfg.InputPath, cfg.Schedule)
+	} else {
+		fmt.Fprintf(os.Stderr, "Watching %s every %s\n", cfg.InputPath, cfg.Interval)
+	}
+
+	for first := true; ; first = false {
+		if schedule == nil || !first {
+			if err := w.pass(ctx); err != nil {
+				if ctx.Err() != nil {
+					return err // interrupted mid-pass, unsynced bookmarks are checkpointed
+				}
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}
+
+		wait := cfg.Interval
+		if schedule != nil {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				return fmt.Errorf("schedule %q never runs", cfg.Schedule)
+			}
+			fmt.Fprintf(os.Stderr, "Next run at %s\n", next.Format(time.DateTime))
+			wait = time.Until(next)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			fmt.Fprintf(os.Stderr, "Stopped watching %s\n", cfg.InputPath)
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// watcher holds the state of watch mode across passes.
+type watcher struct {
+	cfg     *Config
+	pushed  map[int]bool // IDs of bookmarks processed by successful passes
+	lastMod time.Time    // modification time of the input file at the last successful pass
+}
+
+// pass runs the pipeline on the new bookmarks if the input file changed since the last successful pass.
+func (w *watcher) pass(ctx context.Context) error {
+	info, err := os.Stat(w.cfg.InputPath)
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+	if info.ModTime().Equal(w.lastMod) {
+		return nil
+	}
+
+	bookmarks, err := loadBookmarks(w.cfg)
+	if err != nil {
+		return err
+	}
+	var fresh []harmonic.Bookmark
+	for _, bm := range bookmarks {
+		if !w.pushed[bm.ID] {
+			fresh = append(fresh, bm)
+		}
+	}
+
+	now := time.Now()
+	if len(fresh) == 0 {
+		fmt.Fprintf(os.Stderr, "[%s] %s changed, no new bookmarks\n", now.Format(time.DateTime), w.cfg.InputPath)
+		w.lastMod = info.ModTime()
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "[%s] Processing %d new bookmark(s)\n", now.Format(time.DateTime), len(fresh))
+
+	stats := &stats{totalStart: now, found: len(fresh)}
+	err = runPipeline(ctx, w.cfg, fresh, stats)
+	reportFailures(w.cfg, stats.failures)
+	if ctx.Err() == nil {
+		printPassSummary(*stats)
+	}
+	if err != nil {
+		return err
+	}
+	for _, bm := range fresh {
+		w.pushed[bm.ID] = true
+	}
+	w.lastMod = info.ModTime()
+	return nil
+}
+
+// printPassSummary prints a one-line summary of a watch pass, so unattended runs (e.g., under systemd)
+// leave a greppable record of each pass next to the full summary.
+func printPassSummary(stats stats) {
+	fmt.Fprintf(os.Stderr, "[%s] Pass done in %.1fs: %d new, %d created, %d updated, %d skipped, %d failed\n",
+		time.Now().Format(time.DateTime), stats.totalDuration().Seconds(), stats.found,
+		stats.syncCreated, stats.syncUpdated, stats.syncSkipped, stats.syncFailed)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/internal/syncer"
+	"github.com/akhdanfadh/hnkeep/internal/webhook"
+)
+
+// runWebhook posts the converted bookmarks to the webhook endpoint (see -target webhook).
+// Unlike Karakeep sync, there is no deduplication against the endpoint, lock, or checkpoint:
+// every run posts every bookmark, so the endpoint should tolerate repeats.
+func runWebhook(ctx context.Context, cfg *Config, log logger.Logger, bookmarks []converter.Bookmark, stats *stats) error {
+	limiter := newLimiter(cfg.SyncWorkers, log, "webhook")
+	clientOpts := []webhook.ClientOption{
+		webhook.WithTimeout(cfg.APITimeout),
+		webhook.WithLogger(log),
+		webhook.WithRateLimitHook(limiter.Throttle),
+	}
+	for _, h := range cfg.WebhookHdrs {
+		name, value, _ := strings.Cut(h, ":") // validated in parseFlags
+		clientOpts = append(clientOpts, webhook.WithHeader(strings.TrimSpace(name), strings.TrimSpace(value)))
+	}
+	target := syncer.NewWebhookTarget(webhook.NewClient(cfg.WebhookURL, clientOpts...))
+
+	syncOpts := []syncer.Option{
+		syncer.WithLimiter(limiter),
+		syncer.WithLogger(log),
+		syncer.WithMaxFailures(cfg.MaxFailures.of(len(bookmarks))),
+		syncer.WithOnResult(func(bm converter.Bookmark, status syncer.SyncStatus, err error) {
+			if status == syncer.SyncFailed {
+				stats.failures = append(stats.failures, syncFailure(bm, "post", err))
+			}
+		}),
+	}
+	progressPost := newProgress(cfg.Verbose, "Posting: %d/%d")
+	if progressPost != nil {
+		syncOpts = append(syncOpts, syncer.WithProgress(progressPost))
+	}
+
+	stats.syncStart = time.Now()
+	status := syncer.New(target, syncOpts...).Sync(ctx, bookmarks)
+	stats.syncEnd = time.Now()
+	if progressPost != nil {
+		progressPost.Clear()
+	}
+
+	stats.syncCreated = status[syncer.SyncCreated]
+	stats.syncFailed = status[syncer.SyncFailed]
+	printSyncSummary(*stats)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return failureError(cfg.MaxFailures, len(bookmarks), stats.syncFailed, stats.syncCreated, "post")
+}
@@ -0,0 +1,169 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/akhdanfadh/hnkeep/internal/htmlconv"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// maxThreadDepth caps the parent chain walked from a comment to its story.
+const maxThreadDepth = 200
+
+// resolveRoots walks the parent chain of every comment item up to its story, so Convert can
+// bookmark the story instead of the comment. Comments whose chain can't be fetched are left out
+// and converted as before, i.e., as a link to the comment itself.
+func (c *Converter) resolveRoots(ctx context.Context, items map[int]*hackernews.Item) map[int]*hackernews.Item {
+	roots := make(map[int]*hackernews.Item)
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for id, item := range items {
+		if item.Type != "comment" {
+			continue
+		}
+		wg.Add(1)
+		go func(id int, item *hackernews.Item) {
+			defer wg.Done()
+			if c.limiter.Acquire(ctx) != nil {
+				return
+			}
+			defer c.limiter.Release()
+
+			root, err := c.rootOf(ctx, item)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("failed to resolve the story of comment %d: %v, linking the comment instead", id, err)
+				}
+				return
+			}
+			mu.Lock()
+			roots[id] = root
+			mu.Unlock()
+		}(id, item)
+	}
+	wg.Wait()
+
+	return roots
+}
+
+// rootOf returns the story (or poll/job) a comment belongs to.
+func (c *Converter) rootOf(ctx context.Context, item *hackernews.Item) (*hackernews.Item, error) {
+	for range maxThreadDepth {
+		if item.Type != "comment" {
+			return item, nil
+		}
+		if item.Parent == 0 {
+			return nil, errors.New("comment has no parent")
+		}
+		parent, err := c.fetcher.GetItem(ctx, item.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("fetching parent %d: %w", item.Parent, err)
+		}
+		item = parent
+	}
+	return nil, fmt.Errorf("thread is deeper than %d comments", maxThreadDepth)
+}
+
+// commentNote returns the note part describing a bookmarked comment: its permalink, and optionally its text.
+func commentNote(comment *hackernews.Item, withText bool) string {
+	note := fmt.Sprintf("Comment by %s: %s", comment.By, hackernews.DiscussionURL(comment.ID))
+	if text := htmlconv.ToMarkdown(comment.Text); withText && text != "" {
+		note += "\n\n" + text
+	}
+	return note
+}
+
+// maxNoteCommentLength caps the text of each comment embedded by WithTopComments, in runes.
+const maxNoteCommentLength = 1000
+
+// fetchTopComments fetches the first n top-level comments of every story among items and the
+// resolved roots. Kids are in HN ranking order; deleted, dead, and unfetchable ones are passed over.
+func (c *Converter) fetchTopComments(ctx context.Context, items map[int]*hackernews.Item) map[int][]*hackernews.Item {
+	stories := make(map[int]*hackernews.Item)
+	for _, item := range items {
+		if item.Type != "comment" && len(item.Kids) > 0 {
+			stories[item.ID] = item
+		}
+	}
+	for _, root := range c.roots {
+		if len(root.Kids) > 0 {
+			stories[root.ID] = root
+		}
+	}
+
+	comments := make(map[int][]*hackernews.Item)
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for _, story := range stories {
+		wg.Add(1)
+		go func(story *hackernews.Item) {
+			defer wg.Done()
+			if c.limiter.Acquire(ctx) != nil {
+				return
+			}
+			defer c.limiter.Release()
+
+			var top []*hackernews.Item
+			for _, kid := range story.Kids {
+				if len(top) == c.topComments || ctx.Err() != nil {
+					break
+				}
+				comment, err := c.fetcher.GetItem(ctx, kid)
+				if err != nil {
+					c.logger.Info("skipping comment %d of item %d: %v", kid, story.ID, err)
+					continue
+				}
+				if comment.Deleted || comment.Dead || comment.Text == "" {
+					continue
+				}
+				top = append(top, comment)
+			}
+			mu.Lock()
+			comments[story.ID] = top
+			mu.Unlock()
+		}(story)
+	}
+	wg.Wait()
+
+	return comments
+}
+
+// topCommentsNote returns the note part quoting the given comments, or "" if there are none.
+func topCommentsNote(comments []*hackernews.Item) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Top comments:")
+	for _, comment := range comments {
+		fmt.Fprintf(&b, "\n\n%s wrote:\n%s", comment.By, commentText(htmlconv.ToMarkdown(comment.Text)))
+	}
+	return b.String()
+}
+
+// commentHighlights returns a highlight of each given comment, noting its author and permalink.
+func commentHighlights(comments []*hackernews.Item) []Highlight {
+	var highlights []Highlight
+	for _, comment := range comments {
+		highlights = append(highlights, Highlight{
+			Text: commentText(htmlconv.ToText(comment.Text)),
+			Note: fmt.Sprintf("%s on HN: %s", comment.By, hackernews.DiscussionURL(comment.ID)),
+		})
+	}
+	return highlights
+}
+
+// commentText returns the converted text of a comment, cut at maxNoteCommentLength runes.
+func commentText(converted string) string {
+	text := []rune(converted)
+	if len(text) > maxNoteCommentLength {
+		text = append(text[:maxNoteCommentLength], '…')
+	}
+	return string(text)
+}
@@ -3,21 +3,47 @@ package converter
 import (
 	"context"
 	"errors"
-	"strconv"
+	"fmt"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/akhdanfadh/hnkeep/internal/hackernews"
+	"github.com/akhdanfadh/hnkeep/internal/adaptive"
 	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/internal/htmlconv"
 	"github.com/akhdanfadh/hnkeep/internal/logger"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
 )
 
 // Options represents additional options for the conversion process.
 type Options struct {
-	Tags         []string // Tags to apply to all bookmarks
-	NoteTemplate string   // Template for note field (empty = no note)
+	Tags         []string    // Tags to apply to all bookmarks
+	TagRules     []TagRule   // Tags to apply to bookmarks by the host of their URL
+	TypeTags     bool        // Tag bookmarks by HN item type, e.g., hn:story or hn:ask
+	StripPrefix  bool        // Strip title prefixes such as "Ask HN:", tagging the kind instead, e.g., hn:ask
+	DomainTags   bool        // Tag bookmarks with the host of their URL, e.g., site:example.com
+	IDTags       bool        // Tag bookmarks with the HN ID they were converted from (see ProvenanceTag)
+	Overrides    Overrides   // Per-item tags and notes by bookmarked HN ID (see ParseOverrides)
+	ScoreRules   []ScoreRule // Tags to apply to bookmarks by the HN score of their item
+	NoteTemplate string      // Template for note field (empty = no note)
+	SummaryTmpl  string      // Template for summary field, same variables as the note (empty = no summary)
+	TitleTmpl    string      // Template for the title, same variables as the note (empty = the HN title)
+	Archive      bool        // Mark all bookmarks as archived
+	FavouriteAt  int         // Mark bookmarks as favourited if HN score exceeds this (0 = disabled)
+	CommentText  bool        // Include the text of bookmarked comments in the note
+	IncludeText  bool        // Append the text of text posts to the note, unless the template has {{text}}
+	TextPosts    bool        // Convert text posts to text bookmarks with the post as content
+	Highlights   bool        // Turn the top comments (see WithTopComments) into highlights instead of quoting them
+	Lists        []string    // Names of lists to put all bookmarks in (v2 export file only, see WriteJSONV2)
+	Transform    Transformer // Rewrites or drops each new bookmark after the options above (nil = none)
+}
+
+// Transformer rewrites or drops a converted bookmark, given the input bookmark and the HN item it was
+// converted from, e.g., a user-supplied transform script. It returns false to drop the bookmark.
+// An error aborts the conversion, since bookmarks the transform would drop must not be written.
+type Transformer interface {
+	Transform(bm harmonic.Bookmark, item *hackernews.Item, b *Bookmark) (bool, error)
 }
 
 // noteSeparator is used to join notes when merging duplicate URLs.
@@ -39,10 +65,38 @@ func getDefaultFetcher() ItemFetcher {
 type Converter struct {
 	fetcher     ItemFetcher
 	concurrency int
+	limiter     *adaptive.Limiter // bounds parallel fetches, defaults to a limiter of concurrency workers
 	logger      logger.Logger
 	progresser  logger.Progresser
+	deadItems   DeadItemsMode
+	onFetchErr  func(id int, err error)
+
+	prefetched      map[int]*hackernews.Item       // items fetched by an earlier run, not fetched again
+	checkpoint      func(map[int]*hackernews.Item) // called periodically with the items fetched so far
+	checkpointEvery time.Duration
+
+	roots       map[int]*hackernews.Item   // comment ID -> its story, resolved by FetchItems
+	topComments int                        // number of top-level comments to fetch per story (0 = none)
+	comments    map[int][]*hackernews.Item // story ID -> its top comments, fetched by FetchItems
+
+	resolver  URLResolver       // follows redirects of story URLs (nil = disabled)
+	redirects map[string]string // story URL -> its final destination, resolved by FetchItems
+
+	deadLinks   DeadLinksMode     // what to do with dead links (empty = don't check)
+	linkChecker LinkChecker       // detects dead story URLs
+	finder      SnapshotFinder    // finds archived snapshots of dead links
+	archived    map[string]string // dead story URL -> its closest snapshot, found by FetchItems
 }
 
+// DeadItemsMode controls what happens to bookmarks of deleted or dead HN items.
+type DeadItemsMode string
+
+const (
+	DeadItemsSkip    DeadItemsMode = "skip"    // drop the bookmark
+	DeadItemsHNLink  DeadItemsMode = "hn-link" // bookmark the HN discussion, which usually still exists
+	DeadItemsWayback DeadItemsMode = "wayback" // bookmark the Wayback Machine snapshot of the discussion
+)
+
 // Option configures the Converter.
 type Option func(*Converter)
 
@@ -52,10 +106,14 @@ func New(opts ...Option) *Converter {
 		fetcher:     getDefaultFetcher(),
 		concurrency: defaultConcurrency,
 		logger:      logger.Noop(),
+		deadItems:   DeadItemsSkip,
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.limiter == nil {
+		c.limiter = adaptive.NewLimiter(c.concurrency)
+	}
 	return c
 }
 
@@ -73,6 +131,41 @@ func WithConcurrency(n int) Option {
 	}
 }
 
+// WithLimiter sets the limiter bounding parallel HN fetches, overriding WithConcurrency.
+// Sharing it with the HN client's rate limit hook lets the concurrency adapt to 429 responses.
+func WithLimiter(l *adaptive.Limiter) Option {
+	return func(c *Converter) {
+		c.limiter = l
+	}
+}
+
+// WithOnFetchError sets a function called for each bookmark whose item failed to fetch, including items
+// not found, as FetchItems skips them. Deleted or dead items kept by WithDeadItems are not failures.
+func WithOnFetchError(fn func(id int, err error)) Option {
+	return func(c *Converter) {
+		c.onFetchErr = fn
+	}
+}
+
+// WithURLResolver makes FetchItems resolve the redirects of story URLs with the given resolver,
+// so Convert bookmarks their final destination, e.g., instead of a URL shortener.
+func WithURLResolver(r URLResolver) Option {
+	return func(c *Converter) {
+		c.resolver = r
+	}
+}
+
+// WithDeadLinks makes FetchItems check story URLs for dead links with the given checker and look up
+// their archived snapshots with the given finder. Convert then adds the snapshot to the note of
+// the bookmark or bookmarks the snapshot instead, depending on the mode.
+func WithDeadLinks(mode DeadLinksMode, checker LinkChecker, finder SnapshotFinder) Option {
+	return func(c *Converter) {
+		c.deadLinks = mode
+		c.linkChecker = checker
+		c.finder = finder
+	}
+}
+
 // WithLogger sets the logger for info/warn/error messages.
 func WithLogger(l logger.Logger) Option {
 	return func(c *Converter) {
@@ -87,6 +180,40 @@ func WithProgress(p logger.Progresser) Option {
 	}
 }
 
+// WithDeadItems sets how bookmarks of deleted or dead items are handled (default DeadItemsSkip).
+// Unless skipped, FetchItems keeps such items as stubs, which Convert turns into fallback bookmarks.
+func WithDeadItems(mode DeadItemsMode) Option {
+	return func(c *Converter) {
+		c.deadItems = mode
+	}
+}
+
+// WithPrefetched sets items fetched by an earlier, interrupted run, e.g., loaded from a checkpoint.
+// FetchItems returns them as is instead of fetching them again.
+func WithPrefetched(items map[int]*hackernews.Item) Option {
+	return func(c *Converter) {
+		c.prefetched = items
+	}
+}
+
+// WithCheckpoint makes FetchItems call save with the items fetched so far (including prefetched ones)
+// at most once per interval, and once more if the fetch is interrupted.
+// The map must not be modified or retained after save returns.
+func WithCheckpoint(every time.Duration, save func(items map[int]*hackernews.Item)) Option {
+	return func(c *Converter) {
+		c.checkpointEvery = every
+		c.checkpoint = save
+	}
+}
+
+// WithTopComments makes FetchItems also fetch the first n top-level comments of every story,
+// which Convert quotes at the end of the note, or turns into highlights (see Options.Highlights).
+func WithTopComments(n int) Option {
+	return func(c *Converter) {
+		c.topComments = n
+	}
+}
+
 // FetchItems fetches Hacker News items for the given bookmarks concurrently.
 func (c *Converter) FetchItems(ctx context.Context, bookmarks []harmonic.Bookmark) (map[int]*hackernews.Item, error) {
 	type result struct {
@@ -94,29 +221,37 @@ func (c *Converter) FetchItems(ctx context.Context, bookmarks []harmonic.Bookmar
 		item     *hackernews.Item
 		err      error
 	}
-	results := make(chan result, len(bookmarks))
-	semaphore := make(chan struct{}, c.concurrency)
+	// reuse items of an earlier run, only fetch the rest
+	items := make(map[int]*hackernews.Item)
+	var pending []harmonic.Bookmark
+	for _, bm := range bookmarks {
+		if item, ok := c.prefetched[bm.ID]; ok {
+			items[bm.ID] = item
+			continue
+		}
+		pending = append(pending, bm)
+	}
+
+	results := make(chan result, len(pending))
 
-	total := len(bookmarks)
+	total := len(pending)
 	var counter atomic.Int32 // for logging progress
 
-	// fetch items with semaphore
+	// fetch items, bounded by the limiter
 	var wg sync.WaitGroup
-	for _, bm := range bookmarks {
+	for _, bm := range pending {
 		wg.Add(1)
 		go func(bookmark harmonic.Bookmark) { // pass bm as param to avoid closure capture
 			defer wg.Done()
 
 			// check for cancellation before acquiring
 			// this prevents queued goroutines from starting new work after Ctrl+C
-			select {
-			case <-ctx.Done():
+			if c.limiter.Acquire(ctx) != nil {
 				return
-			case semaphore <- struct{}{}: // acquire
 			}
-			defer func() { <-semaphore }() // release
+			defer c.limiter.Release()
 
-			// check again after acquiring (pseudo-random select behavior)
+			// check again after acquiring (a free slot is taken even if cancelled)
 			if ctx.Err() != nil {
 				return
 			}
@@ -142,30 +277,71 @@ func (c *Converter) FetchItems(ctx context.Context, bookmarks []harmonic.Bookmar
 	}()
 
 	// process fetch results
-	items := make(map[int]*hackernews.Item)
+	lastCheckpoint := time.Now()
 	for r := range results {
 		// check for cancellation while processing results
 		if ctx.Err() != nil {
+			c.saveCheckpoint(items)
 			return nil, ctx.Err()
 		}
+		if c.checkpoint != nil && time.Since(lastCheckpoint) >= c.checkpointEvery {
+			c.saveCheckpoint(items)
+			lastCheckpoint = time.Now()
+		}
 
+		if stub := c.deadItemStub(r.bookmark.ID, r.err); stub != nil {
+			c.logger.Info("item %d is deleted or dead, keeping as %s", r.bookmark.ID, c.deadItems)
+			items[r.bookmark.ID] = stub
+			continue
+		}
 		if r.err != nil {
 			if errors.Is(r.err, hackernews.ErrItemNotFound) {
 				c.logger.Warn("item %d not found, skipping", r.bookmark.ID)
 			} else {
 				c.logger.Warn("failed to fetch item %d: %v, skipping", r.bookmark.ID, r.err)
 			}
+			if c.onFetchErr != nil {
+				c.onFetchErr(r.bookmark.ID, r.err)
+			}
 			continue
 		}
 		items[r.bookmark.ID] = r.item
 	}
 
+	// workers stop sending once cancelled, so the loop above may end without noticing
+	if ctx.Err() != nil {
+		c.saveCheckpoint(items)
+		return nil, ctx.Err()
+	}
+
+	c.roots = c.resolveRoots(ctx, items)
+	if c.topComments > 0 {
+		c.comments = c.fetchTopComments(ctx, items)
+	}
+	if c.resolver != nil {
+		c.redirects = c.resolveRedirects(ctx, items)
+	}
+	if c.deadLinks != "" {
+		c.archived = c.checkLinks(ctx, bookmarks, items)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	return items, nil
 }
 
+// saveCheckpoint passes the items fetched so far to the checkpoint function, if any.
+func (c *Converter) saveCheckpoint(items map[int]*hackernews.Item) {
+	if c.checkpoint != nil {
+		c.checkpoint(items)
+	}
+}
+
 // Convert converts the fetched items and bookmarks into Karakeep export format.
-// Returns the export and the number of duplicate URLs that were merged.
-func (c *Converter) Convert(bookmarks []harmonic.Bookmark, items map[int]*hackernews.Item, opts Options) (Schema, int) {
+// Bookmarked comments whose story was resolved by FetchItems become bookmarks of the story,
+// with the comment permalink in the note. Returns the export and the number of duplicate URLs that were merged,
+// or an error if opts.Transform failed, as its bookmarks can't be written as converted.
+func (c *Converter) Convert(bookmarks []harmonic.Bookmark, items map[int]*hackernews.Item, opts Options) (Schema, int, error) {
 	var export Schema
 	seenURLs := make(map[string]int) // url -> index in export.Bookmarks
 	dedupedCount := 0
@@ -176,30 +352,50 @@ func (c *Converter) Convert(bookmarks []harmonic.Bookmark, items map[int]*hacker
 			continue // skip missing items (deleted or fetch error)
 		}
 
+		// a comment is bookmarked as its story, which is what it makes sense in context of
+		story, isComment := c.roots[item.ID]
+		if !isComment {
+			story = item
+		}
+
 		// resolve url
 		var url string
-		if item.URL != "" {
-			url = item.URL
-		} else {
-			url = hackernews.DiscussionURL(item.ID)
+		switch {
+		case item.Deleted || item.Dead:
+			url = c.deadItemURL(item.ID, bm.Timestamp)
+		case c.deadLinks == DeadLinksReplace && c.archived[story.URL] != "":
+			url = c.archived[story.URL]
+		case c.redirects[story.URL] != "":
+			url = c.redirects[story.URL]
+		case story.URL != "":
+			url = story.URL
+		default:
+			url = hackernews.DiscussionURL(story.ID)
 		}
 
 		// render note template
 		var note string
-		if opts.NoteTemplate != "" {
-			smartURL := hackernews.DiscussionURL(item.ID)
-			if item.URL == "" {
-				smartURL = ""
-			}
-			note = strings.NewReplacer(
-				"{{smart_url}}", smartURL,
-				"{{item_url}}", item.URL,
-				"{{hn_url}}", hackernews.DiscussionURL(item.ID),
-				"{{id}}", strconv.Itoa(item.ID),
-				"{{title}}", item.Title,
-				"{{author}}", item.By,
-				"{{date}}", time.Unix(item.Time, 0).Format("2006-01-02"),
-			).Replace(opts.NoteTemplate)
+		switch {
+		case item.Deleted || item.Dead:
+			note = deadItemNote(item) // the template has nothing to fill in
+		case opts.NoteTemplate != "":
+			note = renderNote(opts.NoteTemplate, story)
+		}
+		// text posts become text bookmarks of the post, still identified by the discussion url
+		textPost := opts.TextPosts && story.URL == "" && story.Text != "" && !item.Deleted && !item.Dead
+		if opts.IncludeText && !textPost && story.URL == "" && !item.Deleted && !item.Dead &&
+			!strings.Contains(opts.NoteTemplate, "{{text}}") {
+			note = strings.TrimSpace(note + "\n\n" + htmlconv.ToMarkdown(story.Text))
+		}
+		if isComment {
+			note = strings.TrimSpace(commentNote(item, opts.CommentText) + "\n\n" + note)
+		}
+		if snapshot := c.archived[story.URL]; c.deadLinks == DeadLinksNote && snapshot != "" {
+			note = strings.TrimSpace(note + "\n\n" + deadLinkNote(snapshot))
+		}
+		override := opts.Overrides[bm.ID]
+		if override.Note != nil {
+			note = *override.Note
 		}
 
 		// check for duplicate URL
@@ -214,25 +410,122 @@ func (c *Converter) Convert(bookmarks []harmonic.Bookmark, items map[int]*hacker
 					export.Bookmarks[idx].Note = &note
 				}
 			}
+			if opts.IDTags {
+				export.Bookmarks[idx].Tags = withTag(export.Bookmarks[idx].Tags, ProvenanceTag(item.ID))
+			}
 			dedupedCount++
 			continue // skip adding new bookmark
 		}
 
+		tags := applyTagRules(opts.Tags, opts.TagRules, url)
+		if opts.DomainTags {
+			tags = withTag(tags, domainTag(url))
+		}
+		for _, tag := range override.Tags {
+			tags = withTag(tags, tag)
+		}
+		if opts.TypeTags {
+			tags = withTag(tags, typeTag(item))
+		}
+		if opts.IDTags {
+			tags = withTag(tags, ProvenanceTag(item.ID))
+		}
+		tags = applyScoreRules(tags, opts.ScoreRules, story.Score)
+		title := story.Title
+		if opts.StripPrefix {
+			if stripped, kind := stripTitleKind(title); kind != "" {
+				title = stripped
+				tags = withTag(tags, "hn:"+kind)
+			}
+		}
+		if opts.TitleTmpl != "" {
+			titled := *story
+			titled.Title = title // {{title}} is the title without the prefix stripped above
+			if rendered := strings.TrimSpace(renderNote(opts.TitleTmpl, &titled)); rendered != "" {
+				title = rendered
+			}
+		}
+
 		// build struct
 		kb := Bookmark{
-			CreatedAt: bm.Timestamp,
-			Title:     &item.Title,
-			Content:   NewBookmarkContent(url),
-			Tags:      opts.Tags,
+			CreatedAt:  bm.Timestamp,
+			Title:      &title,
+			Content:    NewBookmarkContent(url),
+			Tags:       tags,
+			Archived:   opts.Archive,
+			Favourited: opts.FavouriteAt > 0 && story.Score > opts.FavouriteAt,
+			Origin:     Origin{ID: story.ID, Author: story.By, Score: story.Score},
+			Lists:      opts.Lists,
 		}
 
+		// quote the top comments once per bookmark, not again for merged duplicates
+		if opts.Highlights {
+			kb.Highlights = commentHighlights(c.comments[story.ID])
+		} else if comments := topCommentsNote(c.comments[story.ID]); comments != "" {
+			note = strings.TrimSpace(note + "\n\n" + comments)
+		}
 		if note != "" { // avoid empty rendered note
 			kb.Note = &note
 		}
+		if textPost {
+			kb.Content = NewTextBookmarkContent(htmlconv.ToMarkdown(story.Text), url)
+		}
+		if opts.SummaryTmpl != "" && !item.Deleted && !item.Dead {
+			if summary := strings.TrimSpace(renderNote(opts.SummaryTmpl, story)); summary != "" {
+				kb.Summary = &summary
+			}
+		}
+		if item.Deleted || item.Dead {
+			kb.Title = nil // let Karakeep crawl it from the page
+		}
+		if opts.Transform != nil {
+			keep, err := opts.Transform.Transform(bm, item, &kb)
+			if err != nil {
+				return Schema{}, 0, fmt.Errorf("transforming item %d: %w", bm.ID, err)
+			}
+			if !keep {
+				continue // a later duplicate of the URL becomes a bookmark of its own
+			}
+		}
 
 		seenURLs[url] = len(export.Bookmarks) // record index for deduplication
 		export.Bookmarks = append(export.Bookmarks, kb)
 	}
 
-	return export, dedupedCount
+	return export, dedupedCount, nil
+}
+
+// deadItemStub returns a stub item for a fetch error of a deleted or dead item,
+// or nil if the error is of another kind or such items are skipped.
+func (c *Converter) deadItemStub(id int, err error) *hackernews.Item {
+	if c.deadItems == DeadItemsSkip || c.deadItems == "" {
+		return nil
+	}
+	switch {
+	case errors.Is(err, hackernews.ErrItemDeleted):
+		return &hackernews.Item{ID: id, Deleted: true}
+	case errors.Is(err, hackernews.ErrItemDead):
+		return &hackernews.Item{ID: id, Dead: true}
+	}
+	return nil
+}
+
+// deadItemURL returns the fallback URL for a deleted or dead item saved at the given Unix time.
+// The original link is unknown since the HN API doesn't return it for such items.
+func (c *Converter) deadItemURL(id int, savedAt int64) string {
+	if c.deadItems == DeadItemsWayback {
+		// the Wayback Machine redirects to the snapshot closest to the save time
+		return "https://web.archive.org/web/" + time.Unix(savedAt, 0).UTC().Format("20060102150405") +
+			"/" + hackernews.DiscussionURL(id)
+	}
+	return hackernews.DiscussionURL(id)
+}
+
+// deadItemNote returns the note for a deleted or dead item.
+func deadItemNote(item *hackernews.Item) string {
+	state := "dead"
+	if item.Deleted {
+		state = "deleted"
+	}
+	return fmt.Sprintf("HN item %d is %s, original link unavailable.\n%s", item.ID, state, hackernews.DiscussionURL(item.ID))
 }
@@ -2,14 +2,17 @@ package converter
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
-	"github.com/akhdanfadh/hnkeep/internal/hackernews"
 	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
 )
 
 // ptr returns a pointer to the given string (helper for test data).
@@ -37,6 +40,12 @@ type mockLogger struct {
 	messages []string
 }
 
+func (m *mockLogger) Debug(format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, "[DEBUG] "+fmt.Sprintf(format, args...))
+}
+
 func (m *mockLogger) Info(format string, args ...any) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -134,13 +143,25 @@ func TestFetchItems(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			logger := &mockLogger{}
 			mock := &mockFetcher{items: tc.items, errors: tc.errors}
-			c := New(WithFetcher(mock), WithConcurrency(2), WithLogger(logger))
+			var failed []int
+			c := New(WithFetcher(mock), WithConcurrency(2), WithLogger(logger),
+				WithOnFetchError(func(id int, _ error) { failed = append(failed, id) }))
 
 			got, err := c.FetchItems(context.Background(), tc.bookmarks)
 			if err != nil {
 				t.Fatalf("FetchItems() unexpected error: %v", err)
 			}
 
+			// every bookmark is either fetched or reported as failed
+			if len(failed) != len(tc.bookmarks)-len(tc.wantItems) {
+				t.Errorf("FetchItems() reported failures %v, want %d", failed, len(tc.bookmarks)-len(tc.wantItems))
+			}
+			for _, id := range failed {
+				if _, ok := tc.wantItems[id]; ok {
+					t.Errorf("FetchItems() reported fetched item %d as failed", id)
+				}
+			}
+
 			// check items count
 			if len(got) != len(tc.wantItems) {
 				t.Fatalf("FetchItems() got %d items, want %d", len(got), len(tc.wantItems))
@@ -263,6 +284,32 @@ func TestConvert(t *testing.T) {
 				},
 			},
 		},
+		"favourite above score threshold": {
+			bookmarks: []harmonic.Bookmark{
+				{ID: 1, Timestamp: 1000},
+				{ID: 2, Timestamp: 2000},
+			},
+			items: map[int]*hackernews.Item{
+				1: {ID: 1, Title: "Popular", URL: "https://popular.com", Score: 500},
+				2: {ID: 2, Title: "Quiet", URL: "https://quiet.com", Score: 100},
+			},
+			opts: Options{FavouriteAt: 100},
+			want: Schema{
+				Bookmarks: []Bookmark{
+					{
+						CreatedAt:  1000,
+						Title:      ptr("Popular"),
+						Content:    NewBookmarkContent("https://popular.com"),
+						Favourited: true,
+					},
+					{
+						CreatedAt: 2000,
+						Title:     ptr("Quiet"),
+						Content:   NewBookmarkContent("https://quiet.com"),
+					},
+				},
+			},
+		},
 		"note template smart_url with external URL": {
 			bookmarks: []harmonic.Bookmark{
 				{ID: 42, Timestamp: 1000},
@@ -331,7 +378,7 @@ func TestConvert(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			c := New()
-			got, _ := c.Convert(tc.bookmarks, tc.items, tc.opts)
+			got, _, _ := c.Convert(tc.bookmarks, tc.items, tc.opts)
 
 			// check bookmarks count
 			if len(got.Bookmarks) != len(tc.want.Bookmarks) {
@@ -395,7 +442,7 @@ func TestConvert_Dedupe(t *testing.T) {
 		}
 		opts := Options{NoteTemplate: "{{hn_url}}"}
 
-		got, deduped := c.Convert(bookmarks, items, opts)
+		got, deduped, _ := c.Convert(bookmarks, items, opts)
 
 		if len(got.Bookmarks) != 1 {
 			t.Errorf("Convert() got %d bookmarks, want 1", len(got.Bookmarks))
@@ -423,7 +470,7 @@ func TestConvert_Dedupe(t *testing.T) {
 		// smart_url is empty when item has no external URL
 		opts := Options{NoteTemplate: "{{smart_url}}"}
 
-		got, deduped := c.Convert(bookmarks, items, opts)
+		got, deduped, _ := c.Convert(bookmarks, items, opts)
 
 		if len(got.Bookmarks) != 1 {
 			t.Errorf("Convert() got %d bookmarks, want 1", len(got.Bookmarks))
@@ -439,3 +486,575 @@ func TestConvert_Dedupe(t *testing.T) {
 		}
 	})
 }
+
+func TestDeadItems(t *testing.T) {
+	fetcher := &mockFetcher{
+		items: map[int]*hackernews.Item{
+			1: {ID: 1, Title: "Alive", URL: "https://example.com"},
+		},
+		errors: map[int]error{
+			2: hackernews.ErrItemDeleted,
+			3: hackernews.ErrItemDead,
+			4: errors.New("network error"),
+		},
+	}
+	bookmarks := []harmonic.Bookmark{
+		{ID: 1, Timestamp: 1704067200},
+		{ID: 2, Timestamp: 1704067200}, // 2024-01-01T00:00:00Z
+		{ID: 3, Timestamp: 1704067200},
+		{ID: 4, Timestamp: 1704067200},
+	}
+
+	tests := map[DeadItemsMode][]string{
+		DeadItemsSkip: {"https://example.com"},
+		DeadItemsHNLink: {
+			"https://example.com",
+			"https://news.ycombinator.com/item?id=2",
+			"https://news.ycombinator.com/item?id=3",
+		},
+		DeadItemsWayback: {
+			"https://example.com",
+			"https://web.archive.org/web/20240101000000/https://news.ycombinator.com/item?id=2",
+			"https://web.archive.org/web/20240101000000/https://news.ycombinator.com/item?id=3",
+		},
+	}
+
+	for mode, wantURLs := range tests {
+		t.Run(string(mode), func(t *testing.T) {
+			c := New(WithFetcher(fetcher), WithDeadItems(mode))
+			items, err := c.FetchItems(context.Background(), bookmarks)
+			if err != nil {
+				t.Fatalf("FetchItems() error = %v", err)
+			}
+			got, _, _ := c.Convert(bookmarks, items, Options{NoteTemplate: "{{title}}"})
+
+			var gotURLs []string
+			for _, bm := range got.Bookmarks {
+				gotURLs = append(gotURLs, bm.Content.URL)
+			}
+			if strings.Join(gotURLs, " ") != strings.Join(wantURLs, " ") {
+				t.Fatalf("URLs = %v, want %v", gotURLs, wantURLs)
+			}
+
+			for _, bm := range got.Bookmarks[1:] {
+				if bm.Title != nil {
+					t.Errorf("fallback bookmark title = %q, want nil", *bm.Title)
+				}
+				if bm.Note == nil || !strings.Contains(*bm.Note, "original link unavailable") {
+					t.Errorf("fallback bookmark note = %v, want explanation", bm.Note)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchItems_Checkpoint(t *testing.T) {
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	t.Run("prefetched items are not fetched again", func(t *testing.T) {
+		mock := &mockFetcher{items: map[int]*hackernews.Item{
+			2: {ID: 2, Title: "Fetched 2"},
+			3: {ID: 3, Title: "Fetched 3"},
+		}}
+		c := New(WithFetcher(mock), WithPrefetched(map[int]*hackernews.Item{
+			1: {ID: 1, Title: "Prefetched 1"},
+			2: {ID: 2, Title: "Prefetched 2"},
+			9: {ID: 9, Title: "Not in input"},
+		}))
+
+		got, err := c.FetchItems(context.Background(), bookmarks)
+		if err != nil {
+			t.Fatalf("FetchItems() unexpected error: %v", err)
+		}
+		want := map[int]string{1: "Prefetched 1", 2: "Prefetched 2", 3: "Fetched 3"}
+		if len(got) != len(want) {
+			t.Fatalf("FetchItems() got %d items, want %d", len(got), len(want))
+		}
+		for id, title := range want {
+			if got[id] == nil || got[id].Title != title {
+				t.Errorf("FetchItems()[%d] = %+v, want title %q", id, got[id], title)
+			}
+		}
+	})
+
+	t.Run("saves on interruption", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		fetcher := &cancellingFetcher{cancelAt: 3, cancel: cancel}
+		var saves []int // number of items per save
+		c := New(WithFetcher(fetcher), WithConcurrency(1), WithCheckpoint(time.Hour, func(items map[int]*hackernews.Item) {
+			saves = append(saves, len(items))
+		}))
+
+		if _, err := c.FetchItems(ctx, bookmarks); !errors.Is(err, context.Canceled) {
+			t.Fatalf("FetchItems() error = %v, want context.Canceled", err)
+		}
+		// the result of the second item may be read before or after noticing the cancellation
+		if len(saves) != 1 || saves[0] < 1 || saves[0] > 2 {
+			t.Errorf("expected one save with the items fetched before cancellation, got saves %v", saves)
+		}
+	})
+}
+
+// cancellingFetcher returns items until the cancelAt-th call, which cancels the context instead.
+type cancellingFetcher struct {
+	mu       sync.Mutex
+	calls    int
+	cancelAt int
+	cancel   context.CancelFunc
+}
+
+func (f *cancellingFetcher) GetItem(ctx context.Context, id int) (*hackernews.Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls == f.cancelAt {
+		f.cancel()
+		return nil, ctx.Err()
+	}
+	return &hackernews.Item{ID: id}, nil
+}
+
+func TestConvert_Comments(t *testing.T) {
+	story := &hackernews.Item{ID: 1, Type: "story", Title: "A Story", URL: "https://example.com/story", Score: 120}
+	fetcher := &mockFetcher{items: map[int]*hackernews.Item{
+		1: story,
+		2: {ID: 2, Type: "comment", By: "alice", Parent: 1, Text: "Top-level"},
+		3: {ID: 3, Type: "comment", By: "bob", Parent: 2, Text: "Nested<p>See <a href=\"https://go.dev/\">go.dev</a> &amp; more"},
+		4: {ID: 4, Type: "comment", By: "carol", Parent: 99, Text: "Orphan"},
+	}}
+
+	t.Run("bookmarks the root story", func(t *testing.T) {
+		bookmarks := []harmonic.Bookmark{{ID: 3, Timestamp: 1700000000}}
+		c := New(WithFetcher(fetcher))
+		items, err := c.FetchItems(context.Background(), bookmarks)
+		if err != nil {
+			t.Fatalf("FetchItems() unexpected error: %v", err)
+		}
+		export, _, _ := c.Convert(bookmarks, items, Options{NoteTemplate: "{{hn_url}}", CommentText: true, FavouriteAt: 100})
+
+		if len(export.Bookmarks) != 1 {
+			t.Fatalf("got %d bookmarks, want 1", len(export.Bookmarks))
+		}
+		bm := export.Bookmarks[0]
+		if bm.Content.URL != story.URL {
+			t.Errorf("URL = %q, want %q", bm.Content.URL, story.URL)
+		}
+		if bm.Title == nil || *bm.Title != story.Title {
+			t.Errorf("Title = %v, want %q", bm.Title, story.Title)
+		}
+		if !bm.Favourited {
+			t.Error("expected the bookmark to be favourited by the story score")
+		}
+		want := "Comment by bob: https://news.ycombinator.com/item?id=3\n\n" +
+			"Nested\n\nSee [go.dev](https://go.dev/) & more\n\n" +
+			"https://news.ycombinator.com/item?id=1"
+		if bm.Note == nil || *bm.Note != want {
+			t.Errorf("Note = %v, want %q", bm.Note, want)
+		}
+	})
+
+	t.Run("omits text unless requested", func(t *testing.T) {
+		bookmarks := []harmonic.Bookmark{{ID: 2, Timestamp: 1700000000}}
+		c := New(WithFetcher(fetcher))
+		items, err := c.FetchItems(context.Background(), bookmarks)
+		if err != nil {
+			t.Fatalf("FetchItems() unexpected error: %v", err)
+		}
+		export, _, _ := c.Convert(bookmarks, items, Options{})
+
+		want := "Comment by alice: https://news.ycombinator.com/item?id=2"
+		if note := export.Bookmarks[0].Note; note == nil || *note != want {
+			t.Errorf("Note = %v, want %q", note, want)
+		}
+	})
+
+	t.Run("merges with the story bookmark", func(t *testing.T) {
+		bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}, {ID: 3, Timestamp: 1700000002}}
+		c := New(WithFetcher(fetcher))
+		items, err := c.FetchItems(context.Background(), bookmarks)
+		if err != nil {
+			t.Fatalf("FetchItems() unexpected error: %v", err)
+		}
+		export, deduped, _ := c.Convert(bookmarks, items, Options{})
+
+		if len(export.Bookmarks) != 1 || deduped != 2 {
+			t.Fatalf("got %d bookmarks and %d deduplicated, want 1 and 2", len(export.Bookmarks), deduped)
+		}
+		want := "Comment by alice: https://news.ycombinator.com/item?id=2" + noteSeparator +
+			"Comment by bob: https://news.ycombinator.com/item?id=3"
+		if note := export.Bookmarks[0].Note; note == nil || *note != want {
+			t.Errorf("Note = %v, want %q", note, want)
+		}
+	})
+
+	t.Run("falls back to the comment link", func(t *testing.T) {
+		bookmarks := []harmonic.Bookmark{{ID: 4, Timestamp: 1700000000}}
+		log := &mockLogger{}
+		c := New(WithFetcher(fetcher), WithLogger(log))
+		items, err := c.FetchItems(context.Background(), bookmarks)
+		if err != nil {
+			t.Fatalf("FetchItems() unexpected error: %v", err)
+		}
+		export, _, _ := c.Convert(bookmarks, items, Options{})
+
+		if got, want := export.Bookmarks[0].Content.URL, hackernews.DiscussionURL(4); got != want {
+			t.Errorf("URL = %q, want %q", got, want)
+		}
+		if export.Bookmarks[0].Note != nil {
+			t.Errorf("Note = %q, want none", *export.Bookmarks[0].Note)
+		}
+		if !slices.ContainsFunc(log.messages, func(m string) bool { return strings.Contains(m, "comment 4") }) {
+			t.Errorf("expected a warning about comment 4, got %v", log.messages)
+		}
+	})
+}
+
+func TestConvert_TopComments(t *testing.T) {
+	fetcher := &mockFetcher{
+		items: map[int]*hackernews.Item{
+			1:  {ID: 1, Type: "story", Title: "A Story", URL: "https://example.com/story", Kids: []int{10, 11, 12, 13}},
+			2:  {ID: 2, Type: "story", Title: "No Comments", URL: "https://example.com/quiet"},
+			10: {ID: 10, Type: "comment", By: "alice", Parent: 1, Text: "First &amp; best"},
+			12: {ID: 12, Type: "comment", By: "bob", Parent: 1, Text: "Second<p>Two <i>paragraphs</i>"},
+			13: {ID: 13, Type: "comment", By: "carol", Parent: 1, Text: "Third"},
+		},
+		errors: map[int]error{11: hackernews.ErrItemDeleted},
+	}
+	bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}}
+
+	c := New(WithFetcher(fetcher), WithTopComments(2))
+	items, err := c.FetchItems(context.Background(), bookmarks)
+	if err != nil {
+		t.Fatalf("FetchItems() unexpected error: %v", err)
+	}
+	export, _, _ := c.Convert(bookmarks, items, Options{NoteTemplate: "{{title}}"})
+
+	if len(export.Bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2", len(export.Bookmarks))
+	}
+	want := "A Story\n\nTop comments:\n\nalice wrote:\nFirst & best\n\nbob wrote:\nSecond\n\nTwo *paragraphs*"
+	if note := export.Bookmarks[0].Note; note == nil || *note != want {
+		t.Errorf("Note = %v, want %q", note, want)
+	}
+	if note := export.Bookmarks[1].Note; note == nil || *note != "No Comments" {
+		t.Errorf("Note = %v, want %q", note, "No Comments")
+	}
+
+	// as highlights, the note is left alone
+	export, _, _ = c.Convert(bookmarks, items, Options{NoteTemplate: "{{title}}", Highlights: true})
+	if note := export.Bookmarks[0].Note; note == nil || *note != "A Story" {
+		t.Errorf("Note = %v, want %q", note, "A Story")
+	}
+	wantHighlights := []Highlight{
+		{Text: "First & best", Note: "alice on HN: https://news.ycombinator.com/item?id=10"},
+		{Text: "Second\n\nTwo paragraphs", Note: "bob on HN: https://news.ycombinator.com/item?id=12"},
+	}
+	if got := export.Bookmarks[0].Highlights; !slices.Equal(got, wantHighlights) {
+		t.Errorf("Highlights = %v, want %v", got, wantHighlights)
+	}
+	if got := export.Bookmarks[1].Highlights; len(got) != 0 {
+		t.Errorf("Highlights = %v, want none", got)
+	}
+}
+
+func TestTopCommentsNote_Truncates(t *testing.T) {
+	long := strings.Repeat("é", maxNoteCommentLength+10)
+	got := topCommentsNote([]*hackernews.Item{{By: "alice", Text: long}})
+	want := "Top comments:\n\nalice wrote:\n" + strings.Repeat("é", maxNoteCommentLength) + "…"
+	if got != want {
+		t.Errorf("topCommentsNote() = %q..., want it cut at %d runes", got[:40], maxNoteCommentLength)
+	}
+}
+
+func TestConvert_IncludeText(t *testing.T) {
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Ask HN: Why?", Text: "Just <i>wondering</i>"},
+		2: {ID: 2, Type: "story", Title: "A Link", URL: "https://example.com", Text: "ignored"},
+	}
+	bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}}
+
+	testCases := []struct {
+		name     string
+		template string
+		want     []string // notes, "" for none
+	}{
+		{"appends to the note", "{{hn_url}}", []string{"https://news.ycombinator.com/item?id=1\n\nJust *wondering*", "https://news.ycombinator.com/item?id=2"}},
+		{"without template", "", []string{"Just *wondering*", ""}},
+		{"not twice with {{text}}", "> {{text}}", []string{"> Just wondering", "> "}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			export, _, _ := New(WithFetcher(&mockFetcher{})).Convert(bookmarks, items, Options{NoteTemplate: tc.template, IncludeText: true})
+			for i, want := range tc.want {
+				var got string
+				if note := export.Bookmarks[i].Note; note != nil {
+					got = *note
+				}
+				if got != want {
+					t.Errorf("bookmark %d note = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// transformFunc adapts a function to the Transformer interface.
+type transformFunc func(bm harmonic.Bookmark, item *hackernews.Item, b *Bookmark) (bool, error)
+
+func (f transformFunc) Transform(bm harmonic.Bookmark, item *hackernews.Item, b *Bookmark) (bool, error) {
+	return f(bm, item, b)
+}
+
+func TestConvert_Transform(t *testing.T) {
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Keep me", URL: "https://example.com/1", Score: 10},
+		2: {ID: 2, Type: "story", Title: "Drop me", URL: "https://example.com/2"},
+		3: {ID: 3, Type: "story", Title: "After", URL: "https://example.com/3"},
+	}
+	bookmarks := []harmonic.Bookmark{
+		{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}, {ID: 3, Timestamp: 1700000002},
+	}
+	transform := transformFunc(func(bm harmonic.Bookmark, item *hackernews.Item, b *Bookmark) (bool, error) {
+		if bm.ID == 2 {
+			return false, nil
+		}
+		title := strings.ToUpper(*b.Title)
+		b.Title = &title
+		b.Tags = append(b.Tags, fmt.Sprintf("score:%d", item.Score))
+		return true, nil
+	})
+
+	export, _, err := New(WithFetcher(&mockFetcher{})).Convert(bookmarks, items, Options{Transform: transform})
+	if err != nil {
+		t.Fatalf("Convert() unexpected error: %v", err)
+	}
+	if len(export.Bookmarks) != 2 {
+		t.Fatalf("Convert() returned %d bookmarks, want 2", len(export.Bookmarks))
+	}
+	if got := *export.Bookmarks[0].Title; got != "KEEP ME" {
+		t.Errorf("transformed title = %q, want %q", got, "KEEP ME")
+	}
+	if got := export.Bookmarks[0].Tags; !slices.Equal(got, []string{"score:10"}) {
+		t.Errorf("transformed tags = %v, want [score:10]", got)
+	}
+	if got := *export.Bookmarks[1].Title; got != "AFTER" {
+		t.Errorf("transformed title = %q, want %q", got, "AFTER")
+	}
+
+	t.Run("failure aborts", func(t *testing.T) {
+		failing := transformFunc(func(bm harmonic.Bookmark, _ *hackernews.Item, _ *Bookmark) (bool, error) {
+			if bm.ID == 2 {
+				return true, errors.New("script crashed")
+			}
+			return true, nil
+		})
+		export, _, err := New(WithFetcher(&mockFetcher{})).Convert(bookmarks, items, Options{Transform: failing})
+		if err == nil || !strings.Contains(err.Error(), "transforming item 2: script crashed") {
+			t.Fatalf("Convert() error = %v, want the transform error of item 2", err)
+		}
+		if len(export.Bookmarks) != 0 {
+			t.Errorf("Convert() returned %d bookmarks on error, want none", len(export.Bookmarks))
+		}
+	})
+}
+
+func TestConvert_Summary(t *testing.T) {
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "A Link", URL: "https://example.com", Score: 123, Descendants: 87, Time: 1614686400},
+		2: {ID: 2, Type: "story", Deleted: true},
+	}
+	bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}}
+	opts := Options{SummaryTmpl: "{{score}} points, {{comments}} comments on HN ({{date}})"}
+
+	export, _, _ := New(WithFetcher(&mockFetcher{}), WithDeadItems(DeadItemsHNLink)).Convert(bookmarks, items, opts)
+	if len(export.Bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2", len(export.Bookmarks))
+	}
+	if got := export.Bookmarks[0].Summary; got == nil || *got != "123 points, 87 comments on HN (2021-03-02)" {
+		t.Errorf("summary = %v, want the rendered template", got)
+	}
+	if got := export.Bookmarks[1].Summary; got != nil {
+		t.Errorf("dead item summary = %q, want none", *got)
+	}
+}
+
+func TestConvert_TitleTemplate(t *testing.T) {
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Show HN: A Tool", URL: "https://example.com", Score: 123, Time: 1614686400},
+		2: {ID: 2, Type: "story", Title: "Plain", URL: "https://example.org", Score: 5, Time: 1614686400},
+	}
+	bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}}
+
+	testCases := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"score and year", Options{TitleTmpl: "{{title}} ({{score}}↑, {{year}})"},
+			[]string{"Show HN: A Tool (123↑, 2021)", "Plain (5↑, 2021)"}},
+		{"after stripping the prefix", Options{TitleTmpl: "[{{score}}] {{title}}", StripPrefix: true},
+			[]string{"[123] A Tool", "[5] Plain"}},
+		{"empty rendering keeps the title", Options{TitleTmpl: "{{text}}"},
+			[]string{"Show HN: A Tool", "Plain"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			export, _, _ := New(WithFetcher(&mockFetcher{})).Convert(bookmarks, items, tc.opts)
+			for i, want := range tc.want {
+				if got := *export.Bookmarks[i].Title; got != want {
+					t.Errorf("bookmark %d title = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// mockResolver is a mock implementation of URLResolver for testing.
+type mockResolver map[string]string
+
+func (m mockResolver) Resolve(_ context.Context, url string) (string, error) {
+	if final, ok := m[url]; ok {
+		return final, nil
+	}
+	return "", errors.New("connection refused")
+}
+
+func TestFetchItems_Redirects(t *testing.T) {
+	mock := &mockFetcher{items: map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Shortened", URL: "https://bit.ly/abc"},
+		2: {ID: 2, Type: "story", Title: "Moved", URL: "https://old.example.com/post"},
+		3: {ID: 3, Type: "story", Title: "Unreachable", URL: "https://gone.example.com"},
+		4: {ID: 4, Type: "story", Title: "Direct", URL: "https://example.com"},
+	}}
+	resolver := mockResolver{
+		"https://bit.ly/abc":           "https://example.com", // merged with the direct link
+		"https://old.example.com/post": "https://new.example.com/post",
+		"https://example.com":          "https://example.com",
+	}
+	bookmarks := []harmonic.Bookmark{
+		{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001},
+		{ID: 3, Timestamp: 1700000002}, {ID: 4, Timestamp: 1700000003},
+	}
+
+	c := New(WithFetcher(mock), WithURLResolver(resolver))
+	items, err := c.FetchItems(context.Background(), bookmarks)
+	if err != nil {
+		t.Fatalf("FetchItems() unexpected error: %v", err)
+	}
+	export, deduped, _ := c.Convert(bookmarks, items, Options{})
+
+	var got []string
+	for _, bm := range export.Bookmarks {
+		got = append(got, bm.Content.URL)
+	}
+	want := []string{"https://example.com", "https://new.example.com/post", "https://gone.example.com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("urls = %v, want %v", got, want)
+	}
+	if deduped != 1 {
+		t.Errorf("deduped = %d, want 1 for the shortened duplicate", deduped)
+	}
+}
+
+// mockLinkChecker is a mock implementation of LinkChecker and SnapshotFinder for testing,
+// mapping dead links to their snapshot ("" for none).
+type mockLinkChecker map[string]string
+
+func (m mockLinkChecker) Dead(_ context.Context, url string) (bool, error) {
+	_, dead := m[url]
+	return dead, nil
+}
+
+func (m mockLinkChecker) Closest(_ context.Context, url string, at time.Time) (string, error) {
+	if m[url] == "" {
+		return "", nil
+	}
+	return m[url] + "@" + at.UTC().Format("20060102"), nil
+}
+
+func TestFetchItems_DeadLinks(t *testing.T) {
+	mock := &mockFetcher{items: map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Dead", URL: "https://dead.example.com"},
+		2: {ID: 2, Type: "story", Title: "Alive", URL: "https://example.com"},
+		3: {ID: 3, Type: "story", Title: "Dead, no snapshot", URL: "https://lost.example.com"},
+	}}
+	checker := mockLinkChecker{"https://dead.example.com": "https://web.archive.org/dead", "https://lost.example.com": ""}
+	bookmarks := []harmonic.Bookmark{
+		{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}, {ID: 3, Timestamp: 1700000002},
+	}
+
+	tests := map[string]struct {
+		mode     DeadLinksMode
+		wantURL  string
+		wantNote string
+	}{
+		"note": {
+			mode:     DeadLinksNote,
+			wantURL:  "https://dead.example.com",
+			wantNote: "Link is dead, archived copy: https://web.archive.org/dead@20231114",
+		},
+		"replace": {
+			mode:    DeadLinksReplace,
+			wantURL: "https://web.archive.org/dead@20231114",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New(WithFetcher(mock), WithDeadLinks(tc.mode, checker, checker))
+			items, err := c.FetchItems(context.Background(), bookmarks)
+			if err != nil {
+				t.Fatalf("FetchItems() unexpected error: %v", err)
+			}
+			export, _, _ := c.Convert(bookmarks, items, Options{})
+
+			dead := export.Bookmarks[0]
+			if dead.Content.URL != tc.wantURL {
+				t.Errorf("dead link url = %q, want %q", dead.Content.URL, tc.wantURL)
+			}
+			var note string
+			if dead.Note != nil {
+				note = *dead.Note
+			}
+			if note != tc.wantNote {
+				t.Errorf("dead link note = %q, want %q", note, tc.wantNote)
+			}
+			for _, bm := range export.Bookmarks[1:] {
+				if bm.Note != nil {
+					t.Errorf("note of %s = %q, want none", bm.Content.URL, *bm.Note)
+				}
+			}
+			if got := export.Bookmarks[2].Content.URL; got != "https://lost.example.com" {
+				t.Errorf("dead link without snapshot url = %q, want it kept", got)
+			}
+		})
+	}
+}
+
+func TestConvert_TextPosts(t *testing.T) {
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Ask HN: Why?", Text: "Just <i>wondering</i>"},
+		2: {ID: 2, Type: "story", Title: "A Link", URL: "https://example.com"},
+	}
+	bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1700000000}, {ID: 2, Timestamp: 1700000001}}
+
+	export, _, _ := New(WithFetcher(&mockFetcher{})).Convert(bookmarks, items, Options{TextPosts: true, IncludeText: true})
+
+	if got, want := export.Bookmarks[0].Content, NewTextBookmarkContent("Just *wondering*", hackernews.DiscussionURL(1)); got != want {
+		t.Errorf("text post content = %+v, want %+v", got, want)
+	}
+	if note := export.Bookmarks[0].Note; note != nil {
+		t.Errorf("text post note = %q, want none since the text is the content", *note)
+	}
+	if got, want := export.Bookmarks[1].Content, NewBookmarkContent("https://example.com"); got != want {
+		t.Errorf("link content = %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(export.Bookmarks[0].Content)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	if want := `{"type":"text","text":"Just *wondering*","sourceUrl":"https://news.ycombinator.com/item?id=1"}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader lists the columns written by WriteCSV.
+var csvHeader = []string{"id", "title", "url", "hn_url", "author", "score", "saved_at", "tags", "note"}
+
+// WriteCSV writes the export as CSV with a header row, one bookmark per row in export order.
+// Tags are comma-separated in a single column and saved_at is RFC 3339 in UTC.
+// The HN columns are empty for bookmarks of unknown origin.
+func WriteCSV(w io.Writer, export Schema) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(csvHeader)
+	for _, bm := range export.Bookmarks {
+		var id, score string
+		if bm.Origin.ID != 0 {
+			id, score = strconv.Itoa(bm.Origin.ID), strconv.Itoa(bm.Origin.Score)
+		}
+		_ = cw.Write([]string{
+			id,
+			ptrValue(bm.Title),
+			bm.Content.URL,
+			bm.Origin.hnURL(),
+			bm.Origin.Author,
+			score,
+			time.Unix(bm.CreatedAt, 0).UTC().Format(time.RFC3339),
+			strings.Join(bm.Tags, ","),
+			ptrValue(bm.Note),
+		})
+	}
+	cw.Flush()
+	return cw.Error() // reports any earlier write error
+}
+
+// raindropHeader lists the columns of Raindrop.io's CSV import, written by WriteRaindropCSV.
+// Refer to https://help.raindrop.io/import#csv
+var raindropHeader = []string{"url", "folder", "title", "note", "tags", "created"}
+
+// WriteRaindropCSV writes the export as a Raindrop.io CSV import file. The folder is left empty,
+// so Raindrop puts the bookmarks in Unsorted. Text bookmarks are written as links to their source.
+func WriteRaindropCSV(w io.Writer, export Schema) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(raindropHeader)
+	for _, bm := range export.Bookmarks {
+		_ = cw.Write([]string{
+			bm.Content.URL,
+			"",
+			ptrValue(bm.Title),
+			ptrValue(bm.Note),
+			strings.Join(bm.Tags, ","),
+			time.Unix(bm.CreatedAt, 0).UTC().Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	return cw.Error() // reports any earlier write error
+}
@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	export := Schema{Bookmarks: []Bookmark{
+		{
+			CreatedAt: 1704067200,
+			Title:     ptr(`Say "hi", world`),
+			Content:   NewBookmarkContent("https://example.com"),
+			Tags:      []string{"go", "web"},
+			Note:      ptr("line one\nline two"),
+			Origin:    Origin{ID: 1, Author: "alice", Score: 42},
+		},
+		{
+			CreatedAt: 1704153600,
+			Content:   NewBookmarkContent("https://news.ycombinator.com/item?id=2"),
+		},
+	}}
+
+	var b strings.Builder
+	if err := WriteCSV(&b, export); err != nil {
+		t.Fatalf("WriteCSV() unexpected error: %v", err)
+	}
+	want := `id,title,url,hn_url,author,score,saved_at,tags,note
+1,"Say ""hi"", world",https://example.com,https://news.ycombinator.com/item?id=1,alice,42,2024-01-01T00:00:00Z,"go,web","line one
+line two"
+,,https://news.ycombinator.com/item?id=2,,,,2024-01-02T00:00:00Z,,
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteRaindropCSV(t *testing.T) {
+	export := Schema{Bookmarks: []Bookmark{{
+		CreatedAt: 1704067200,
+		Title:     ptr("A, B"),
+		Content:   NewBookmarkContent("https://example.com"),
+		Tags:      []string{"go", "web"},
+		Note:      ptr("note"),
+	}}}
+
+	var b strings.Builder
+	if err := WriteRaindropCSV(&b, export); err != nil {
+		t.Fatalf("WriteRaindropCSV() unexpected error: %v", err)
+	}
+	want := "url,folder,title,note,tags,created\n" +
+		"https://example.com,,\"A, B\",note,\"go,web\",2024-01-01T00:00:00Z\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteRaindropCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// parallelEncodeThreshold is the number of bookmarks from which WriteJSON encodes in parallel.
+// Below it, spawning workers costs more than it saves.
+const parallelEncodeThreshold = 2000
+
+// encodeChunkSize is the number of bookmarks encoded by one worker at a time.
+const encodeChunkSize = 500
+
+// WriteJSON writes the export as indented JSON, byte for byte what a json.Encoder with
+// a two-space indent writes. Large exports are encoded in parallel chunks, since encoding
+// tens of thousands of bookmarks is otherwise a noticeable single-core bottleneck.
+func WriteJSON(w io.Writer, export Schema) error {
+	if len(export.Bookmarks) < parallelEncodeThreshold {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ") // pretty print
+		return encoder.Encode(export)
+	}
+
+	chunks, err := encodeChunks(export.Bookmarks)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriterSize(w, 64*1024)
+	_, _ = bw.WriteString("{\n  \"bookmarks\": [\n")
+	for i, chunk := range chunks {
+		if i > 0 {
+			_, _ = bw.WriteString(",\n")
+		}
+		_, _ = bw.Write(chunk)
+	}
+	_, _ = bw.WriteString("\n  ]\n}\n")
+	return bw.Flush() // reports any earlier write error
+}
+
+// encodeChunks encodes the bookmarks as indented array elements, in chunks of encodeChunkSize
+// encoded concurrently. Chunks are returned in order, each without a trailing separator.
+func encodeChunks(bookmarks []Bookmark) ([][]byte, error) {
+	n := (len(bookmarks) + encodeChunkSize - 1) / encodeChunkSize
+	chunks := make([][]byte, n)
+	errs := make([]error, n)
+
+	semaphore := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{} // acquire
+			defer func() { <-semaphore }()
+
+			end := min((i+1)*encodeChunkSize, len(bookmarks))
+			chunks[i], errs[i] = encodeChunk(bookmarks[i*encodeChunkSize : end])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chunks, nil
+}
+
+// encodeChunk encodes bookmarks as elements of the indented "bookmarks" array, separated by ",\n".
+func encodeChunk(bookmarks []Bookmark) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, bm := range bookmarks {
+		data, err := json.Marshal(bm)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("    ") // elements sit two levels deep
+		if err := json.Indent(&buf, data, "    ", "  "); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteJSONL writes the export as JSON Lines: one compact bookmark object per line, in the schema
+// of Karakeep's import file, for streaming into other tools.
+func WriteJSONL(w io.Writer, export Schema) error {
+	bw := bufio.NewWriterSize(w, 64*1024)
+	encoder := json.NewEncoder(bw)
+	for _, bm := range export.Bookmarks {
+		if err := encoder.Encode(bm); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,101 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// syntheticExport returns an export of n bookmarks covering nullable fields, tags, and escaped characters.
+func syntheticExport(n int) Schema {
+	var export Schema
+	for i := range n {
+		bm := Bookmark{
+			CreatedAt: int64(1700000000 + i),
+			Title:     ptr(fmt.Sprintf("Show HN: <Tool> #%d & \"friends\" – ünïcode", i)),
+			Content:   NewBookmarkContent(fmt.Sprintf("https://example.com/%d?a=1&b=2", i)),
+		}
+		if i%2 == 0 {
+			bm.Note = ptr(fmt.Sprintf("https://news.ycombinator.com/item?id=%d\nline two", i))
+			bm.Tags = BookmarkTags{"hn", "tag-" + fmt.Sprint(i%7)}
+		}
+		if i%3 == 0 {
+			bm.Title = nil
+			bm.Archived = true
+		}
+		bm.Favourited = i%5 == 0
+		export.Bookmarks = append(export.Bookmarks, bm)
+	}
+	return export
+}
+
+// encoderJSON returns the export as written by a json.Encoder with a two-space indent.
+func encoderJSON(t testing.TB, export Schema) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteJSON(t *testing.T) {
+	sizes := []int{0, 1, parallelEncodeThreshold - 1, parallelEncodeThreshold, parallelEncodeThreshold + encodeChunkSize + 1}
+	for _, n := range sizes {
+		t.Run(fmt.Sprintf("%d bookmarks", n), func(t *testing.T) {
+			export := syntheticExport(n)
+			var got bytes.Buffer
+			if err := WriteJSON(&got, export); err != nil {
+				t.Fatalf("WriteJSON() error: %v", err)
+			}
+			if want := encoderJSON(t, export); !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("WriteJSON() output differs from json.Encoder (got %d bytes, want %d)", got.Len(), len(want))
+			}
+		})
+	}
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	export := syntheticExport(50000)
+	b.Run("encoder", func(b *testing.B) {
+		for b.Loop() {
+			encoder := json.NewEncoder(io.Discard)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(export); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("WriteJSON", func(b *testing.B) {
+		for b.Loop() {
+			if err := WriteJSON(io.Discard, export); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestWriteJSONL(t *testing.T) {
+	export := syntheticExport(3)
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, export); err != nil {
+		t.Fatalf("WriteJSONL() unexpected error: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	if len(lines) != len(export.Bookmarks) {
+		t.Fatalf("WriteJSONL() wrote %d lines, want %d", len(lines), len(export.Bookmarks))
+	}
+	for i, line := range lines {
+		want, err := json.Marshal(export.Bookmarks[i])
+		if err != nil {
+			t.Fatalf("json.Marshal() unexpected error: %v", err)
+		}
+		if !bytes.Equal(line, want) {
+			t.Errorf("WriteJSONL() line %d = %s, want %s", i, line, want)
+		}
+	}
+}
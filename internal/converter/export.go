@@ -0,0 +1,161 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Exporter writes an export in one output format.
+type Exporter interface {
+	Export(w io.Writer, export Schema) error
+}
+
+// ExporterFunc adapts an ordinary function to the Exporter interface.
+type ExporterFunc func(w io.Writer, export Schema) error
+
+// Export calls f(w, export).
+func (f ExporterFunc) Export(w io.Writer, export Schema) error {
+	return f(w, export)
+}
+
+// ExportOptions configures the exporter created for a format.
+type ExportOptions struct {
+	Path    string // output path; the target directory of directory formats
+	GroupBy string // grouping of markdown output, GroupByMonth or GroupByTag
+	Schema  int    // structure of the Karakeep import file, SchemaV1 (default) or SchemaV2
+}
+
+// Format is a registered output format.
+type Format struct {
+	Name        string
+	Aliases     []string // other names accepted for the format
+	Description string   // short description, for usage messages
+	Directory   bool     // writes to the ExportOptions.Path directory and ignores w
+	New         func(opts ExportOptions) (Exporter, error)
+}
+
+// formats lists the registered output formats, in registration order.
+var formats []Format
+
+// Names of the built-in output formats.
+const (
+	FormatKarakeepJSON = "karakeep-json"
+	FormatJSONL        = "jsonl"
+	FormatCSV          = "csv"
+	FormatRaindropCSV  = "raindrop-csv"
+	FormatMarkdown     = "markdown"
+	FormatNetscapeHTML = "netscape-html"
+	FormatObsidian     = "obsidian"
+)
+
+func init() {
+	RegisterFormat(Format{
+		Name:        FormatKarakeepJSON,
+		Aliases:     []string{"json"},
+		Description: "Karakeep import file",
+		New: func(opts ExportOptions) (Exporter, error) {
+			switch opts.Schema {
+			case 0, SchemaV1:
+				return ExporterFunc(WriteJSON), nil
+			case SchemaV2:
+				return ExporterFunc(WriteJSONV2), nil
+			}
+			return nil, fmt.Errorf("unknown Karakeep schema version %d (supported: %d, %d)", opts.Schema, SchemaV1, SchemaV2)
+		},
+	})
+	RegisterFormat(Format{
+		Name:        FormatJSONL,
+		Description: "one Karakeep bookmark object per line",
+		New:         writerExporter(WriteJSONL),
+	})
+	RegisterFormat(Format{
+		Name:        FormatCSV,
+		Description: "spreadsheets",
+		New:         writerExporter(WriteCSV),
+	})
+	RegisterFormat(Format{
+		Name:        FormatRaindropCSV,
+		Description: "Raindrop.io import file",
+		New:         writerExporter(WriteRaindropCSV),
+	})
+	RegisterFormat(Format{
+		Name:        FormatMarkdown,
+		Description: "reading list",
+		New: func(opts ExportOptions) (Exporter, error) {
+			groupBy := opts.GroupBy
+			if groupBy == "" {
+				groupBy = GroupByMonth
+			}
+			if groupBy != GroupByMonth && groupBy != GroupByTag {
+				return nil, fmt.Errorf("unknown markdown grouping %q (supported: %s, %s)", groupBy, GroupByMonth, GroupByTag)
+			}
+			return ExporterFunc(func(w io.Writer, export Schema) error {
+				return WriteMarkdown(w, export, groupBy)
+			}), nil
+		},
+	})
+	RegisterFormat(Format{
+		Name:        FormatNetscapeHTML,
+		Description: "browser bookmarks file",
+		New:         writerExporter(WriteNetscapeHTML),
+	})
+	RegisterFormat(Format{
+		Name:        FormatObsidian,
+		Description: "one note per bookmark in the output directory",
+		Directory:   true,
+		New: func(opts ExportOptions) (Exporter, error) {
+			if opts.Path == "" {
+				return nil, fmt.Errorf("the %s format requires an output directory", FormatObsidian)
+			}
+			return ExporterFunc(func(_ io.Writer, export Schema) error {
+				return WriteVault(opts.Path, export)
+			}), nil
+		},
+	})
+}
+
+// writerExporter returns a Format.New for a writer function without options.
+func writerExporter(write func(w io.Writer, export Schema) error) func(ExportOptions) (Exporter, error) {
+	return func(ExportOptions) (Exporter, error) {
+		return ExporterFunc(write), nil
+	}
+}
+
+// RegisterFormat registers an output format. It panics if the format has no name or constructor,
+// or if its name or an alias is already taken, as registration happens at init time.
+func RegisterFormat(f Format) {
+	if f.Name == "" || f.New == nil {
+		panic("converter: RegisterFormat with no name or constructor")
+	}
+	for _, name := range append([]string{f.Name}, f.Aliases...) {
+		if _, ok := LookupFormat(name); ok {
+			panic("converter: output format " + name + " registered twice")
+		}
+	}
+	formats = append(formats, f)
+}
+
+// LookupFormat returns the output format registered under the given name or alias.
+func LookupFormat(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.Name == name || slices.Contains(f.Aliases, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Formats returns the registered output formats, in registration order.
+func Formats() []Format {
+	return slices.Clone(formats)
+}
+
+// FormatNames returns the names of the registered output formats, for messages.
+func FormatNames() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
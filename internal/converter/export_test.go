@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantOK   bool
+	}{
+		{name: "karakeep-json", wantName: FormatKarakeepJSON, wantOK: true},
+		{name: "json", wantName: FormatKarakeepJSON, wantOK: true},
+		{name: "netscape-html", wantName: FormatNetscapeHTML, wantOK: true},
+		{name: "yaml", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := LookupFormat(tt.name)
+			if ok != tt.wantOK || f.Name != tt.wantName {
+				t.Errorf("LookupFormat(%q) = %q, %v, want %q, %v", tt.name, f.Name, ok, tt.wantName, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFormat_New(t *testing.T) {
+	export := Schema{Bookmarks: []Bookmark{{
+		CreatedAt: 1704067200,
+		Title:     ptr("Title"),
+		Content:   NewBookmarkContent("https://example.com"),
+		Tags:      []string{"go"},
+	}}}
+
+	for _, f := range Formats() {
+		if f.Directory {
+			continue
+		}
+		t.Run(f.Name, func(t *testing.T) {
+			exporter, err := f.New(ExportOptions{})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			var b strings.Builder
+			if err := exporter.Export(&b, export); err != nil {
+				t.Fatalf("Export() unexpected error: %v", err)
+			}
+			if !strings.Contains(b.String(), "https://example.com") {
+				t.Errorf("Export() = %q, missing the bookmark URL", b.String())
+			}
+		})
+	}
+
+	markdown, _ := LookupFormat(FormatMarkdown)
+	if _, err := markdown.New(ExportOptions{GroupBy: "year"}); err == nil {
+		t.Error("markdown New() with an unknown grouping, want error")
+	}
+	obsidian, _ := LookupFormat(FormatObsidian)
+	if _, err := obsidian.New(ExportOptions{}); err == nil {
+		t.Error("obsidian New() without a path, want error")
+	}
+}
+
+func TestRegisterFormat_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterFormat() with a taken alias did not panic")
+		}
+	}()
+	RegisterFormat(Format{Name: "other", Aliases: []string{"json"}, New: writerExporter(WriteJSON)})
+}
@@ -0,0 +1,466 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/akhdanfadh/hnkeep/internal/htmlconv"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// FilterExpr is a compiled filter expression over HN items, e.g.,
+// `score > 100 && type == "story" && !contains(domain, "medium.com")`.
+//
+// Expressions combine the item fields listed in ExprFields with integer and string literals,
+// true and false, the comparisons == != < <= > >=, the operators && || !, parentheses,
+// and the functions contains, hasPrefix, hasSuffix, and lower. They are type-checked
+// when parsed, so evaluating one can't fail.
+type FilterExpr struct {
+	src  string
+	eval func(*hackernews.Item) any
+}
+
+// exprType is the static type of an expression.
+type exprType int
+
+const (
+	exprBool exprType = iota
+	exprInt
+	exprString
+)
+
+func (t exprType) String() string {
+	switch t {
+	case exprBool:
+		return "bool"
+	case exprInt:
+		return "int"
+	}
+	return "string"
+}
+
+// exprNode is a type-checked subexpression.
+type exprNode struct {
+	typ  exprType
+	eval func(*hackernews.Item) any
+}
+
+// exprField is an item field available to expressions.
+type exprField struct {
+	typ  exprType
+	eval func(*hackernews.Item) any
+}
+
+// exprFields maps the field names of expressions to their values.
+var exprFields = map[string]exprField{
+	"id":       {exprInt, func(i *hackernews.Item) any { return i.ID }},
+	"score":    {exprInt, func(i *hackernews.Item) any { return i.Score }},
+	"comments": {exprInt, func(i *hackernews.Item) any { return i.Descendants }},
+	"time":     {exprInt, func(i *hackernews.Item) any { return int(i.Time) }},
+	"type":     {exprString, func(i *hackernews.Item) any { return i.Type }},
+	"kind":     {exprString, func(i *hackernews.Item) any { return ItemKind(i) }},
+	"by":       {exprString, func(i *hackernews.Item) any { return i.By }},
+	"title":    {exprString, func(i *hackernews.Item) any { return i.Title }},
+	"url":      {exprString, func(i *hackernews.Item) any { return i.URL }},
+	"domain":   {exprString, func(i *hackernews.Item) any { return domainOf(i.URL) }},
+	"text":     {exprString, func(i *hackernews.Item) any { return htmlconv.ToText(i.Text) }},
+}
+
+// ExprFields lists the item fields available to filter expressions, for messages.
+var ExprFields = []string{"id", "score", "comments", "time", "type", "kind", "by", "title", "url", "domain", "text"}
+
+// exprFuncs maps the function names of expressions to their implementations, all on strings.
+var exprFuncs = map[string]struct {
+	args int
+	ret  exprType
+	call func(args []string) any
+}{
+	"contains":  {2, exprBool, func(a []string) any { return strings.Contains(a[0], a[1]) }},
+	"hasPrefix": {2, exprBool, func(a []string) any { return strings.HasPrefix(a[0], a[1]) }},
+	"hasSuffix": {2, exprBool, func(a []string) any { return strings.HasSuffix(a[0], a[1]) }},
+	"lower":     {1, exprString, func(a []string) any { return strings.ToLower(a[0]) }},
+}
+
+// domainOf returns the host of rawURL without "www.", lowercase, or "" if it has none.
+func domainOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// ParseFilterExpr parses and type-checks a filter expression, which must be of type bool.
+func ParseFilterExpr(src string) (*FilterExpr, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+	}
+	if n.typ != exprBool {
+		return nil, fmt.Errorf("filter expression is of type %s, want bool", n.typ)
+	}
+	return &FilterExpr{src: src, eval: n.eval}, nil
+}
+
+// Match reports whether the item satisfies the expression.
+func (e *FilterExpr) Match(item *hackernews.Item) bool {
+	return e.eval(item).(bool)
+}
+
+// String returns the source of the expression.
+func (e *FilterExpr) String() string {
+	return e.src
+}
+
+// tokenKind is the kind of a lexical token of an expression.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokInt
+	tokString
+	tokIdent
+	tokOp // operators and punctuation
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string // the operator, identifier, or literal; strings unquoted
+	pos  int    // byte offset in the source
+}
+
+func (t exprToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// exprOps lists the operators, two-character ones first so they are matched before their prefixes.
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", ",", "-"}
+
+// lexExpr splits an expression into tokens.
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, exprToken{tokInt, src[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, exprToken{tokIdent, src[i:j], i})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && rune(src[j]) != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			lit := src[i : j+1]
+			if c == '\'' { // single quotes are easier to write in shells
+				lit = `"` + strings.ReplaceAll(strings.ReplaceAll(lit[1:len(lit)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			s, err := strconv.Unquote(lit)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, exprToken{tokString, s, i})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range exprOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, exprToken{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{tokEOF, "", len(src)}), nil
+}
+
+// exprParser is a recursive descent parser of expressions. Precedence follows Go:
+// unary operators, then comparisons, then &&, then ||.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the given operator.
+func (p *exprParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q at offset %d, got %s", op, tok.pos, tok)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for {
+		pos := p.peek().pos
+		if !p.accept("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if err := checkTypes("||", pos, exprBool, left, right); err != nil {
+			return exprNode{}, err
+		}
+		l, r := left.eval, right.eval
+		left = exprNode{exprBool, func(i *hackernews.Item) any { return l(i).(bool) || r(i).(bool) }}
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for {
+		pos := p.peek().pos
+		if !p.accept("&&") {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if err := checkTypes("&&", pos, exprBool, left, right); err != nil {
+			return exprNode{}, err
+		}
+		l, r := left.eval, right.eval
+		left = exprNode{exprBool, func(i *hackernews.Item) any { return l(i).(bool) && r(i).(bool) }}
+	}
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return exprNode{}, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return left, nil
+	}
+	op := tok.text
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseUnary()
+	if err != nil {
+		return exprNode{}, err
+	}
+	if left.typ != right.typ {
+		return exprNode{}, fmt.Errorf("mismatched types %s and %s for %s at offset %d", left.typ, right.typ, op, tok.pos)
+	}
+	if left.typ == exprBool && op != "==" && op != "!=" {
+		return exprNode{}, fmt.Errorf("operator %s not defined on bool at offset %d", op, tok.pos)
+	}
+	l, r := left.eval, right.eval
+	return exprNode{exprBool, func(i *hackernews.Item) any { return compare(op, l(i), r(i)) }}, nil
+}
+
+// compare applies a comparison operator to two values of the same type.
+func compare(op string, a, b any) bool {
+	var c int
+	switch a := a.(type) {
+	case bool:
+		if op == "==" {
+			return a == b.(bool)
+		}
+		return a != b.(bool)
+	case int:
+		c = a - b.(int)
+	case string:
+		c = strings.Compare(a, b.(string))
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	tok := p.peek()
+	switch {
+	case p.accept("!"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if err := checkTypes("!", tok.pos, exprBool, operand); err != nil {
+			return exprNode{}, err
+		}
+		f := operand.eval
+		return exprNode{exprBool, func(i *hackernews.Item) any { return !f(i).(bool) }}, nil
+	case p.accept("-"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if err := checkTypes("-", tok.pos, exprInt, operand); err != nil {
+			return exprNode{}, err
+		}
+		f := operand.eval
+		return exprNode{exprInt, func(i *hackernews.Item) any { return -f(i).(int) }}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokInt:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return exprNode{}, fmt.Errorf("invalid number %s at offset %d", tok.text, tok.pos)
+		}
+		return exprNode{exprInt, func(*hackernews.Item) any { return n }}, nil
+	case tokString:
+		s := tok.text
+		return exprNode{exprString, func(*hackernews.Item) any { return s }}, nil
+	case tokIdent:
+		if p.peek().kind == tokOp && p.peek().text == "(" {
+			return p.parseCall(tok)
+		}
+		switch tok.text {
+		case "true", "false":
+			b := tok.text == "true"
+			return exprNode{exprBool, func(*hackernews.Item) any { return b }}, nil
+		}
+		field, ok := exprFields[tok.text]
+		if !ok {
+			return exprNode{}, fmt.Errorf("unknown field %q at offset %d (supported: %s)",
+				tok.text, tok.pos, strings.Join(ExprFields, ", "))
+		}
+		return exprNode(field), nil
+	case tokOp:
+		if tok.text == "(" {
+			n, err := p.parseOr()
+			if err != nil {
+				return exprNode{}, err
+			}
+			return n, p.expect(")")
+		}
+	}
+	return exprNode{}, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+}
+
+// parseCall parses the arguments of a call to the named function, after its name.
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	fn, ok := exprFuncs[name.text]
+	if !ok {
+		return exprNode{}, fmt.Errorf("unknown function %q at offset %d (supported: contains, hasPrefix, hasSuffix, lower)",
+			name.text, name.pos)
+	}
+	p.next() // (
+	var args []exprNode
+	for !p.accept(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return exprNode{}, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return exprNode{}, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) != fn.args {
+		return exprNode{}, fmt.Errorf("%s takes %d arguments, got %d at offset %d", name.text, fn.args, len(args), name.pos)
+	}
+	if err := checkTypes(name.text, name.pos, exprString, args...); err != nil {
+		return exprNode{}, err
+	}
+	return exprNode{fn.ret, func(i *hackernews.Item) any {
+		values := make([]string, len(args))
+		for k, arg := range args {
+			values[k] = arg.eval(i).(string)
+		}
+		return fn.call(values)
+	}}, nil
+}
+
+// checkTypes returns an error if an operand of op is not of the wanted type.
+func checkTypes(op string, pos int, want exprType, operands ...exprNode) error {
+	for _, n := range operands {
+		if n.typ != want {
+			return fmt.Errorf("%s needs %s operands, got %s at offset %d", op, want, n.typ, pos)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestFilterExpr_Match(t *testing.T) {
+	story := &hackernews.Item{
+		ID: 1, Type: "story", By: "pg", Score: 150, Descendants: 42, Time: 1700000000,
+		Title: "Ask HN: Why Go?", URL: "https://www.Example.com/post", Text: "<p>It&#x27;s simple",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`score > 100 && type == "story" && !contains(domain, "medium.com")`, true},
+		{`score > 100 && type == "story" && !contains(domain, "example.com")`, false},
+		{`domain == "example.com"`, true},
+		{`kind == "ask"`, true},
+		{`score >= 150 && score <= 150 && score != 149`, true},
+		{`score < 100 || comments > 40`, true},
+		{`!(score < 100 || comments > 40)`, false},
+		{`hasPrefix(lower(title), "ask hn") && hasSuffix(url, "/post")`, true},
+		{`by == 'pg' && text == "It's simple"`, true},
+		{`time > 1600000000 && id == 1 && -score < 0`, true},
+		{`title < "B"`, true},
+		{`true && (false || score == 150) == true`, true},
+		{`contains("a\"b", "\"")`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := ParseFilterExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilterExpr() unexpected error: %v", err)
+			}
+			if got := e.Match(story); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterExpr_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{`score`, "of type int, want bool"},
+		{`score > "100"`, "mismatched types int and string"},
+		{`points > 1`, `unknown field "points"`},
+		{`matches(title, "x")`, `unknown function "matches"`},
+		{`contains(title)`, "takes 2 arguments, got 1"},
+		{`contains(score, "1")`, "contains needs string operands, got int"},
+		{`score > 1 &&`, "unexpected end of expression"},
+		{`(score > 1`, `expected ")"`},
+		{`score > 1 score`, `unexpected "score" at offset 10`},
+		{`title == "x`, "unterminated string at offset 9"},
+		{`score > 1 & true`, `unexpected character '&'`},
+		{`!score`, "! needs bool operands, got int"},
+		{`true < false`, "operator < not defined on bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseFilterExpr(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFilterExpr() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
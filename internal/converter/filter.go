@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// ItemFilter selects the fetched items to convert. The zero value keeps every item.
+type ItemFilter struct {
+	MinScore int         // keep items with at least this HN score (0 = all)
+	Kinds    []string    // keep items of these kinds, see ItemKind (empty = all)
+	Excluded []string    // drop items linking to these domains or their subdomains
+	Only     []string    // keep only items linking to these domains or their subdomains (empty = all)
+	Expr     *FilterExpr // keep items matching this expression (nil = all)
+}
+
+// Keep reports whether the item passes the filter.
+// Stubs of deleted or dead items always pass, since their score and type are unknown.
+func (f ItemFilter) Keep(item *hackernews.Item) bool {
+	if item.Deleted || item.Dead {
+		return true
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, ItemKind(item)) {
+		return false
+	}
+	if len(f.Excluded) > 0 && item.URL != "" && urlOnDomains(item.URL, f.Excluded) {
+		return false
+	}
+	if len(f.Only) > 0 && !urlOnDomains(item.URL, f.Only) {
+		return false // including items without a link, e.g., text posts
+	}
+	if f.Expr != nil && !f.Expr.Match(item) {
+		return false
+	}
+	return item.Score >= f.MinScore
+}
+
+// urlOnDomains reports whether the host of rawURL is one of the domains or a subdomain of one.
+func urlOnDomains(rawURL string, domains []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, d := range domains {
+		if onDomain(host, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply removes the items that don't pass the filter and returns their IDs.
+func (f ItemFilter) Apply(items map[int]*hackernews.Item) map[int]bool {
+	dropped := make(map[int]bool)
+	for id, item := range items {
+		if !f.Keep(item) {
+			delete(items, id)
+			dropped[id] = true
+		}
+	}
+	return dropped
+}
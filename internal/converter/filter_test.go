@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestItemFilter(t *testing.T) {
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Score: 5},
+		2: {ID: 2, Score: 100},
+		3: {ID: 3, Score: 250},
+		4: {ID: 4, Deleted: true},
+	}
+
+	dropped := ItemFilter{MinScore: 100}.Apply(items)
+	if len(dropped) != 1 || !dropped[1] {
+		t.Errorf("Apply() dropped %v, want only item 1", dropped)
+	}
+	for _, id := range []int{2, 3, 4} {
+		if _, ok := items[id]; !ok {
+			t.Errorf("Apply() removed item %d, want it kept", id)
+		}
+	}
+	if _, ok := items[1]; ok {
+		t.Error("Apply() kept item 1 below the minimum score")
+	}
+
+	if !(ItemFilter{}).Keep(&hackernews.Item{Score: 0}) {
+		t.Error("zero filter should keep every item")
+	}
+}
+
+func TestItemFilter_Kinds(t *testing.T) {
+	f := ItemFilter{Kinds: []string{"story", "show"}}
+	tests := map[string]struct {
+		item hackernews.Item
+		want bool
+	}{
+		"story":        {hackernews.Item{Type: "story", Title: "A database"}, true},
+		"show":         {hackernews.Item{Type: "story", Title: "Show HN: A tool"}, true},
+		"ask":          {hackernews.Item{Type: "story", Title: "Ask HN: Why?"}, false},
+		"job":          {hackernews.Item{Type: "job", Title: "Hiring"}, false},
+		"comment":      {hackernews.Item{Type: "comment"}, false},
+		"deleted stub": {hackernews.Item{Deleted: true}, true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := f.Keep(&tc.item); got != tc.want {
+				t.Errorf("Keep() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestItemFilter_Excluded(t *testing.T) {
+	f := ItemFilter{Excluded: []string{"reddit.com", "twitter.com"}}
+	tests := map[string]struct {
+		item hackernews.Item
+		want bool
+	}{
+		"excluded":      {hackernews.Item{Type: "story", URL: "https://www.reddit.com/r/golang"}, false},
+		"subdomain":     {hackernews.Item{Type: "story", URL: "https://old.Reddit.com/r/golang"}, false},
+		"other domain":  {hackernews.Item{Type: "story", URL: "https://notreddit.com/"}, true},
+		"text post":     {hackernews.Item{Type: "story", Title: "Ask HN: Why?"}, true},
+		"deleted stub":  {hackernews.Item{Deleted: true}, true},
+		"twitter links": {hackernews.Item{Type: "story", URL: "https://twitter.com/x/status/1"}, false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := f.Keep(&tc.item); got != tc.want {
+				t.Errorf("Keep() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestItemFilter_Only(t *testing.T) {
+	f := ItemFilter{Only: []string{"github.com", "arxiv.org"}, Excluded: []string{"gist.github.com"}}
+	tests := map[string]struct {
+		item hackernews.Item
+		want bool
+	}{
+		"listed":       {hackernews.Item{Type: "story", URL: "https://github.com/a/b"}, true},
+		"subdomain":    {hackernews.Item{Type: "story", URL: "https://export.arxiv.org/abs/1"}, true},
+		"excluded too": {hackernews.Item{Type: "story", URL: "https://gist.github.com/a/1"}, false},
+		"other domain": {hackernews.Item{Type: "story", URL: "https://example.com/"}, false},
+		"text post":    {hackernews.Item{Type: "story", Title: "Ask HN: Why?"}, false},
+		"deleted stub": {hackernews.Item{Deleted: true}, true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := f.Keep(&tc.item); got != tc.want {
+				t.Errorf("Keep() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestItemFilter_Expr(t *testing.T) {
+	expr, err := ParseFilterExpr(`score > 100 && !contains(domain, "medium.com")`)
+	if err != nil {
+		t.Fatalf("ParseFilterExpr() unexpected error: %v", err)
+	}
+	f := ItemFilter{Expr: expr}
+	tests := map[string]struct {
+		item hackernews.Item
+		want bool
+	}{
+		"match":        {hackernews.Item{Score: 150, URL: "https://example.com"}, true},
+		"low score":    {hackernews.Item{Score: 50, URL: "https://example.com"}, false},
+		"medium":       {hackernews.Item{Score: 150, URL: "https://blog.medium.com/x"}, false},
+		"deleted stub": {hackernews.Item{Deleted: true}, true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := f.Keep(&tc.item); got != tc.want {
+				t.Errorf("Keep() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Markdown groupings supported by WriteMarkdown.
+const (
+	GroupByMonth = "month"
+	GroupByTag   = "tag"
+)
+
+// untaggedGroup is the heading of bookmarks without tags when grouping by tag.
+const untaggedGroup = "Untagged"
+
+// WriteMarkdown writes the export as a Markdown reading list, grouped by the month the bookmarks
+// were saved (newest first) or by tag (alphabetically, a bookmark is listed under each of its tags).
+// Each entry links the title to the bookmarked URL and the HN discussion, followed by the note as a quote.
+func WriteMarkdown(w io.Writer, export Schema, groupBy string) error {
+	groups := make(map[string][]Bookmark)
+	for _, bm := range export.Bookmarks {
+		switch {
+		case groupBy == GroupByTag && len(bm.Tags) == 0:
+			groups[untaggedGroup] = append(groups[untaggedGroup], bm)
+		case groupBy == GroupByTag:
+			for _, tag := range bm.Tags {
+				groups[tag] = append(groups[tag], bm)
+			}
+		default:
+			month := time.Unix(bm.CreatedAt, 0).UTC().Format("2006-01")
+			groups[month] = append(groups[month], bm)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if groupBy != GroupByTag {
+		slices.Reverse(names) // newest month first
+	}
+
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("# Hacker News bookmarks\n")
+	for _, name := range names {
+		bookmarks := groups[name]
+		slices.SortStableFunc(bookmarks, func(a, b Bookmark) int { return cmp.Compare(b.CreatedAt, a.CreatedAt) })
+
+		fmt.Fprintf(bw, "\n## %s\n\n", name)
+		for _, bm := range bookmarks {
+			writeMarkdownEntry(bw, bm)
+		}
+	}
+	return bw.Flush() // reports any earlier write error
+}
+
+// writeMarkdownEntry writes a bookmark as a list item, with its text and note quoted below it.
+func writeMarkdownEntry(w io.Writer, bm Bookmark) {
+	title := bm.Content.URL
+	if bm.Title != nil && *bm.Title != "" {
+		title = *bm.Title
+	}
+	fmt.Fprintf(w, "- [%s](%s)", escapeMarkdown(title), bm.Content.URL)
+	if hnURL := bm.Origin.hnURL(); hnURL != "" && hnURL != bm.Content.URL {
+		fmt.Fprintf(w, " ([HN](%s))", hnURL)
+	}
+	fmt.Fprintf(w, " - %s\n", time.Unix(bm.CreatedAt, 0).UTC().Format("2006-01-02"))
+
+	quote := strings.TrimSpace(bm.Content.Text + "\n\n" + ptrValue(bm.Note))
+	if quote == "" {
+		return
+	}
+	for line := range strings.SplitSeq(quote, "\n") {
+		fmt.Fprintf(w, "  %s\n", strings.TrimRight("> "+line, " "))
+	}
+}
+
+// escapeMarkdown escapes the characters that would end or break a link text.
+var escapeMarkdown = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace
+
+// ptrValue returns the string pointed to, or "" if nil.
+func ptrValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
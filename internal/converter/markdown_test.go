@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	export := Schema{Bookmarks: []Bookmark{
+		{
+			CreatedAt: 1704067200, // 2024-01-01
+			Title:     ptr("Old [draft]"),
+			Content:   NewBookmarkContent("https://example.com/old"),
+			Tags:      []string{"go"},
+			Note:      ptr("first line\n\nsecond line"),
+			Origin:    Origin{ID: 1},
+		},
+		{
+			CreatedAt: 1706832000, // 2024-02-02
+			Title:     ptr("Ask HN: Why?"),
+			Content:   NewTextBookmarkContent("Just wondering", "https://news.ycombinator.com/item?id=2"),
+			Tags:      []string{"go", "ask"},
+			Origin:    Origin{ID: 2},
+		},
+		{
+			CreatedAt: 1704153600, // 2024-01-02
+			Content:   NewBookmarkContent("https://news.ycombinator.com/item?id=3"),
+		},
+	}}
+
+	testCases := []struct {
+		name    string
+		groupBy string
+		want    string
+	}{
+		{
+			name:    "by month",
+			groupBy: GroupByMonth,
+			want: `# Hacker News bookmarks
+
+## 2024-02
+
+- [Ask HN: Why?](https://news.ycombinator.com/item?id=2) - 2024-02-02
+  > Just wondering
+
+## 2024-01
+
+- [https://news.ycombinator.com/item?id=3](https://news.ycombinator.com/item?id=3) - 2024-01-02
+- [Old \[draft\]](https://example.com/old) ([HN](https://news.ycombinator.com/item?id=1)) - 2024-01-01
+  > first line
+  >
+  > second line
+`,
+		},
+		{
+			name:    "by tag",
+			groupBy: GroupByTag,
+			want: `# Hacker News bookmarks
+
+## Untagged
+
+- [https://news.ycombinator.com/item?id=3](https://news.ycombinator.com/item?id=3) - 2024-01-02
+
+## ask
+
+- [Ask HN: Why?](https://news.ycombinator.com/item?id=2) - 2024-02-02
+  > Just wondering
+
+## go
+
+- [Ask HN: Why?](https://news.ycombinator.com/item?id=2) - 2024-02-02
+  > Just wondering
+- [Old \[draft\]](https://example.com/old) ([HN](https://news.ycombinator.com/item?id=1)) - 2024-01-01
+  > first line
+  >
+  > second line
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := WriteMarkdown(&b, export, tc.groupBy); err != nil {
+				t.Fatalf("WriteMarkdown() unexpected error: %v", err)
+			}
+			if got := b.String(); got != tc.want {
+				t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
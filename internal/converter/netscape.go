@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// netscapeHeader is the preamble of a Netscape bookmark file, the format browsers import and export.
+const netscapeHeader = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`
+
+// WriteNetscapeHTML writes the export as a Netscape bookmark file, which browsers and most bookmark
+// managers import. Bookmarks sit in a single flat list in export order, with tags in the TAGS
+// attribute and notes as descriptions. Text bookmarks are written as links to their source.
+func WriteNetscapeHTML(w io.Writer, export Schema) error {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString(netscapeHeader)
+	for _, bm := range export.Bookmarks {
+		title := ptrValue(bm.Title)
+		if title == "" {
+			title = bm.Content.URL
+		}
+		_, _ = bw.WriteString(`    <DT><A HREF="` + html.EscapeString(bm.Content.URL) +
+			`" ADD_DATE="` + strconv.FormatInt(bm.CreatedAt, 10) + `"`)
+		if len(bm.Tags) > 0 {
+			_, _ = bw.WriteString(` TAGS="` + html.EscapeString(strings.Join(bm.Tags, ",")) + `"`)
+		}
+		_, _ = bw.WriteString(">" + html.EscapeString(title) + "</A>\n")
+		if note := ptrValue(bm.Note); note != "" {
+			_, _ = bw.WriteString("    <DD>" + html.EscapeString(note) + "\n")
+		}
+	}
+	_, _ = bw.WriteString("</DL><p>\n")
+	return bw.Flush() // reports any earlier write error
+}
@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteNetscapeHTML(t *testing.T) {
+	export := Schema{Bookmarks: []Bookmark{
+		{
+			CreatedAt: 1704067200,
+			Title:     ptr(`Say "hi" & <wave>`),
+			Content:   NewBookmarkContent("https://example.com/?a=1&b=2"),
+			Tags:      []string{"go", "web"},
+			Note:      ptr("a note"),
+		},
+		{
+			CreatedAt: 1704153600,
+			Content:   NewBookmarkContent("https://news.ycombinator.com/item?id=2"),
+		},
+	}}
+
+	var b strings.Builder
+	if err := WriteNetscapeHTML(&b, export); err != nil {
+		t.Fatalf("WriteNetscapeHTML() unexpected error: %v", err)
+	}
+	want := netscapeHeader +
+		`    <DT><A HREF="https://example.com/?a=1&amp;b=2" ADD_DATE="1704067200" TAGS="go,web">Say &#34;hi&#34; &amp; &lt;wave&gt;</A>` + "\n" +
+		"    <DD>a note\n" +
+		`    <DT><A HREF="https://news.ycombinator.com/item?id=2" ADD_DATE="1704153600">https://news.ycombinator.com/item?id=2</A>` + "\n" +
+		"</DL><p>\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteNetscapeHTML() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that don't identify the page and only track its referrer.
+// Generic names like ref are left out, since sites also use them for content, e.g., a git branch.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "mc_cid": true, "mc_eid": true,
+}
+
+// NormalizeURL returns a canonical form of the URL, so that URLs differing only in cosmetic details
+// (case of scheme and host, "www." prefix, default port, trailing slash, tracking parameters, query order)
+// compare equal. It is conservative since dedupe-remote deletes bookmarks with the same key: the scheme,
+// userinfo, fragment, and other query parameters are kept, as they may identify a different page,
+// e.g., #/a and #/b of a hash-routed app. The result is meant as a comparison key, not as a URL to visit.
+// Unparsable URLs are returned unchanged.
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") || trackingParams[key] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode() // sorted by key
+	return u.String()
+}
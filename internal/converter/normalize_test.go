@@ -0,0 +1,42 @@
+package converter
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := map[string]struct {
+		a, b  string
+		equal bool
+	}{
+		"scheme case":       {"HTTPS://example.com/a", "https://example.com/a", true},
+		"www and case":      {"https://WWW.Example.com/a", "https://example.com/a", true},
+		"trailing slash":    {"https://example.com/a/", "https://example.com/a", true},
+		"root path":         {"https://example.com/", "https://example.com", true},
+		"default port":      {"https://example.com:443/a", "https://example.com/a", true},
+		"tracking params":   {"https://example.com/a?utm_source=hn&fbclid=x&id=1", "https://example.com/a?id=1", true},
+		"query order":       {"https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2", true},
+		"scheme kept":       {"http://example.com/a", "https://example.com/a", false},
+		"fragment kept":     {"https://example.com/app#/a", "https://example.com/app#/b", false},
+		"ref kept":          {"https://example.com/tree?ref=main", "https://example.com/tree?ref=dev", false},
+		"userinfo kept":     {"https://alice@example.com/a", "https://example.com/a", false},
+		"other scheme port": {"http://example.com:443/a", "http://example.com/a", false},
+		"different path":    {"https://example.com/a", "https://example.com/b", false},
+		"different query":   {"https://example.com/item?id=1", "https://example.com/item?id=2", false},
+		"path case kept":    {"https://example.com/A", "https://example.com/a", false},
+		"non-default port":  {"https://example.com:8080/a", "https://example.com/a", false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, b := NormalizeURL(tc.a), NormalizeURL(tc.b)
+			if (a == b) != tc.equal {
+				t.Errorf("NormalizeURL(%q) = %q, NormalizeURL(%q) = %q, want equal=%v", tc.a, a, tc.b, b, tc.equal)
+			}
+		})
+	}
+
+	t.Run("unparsable is returned unchanged", func(t *testing.T) {
+		if got := NormalizeURL("not a url"); got != "not a url" {
+			t.Errorf("got %q", got)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Override curates the bookmark of a specific HN item (see ParseOverrides).
+type Override struct {
+	Tags []string `json:"tags"` // extra tags, added to the ones of -tags and the rules
+	Note *string  `json:"note"` // replaces the rendered note (nil = keep it, "" = no note)
+	Skip bool     `json:"skip"` // leave the item out entirely
+}
+
+// Overrides maps HN IDs to the overrides of their bookmarks.
+type Overrides map[int]Override
+
+// ParseOverrides parses an overrides file, a JSON object mapping HN IDs to overrides, e.g.,
+//
+//	{"3742902": {"tags": ["classic"], "note": "Read this first"}, "37392676": {"skip": true}}
+//
+// Unknown fields are rejected so that typos don't silently do nothing.
+func ParseOverrides(data []byte) (Overrides, error) {
+	var raw map[string]Override
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("parsing overrides: %w", err)
+	}
+
+	overrides := make(Overrides, len(raw))
+	for key, o := range raw {
+		id, err := strconv.Atoi(strings.TrimSpace(key))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid HN ID %q in overrides", key)
+		}
+		for i, tag := range o.Tags {
+			o.Tags[i] = strings.TrimSpace(tag)
+			if o.Tags[i] == "" {
+				return nil, fmt.Errorf("empty tag in overrides of %d", id)
+			}
+		}
+		overrides[id] = o
+	}
+	return overrides, nil
+}
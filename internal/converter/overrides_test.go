@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestParseOverrides(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		want    Overrides
+		wantErr bool
+	}{
+		"tags note and skip": {
+			data: `{"1": {"tags": [" classic "], "note": "Read first"}, "2": {"skip": true}, "3": {"note": ""}}`,
+			want: Overrides{1: {Tags: []string{"classic"}, Note: ptr("Read first")}, 2: {Skip: true}, 3: {Note: ptr("")}},
+		},
+		"empty":         {data: `{}`, want: Overrides{}},
+		"invalid id":    {data: `{"abc": {"skip": true}}`, wantErr: true},
+		"unknown field": {data: `{"1": {"tag": ["typo"]}}`, wantErr: true},
+		"empty tag":     {data: `{"1": {"tags": [" "]}}`, wantErr: true},
+		"not json":      {data: `1: {skip: true}`, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseOverrides([]byte(tc.data))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseOverrides() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOverrides() unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("ParseOverrides() = %v, want %v", got, tc.want)
+			}
+			for id, want := range tc.want {
+				o := got[id]
+				if !slices.Equal(o.Tags, want.Tags) || o.Skip != want.Skip || (o.Note == nil) != (want.Note == nil) ||
+					(o.Note != nil && *o.Note != *want.Note) {
+					t.Errorf("ParseOverrides()[%d] = %+v, want %+v", id, o, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvert_Overrides(t *testing.T) {
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}}
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "First", URL: "https://example.com/1"},
+		2: {ID: 2, Type: "story", Title: "Second", URL: "https://example.com/2"},
+	}
+	opts := Options{
+		Tags:         []string{"hn"},
+		NoteTemplate: "{{title}}",
+		Overrides:    Overrides{1: {Tags: []string{"classic", "hn"}, Note: ptr("Read first")}},
+	}
+
+	got, _, _ := New().Convert(bookmarks, items, opts)
+	if first := got.Bookmarks[0]; !slices.Equal(first.Tags, []string{"hn", "classic"}) || *first.Note != "Read first" {
+		t.Errorf("overridden bookmark = %v %q, want the extra tag and the custom note", first.Tags, *first.Note)
+	}
+	if second := got.Bookmarks[1]; !slices.Equal(second.Tags, []string{"hn"}) || *second.Note != "Second" {
+		t.Errorf("other bookmark = %v %q, want it unchanged", second.Tags, *second.Note)
+	}
+}
@@ -0,0 +1,31 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// provenancePrefix is the prefix of the tag identifying the HN item a bookmark was converted from.
+const provenancePrefix = "hnkeep:id="
+
+// ProvenanceTag returns the tag identifying bookmarks converted from the HN item with the given ID,
+// e.g., hnkeep:id=3742902. Unlike the URL, it survives URL changes in Karakeep.
+func ProvenanceTag(id int) string {
+	return provenancePrefix + strconv.Itoa(id)
+}
+
+// ProvenanceIDs returns the HN IDs of the provenance tags among tags (see ProvenanceTag).
+// A bookmark merged from duplicates has several.
+func ProvenanceIDs(tags []string) []int {
+	var ids []int
+	for _, tag := range tags {
+		num, ok := strings.CutPrefix(tag, provenancePrefix)
+		if !ok {
+			continue
+		}
+		if id, err := strconv.Atoi(num); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
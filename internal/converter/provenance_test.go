@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestProvenanceIDs(t *testing.T) {
+	tags := []string{"src:hackernews", ProvenanceTag(3742902), "hnkeep:20260117", "hnkeep:id=abc", "hnkeep:id=0", ProvenanceTag(1)}
+	if got, want := ProvenanceIDs(tags), []int{3742902, 1}; !slices.Equal(got, want) {
+		t.Errorf("ProvenanceIDs() = %v, want %v", got, want)
+	}
+	if got := ProvenanceTag(3742902); got != "hnkeep:id=3742902" {
+		t.Errorf("ProvenanceTag() = %q, want %q", got, "hnkeep:id=3742902")
+	}
+}
+
+func TestConvert_IDTags(t *testing.T) {
+	c := New()
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}, {ID: 3}}
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Repo", URL: "https://github.com/a/b"},
+		2: {ID: 2, Type: "story", Title: "Repo again", URL: "https://github.com/a/b"},
+		3: {ID: 3, Type: "story", Title: "Ask HN: Why?"},
+	}
+
+	shared := []string{"hn"}
+	got, _, _ := c.Convert(bookmarks, items, Options{Tags: shared, IDTags: true})
+	want := [][]string{{"hn", "hnkeep:id=1", "hnkeep:id=2"}, {"hn", "hnkeep:id=3"}} // duplicates keep every ID
+	if len(got.Bookmarks) != len(want) {
+		t.Fatalf("got %d bookmarks, want %d", len(got.Bookmarks), len(want))
+	}
+	for i, bm := range got.Bookmarks {
+		if !slices.Equal(bm.Tags, want[i]) {
+			t.Errorf("bookmark %d tags = %v, want %v", i, bm.Tags, want[i])
+		}
+	}
+	if !slices.Equal(shared, []string{"hn"}) {
+		t.Errorf("shared tags modified: %v", shared)
+	}
+}
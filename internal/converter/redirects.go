@@ -0,0 +1,162 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// URLResolver defines the interface for resolving URLs to the final destination of their redirects.
+type URLResolver interface {
+	Resolve(ctx context.Context, url string) (string, error)
+}
+
+// resolveRedirects resolves the external URL of every fetched story, including the stories of
+// bookmarked comments, so Convert can bookmark the final destination of URL shorteners and moved pages.
+// Returns only the URLs that redirect elsewhere; URLs that fail to resolve are kept as they are.
+func (c *Converter) resolveRedirects(ctx context.Context, items map[int]*hackernews.Item) map[string]string {
+	urls := make(map[string]bool)
+	for _, item := range items {
+		if item.URL != "" && !item.Deleted && !item.Dead {
+			urls[item.URL] = true
+		}
+	}
+	for _, story := range c.roots {
+		if story.URL != "" {
+			urls[story.URL] = true
+		}
+	}
+
+	resolved := make(map[string]string)
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			if c.limiter.Acquire(ctx) != nil {
+				return
+			}
+			defer c.limiter.Release()
+
+			final, err := c.resolver.Resolve(ctx, url)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("failed to resolve redirects of %s: %v, keeping it", url, err)
+				}
+				return
+			}
+			if final == url {
+				return
+			}
+			c.logger.Info("%s redirects to %s", url, final)
+			mu.Lock()
+			resolved[url] = final
+			mu.Unlock()
+		}(url)
+	}
+	wg.Wait()
+
+	return resolved
+}
+
+// LinkChecker defines the interface for detecting dead links.
+type LinkChecker interface {
+	Dead(ctx context.Context, url string) (bool, error)
+}
+
+// SnapshotFinder defines the interface for finding archived snapshots of dead links.
+type SnapshotFinder interface {
+	Closest(ctx context.Context, url string, at time.Time) (string, error)
+}
+
+// DeadLinksMode controls what happens to bookmarks whose link is dead (see WithDeadLinks).
+type DeadLinksMode string
+
+const (
+	DeadLinksNote    DeadLinksMode = "note"    // keep the link, add the snapshot to the note
+	DeadLinksReplace DeadLinksMode = "replace" // bookmark the snapshot instead of the link
+)
+
+// checkLinks checks the external URL of every bookmarked story for dead links, and looks up the
+// snapshot of each dead one closest to when it was bookmarked. Returns the snapshots of dead links,
+// leaving out links that are alive, couldn't be checked, or have no snapshot.
+func (c *Converter) checkLinks(ctx context.Context, bookmarks []harmonic.Bookmark, items map[int]*hackernews.Item) map[string]string {
+	savedAt := make(map[string]int64) // url -> earliest bookmark time
+	for _, bm := range bookmarks {
+		item, ok := items[bm.ID]
+		if !ok || item.Deleted || item.Dead {
+			continue
+		}
+		story, isComment := c.roots[item.ID]
+		if !isComment {
+			story = item
+		}
+		if story.URL == "" {
+			continue
+		}
+		if at, seen := savedAt[story.URL]; !seen || bm.Timestamp < at {
+			savedAt[story.URL] = bm.Timestamp
+		}
+	}
+
+	snapshots := make(map[string]string)
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for url, at := range savedAt {
+		wg.Add(1)
+		go func(url string, at int64) {
+			defer wg.Done()
+			if c.limiter.Acquire(ctx) != nil {
+				return
+			}
+			defer c.limiter.Release()
+
+			snapshot, err := c.snapshotOf(ctx, url, at)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("failed to check link %s: %v, keeping it", url, err)
+				}
+				return
+			}
+			if snapshot == "" {
+				return
+			}
+			mu.Lock()
+			snapshots[url] = snapshot
+			mu.Unlock()
+		}(url, at)
+	}
+	wg.Wait()
+
+	return snapshots
+}
+
+// snapshotOf returns the snapshot closest to savedAt if the URL is a dead link, or "" otherwise.
+func (c *Converter) snapshotOf(ctx context.Context, url string, savedAt int64) (string, error) {
+	dead, err := c.linkChecker.Dead(ctx, url)
+	if err != nil || !dead {
+		return "", err
+	}
+	snapshot, err := c.finder.Closest(ctx, url, time.Unix(savedAt, 0))
+	if err != nil {
+		return "", fmt.Errorf("link is dead, looking up snapshot: %w", err)
+	}
+	if snapshot == "" {
+		c.logger.Warn("link %s is dead and has no Wayback Machine snapshot", url)
+		return "", nil
+	}
+	c.logger.Info("link %s is dead, found snapshot %s", url, snapshot)
+	return snapshot, nil
+}
+
+// deadLinkNote returns the note part pointing to the snapshot of a dead link.
+func deadLinkNote(snapshot string) string {
+	return "Link is dead, archived copy: " + snapshot
+}
@@ -0,0 +1,184 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// TagRule tags bookmarks whose URL host is Domain or one of its subdomains.
+type TagRule struct {
+	Domain string // lowercase, without "www."
+	Tag    string
+}
+
+// ParseTagRule parses a rule written as "domain=tag", e.g., "github.com=code".
+func ParseTagRule(s string) (TagRule, error) {
+	domain, tag, ok := strings.Cut(s, "=")
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+	tag = strings.TrimSpace(tag)
+	if !ok || domain == "" || tag == "" {
+		return TagRule{}, fmt.Errorf("invalid tag rule %q, expected domain=tag", s)
+	}
+	if strings.ContainsAny(domain, "/:") {
+		return TagRule{}, fmt.Errorf("invalid tag rule %q, expected a domain like example.com, not a URL", s)
+	}
+	return TagRule{Domain: domain, Tag: tag}, nil
+}
+
+// ParseTagRules parses every rule with ParseTagRule.
+func ParseTagRules(specs []string) ([]TagRule, error) {
+	rules := make([]TagRule, 0, len(specs))
+	for _, s := range specs {
+		rule, err := ParseTagRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ScoreRule tags bookmarks whose HN item has a score of at least MinScore.
+type ScoreRule struct {
+	MinScore int
+	Tag      string
+}
+
+// ParseScoreRule parses a rule written as "score=tag", e.g., "500=hn:popular".
+func ParseScoreRule(s string) (ScoreRule, error) {
+	score, tag, ok := strings.Cut(s, "=")
+	tag = strings.TrimSpace(tag)
+	if !ok || tag == "" {
+		return ScoreRule{}, fmt.Errorf("invalid score rule %q, expected score=tag", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(score))
+	if err != nil || n < 0 {
+		return ScoreRule{}, fmt.Errorf("invalid score rule %q, score must be a non-negative integer", s)
+	}
+	return ScoreRule{MinScore: n, Tag: tag}, nil
+}
+
+// ParseScoreRules parses every rule with ParseScoreRule.
+func ParseScoreRules(specs []string) ([]ScoreRule, error) {
+	rules := make([]ScoreRule, 0, len(specs))
+	for _, s := range specs {
+		rule, err := ParseScoreRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// applyScoreRules returns tags extended with the tags of the rules the score reaches.
+func applyScoreRules(tags []string, rules []ScoreRule, score int) []string {
+	for _, r := range rules {
+		if score >= r.MinScore {
+			tags = withTag(tags, r.Tag)
+		}
+	}
+	return tags
+}
+
+// matches reports whether the rule applies to the given lowercase host.
+func (r TagRule) matches(host string) bool {
+	return onDomain(host, r.Domain)
+}
+
+// onDomain reports whether host is the domain or one of its subdomains.
+func onDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// applyTagRules returns tags extended with the tags of the rules matching the host of rawURL.
+// The tags slice is returned as is if no rule adds a new tag, so bookmarks can keep sharing it.
+func applyTagRules(tags []string, rules []TagRule, rawURL string) []string {
+	if len(rules) == 0 {
+		return tags
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return tags
+	}
+	host := strings.ToLower(u.Hostname())
+
+	merged := tags
+	for _, r := range rules {
+		if r.matches(host) {
+			merged = withTag(merged, r.Tag)
+		}
+	}
+	return merged
+}
+
+// domainTag returns the "site:" tag of the URL's host without "www.", e.g., "site:example.com",
+// or "" if the URL has no host.
+func domainTag(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return "site:" + strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// ItemKinds lists the kinds of HN items told apart by ItemKind.
+var ItemKinds = []string{"story", "ask", "show", "tell", "launch", "job", "poll", "comment"}
+
+// titleKinds maps the title prefixes of HN story kinds to their kind.
+var titleKinds = []struct{ prefix, kind string }{
+	{"Ask HN:", "ask"},
+	{"Show HN:", "show"},
+	{"Tell HN:", "tell"},
+	{"Launch HN:", "launch"},
+}
+
+// ItemKind returns the kind of the HN item, one of ItemKinds, derived from its type and,
+// for stories, its title prefix. Returns "" for items of unknown type such as stubs of deleted items.
+func ItemKind(item *hackernews.Item) string {
+	switch item.Type {
+	case "job", "poll", "comment":
+		return item.Type
+	case "story":
+		for _, t := range titleKinds {
+			if strings.HasPrefix(item.Title, t.prefix) {
+				return t.kind
+			}
+		}
+		return "story"
+	}
+	return ""
+}
+
+// stripTitleKind returns the title without its kind prefix, e.g., "Ask HN:", and the kind it stands for,
+// or the title unchanged and "" if it has no such prefix.
+func stripTitleKind(title string) (string, string) {
+	for _, t := range titleKinds {
+		if rest, ok := strings.CutPrefix(title, t.prefix); ok {
+			return strings.TrimSpace(rest), t.kind
+		}
+	}
+	return title, ""
+}
+
+// typeTag returns the tag for the kind of the HN item, e.g., "hn:ask" for Ask HN posts, or "" if unknown.
+func typeTag(item *hackernews.Item) string {
+	if kind := ItemKind(item); kind != "" {
+		return "hn:" + kind
+	}
+	return ""
+}
+
+// withTag returns tags with tag appended, unless already present. The slice is copied
+// before appending, since the tags of several bookmarks may share one backing array.
+func withTag(tags []string, tag string) []string {
+	if tag == "" || slices.Contains(tags, tag) {
+		return tags
+	}
+	return append(slices.Clip(tags), tag)
+}
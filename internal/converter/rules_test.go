@@ -0,0 +1,228 @@
+package converter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestParseTagRule(t *testing.T) {
+	tests := map[string]struct {
+		spec    string
+		want    TagRule
+		wantErr bool
+	}{
+		"simple":          {spec: "github.com=code", want: TagRule{"github.com", "code"}},
+		"www and case":    {spec: " WWW.ArXiv.org = paper ", want: TagRule{"arxiv.org", "paper"}},
+		"tag with equals": {spec: "example.com=a=b", want: TagRule{"example.com", "a=b"}},
+		"missing tag":     {spec: "github.com=", wantErr: true},
+		"missing domain":  {spec: "=code", wantErr: true},
+		"no separator":    {spec: "github.com", wantErr: true},
+		"url not domain":  {spec: "https://github.com=code", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTagRule(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseTagRule(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseTagRule(%q) = %+v, want %+v", tc.spec, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyTagRules(t *testing.T) {
+	rules := []TagRule{{"github.com", "code"}, {"arxiv.org", "paper"}, {"github.com", "hn"}}
+	tests := map[string]struct {
+		url  string
+		want []string
+	}{
+		"no match":            {"https://example.com/a", []string{"hn"}},
+		"exact host":          {"https://github.com/golang/go", []string{"hn", "code"}},
+		"subdomain":           {"https://gist.github.com/x", []string{"hn", "code"}},
+		"www and case":        {"https://WWW.ArXiv.org/abs/1", []string{"hn", "paper"}},
+		"suffix not domain":   {"https://notgithub.com/x", []string{"hn"}},
+		"existing tag kept":   {"https://github.com/x", []string{"hn", "code"}},
+		"unparsable url":      {"://bad", []string{"hn"}},
+		"host with port":      {"https://github.com:443/x", []string{"hn", "code"}},
+		"domain in path":      {"https://example.com/github.com", []string{"hn"}},
+		"domain in subdomain": {"https://github.com.evil.io/x", []string{"hn"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			base := []string{"hn"}
+			got := applyTagRules(base, rules, tc.url)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("applyTagRules(%q) = %v, want %v", tc.url, got, tc.want)
+			}
+			if !slices.Equal(base, []string{"hn"}) {
+				t.Errorf("applyTagRules modified the shared tags: %v", base)
+			}
+		})
+	}
+}
+
+func TestConvert_TagRules(t *testing.T) {
+	c := New()
+	bookmarks := []harmonic.Bookmark{{ID: 1, Timestamp: 1000}, {ID: 2, Timestamp: 2000}}
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Title: "Repo", URL: "https://github.com/a/b"},
+		2: {ID: 2, Title: "Ask HN"},
+	}
+	rules := []TagRule{{"github.com", "code"}, {"news.ycombinator.com", "discussion"}}
+
+	got, _, _ := c.Convert(bookmarks, items, Options{Tags: []string{"hn"}, TagRules: rules})
+	want := [][]string{{"hn", "code"}, {"hn", "discussion"}}
+	for i, bm := range got.Bookmarks {
+		if !slices.Equal(bm.Tags, want[i]) {
+			t.Errorf("bookmark %d tags = %v, want %v", i, bm.Tags, want[i])
+		}
+	}
+}
+
+func TestTypeTag(t *testing.T) {
+	tests := map[string]struct {
+		item hackernews.Item
+		want string
+	}{
+		"story":      {hackernews.Item{Type: "story", Title: "A new database"}, "hn:story"},
+		"ask":        {hackernews.Item{Type: "story", Title: "Ask HN: How do you take notes?"}, "hn:ask"},
+		"show":       {hackernews.Item{Type: "story", Title: "Show HN: hnkeep"}, "hn:show"},
+		"tell":       {hackernews.Item{Type: "story", Title: "Tell HN: I quit"}, "hn:tell"},
+		"launch":     {hackernews.Item{Type: "story", Title: "Launch HN: Foo (YC W26)"}, "hn:launch"},
+		"prefix mid": {hackernews.Item{Type: "story", Title: "Why Ask HN: threads work"}, "hn:story"},
+		"job":        {hackernews.Item{Type: "job", Title: "Foo is hiring"}, "hn:job"},
+		"poll":       {hackernews.Item{Type: "poll"}, "hn:poll"},
+		"comment":    {hackernews.Item{Type: "comment"}, "hn:comment"},
+		"unknown":    {hackernews.Item{Deleted: true}, ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := typeTag(&tc.item); got != tc.want {
+				t.Errorf("typeTag() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvert_TypeTags(t *testing.T) {
+	c := New()
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}}
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Show HN: Repo", URL: "https://github.com/a/b"},
+		2: {ID: 2, Type: "job", Title: "Hiring"},
+	}
+
+	shared := []string{"hn"}
+	got, _, _ := c.Convert(bookmarks, items, Options{Tags: shared, TypeTags: true})
+	want := [][]string{{"hn", "hn:show"}, {"hn", "hn:job"}}
+	for i, bm := range got.Bookmarks {
+		if !slices.Equal(bm.Tags, want[i]) {
+			t.Errorf("bookmark %d tags = %v, want %v", i, bm.Tags, want[i])
+		}
+	}
+
+	got, _, _ = c.Convert(bookmarks, items, Options{Tags: shared})
+	for i, bm := range got.Bookmarks {
+		if !slices.Equal(bm.Tags, shared) {
+			t.Errorf("bookmark %d tags = %v without -type-tags, want %v", i, bm.Tags, shared)
+		}
+	}
+}
+
+func TestDomainTag(t *testing.T) {
+	tests := map[string]string{
+		"https://www.Example.com/post?id=1": "site:example.com",
+		"https://blog.example.com:8080/":    "site:blog.example.com",
+		"https://news.ycombinator.com/item": "site:news.ycombinator.com",
+		"not a url":                         "",
+	}
+	for rawURL, want := range tests {
+		if got := domainTag(rawURL); got != want {
+			t.Errorf("domainTag(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestConvert_StripPrefix(t *testing.T) {
+	c := New()
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}, {ID: 3}}
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Type: "story", Title: "Ask HN: How do you take notes?"},
+		2: {ID: 2, Type: "story", Title: "Show HN:hnkeep", URL: "https://github.com/a/b"},
+		3: {ID: 3, Type: "story", Title: "A new database", URL: "https://example.com"},
+	}
+
+	got, _, _ := c.Convert(bookmarks, items, Options{Tags: []string{"hn"}, TypeTags: true, StripPrefix: true})
+	want := []struct {
+		title string
+		tags  []string
+	}{
+		{"How do you take notes?", []string{"hn", "hn:ask"}}, // not tagged twice with -type-tags
+		{"hnkeep", []string{"hn", "hn:show"}},
+		{"A new database", []string{"hn", "hn:story"}},
+	}
+	for i, bm := range got.Bookmarks {
+		if *bm.Title != want[i].title || !slices.Equal(bm.Tags, want[i].tags) {
+			t.Errorf("bookmark %d = %q %v, want %q %v", i, *bm.Title, bm.Tags, want[i].title, want[i].tags)
+		}
+	}
+	if items[1].Title != "Ask HN: How do you take notes?" {
+		t.Errorf("item title = %q, want it unchanged", items[1].Title)
+	}
+}
+
+func TestParseScoreRule(t *testing.T) {
+	tests := map[string]struct {
+		spec    string
+		want    ScoreRule
+		wantErr bool
+	}{
+		"simple":        {spec: "500=hn:popular", want: ScoreRule{500, "hn:popular"}},
+		"spaces":        {spec: " 100 = hn:notable ", want: ScoreRule{100, "hn:notable"}},
+		"zero":          {spec: "0=all", want: ScoreRule{0, "all"}},
+		"negative":      {spec: "-1=x", wantErr: true},
+		"not a number":  {spec: "many=x", wantErr: true},
+		"missing tag":   {spec: "500=", wantErr: true},
+		"no separator":  {spec: "500", wantErr: true},
+		"decimal score": {spec: "1.5=x", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseScoreRule(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseScoreRule(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseScoreRule(%q) = %+v, want %+v", tc.spec, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvert_ScoreRules(t *testing.T) {
+	c := New()
+	bookmarks := []harmonic.Bookmark{{ID: 1}, {ID: 2}, {ID: 3}}
+	items := map[int]*hackernews.Item{
+		1: {ID: 1, Title: "Low", URL: "https://example.com/1", Score: 42},
+		2: {ID: 2, Title: "Exactly", URL: "https://example.com/2", Score: 100},
+		3: {ID: 3, Title: "High", URL: "https://example.com/3", Score: 1200},
+	}
+	rules := []ScoreRule{{100, "hn:notable"}, {1000, "hn:popular"}}
+
+	got, _, _ := c.Convert(bookmarks, items, Options{Tags: []string{"hn"}, ScoreRules: rules})
+	want := [][]string{{"hn"}, {"hn", "hn:notable"}, {"hn", "hn:notable", "hn:popular"}}
+	for i, bm := range got.Bookmarks {
+		if !slices.Equal(bm.Tags, want[i]) {
+			t.Errorf("bookmark %d tags = %v, want %v", i, bm.Tags, want[i])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Versions of the Karakeep import file structure, see WriteJSON and WriteJSONV2.
+const (
+	SchemaV1 = 1 // bookmarks with tag names, read by every Karakeep release
+	SchemaV2 = 2 // bookmarks with tag objects and list memberships, plus the lists
+)
+
+// listIcon is the emoji of the lists written by WriteJSONV2, which Karakeep requires.
+const listIcon = "📰"
+
+// schemaV2 is the newer structure of Karakeep's export file, with lists.
+type schemaV2 struct {
+	Bookmarks []bookmarkV2 `json:"bookmarks"`
+	Lists     []listV2     `json:"lists"`
+}
+
+type bookmarkV2 struct {
+	CreatedAt  int64           `json:"createdAt"`
+	Title      *string         `json:"title"`
+	Tags       []tagV2         `json:"tags"`
+	Content    BookmarkContent `json:"content"`
+	Note       *string         `json:"note"`
+	Archived   bool            `json:"archived"`
+	Favourited bool            `json:"favourited"`
+	Lists      []string        `json:"lists"` // IDs of the lists in schemaV2.Lists
+}
+
+type tagV2 struct {
+	Name string `json:"name"`
+}
+
+type listV2 struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Icon     string  `json:"icon"`
+	Type     string  `json:"type"`
+	Query    *string `json:"query"`
+	ParentID *string `json:"parentId"`
+}
+
+// WriteJSONV2 writes the export in the newer structure of Karakeep's export file: tags are objects,
+// archived and favourited are always present, and the lists named in the bookmarks' Lists are written
+// as manual lists the bookmarks refer to by ID.
+func WriteJSONV2(w io.Writer, export Schema) error {
+	out := schemaV2{Bookmarks: make([]bookmarkV2, 0, len(export.Bookmarks)), Lists: []listV2{}}
+	listIDs := make(map[string]string) // list name -> ID
+	for _, bm := range export.Bookmarks {
+		b := bookmarkV2{
+			CreatedAt:  bm.CreatedAt,
+			Title:      bm.Title,
+			Tags:       make([]tagV2, len(bm.Tags)),
+			Content:    bm.Content,
+			Note:       bm.Note,
+			Archived:   bm.Archived,
+			Favourited: bm.Favourited,
+			Lists:      make([]string, 0, len(bm.Lists)),
+		}
+		for i, tag := range bm.Tags {
+			b.Tags[i] = tagV2{Name: tag}
+		}
+		for _, name := range bm.Lists {
+			id, ok := listIDs[name]
+			if !ok {
+				id = fmt.Sprintf("list-%d", len(out.Lists)+1)
+				listIDs[name] = id
+				out.Lists = append(out.Lists, listV2{ID: id, Name: name, Icon: listIcon, Type: "manual"})
+			}
+			b.Lists = append(b.Lists, id)
+		}
+		out.Bookmarks = append(out.Bookmarks, b)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // pretty print, like WriteJSON
+	return encoder.Encode(out)
+}
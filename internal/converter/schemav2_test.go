@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteJSONV2(t *testing.T) {
+	export := Schema{Bookmarks: []Bookmark{
+		{
+			CreatedAt: 1704067200,
+			Title:     ptr("Title"),
+			Content:   NewBookmarkContent("https://example.com"),
+			Tags:      []string{"hn", "go"},
+			Note:      ptr("note"),
+			Archived:  true,
+			Lists:     []string{"Hacker News", "Reading"},
+		},
+		{
+			CreatedAt: 1704153600,
+			Content:   NewTextBookmarkContent("post", "https://news.ycombinator.com/item?id=2"),
+			Lists:     []string{"Hacker News"},
+		},
+	}}
+
+	var b strings.Builder
+	if err := WriteJSONV2(&b, export); err != nil {
+		t.Fatalf("WriteJSONV2() unexpected error: %v", err)
+	}
+	want := `{
+  "bookmarks": [
+    {
+      "createdAt": 1704067200,
+      "title": "Title",
+      "tags": [
+        {
+          "name": "hn"
+        },
+        {
+          "name": "go"
+        }
+      ],
+      "content": {
+        "type": "link",
+        "url": "https://example.com"
+      },
+      "note": "note",
+      "archived": true,
+      "favourited": false,
+      "lists": [
+        "list-1",
+        "list-2"
+      ]
+    },
+    {
+      "createdAt": 1704153600,
+      "title": null,
+      "tags": [],
+      "content": {
+        "type": "text",
+        "text": "post",
+        "sourceUrl": "https://news.ycombinator.com/item?id=2"
+      },
+      "note": null,
+      "archived": false,
+      "favourited": false,
+      "lists": [
+        "list-1"
+      ]
+    }
+  ],
+  "lists": [
+    {
+      "id": "list-1",
+      "name": "Hacker News",
+      "icon": "📰",
+      "type": "manual",
+      "query": null,
+      "parentId": null
+    },
+    {
+      "id": "list-2",
+      "name": "Reading",
+      "icon": "📰",
+      "type": "manual",
+      "query": null,
+      "parentId": null
+    }
+  ]
+}
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteJSONV2() =\n%s\nwant\n%s", got, want)
+	}
+
+	var empty strings.Builder
+	if err := WriteJSONV2(&empty, Schema{}); err != nil {
+		t.Fatalf("WriteJSONV2() unexpected error: %v", err)
+	}
+	if got, want := empty.String(), "{\n  \"bookmarks\": [],\n  \"lists\": []\n}\n"; got != want {
+		t.Errorf("WriteJSONV2() of an empty export = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/htmlconv"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// NoteVariable documents a variable available in note templates.
+type NoteVariable struct {
+	Name        string // including braces, e.g., "{{title}}"
+	Description string
+}
+
+// NoteVariables lists the variables available in note templates, in documentation order.
+var NoteVariables = []NoteVariable{
+	{"{{smart_url}}", "HN discussion URL if the item has an external link, empty otherwise"},
+	{"{{item_url}}", "Item's external URL (empty for text posts like Ask HN)"},
+	{"{{hn_url}}", "HN discussion URL"},
+	{"{{id}}", "HN item ID"},
+	{"{{title}}", "Item title"},
+	{"{{author}}", "Author username"},
+	{"{{date}}", "Post date (YYYY-MM-DD)"},
+	{"{{year}}", "Post year (YYYY)"},
+	{"{{score}}", "HN score (points)"},
+	{"{{comments}}", "Number of comments"},
+	{"{{type}}", "HN item type: story, job, or poll"},
+	{"{{domain}}", "Host of the external URL without www. (empty for text posts like Ask HN)"},
+	{"{{text}}", "Text of text posts like Ask HN, as plain text (empty for links)"},
+}
+
+// NotePreset is a named, maintained note template.
+type NotePreset struct {
+	Name        string
+	Description string
+	Template    string
+}
+
+// NotePresets lists the built-in note templates, selectable by name instead of writing a template.
+var NotePresets = []NotePreset{
+	{"minimal", "Link to the HN discussion of external articles (the default)", "{{smart_url}}"},
+	{"discussion-only", "Link to the HN discussion of every item, including text posts", "{{hn_url}}"},
+	{"full", "Title, author, date, and the HN discussion link", "{{title}}\nby {{author}} on {{date}}\n{{hn_url}}"},
+	{"stats", "Score and comment count at import time, with the HN discussion link", "{{score}} points, {{comments}} comments\n{{hn_url}}"},
+}
+
+// LookupNotePreset returns the note preset with the given name.
+func LookupNotePreset(name string) (NotePreset, bool) {
+	for _, p := range NotePresets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return NotePreset{}, false
+}
+
+// UsedVariables returns the variables the template uses, in documentation order.
+func UsedVariables(template string) []string {
+	var used []string
+	for _, v := range NoteVariables {
+		if strings.Contains(template, v.Name) {
+			used = append(used, v.Name)
+		}
+	}
+	return used
+}
+
+// renderNote renders the note (or summary) template for the given item. See NoteVariables.
+func renderNote(template string, item *hackernews.Item) string {
+	smartURL, text := hackernews.DiscussionURL(item.ID), ""
+	if item.URL == "" {
+		smartURL, text = "", htmlconv.ToText(item.Text)
+	}
+	return strings.NewReplacer(
+		"{{smart_url}}", smartURL,
+		"{{item_url}}", item.URL,
+		"{{hn_url}}", hackernews.DiscussionURL(item.ID),
+		"{{id}}", strconv.Itoa(item.ID),
+		"{{title}}", item.Title,
+		"{{author}}", item.By,
+		"{{date}}", time.Unix(item.Time, 0).Format("2006-01-02"),
+		"{{year}}", time.Unix(item.Time, 0).Format("2006"),
+		"{{score}}", strconv.Itoa(item.Score),
+		"{{comments}}", strconv.Itoa(item.Descendants),
+		"{{type}}", item.Type,
+		"{{domain}}", domainOf(item.URL),
+		"{{text}}", text,
+	).Replace(template)
+}
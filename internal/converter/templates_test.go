@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+func TestNotePresets(t *testing.T) {
+	placeholder := regexp.MustCompile(`\{\{[a-z_]+\}\}`)
+	known := make([]string, len(NoteVariables))
+	for i, v := range NoteVariables {
+		known[i] = v.Name
+	}
+
+	for _, p := range NotePresets {
+		t.Run(p.Name, func(t *testing.T) {
+			for _, v := range placeholder.FindAllString(p.Template, -1) {
+				if !slices.Contains(known, v) {
+					t.Errorf("preset uses unknown variable %s", v)
+				}
+			}
+			if got, ok := LookupNotePreset(p.Name); !ok || got.Template != p.Template {
+				t.Errorf("LookupNotePreset(%q) = %v, %v", p.Name, got, ok)
+			}
+		})
+	}
+
+	if _, ok := LookupNotePreset("missing"); ok {
+		t.Error("expected unknown preset not to be found")
+	}
+}
+
+func TestUsedVariables(t *testing.T) {
+	got := UsedVariables("{{title}} ({{score}}) {{title}}")
+	if want := []string{"{{title}}", "{{score}}"}; !slices.Equal(got, want) {
+		t.Errorf("UsedVariables() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderNote(t *testing.T) {
+	item := &hackernews.Item{
+		ID: 42, Type: "story", Title: "Show HN: Thing", By: "alice", Time: 1704067200,
+		URL: "https://www.Example.com/post", Score: 128, Descendants: 37,
+	}
+	preset, _ := LookupNotePreset("stats")
+	got := renderNote(preset.Template, item)
+	if want := "128 points, 37 comments\nhttps://news.ycombinator.com/item?id=42"; got != want {
+		t.Errorf("renderNote() = %q, want %q", got, want)
+	}
+
+	if got, want := renderNote("{{type}} on {{domain}}", item), "story on example.com"; got != want {
+		t.Errorf("renderNote() = %q, want %q", got, want)
+	}
+
+	item.URL = "" // text post has no smart url or domain
+	if got := renderNote("[{{smart_url}}{{domain}}]", item); got != "[]" {
+		t.Errorf("renderNote() smart_url and domain for text post = %q, want empty", got)
+	}
+
+	item.Text = "Question?<p>Details &amp; <i>more</i>"
+	if got, want := renderNote("{{text}}", item), "Question?\n\nDetails & more"; got != want {
+		t.Errorf("renderNote() text = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,10 @@
 package converter
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
 
 // Schema represents the Karakeep export/import file schema.
 // Refer to https://github.com/karakeep-app/karakeep/blob/main/packages/shared/import-export/exporters.ts
@@ -20,11 +24,38 @@ func (s SchemaBookmarks) MarshalJSON() ([]byte, error) {
 
 // Bookmark represents a single bookmark in the Karakeep export/import file.
 type Bookmark struct {
-	CreatedAt int64           `json:"createdAt"` // Unix timestamp (in seconds)
-	Title     *string         `json:"title"`     // Nullable
-	Tags      BookmarkTags    `json:"tags"`      // Empty array if no tags
-	Content   BookmarkContent `json:"content"`   // Always link type
-	Note      *string         `json:"note"`      // Nullable
+	CreatedAt  int64           `json:"createdAt"` // Unix timestamp (in seconds)
+	Title      *string         `json:"title"`     // Nullable
+	Tags       BookmarkTags    `json:"tags"`      // Empty array if no tags
+	Content    BookmarkContent `json:"content"`   // Link type, or text type for text posts
+	Note       *string         `json:"note"`      // Nullable
+	Archived   bool            `json:"archived,omitempty"`
+	Favourited bool            `json:"favourited,omitempty"`
+	Summary    *string         `json:"-"` // API sync only, not part of Karakeep's import format
+	Highlights []Highlight     `json:"-"` // API sync only, created with new bookmarks
+	Origin     Origin          `json:"-"` // for output formats other than Karakeep's
+	Lists      []string        `json:"-"` // names of lists holding the bookmark, see WriteJSONV2
+}
+
+// Highlight is a passage to highlight on a bookmark in Karakeep, e.g., a top HN comment.
+type Highlight struct {
+	Text string
+	Note string // e.g., who wrote the passage and where
+}
+
+// Origin describes the HN item a bookmark was converted from. The zero value means unknown.
+type Origin struct {
+	ID     int
+	Author string
+	Score  int
+}
+
+// hnURL returns the HN discussion URL of the item, or "" if unknown.
+func (o Origin) hnURL() string {
+	if o.ID == 0 {
+		return ""
+	}
+	return hackernews.DiscussionURL(o.ID)
 }
 
 // BookmarkTags is a custom type to handle marshaling empty arrays instead of null.
@@ -37,15 +68,35 @@ func (s BookmarkTags) MarshalJSON() ([]byte, error) {
 	return json.Marshal([]string(s))
 }
 
-// BookmarkContent represents the content of a link-type bookmark in Karakeep export/import file.
-// The original schema supports discriminated union between "link" or "text" types,
-// but we only use "link" type here for our use case.
+// BookmarkContent represents the content of a bookmark in Karakeep export/import file,
+// a discriminated union between "link" and "text" types.
+// URL identifies the bookmark either way: for text bookmarks, it is the page the text was taken from.
 type BookmarkContent struct {
-	Type string `json:"type"`
-	URL  string `json:"url"`
+	Type string
+	URL  string
+	Text string // text type only
 }
 
 // NewBookmarkContent creates a new BookmarkContent with the given URL and the type field pre-set.
 func NewBookmarkContent(url string) BookmarkContent {
 	return BookmarkContent{Type: "link", URL: url}
 }
+
+// NewTextBookmarkContent creates a new text-type BookmarkContent with the given text taken from sourceURL.
+func NewTextBookmarkContent(text, sourceURL string) BookmarkContent {
+	return BookmarkContent{Type: "text", URL: sourceURL, Text: text}
+}
+
+func (c BookmarkContent) MarshalJSON() ([]byte, error) {
+	if c.Type == "text" {
+		return json.Marshal(struct {
+			Type      string `json:"type"`
+			Text      string `json:"text"`
+			SourceURL string `json:"sourceUrl,omitempty"`
+		}{c.Type, c.Text, c.URL})
+	}
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}{c.Type, c.URL})
+}
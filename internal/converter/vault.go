@@ -0,0 +1,108 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxNoteNameLength caps the length of vault note file names, in runes, leaving room for a suffix.
+const maxNoteNameLength = 100
+
+// WriteVault writes the export as an Obsidian (or Logseq) vault: one Markdown note per bookmark in dir,
+// named after its title, with the URL, HN item, save date, and tags in YAML frontmatter.
+// Existing notes of the same name are overwritten, so exporting again updates the vault.
+func WriteVault(dir string, export Schema) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, bm := range export.Bookmarks {
+		name := vaultNoteName(bm, used)
+		used[strings.ToLower(name)] = true // case-insensitive file systems
+		if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(vaultNote(bm)), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vaultNoteName returns a file name (without extension) for the bookmark that is not in used.
+func vaultNoteName(bm Bookmark, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#^[]`, r) || r < ' ' {
+			return ' '
+		}
+		return r
+	}, ptrValue(bm.Title))
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimLeft(name, ".") // no hidden files
+	if runes := []rune(name); len(runes) > maxNoteNameLength {
+		name = strings.TrimSpace(string(runes[:maxNoteNameLength]))
+	}
+	if name == "" {
+		name = "Untitled"
+	}
+
+	unique := name
+	if used[strings.ToLower(unique)] && bm.Origin.ID != 0 {
+		unique = fmt.Sprintf("%s (%d)", name, bm.Origin.ID)
+	}
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return unique
+}
+
+// vaultNote returns the Markdown note of the bookmark, with YAML frontmatter.
+func vaultNote(bm Bookmark) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(ptrValue(bm.Title)))
+	fmt.Fprintf(&b, "url: %s\n", strconv.Quote(bm.Content.URL))
+	if bm.Origin.ID != 0 {
+		fmt.Fprintf(&b, "hn_id: %d\n", bm.Origin.ID)
+		fmt.Fprintf(&b, "hn_url: %s\n", strconv.Quote(bm.Origin.hnURL()))
+		fmt.Fprintf(&b, "author: %s\n", strconv.Quote(bm.Origin.Author))
+		fmt.Fprintf(&b, "score: %d\n", bm.Origin.Score)
+	}
+	fmt.Fprintf(&b, "date: %s\n", time.Unix(bm.CreatedAt, 0).UTC().Format(time.RFC3339))
+	if len(bm.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range bm.Tags {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(vaultTag(tag)))
+		}
+	}
+	b.WriteString("---\n")
+
+	if bm.Title != nil && *bm.Title != "" {
+		fmt.Fprintf(&b, "\n# %s\n", *bm.Title)
+	}
+	fmt.Fprintf(&b, "\n[Link](%s)", bm.Content.URL)
+	if hnURL := bm.Origin.hnURL(); hnURL != "" && hnURL != bm.Content.URL {
+		fmt.Fprintf(&b, " · [HN discussion](%s)", hnURL)
+	}
+	b.WriteString("\n")
+	if body := strings.TrimSpace(bm.Content.Text + "\n\n" + ptrValue(bm.Note)); body != "" {
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	return b.String()
+}
+
+// vaultTag converts a tag to the characters Obsidian allows in tags: letters, digits, "_", "-", and "/"
+// for nesting. Colons become nesting, e.g., "src:hackernews" becomes "src/hackernews", others become "-".
+func vaultTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ':':
+			return '/'
+		case r == '_' || r == '-' || r == '/' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		}
+		return '-'
+	}, tag)
+}
@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteVault(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "vault")
+	export := Schema{Bookmarks: []Bookmark{
+		{
+			CreatedAt: 1704067200,
+			Title:     ptr(`Show HN: A/B "testing"`),
+			Content:   NewBookmarkContent("https://example.com"),
+			Tags:      []string{"src:hackernews", "read later"},
+			Note:      ptr("Worth a look"),
+			Origin:    Origin{ID: 1, Author: "alice", Score: 42},
+		},
+		{
+			CreatedAt: 1704153600,
+			Title:     ptr(`show hn: a b  testing`), // clashes with the first one on case-insensitive file systems
+			Content:   NewBookmarkContent("https://example.org"),
+			Origin:    Origin{ID: 7},
+		},
+		{
+			CreatedAt: 1704240000,
+			Content:   NewBookmarkContent("https://news.ycombinator.com/item?id=3"),
+		},
+	}}
+
+	if err := WriteVault(dir, export); err != nil {
+		t.Fatalf("WriteVault() unexpected error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading vault: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"Show HN A B testing.md", "Untitled.md", "show hn a b testing (7).md"}; !slices.Equal(names, want) {
+		t.Errorf("vault notes = %q, want %q", names, want)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "Show HN A B testing.md"))
+	if err != nil {
+		t.Fatalf("reading note: %v", err)
+	}
+	want := `---
+title: "Show HN: A/B \"testing\""
+url: "https://example.com"
+hn_id: 1
+hn_url: "https://news.ycombinator.com/item?id=1"
+author: "alice"
+score: 42
+date: 2024-01-01T00:00:00Z
+tags:
+  - "src/hackernews"
+  - "read-later"
+---
+
+# Show HN: A/B "testing"
+
+[Link](https://example.com) · [HN discussion](https://news.ycombinator.com/item?id=1)
+
+Worth a look
+`
+	if string(got) != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every",
+	}
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) expected error, got nil", expr)
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Sunday, 2024-03-10 14:30:45 UTC
+	from := time.Date(2024, 3, 10, 14, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 10, 14, 31, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 3, 11, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 10, 14, 45, 0, 0, time.UTC)},
+		{"10-40/20 * * * *", time.Date(2024, 3, 10, 15, 10, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// both day fields restricted: the 15th or any Friday, whichever comes first
+		{"0 12 15 * fri", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 12 13 * fri", time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next() = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %s, want zero time", got)
+	}
+}
@@ -0,0 +1,2 @@
+// Package cron parses five-field cron expressions and computes when they next fire.
+package cron
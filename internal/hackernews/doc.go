@@ -1,2 +0,0 @@
-// Package hackernews provides a client for interacting with the Hacker News API.
-package hackernews
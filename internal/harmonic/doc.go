@@ -1,2 +1,3 @@
-// Package harmonic contains functions to parse Harmonic-HN bookmarks export file.
+// Package harmonic contains functions to parse Harmonic-HN bookmarks export file,
+// also as found in the app's shared preferences on an Android device.
 package harmonic
@@ -0,0 +1,45 @@
+package harmonic
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PackageName is the Android application ID of Harmonic-HN. Harmonic keeps its bookmarks in
+// its default SharedPreferences file, in the same format as its export.
+const PackageName = "com.simon.harmonichackernews"
+
+// sharedPrefs is the XML document Android stores SharedPreferences in.
+type sharedPrefs struct {
+	Strings []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"string"`
+}
+
+// ExtractBookmarks returns the bookmarks export string from the XML of Harmonic's SharedPreferences.
+// The entry is looked up by a key containing "bookmarks", falling back to the first string value
+// that parses as an export, in case the key is renamed in a later Harmonic version.
+func ExtractBookmarks(prefsXML []byte) (string, error) {
+	var prefs sharedPrefs
+	if err := xml.Unmarshal(prefsXML, &prefs); err != nil {
+		return "", fmt.Errorf("parsing shared preferences: %w", err)
+	}
+
+	for _, s := range prefs.Strings {
+		if strings.Contains(strings.ToLower(s.Name), "bookmarks") {
+			if _, err := Parse(s.Value); err != nil {
+				return "", fmt.Errorf("bookmarks entry %q: %w", s.Name, err)
+			}
+			return strings.TrimSpace(s.Value), nil
+		}
+	}
+	for _, s := range prefs.Strings {
+		if _, err := Parse(s.Value); err == nil {
+			return strings.TrimSpace(s.Value), nil
+		}
+	}
+	return "", errors.New("no bookmarks found in shared preferences")
+}
@@ -0,0 +1,58 @@
+package harmonic
+
+import "testing"
+
+func TestExtractBookmarks(t *testing.T) {
+	tests := map[string]struct {
+		xml     string
+		want    string
+		wantErr bool
+	}{
+		"bookmarks key": {
+			xml: `<?xml version='1.0' encoding='utf-8' standalone='yes' ?>
+<map>
+    <string name="pref_theme">dark</string>
+    <boolean name="pref_compact" value="true" />
+    <string name="com.simon.harmonichackernews.KEY_SHARED_PREFERENCES_BOOKMARKS">3742902q1688536396765-37392676q1748370394349</string>
+</map>`,
+			want: "3742902q1688536396765-37392676q1748370394349",
+		},
+		"unknown key falls back to parsable value": {
+			xml: `<map>
+    <string name="pref_theme">dark</string>
+    <string name="saved">3742902q1688536396765</string>
+</map>`,
+			want: "3742902q1688536396765",
+		},
+		"invalid bookmarks entry": {
+			xml:     `<map><string name="bookmarks">not-an-export</string></map>`,
+			wantErr: true,
+		},
+		"no bookmarks": {
+			xml:     `<map><string name="pref_theme">dark</string></map>`,
+			wantErr: true,
+		},
+		"not xml": {
+			xml:     `run-as: package not debuggable`,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ExtractBookmarks([]byte(tt.xml))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ExtractBookmarks() expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractBookmarks() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractBookmarks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
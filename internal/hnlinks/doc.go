@@ -0,0 +1,3 @@
+// Package hnlinks contains functions to parse bookmarks from lists of HN items without save times,
+// such as the lists shared by some HN clients or a hand-curated list of item IDs.
+package hnlinks
@@ -0,0 +1,74 @@
+package hnlinks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+// itemURLPattern matches HN item links, also when escaped in JSON ("\/item?id=") or HTML ("&amp;").
+var itemURLPattern = regexp.MustCompile(`news\.ycombinator\.com\\?/item\?id=(\d+)`)
+
+// listLinePattern matches a line of an ID list: an item ID or item URL.
+var listLinePattern = regexp.MustCompile(`^(?:(?:https?://)?news\.ycombinator\.com/item\?id=)?(\d+)(?:[&#]\S*)?$`)
+
+// Parse returns a bookmark for every distinct HN item linked in the input, in order of appearance,
+// whatever the surrounding format (plain text, Markdown, HTML, JSON, ...).
+//
+// Such lists carry no save times, so bookmarks are timestamped with now, one second apart
+// going backwards, since clients list the newest bookmarks first.
+func Parse(input string, now time.Time) ([]harmonic.Bookmark, error) {
+	var bookmarks []harmonic.Bookmark
+	seen := make(map[int]bool)
+	for _, m := range itemURLPattern.FindAllStringSubmatch(input, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || id <= 0 || seen[id] {
+			continue // overflowing IDs are not HN items; a story linking to an HN item lists it twice
+		}
+		seen[id] = true
+		bookmarks = append(bookmarks, harmonic.Bookmark{ID: id, Timestamp: now.Unix() - int64(len(bookmarks))})
+	}
+
+	if len(bookmarks) == 0 {
+		return nil, errors.New("no HN item links found")
+	}
+	return bookmarks, nil
+}
+
+// ParseList parses a list of one HN item ID or item URL (news.ycombinator.com/item?id=N) per line,
+// e.g., hand-curated or piped from other tools. Blank lines and lines starting with # are skipped.
+// Unlike Parse, any other line is an error, so typos don't go unnoticed.
+// Bookmarks are timestamped as by Parse, with the first line as the newest.
+func ParseList(input string, now time.Time) ([]harmonic.Bookmark, error) {
+	var bookmarks []harmonic.Bookmark
+	seen := make(map[int]bool)
+	for i, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := listLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: %q is not an HN item ID or URL", i+1, line)
+		}
+		id, err := strconv.Atoi(m[1])
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("line %d: invalid item ID %q", i+1, m[1])
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		bookmarks = append(bookmarks, harmonic.Bookmark{ID: id, Timestamp: now.Unix() - int64(len(bookmarks))})
+	}
+
+	if len(bookmarks) == 0 {
+		return nil, errors.New("no valid bookmarks found")
+	}
+	return bookmarks, nil
+}
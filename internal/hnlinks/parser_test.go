@@ -0,0 +1,115 @@
+package hnlinks
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := map[string]struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		"plain text": {
+			input: "Show HN: A thing\nhttps://example.com/thing\nhttps://news.ycombinator.com/item?id=37392676\n\n" +
+				"Ask HN: A question\nhttps://news.ycombinator.com/item?id=3742902\n",
+			want: []int{37392676, 3742902},
+		},
+		"duplicates listed once": {
+			input: "https://news.ycombinator.com/item?id=3742902 https://news.ycombinator.com/item?id=3742902",
+			want:  []int{3742902},
+		},
+		"escaped JSON": {
+			input: `[{"title":"A","hnUrl":"https:\/\/news.ycombinator.com\/item?id=3742902"}]`,
+			want:  []int{3742902},
+		},
+		"HTML": {
+			input: `<a href="https://news.ycombinator.com/item?id=3742902&amp;p=2">A</a>`,
+			want:  []int{3742902},
+		},
+		"no links": {
+			input:   "https://example.com/item?id=1",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(tt.input, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse() expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			var want []harmonic.Bookmark
+			for i, id := range tt.want {
+				want = append(want, harmonic.Bookmark{ID: id, Timestamp: now.Unix() - int64(i)})
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := map[string]struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		"IDs and URLs": {
+			input: "# reading list\n3742902\n\nhttps://news.ycombinator.com/item?id=37392676\n" +
+				"news.ycombinator.com/item?id=16582136#comments\r\n3742902\n",
+			want: []int{3742902, 37392676, 16582136},
+		},
+		"other URL": {
+			input:   "3742902\nhttps://example.com/item?id=1\n",
+			wantErr: true,
+		},
+		"typo": {
+			input:   "37429o2\n",
+			wantErr: true,
+		},
+		"zero ID": {
+			input:   "0\n",
+			wantErr: true,
+		},
+		"comments only": {
+			input:   "# nothing yet\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseList(tt.input, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseList() expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseList() error: %v", err)
+			}
+			var want []harmonic.Bookmark
+			for i, id := range tt.want {
+				want = append(want, harmonic.Bookmark{ID: id, Timestamp: now.Unix() - int64(i)})
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseList() = %v, want %v", got, want)
+			}
+		})
+	}
+}
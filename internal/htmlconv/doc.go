@@ -0,0 +1,2 @@
+// Package htmlconv converts the HTML of HN comments and self-posts to plain text or Markdown.
+package htmlconv
@@ -0,0 +1,115 @@
+package htmlconv
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	linkRe       = regexp.MustCompile(`(?s)<a\s[^>]*href="([^"]*)"[^>]*>.*?</a>`)
+	paragraphRe  = regexp.MustCompile(`(?i)<p>`)
+	tagRe        = regexp.MustCompile(`<[^>]+>`)
+	blankLinesRe = regexp.MustCompile(`\n{3,}`)
+
+	markdownTagRe = regexp.MustCompile(`(?s)<(/?)([a-zA-Z]+)([^>]*)>`)
+	hrefRe        = regexp.MustCompile(`href="([^"]*)"`)
+)
+
+// ToText converts the HTML of HN comments and self-posts to plain text.
+// HN uses a small subset: <p> between paragraphs, <a href>, <i>, and <pre><code>.
+// Links are replaced by their full URL, since HN truncates the link text.
+func ToText(s string) string {
+	s = linkRe.ReplaceAllString(s, "$1")
+	s = paragraphRe.ReplaceAllString(s, "\n\n")
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = blankLinesRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// markdownEscaper escapes the characters that would start Markdown emphasis, code, or links.
+// ">" is left alone, so HN's "> quoted" lines become block quotes as intended.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+// ToMarkdown converts the HTML of HN comments and self-posts to Markdown: paragraphs stay paragraphs,
+// <i> becomes *emphasis*, <pre><code> a fenced code block, and links Markdown links. Links whose
+// text is the URL, which HN truncates with "...", become autolinks of the full URL. Other tags
+// are dropped and Markdown characters in the text are escaped.
+func ToMarkdown(s string) string {
+	var b strings.Builder
+	var inPre bool
+	var link *strings.Builder // text of the open link
+	var href string
+
+	write := func(text string) {
+		switch {
+		case inPre:
+			b.WriteString(html.UnescapeString(text))
+		case link != nil:
+			link.WriteString(html.UnescapeString(text))
+		default:
+			b.WriteString(markdownEscaper.Replace(html.UnescapeString(text)))
+		}
+	}
+
+	last := 0
+	for _, m := range markdownTagRe.FindAllStringSubmatchIndex(s, -1) {
+		write(s[last:m[0]])
+		last = m[1]
+		closing, tag, attrs := s[m[2]:m[3]] == "/", strings.ToLower(s[m[4]:m[5]]), s[m[6]:m[7]]
+
+		switch {
+		case tag == "pre" && !closing:
+			inPre = true
+			b.WriteString("\n\n```\n")
+		case tag == "pre" && closing:
+			inPre = false
+			if !strings.HasSuffix(b.String(), "\n") {
+				b.WriteString("\n")
+			}
+			b.WriteString("```\n\n")
+		case inPre:
+			// <code> and anything else inside a code block is not markup
+		case tag == "p":
+			b.WriteString("\n\n")
+		case tag == "i" || tag == "em":
+			if link != nil {
+				continue // link text is plain
+			}
+			b.WriteString("*")
+		case tag == "a" && !closing:
+			href = ""
+			if hm := hrefRe.FindStringSubmatch(attrs); hm != nil {
+				href = html.UnescapeString(hm[1])
+			}
+			link = &strings.Builder{}
+		case tag == "a" && closing && link != nil:
+			b.WriteString(markdownLink(link.String(), href))
+			link = nil
+		}
+	}
+	write(s[last:])
+	if link != nil { // unclosed link
+		b.WriteString(markdownLink(link.String(), href))
+	}
+
+	out := blankLinesRe.ReplaceAllString(b.String(), "\n\n")
+	return strings.TrimSpace(out)
+}
+
+// markdownLink returns a Markdown link to href with the given text, or an autolink if the text
+// is the URL or HN's truncation of it.
+func markdownLink(text, href string) string {
+	if href == "" {
+		return markdownEscaper.Replace(text)
+	}
+	if text == href || text == "" ||
+		strings.HasSuffix(text, "...") && strings.HasPrefix(href, strings.TrimSuffix(text, "...")) {
+		return "<" + href + ">"
+	}
+	if strings.ContainsAny(href, " ()<>") {
+		href = "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">"
+	}
+	return "[" + markdownEscaper.Replace(text) + "](" + href + ")"
+}
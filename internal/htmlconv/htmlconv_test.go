@@ -0,0 +1,63 @@
+package htmlconv
+
+import "testing"
+
+func TestToText(t *testing.T) {
+	tests := map[string]struct {
+		html string
+		want string
+	}{
+		"plain":      {"Just text", "Just text"},
+		"paragraphs": {"First<p>Second<p>Third", "First\n\nSecond\n\nThird"},
+		"entities":   {"It&#x27;s &quot;fine&quot; &amp; &lt;ok&gt;", `It's "fine" & <ok>`},
+		"link": {
+			`See <a href="https:&#x2F;&#x2F;example.com&#x2F;a-long-path" rel="nofollow">https:&#x2F;&#x2F;example.com&#x2F;a-lo...</a> here`,
+			"See https://example.com/a-long-path here",
+		},
+		"italic":   {"This is <i>important</i>", "This is important"},
+		"code":     {"Try:<p><pre><code>  go test ./...\n</code></pre>", "Try:\n\n  go test ./..."},
+		"empty":    {"", ""},
+		"trailing": {"<p>Text<p><p>", "Text"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := ToText(tc.html); got != tc.want {
+				t.Errorf("ToText(%q) = %q, want %q", tc.html, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	tests := map[string]struct {
+		html string
+		want string
+	}{
+		"plain":      {"Just text", "Just text"},
+		"paragraphs": {"First<p>Second<p>Third", "First\n\nSecond\n\nThird"},
+		"entities":   {"It&#x27;s &quot;fine&quot; &amp; &lt;ok&gt;", `It's "fine" & <ok>`},
+		"truncated link": {
+			`See <a href="https:&#x2F;&#x2F;example.com&#x2F;a-long-path" rel="nofollow">https:&#x2F;&#x2F;example.com&#x2F;a-lo...</a> here`,
+			"See <https://example.com/a-long-path> here",
+		},
+		"titled link": {`<a href="https://example.com/a_(b)">the_docs</a>`, `[the\_docs](<https://example.com/a_(b)>)`},
+		"italic":      {"This is <i>important</i>", "This is *important*"},
+		"escaped":     {"2*3 = x_1 [sic] `code`", "2\\*3 = x\\_1 \\[sic\\] \\`code\\`"},
+		"quote":       {"&gt; quoted<p>reply", "> quoted\n\nreply"},
+		"code": {
+			"Try:<p><pre><code>  a_b *c* &lt;d&gt;\n</code></pre>After",
+			"Try:\n\n```\n  a_b *c* <d>\n```\n\nAfter",
+		},
+		"empty":    {"", ""},
+		"trailing": {"<p>Text<p><p>", "Text"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := ToMarkdown(tc.html); got != tc.want {
+				t.Errorf("ToMarkdown(%q) = %q, want %q", tc.html, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Package idmap contains functions to parse the generic JSON mapping of HN item IDs to save times.
+//
+// It is the simplest possible interchange format, so other HN clients can offer an
+// "export for hnkeep" without replicating the Harmonic-HN format:
+//
+//	{"3742902": "2023-07-05T05:53:16Z", "37392676": "2025-05-27T18:26:34Z"}
+package idmap
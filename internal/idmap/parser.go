@@ -0,0 +1,78 @@
+package idmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+// Parse parses a JSON object mapping HN item IDs to ISO 8601 save times.
+// Bookmarks are returned in the order they appear in the object.
+func Parse(input string) ([]harmonic.Bookmark, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("empty input")
+	}
+
+	// stream tokens instead of decoding into a map to keep the file order
+	dec := json.NewDecoder(strings.NewReader(input))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("input is not a JSON object")
+	}
+
+	var bookmarks []harmonic.Bookmark
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		key := tok.(string) // object keys are always strings
+
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid bookmark %q: timestamp must be a string: %w", key, err)
+		}
+		bookmark, err := parseBookmark(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bookmark %q: %w", key, err)
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	if _, err := dec.Token(); err != nil { // closing brace
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if len(bookmarks) == 0 {
+		return nil, errors.New("no valid bookmarks found")
+	}
+	return bookmarks, nil
+}
+
+// parseBookmark parses a single item ID and its ISO 8601 timestamp (RFC 3339 or a plain date).
+func parseBookmark(idStr, tsStr string) (harmonic.Bookmark, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(idStr))
+	if err != nil {
+		return harmonic.Bookmark{}, fmt.Errorf("invalid item ID: %w", err)
+	}
+	if id <= 0 {
+		return harmonic.Bookmark{}, errors.New("item ID must be positive")
+	}
+
+	tsStr = strings.TrimSpace(tsStr)
+	t, err := time.Parse(time.RFC3339, tsStr)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, tsStr)
+	}
+	if err != nil {
+		return harmonic.Bookmark{}, fmt.Errorf("invalid timestamp %q: expected ISO 8601 (e.g., 2025-01-17T10:00:00Z)", tsStr)
+	}
+	if t.Unix() <= 0 {
+		return harmonic.Bookmark{}, errors.New("timestamp must be after 1970")
+	}
+
+	return harmonic.Bookmark{ID: id, Timestamp: t.Unix()}, nil
+}
@@ -0,0 +1,64 @@
+package idmap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    []harmonic.Bookmark
+		wantErr bool
+	}{
+		"single bookmark": {
+			input: `{"3742902": "2023-07-05T05:53:16Z"}`,
+			want:  []harmonic.Bookmark{{ID: 3742902, Timestamp: 1688536396}},
+		},
+		"file order is kept": {
+			input: `{"37392676": "2025-05-27T18:26:34Z", "3742902": "2023-07-05T05:53:16Z"}`,
+			want: []harmonic.Bookmark{
+				{ID: 37392676, Timestamp: 1748370394},
+				{ID: 3742902, Timestamp: 1688536396},
+			},
+		},
+		"offset and fractional seconds": {
+			input: `{"1": "2023-07-05T07:53:16.765+02:00"}`,
+			want:  []harmonic.Bookmark{{ID: 1, Timestamp: 1688536396}},
+		},
+		"date only": {
+			input: `{"1": "2023-07-05"}`,
+			want:  []harmonic.Bookmark{{ID: 1, Timestamp: 1688515200}},
+		},
+		"empty input":         {input: "  ", wantErr: true},
+		"empty object":        {input: "{}", wantErr: true},
+		"not an object":       {input: `["1"]`, wantErr: true},
+		"harmonic format":     {input: "3742902q1688536396765", wantErr: true},
+		"non-numeric id":      {input: `{"abc": "2023-07-05T05:53:16Z"}`, wantErr: true},
+		"negative id":         {input: `{"-1": "2023-07-05T05:53:16Z"}`, wantErr: true},
+		"numeric timestamp":   {input: `{"1": 1688536396}`, wantErr: true},
+		"invalid timestamp":   {input: `{"1": "yesterday"}`, wantErr: true},
+		"truncated object":    {input: `{"1": "2023-07-05T05:53:16Z"`, wantErr: true},
+		"timestamp pre-epoch": {input: `{"1": "1969-12-31T00:00:00Z"}`, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
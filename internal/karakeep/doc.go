@@ -1,9 +0,0 @@
-// Package karakeep provides a client for interacting with the Karakeep API.
-//
-// This package was built against Karakeep v0.30.0.
-// Most of the code references the following files in the Karakeep codebase:
-//   - Commit hash: https://github.com/karakeep-app/karakeep/tree/v0.30.0
-//   - API routes (http layer): packages/api/routes/bookmarks.ts
-//   - TRPC routers (business logic): package/trpc/routers/bookmarks.ts
-//   - Shared types: packages/shared/types/bookmarks.ts
-package karakeep
@@ -0,0 +1,8 @@
+// Package keyring stores secrets in the credential store of the operating system:
+// the login Keychain on macOS, the Secret Service (e.g., GNOME Keyring, KWallet) on Linux
+// and other Unix systems, and the Credential Manager on Windows.
+//
+// The Keychain and Secret Service are accessed through their command line tools (security and
+// secret-tool), so no cgo or third-party dependency is needed. Secrets are passed to the tools
+// through stdin, never as arguments, so they don't show up in the process list.
+package keyring
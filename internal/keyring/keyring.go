@@ -0,0 +1,27 @@
+package keyring
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no secret is stored for the service and account.
+	ErrNotFound = errors.New("secret not found in keyring")
+
+	// ErrUnsupported is returned when the credential store is not available,
+	// e.g., secret-tool is not installed or there is no desktop session.
+	ErrUnsupported = errors.New("keyring not available on this system")
+)
+
+// Set stores the secret for the service and account, replacing any previous one.
+func Set(service, account, secret string) error {
+	return set(service, account, secret)
+}
+
+// Get returns the secret stored for the service and account.
+func Get(service, account string) (string, error) {
+	return get(service, account)
+}
+
+// Delete removes the secret stored for the service and account.
+func Delete(service, account string) error {
+	return del(service, account)
+}
@@ -0,0 +1,59 @@
+//go:build darwin
+
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityNotFound is the exit code of the security tool for a missing keychain item.
+const securityNotFound = 44
+
+// set adds the item via the interactive mode of security, which reads the command from stdin.
+func set(service, account, secret string) error {
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(service), quote(account), quote(secret))
+	_, err := security(cmd, "-i")
+	return err
+}
+
+func get(service, account string) (string, error) {
+	out, err := security("", "find-generic-password", "-s", service, "-a", account, "-w")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func del(service, account string) error {
+	_, err := security("", "delete-generic-password", "-s", service, "-a", account)
+	return err
+}
+
+// security runs the security tool with the given stdin and arguments and returns its output.
+func security(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return "", ErrUnsupported
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("security: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// quote quotes an argument for the command parser of security -i.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
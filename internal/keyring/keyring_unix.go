@@ -0,0 +1,53 @@
+//go:build !windows && !darwin
+
+package keyring
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// set stores the secret with secret-tool, which reads it from stdin.
+func set(service, account, secret string) error {
+	_, err := secretTool(secret, "store", "--label="+service+" ("+account+")", "service", service, "account", account)
+	return err
+}
+
+func get(service, account string) (string, error) {
+	out, err := secretTool("", "lookup", "service", service, "account", account)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", ErrNotFound // older secret-tool versions exit 0 with no output
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func del(service, account string) error {
+	_, err := secretTool("", "clear", "service", service, "account", account)
+	return err
+}
+
+// secretTool runs secret-tool with the given stdin and arguments and returns its output.
+// lookup exits with status 1 and no message if nothing is found, so that is reported as ErrNotFound.
+func secretTool(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", ErrUnsupported
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" && args[0] == "lookup" {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool %s: %w: %s", args[0], err, msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,56 @@
+//go:build !windows && !darwin
+
+package keyring
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSecretTool installs a secret-tool script on PATH that runs the given shell body.
+func fakeSecretTool(t *testing.T, body string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGet_Unsupported(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no secret-tool
+	if _, err := Get("hnkeep", "https://karakeep.example.com"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Get() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestGet_NotFound(t *testing.T) {
+	fakeSecretTool(t, "exit 1")
+	if _, err := Get("hnkeep", "https://karakeep.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "secret")
+	// store saves stdin, lookup prints it back; the arguments are checked so a wrong call fails
+	fakeSecretTool(t, `case "$*" in
+"store --label=hnkeep (acct) service hnkeep account acct") cat > `+store+` ;;
+"lookup service hnkeep account acct") cat `+store+` ;;
+*) echo "unexpected: $*" >&2; exit 2 ;;
+esac`)
+
+	if err := Set("hnkeep", "acct", "s3cret"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	got, err := Get("hnkeep", "acct")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if got != "s3cret" {
+		t.Errorf("Get() = %q, want %q", got, "s3cret")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package logger
+
+import "os"
+
+// EnableVirtualTerminal is a no-op on non-Windows platforms, where terminals
+// interpret ANSI escape codes natively.
+func EnableVirtualTerminal(_ *os.File) bool {
+	return true
+}
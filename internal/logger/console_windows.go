@@ -0,0 +1,30 @@
+//go:build windows
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing lets the Windows console interpret ANSI escape codes.
+// Refer to https://learn.microsoft.com/en-us/windows/console/setconsolemode.
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// EnableVirtualTerminal enables ANSI escape code processing for the given console.
+// Returns false if the file is not a console or the console does not support it
+// (e.g., legacy conhost before Windows 10).
+func EnableVirtualTerminal(f *os.File) bool {
+	handle := syscall.Handle(f.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true // already enabled, e.g., Windows Terminal
+	}
+	ok, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+	return ok != 0
+}
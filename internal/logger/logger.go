@@ -1,13 +1,19 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"os"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Logger defines the interface for logging messages.
 type Logger interface {
+	Debug(format string, args ...any)
 	Info(format string, args ...any)
 	Warn(format string, args ...any)
 	Error(format string, args ...any)
@@ -18,46 +24,181 @@ func Noop() Logger { return &noopLogger{} }
 
 type noopLogger struct{}
 
+func (noopLogger) Debug(string, ...any) {}
 func (noopLogger) Info(string, ...any)  {}
 func (noopLogger) Warn(string, ...any)  {}
 func (noopLogger) Error(string, ...any) {}
 
-// StdLogger provides thread-safe structured logging to an output writer.
+// ParseLevel parses a level name: debug, info, warn, or error (case-insensitive).
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (supported: debug, info, warn, error)", s)
+}
+
+// StdLogger provides thread-safe leveled logging to an output writer, backed by log/slog.
 type StdLogger struct {
-	mu    sync.Mutex
-	out   io.Writer
-	quiet bool
+	slog *slog.Logger
+}
+
+// StdLoggerOption configures the StdLogger.
+type StdLoggerOption func(*lineHandler)
+
+// WithTimestamps prefixes each line with its local time, e.g., for log files read after the run.
+func WithTimestamps() StdLoggerOption {
+	return func(h *lineHandler) {
+		h.timestamps = true
+	}
+}
+
+// WithColor renders the [WARN] prefix in yellow and the [ERROR] prefix in red using ANSI escape codes.
+// Only enable it for terminals, see ColorEnabled.
+func WithColor() StdLoggerOption {
+	return func(h *lineHandler) {
+		h.color = true
+	}
 }
 
-// NewStdLogger creates a new Logger that writes to the given writer.
-// If quiet is true, Info messages are suppressed.
-func NewStdLogger(out io.Writer, quiet bool) *StdLogger {
-	return &StdLogger{
-		out:   out,
-		quiet: quiet,
+// ANSI escape codes for the colored level prefixes.
+const (
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+	ansiReset  = "\033[0m"
+)
+
+// ColorEnabled reports whether log output to stderr should be colored: stderr is a terminal that
+// interprets ANSI escape codes and the NO_COLOR environment variable (https://no-color.org) is not set.
+func ColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return IsStderrTTY() && EnableVirtualTerminal(os.Stderr)
+}
+
+// NewStdLogger creates a new Logger that writes messages of at least the given level to the writer,
+// one line each with a level prefix, e.g., "[WARN] ...".
+func NewStdLogger(out io.Writer, level slog.Level, opts ...StdLoggerOption) *StdLogger {
+	h := &lineHandler{mu: &sync.Mutex{}, out: out, level: level}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return &StdLogger{slog: slog.New(h)}
 }
 
+// Slog returns the underlying slog.Logger, e.g., for structured logging with attributes.
+func (l *StdLogger) Slog() *slog.Logger { return l.slog }
+
+// Debug logs a debugging message with [DEBUG] prefix.
+func (l *StdLogger) Debug(format string, args ...any) { l.log(slog.LevelDebug, format, args) }
+
 // Info logs an informational message with [INFO] prefix.
-func (l *StdLogger) Info(format string, args ...any) {
-	if l.quiet {
+func (l *StdLogger) Info(format string, args ...any) { l.log(slog.LevelInfo, format, args) }
+
+// Warn logs a warning message with [WARN] prefix.
+func (l *StdLogger) Warn(format string, args ...any) { l.log(slog.LevelWarn, format, args) }
+
+// Error logs an error message with [ERROR] prefix.
+func (l *StdLogger) Error(format string, args ...any) { l.log(slog.LevelError, format, args) }
+
+// log formats and logs the message, skipping the formatting if the level is disabled.
+func (l *StdLogger) log(level slog.Level, format string, args []any) {
+	ctx := context.Background()
+	if !l.slog.Enabled(ctx, level) {
 		return
 	}
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	_, _ = fmt.Fprintf(l.out, "[INFO] "+format+"\n", args...)
+	l.slog.Log(ctx, level, fmt.Sprintf(format, args...))
 }
 
-// Warn logs an informational message with [WARN] prefix.
-func (l *StdLogger) Warn(format string, args ...any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	_, _ = fmt.Fprintf(l.out, "[WARN] "+format+"\n", args...)
+// lineHandler is a slog.Handler writing each record as "[LEVEL] message key=value ...".
+// Records are written whole under a mutex shared by derived handlers, so concurrent lines never interleave.
+type lineHandler struct {
+	mu         *sync.Mutex
+	out        io.Writer
+	level      slog.Leveler
+	timestamps bool   // prefix lines with the record time
+	color      bool   // color the level prefix of warnings and errors
+	attrs      string // preformatted attributes of WithAttrs
+	prefix     string // group prefix of WithGroup, e.g., "http."
 }
 
-// Error logs an informational message with [ERROR] prefix.
-func (l *StdLogger) Error(format string, args ...any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	_, _ = fmt.Fprintf(l.out, "[ERROR] "+format+"\n", args...)
+func (h *lineHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *lineHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	if h.timestamps {
+		b.WriteString(r.Time.Format(time.RFC3339) + " ")
+	}
+	fmt.Fprintf(&b, "%s %s%s", h.levelPrefix(r.Level), r.Message, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+// levelPrefix returns the level in brackets, e.g., "[WARN]", colored if enabled.
+func (h *lineHandler) levelPrefix(level slog.Level) string {
+	prefix := "[" + level.String() + "]"
+	if !h.color {
+		return prefix
+	}
+	switch {
+	case level >= slog.LevelError:
+		return ansiRed + prefix + ansiReset
+	case level >= slog.LevelWarn:
+		return ansiYellow + prefix + ansiReset
+	}
+	return prefix
+}
+
+func (h *lineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		appendAttr(&b, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *lineHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// appendAttr appends the attribute as " key=value", flattening groups into dotted keys.
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(b, prefix, ga)
+		}
+		return
+	}
+	fmt.Fprintf(b, " %s%s=%v", prefix, a.Key, a.Value.Any())
 }
@@ -2,14 +2,16 @@ package logger
 
 import (
 	"bytes"
+	"log/slog"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestLoggerInfo(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewStdLogger(&buf, false)
+	logger := NewStdLogger(&buf, slog.LevelInfo)
 	logger.Info("test message: %s", "hello")
 
 	got := buf.String()
@@ -21,7 +23,7 @@ func TestLoggerInfo(t *testing.T) {
 
 func TestLoggerWarn(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewStdLogger(&buf, false)
+	logger := NewStdLogger(&buf, slog.LevelInfo)
 	logger.Warn("test message: %s", "hello")
 
 	got := buf.String()
@@ -33,7 +35,7 @@ func TestLoggerWarn(t *testing.T) {
 
 func TestLoggerError(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewStdLogger(&buf, false)
+	logger := NewStdLogger(&buf, slog.LevelInfo)
 	logger.Error("test message: %s", "hello")
 
 	got := buf.String()
@@ -43,16 +45,29 @@ func TestLoggerError(t *testing.T) {
 	}
 }
 
-func TestLoggerQuietMode(t *testing.T) {
+func TestLoggerDebug(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewStdLogger(&buf, true)
+	logger := NewStdLogger(&buf, slog.LevelDebug)
+	logger.Debug("test message: %s", "hello")
+
+	got := buf.String()
+	want := "[DEBUG] test message: hello\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(&buf, slog.LevelWarn)
+	logger.Debug("this should be suppressed")
 	logger.Info("this should be suppressed")
 	logger.Warn("this should appear")
 	logger.Error("this should also appear")
 
 	got := buf.String()
 	if strings.Contains(got, "this should be suppressed") {
-		t.Errorf("Info message was not suppressed in quiet mode")
+		t.Errorf("message below level was not suppressed")
 	}
 	if !strings.Contains(got, "this should appear") {
 		t.Errorf("Warn message was not logged")
@@ -64,7 +79,7 @@ func TestLoggerQuietMode(t *testing.T) {
 
 func TestLoggerConcurrentWrites(t *testing.T) {
 	var buf bytes.Buffer
-	logger := NewStdLogger(&buf, false)
+	logger := NewStdLogger(&buf, slog.LevelInfo)
 
 	var wg sync.WaitGroup
 	iterations := 100
@@ -105,3 +120,78 @@ func TestLoggerConcurrentWrites(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggerSlogAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(&buf, slog.LevelInfo)
+	logger.Slog().WithGroup("http").With("method", "GET").Info("request", "status", 200)
+
+	got := buf.String()
+	want := "[INFO] request http.method=GET http.status=200\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"info", slog.LevelInfo, false},
+		{"WARN", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerTimestamps(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(&buf, slog.LevelInfo, WithTimestamps())
+	logger.Warn("hello")
+
+	got := buf.String()
+	ts, rest, ok := strings.Cut(got, " ")
+	if !ok || rest != "[WARN] hello\n" {
+		t.Fatalf("got %q, want timestamp followed by %q", got, "[WARN] hello\n")
+	}
+	if _, err := time.Parse(time.RFC3339, ts); err != nil {
+		t.Errorf("timestamp %q is not RFC 3339: %v", ts, err)
+	}
+}
+
+func TestLoggerColor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(&buf, slog.LevelInfo, WithColor())
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	got := buf.String()
+	want := "[INFO] info\n" +
+		"\033[33m[WARN]\033[0m warn\n" +
+		"\033[31m[ERROR]\033[0m error\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestColorEnabled_NoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled() {
+		t.Error("ColorEnabled() = true with NO_COLOR set")
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Phaser reports pipeline phase transitions (e.g., connectivity check, pre-fetch)
+// as timestamped lines of the form "[15:04:05] Pre-fetching existing bookmarks... found 42 (0.31s)".
+type Phaser struct {
+	mu    sync.Mutex
+	out   io.Writer
+	quiet bool
+	now   func() time.Time // for testing
+}
+
+// NewPhaser creates a Phaser that writes to the given writer.
+// If quiet is true, nothing is written.
+func NewPhaser(out io.Writer, quiet bool) *Phaser {
+	return &Phaser{out: out, quiet: quiet, now: time.Now}
+}
+
+// Phase is a running phase started by Phaser.Start.
+type Phase struct {
+	p     *Phaser
+	start time.Time
+}
+
+// Start writes the phase name and returns the Phase to be finished with Done or Fail.
+func (p *Phaser) Start(name string) *Phase {
+	ph := &Phase{p: p, start: p.now()}
+	if p.quiet {
+		return ph
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = fmt.Fprintf(p.out, "[%s] %s... ", ph.start.Format(time.TimeOnly), name)
+	return ph
+}
+
+// Done finishes the phase with a result message (e.g., "ok") and the elapsed time.
+func (ph *Phase) Done(format string, args ...any) {
+	ph.finish(fmt.Sprintf(format, args...))
+}
+
+// Fail finishes the phase as failed. The error itself is left for the caller to report.
+func (ph *Phase) Fail() {
+	ph.finish("failed")
+}
+
+// finish writes the result and elapsed time, ending the phase line.
+func (ph *Phase) finish(result string) {
+	if ph.p.quiet {
+		return
+	}
+	ph.p.mu.Lock()
+	defer ph.p.mu.Unlock()
+	elapsed := ph.p.now().Sub(ph.start)
+	_, _ = fmt.Fprintf(ph.p.out, "%s (%.2fs)\n", result, elapsed.Seconds())
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPhaser(t *testing.T) {
+	t.Run("writes timestamped phase with result and elapsed time", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewPhaser(&buf, false)
+		clock := time.Date(2026, 1, 17, 9, 30, 0, 0, time.UTC)
+		p.now = func() time.Time { return clock }
+
+		ph := p.Start("Pre-fetching existing bookmarks")
+		clock = clock.Add(1500 * time.Millisecond)
+		ph.Done("found %d", 42)
+
+		got := buf.String()
+		want := "[09:30:00] Pre-fetching existing bookmarks... found 42 (1.50s)\n"
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("quiet mode writes nothing", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewPhaser(&buf, true)
+
+		p.Start("Checking connectivity").Fail()
+
+		if buf.Len() != 0 {
+			t.Errorf("expected no output in quiet mode, got %q", buf.String())
+		}
+	})
+}
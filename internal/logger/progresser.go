@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+	"time"
 )
 
 // IsTTY checks if the given file is connected to a terminal.
@@ -23,24 +25,58 @@ type Progresser interface {
 	Update(current, total int)
 }
 
-// TTYProgresser provides in-place progress updates to a writer.
+// progressBarWidth is the number of cells of the TTYProgresser bar.
+const progressBarWidth = 20
+
+// minETAElapsed is how long progress must run before the rate is stable enough for an ETA.
+const minETAElapsed = time.Second
+
+// TTYProgresser provides in-place progress updates to a writer, rendered as a bar with the
+// processing rate and the estimated time left, e.g., "Fetching: 120/5000 [##....] 2% 35.2/s ETA 2m19s".
 type TTYProgresser struct {
 	mu     sync.Mutex // protects concurrent writes
 	out    io.Writer
 	format string
+	start  time.Time
+	now    func() time.Time // stubbed in tests
 }
 
 // NewProgresser creates a Progresser that writes to the given writer.
 // Format should include two %d placeholders for current and total (e.g., "Fetching: %d/%d").
+// The rate and ETA are measured from the time of this call.
 func NewProgresser(out io.Writer, format string) *TTYProgresser {
-	return &TTYProgresser{out: out, format: format}
+	return &TTYProgresser{out: out, format: format, start: time.Now(), now: time.Now}
 }
 
 // Update updates the progress display in place.
 func (p *TTYProgresser) Update(current, total int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	_, _ = fmt.Fprintf(p.out, "\r"+p.format, current, total)
+	// \033[K erases what is left of a previous, longer line
+	status := progressStatus(current, total, p.now().Sub(p.start))
+	_, _ = fmt.Fprintf(p.out, "\r"+p.format+" %s\033[K", current, total, status)
+}
+
+// progressStatus renders the bar, percentage, rate, and ETA for the given progress and elapsed time.
+func progressStatus(current, total int, elapsed time.Duration) string {
+	fraction := 1.0
+	if total > 0 {
+		fraction = min(max(float64(current)/float64(total), 0), 1)
+	}
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+	status := fmt.Sprintf("[%s] %3d%%", bar, int(fraction*100))
+
+	if elapsed < minETAElapsed || current <= 0 {
+		return status // too early for a meaningful rate
+	}
+	rate := float64(current) / elapsed.Seconds()
+	status += fmt.Sprintf(" %.1f/s", rate)
+	if current < total {
+		eta := time.Duration(float64(total-current) / rate * float64(time.Second))
+		status += " ETA " + eta.Round(time.Second).String()
+	}
+	return status
 }
 
 // Clear clears the progress line using ANSI escape codes.
@@ -50,3 +86,34 @@ func (p *TTYProgresser) Clear() {
 	// \r moves cursor to start of line, \033[K erases from cursor to end of line
 	_, _ = fmt.Fprintf(p.out, "\r\033[K")
 }
+
+// HeartbeatProgresser prints progress as plain lines at a fixed interval.
+// It is a fallback for terminals that cannot interpret the escape codes used by TTYProgresser.
+type HeartbeatProgresser struct {
+	mu       sync.Mutex
+	out      io.Writer
+	format   string
+	interval time.Duration
+	last     time.Time
+}
+
+// NewHeartbeatProgresser creates a Progresser that writes a progress line at most once per interval.
+// Format follows NewProgresser; the final update (current == total) is always written.
+func NewHeartbeatProgresser(out io.Writer, format string, interval time.Duration) *HeartbeatProgresser {
+	return &HeartbeatProgresser{out: out, format: format, interval: interval}
+}
+
+// Update writes a progress line if the interval has elapsed since the last one.
+func (p *HeartbeatProgresser) Update(current, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if current < total && now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	_, _ = fmt.Fprintf(p.out, p.format+"\n", current, total)
+}
+
+// Clear is a no-op since heartbeat lines are meant to stay on screen.
+func (p *HeartbeatProgresser) Clear() {}
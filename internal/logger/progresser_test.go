@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatProgresser(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewHeartbeatProgresser(&buf, "Fetching: %d/%d", time.Hour)
+
+	p.Update(1, 3) // first update always prints (zero last time)
+	p.Update(2, 3) // within interval, suppressed
+	p.Update(3, 3) // final update always prints
+	p.Clear()
+
+	got := buf.String()
+	want := "Fetching: 1/3\nFetching: 3/3\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTTYProgresser(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		current  int
+		total    int
+		wantLine string
+	}{
+		{"start without rate", 0, 0, 100, "Fetching: 0/100 [....................]   0%"},
+		{"too early for ETA", 500 * time.Millisecond, 10, 100, "Fetching: 10/100 [##..................]  10%"},
+		{"rate and ETA", 10 * time.Second, 25, 100, "Fetching: 25/100 [#####...............]  25% 2.5/s ETA 30s"},
+		{"done without ETA", 40 * time.Second, 100, 100, "Fetching: 100/100 [####################] 100% 2.5/s"},
+		{"empty total", 0, 0, 0, "Fetching: 0/0 [####################] 100%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgresser(&buf, "Fetching: %d/%d")
+			p.now = func() time.Time { return p.start.Add(tt.elapsed) }
+
+			p.Update(tt.current, tt.total)
+
+			want := "\r" + tt.wantLine + "\033[K"
+			if got := buf.String(); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
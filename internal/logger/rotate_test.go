@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hnkeep.log")
+	r, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	// each write fills the file, so every following write rotates
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) error: %v", line, err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n", // "first" was dropped as the oldest backup
+	}
+	for p, content := range want {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("reading %s: %v", p, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), got, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no third backup, got err %v", err)
+	}
+}
+
+func TestRotatingFile_AppendsBelowMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hnkeep.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenRotatingFile(path, 1024, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	if _, err := r.Write([]byte("new\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "old\nnew\n" {
+		t.Errorf("content = %q, want %q", got, "old\nnew\n")
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("expected no rotation, got err %v", err)
+	}
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	r, err := OpenRotatingFile(filepath.Join(t.TempDir(), "hnkeep.log"), 0, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error: %v", err)
+	}
+	_ = r.Close()
+	if _, err := r.Write([]byte("late\n")); err == nil {
+		t.Error("expected error writing to a closed file")
+	}
+}
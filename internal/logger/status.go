@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatusBoard is a block of status lines redrawn in place at the bottom of a terminal,
+// e.g., one line per pipeline stage plus result counters, so all stages are visible at once.
+//
+// Anything else written to the terminal while the board is shown must go through Write,
+// which prints it above the board. Use it as the output of the logger.
+type StatusBoard struct {
+	mu     sync.Mutex
+	out    io.Writer
+	lines  []string
+	drawn  int  // number of board lines currently on screen
+	closed bool // board is final, writes pass through
+}
+
+// NewStatusBoard creates an empty StatusBoard drawing on the given writer, which must interpret ANSI escape codes.
+func NewStatusBoard(out io.Writer) *StatusBoard {
+	return &StatusBoard{out: out}
+}
+
+// StatusLine is a line of a StatusBoard.
+type StatusLine struct {
+	b *StatusBoard
+	i int
+}
+
+// Line appends an empty line to the board.
+func (b *StatusBoard) Line() *StatusLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, "")
+	return &StatusLine{b: b, i: len(b.lines) - 1}
+}
+
+// Set replaces the text of the line and redraws the board.
+func (l *StatusLine) Set(format string, args ...any) {
+	l.b.mu.Lock()
+	defer l.b.mu.Unlock()
+	l.b.lines[l.i] = fmt.Sprintf(format, args...)
+	l.b.redraw()
+}
+
+// Stage appends a progress line to the board, rendered like TTYProgresser.
+// Format follows NewProgresser; rate and ETA are measured from the time of this call.
+func (b *StatusBoard) Stage(format string) *StageProgresser {
+	return &StageProgresser{line: b.Line(), format: format, start: time.Now(), now: time.Now}
+}
+
+// StageProgresser reports the progress of a stage on its StatusBoard line.
+type StageProgresser struct {
+	line   *StatusLine
+	format string
+	start  time.Time
+	now    func() time.Time // stubbed in tests
+}
+
+// Update updates the stage line.
+func (p *StageProgresser) Update(current, total int) {
+	status := progressStatus(current, total, p.now().Sub(p.start))
+	p.line.Set(p.format+" %s", current, total, status)
+}
+
+// Clear is a no-op since the line of a finished stage stays on the board.
+func (p *StageProgresser) Clear() {}
+
+// Write prints p above the board. The board is erased, p written, and the board drawn again below it.
+func (b *StatusBoard) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || b.drawn == 0 {
+		return b.out.Write(p)
+	}
+	b.erase()
+	n, err := b.out.Write(p)
+	if err != nil {
+		return n, err
+	}
+	b.drawn = 0
+	b.draw()
+	return n, nil
+}
+
+// Close leaves the board on screen as is. Later writes are passed through below it.
+func (b *StatusBoard) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+}
+
+// redraw moves the cursor back to the top of the board and draws it again.
+func (b *StatusBoard) redraw() {
+	if b.closed {
+		return
+	}
+	if b.drawn > 0 {
+		_, _ = fmt.Fprintf(b.out, "\033[%dA", b.drawn) // cursor up to the first board line
+	}
+	b.draw()
+}
+
+// draw writes all lines from the cursor position, erasing the rest of each line.
+func (b *StatusBoard) draw() {
+	var sb strings.Builder
+	for _, line := range b.lines {
+		sb.WriteString("\r" + line + "\033[K\n")
+	}
+	_, _ = io.WriteString(b.out, sb.String())
+	b.drawn = len(b.lines)
+}
+
+// erase moves the cursor to the top of the board and erases everything below.
+func (b *StatusBoard) erase() {
+	_, _ = fmt.Fprintf(b.out, "\033[%dA\r\033[J", b.drawn)
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestStatusBoard(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewStatusBoard(&buf)
+
+	first := b.Line()
+	first.Set("first %d", 1)
+	second := b.Line()
+	second.Set("second")
+	first.Set("first %d", 2)
+
+	want := "\rfirst 1\033[K\n" +
+		"\033[1A" + "\rfirst 1\033[K\n\rsecond\033[K\n" + // new line appended below
+		"\033[2A" + "\rfirst 2\033[K\n\rsecond\033[K\n" // both redrawn in place
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStatusBoard_WriteAbove(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewStatusBoard(&buf)
+
+	if _, err := b.Write([]byte("before\n")); err != nil { // nothing drawn yet, passed through
+		t.Fatalf("Write() error: %v", err)
+	}
+	b.Line().Set("status")
+	if _, err := b.Write([]byte("[WARN] hello\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	want := "before\n" +
+		"\rstatus\033[K\n" +
+		"\033[1A\r\033[J" + "[WARN] hello\n" + "\rstatus\033[K\n" // erased, written, redrawn below
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStatusBoard_Close(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewStatusBoard(&buf)
+	line := b.Line()
+	line.Set("done")
+	b.Close()
+	buf.Reset()
+
+	line.Set("ignored")
+	_, _ = b.Write([]byte("after\n"))
+
+	if got := buf.String(); got != "after\n" {
+		t.Errorf("got %q, want only the passed-through write", got)
+	}
+}
+
+func TestStatusBoard_Stage(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewStatusBoard(&buf)
+	p := b.Stage("Syncing: %d/%d")
+	p.now = func() time.Time { return p.start.Add(10 * time.Second) }
+
+	p.Update(25, 100)
+	p.Clear() // finished stages stay on the board
+
+	want := "\rSyncing: 25/100 [#####...............]  25% 2.5/s ETA 30s\033[K\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,3 @@
+// Package materialistic contains functions to parse the saved stories of the Materialistic Android app,
+// either from its text export or from its database dumped as CSV.
+package materialistic
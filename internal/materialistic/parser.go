@@ -0,0 +1,71 @@
+package materialistic
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/internal/hnlinks"
+)
+
+// Parse parses Materialistic saved stories, given as either:
+//   - the favorite table of its database dumped as CSV with a header, e.g.,
+//     sqlite3 -header -csv Materialistic.db "SELECT * FROM favorite", which has the save times
+//   - the text export shared from the app, a title, URL, and HN link per story
+//
+// The text export has no save times, so its bookmarks are timestamped as by hnlinks.Parse.
+func Parse(input string) ([]harmonic.Bookmark, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, errors.New("empty input")
+	}
+
+	first, _, _ := strings.Cut(strings.TrimSpace(input), "\n")
+	if strings.Contains(strings.ToLower(first), "itemid") {
+		return parseCSV(input)
+	}
+	return hnlinks.Parse(input, time.Now())
+}
+
+// parseCSV parses the favorite table dumped as CSV, with the item ID in the itemid column
+// and the save time in milliseconds in the time column.
+func parseCSV(input string) ([]harmonic.Bookmark, error) {
+	records, err := csv.NewReader(strings.NewReader(strings.TrimSpace(input))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	idCol, timeCol := -1, -1
+	for i, name := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "itemid":
+			idCol = i
+		case "time":
+			timeCol = i
+		}
+	}
+	if idCol < 0 || timeCol < 0 {
+		return nil, errors.New("CSV header must have itemid and time columns")
+	}
+
+	bookmarks := make([]harmonic.Bookmark, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		id, err := strconv.Atoi(strings.TrimSpace(rec[idCol]))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid item ID %q on row %d", rec[idCol], i+1)
+		}
+		ms, err := strconv.ParseInt(strings.TrimSpace(rec[timeCol]), 10, 64)
+		if err != nil || ms <= 0 {
+			return nil, fmt.Errorf("invalid time %q on row %d", rec[timeCol], i+1)
+		}
+		bookmarks = append(bookmarks, harmonic.Bookmark{ID: id, Timestamp: ms / 1000})
+	}
+
+	if len(bookmarks) == 0 {
+		return nil, errors.New("no valid bookmarks found")
+	}
+	return bookmarks, nil
+}
@@ -0,0 +1,77 @@
+package materialistic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+)
+
+func TestParse_CSV(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    []harmonic.Bookmark
+		wantErr bool
+	}{
+		"sqlite3 dump": {
+			input: "_id,itemid,url,title,time\n" +
+				"1,3742902,https://example.com/a,\"A, with comma\",1688536396765\n" +
+				"2,37392676,https://example.com/b,B,1748370394349\n",
+			want: []harmonic.Bookmark{
+				{ID: 3742902, Timestamp: 1688536396},
+				{ID: 37392676, Timestamp: 1748370394},
+			},
+		},
+		"header only": {
+			input:   "_id,itemid,url,title,time\n",
+			wantErr: true,
+		},
+		"missing time column": {
+			input:   "itemid,url\n3742902,https://example.com\n",
+			wantErr: true,
+		},
+		"invalid item ID": {
+			input:   "itemid,time\nabc,1688536396765\n",
+			wantErr: true,
+		},
+		"invalid time": {
+			input:   "itemid,time\n3742902,yesterday\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse() expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	if _, err := Parse(" \n"); err == nil {
+		t.Error("Parse() expected error for empty input")
+	}
+}
+
+func TestParse_Text(t *testing.T) {
+	input := "Show HN: A thing\nhttps://example.com/thing\nhttps://news.ycombinator.com/item?id=37392676\n\n"
+	got, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 37392676 {
+		t.Errorf("Parse() = %v, want item 37392676", got)
+	}
+}
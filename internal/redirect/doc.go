@@ -0,0 +1,3 @@
+// Package redirect resolves URLs to the final destination of their redirects, e.g., of URL shorteners,
+// and detects dead links.
+package redirect
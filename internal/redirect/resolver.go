@@ -0,0 +1,129 @@
+package redirect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultTimeout = 15 * time.Second
+	maxRedirects   = 10
+)
+
+// userAgent identifies the resolver, as some sites reject requests without a user agent.
+const userAgent = "hnkeep (+https://github.com/akhdanfadh/hnkeep)"
+
+// Resolver follows the redirects of URLs.
+type Resolver struct {
+	httpClient *http.Client
+}
+
+// Option configures the Resolver.
+type Option func(*Resolver)
+
+// NewResolver creates a new Resolver with the given options.
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.httpClient.CheckRedirect = checkRedirect
+	return r
+}
+
+// WithHTTPClient sets a custom HTTP client. Its redirect policy is replaced by the resolver's.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Resolver) {
+		r.httpClient = client
+	}
+}
+
+// WithTimeout sets the timeout of resolving a URL, including all redirects.
+func WithTimeout(d time.Duration) Option {
+	return func(r *Resolver) {
+		r.httpClient.Timeout = d
+	}
+}
+
+// checkRedirect follows up to maxRedirects redirects, like the default policy, with a clearer error.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return nil
+}
+
+// Resolve returns the URL the given one finally redirects to, or the URL itself if it doesn't redirect.
+//
+// It tries a HEAD request first, falling back to GET (without reading the body) for servers that
+// don't support HEAD. A destination responding with an HTTP error is an error, since it is
+// no better than the original URL.
+func (r *Resolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	resp, err := r.do(ctx, http.MethodHead, rawURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp, err = r.do(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 400 {
+		return "", &StatusError{URL: resp.Request.URL.String(), StatusCode: resp.StatusCode}
+	}
+	return resp.Request.URL.String(), nil
+}
+
+// StatusError is returned by Resolve when the destination responds with an HTTP error.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s responded with HTTP %d", e.URL, e.StatusCode)
+}
+
+// Dead reports whether the URL is a dead link: it (or its redirect destination) is gone with
+// HTTP 404 or 410, or its domain no longer exists. Errors that may be temporary, e.g., timeouts
+// or server errors, are returned instead, as they don't tell whether the link is dead.
+func (r *Resolver) Dead(ctx context.Context, rawURL string) (bool, error) {
+	_, err := r.Resolve(ctx, rawURL)
+	if err == nil {
+		return false, nil
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone {
+			return true, nil
+		}
+		return false, err
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return true, nil
+	}
+	return false, err
+}
+
+// do sends a request following redirects, closing the response body as only the final URL is needed.
+func (r *Resolver) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096)) // lets the connection be reused for small bodies
+	_ = resp.Body.Close()
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package redirect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final?id=1", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := map[string]struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		"redirect chain":     {path: "/short", want: "/final?id=1"},
+		"no redirect":        {path: "/final", want: "/final"},
+		"head not supported": {path: "/no-head", want: "/final"},
+		"http error":         {path: "/gone", wantErr: true},
+		"redirect loop":      {path: "/loop", wantErr: true},
+	}
+
+	r := NewResolver(WithHTTPClient(server.Client()))
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), server.URL+tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Resolve() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error: %v", err)
+			}
+			if got != server.URL+tc.want {
+				t.Errorf("Resolve() = %q, want %q", got, server.URL+tc.want)
+			}
+		})
+	}
+}
+
+func TestDead(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/down", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := map[string]struct {
+		url      string
+		wantDead bool
+		wantErr  bool
+	}{
+		"alive":              {url: server.URL + "/ok"},
+		"redirect to 404":    {url: server.URL + "/moved", wantDead: true},
+		"gone":               {url: server.URL + "/gone", wantDead: true},
+		"server error":       {url: server.URL + "/down", wantErr: true},
+		"nonexistent domain": {url: "http://hnkeep-dead-link.invalid/", wantDead: true},
+	}
+
+	r := NewResolver()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dead, err := r.Dead(context.Background(), tc.url)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Dead() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if dead != tc.wantDead {
+				t.Errorf("Dead() = %v, want %v", dead, tc.wantDead)
+			}
+		})
+	}
+}
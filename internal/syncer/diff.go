@@ -0,0 +1,99 @@
+package syncer
+
+import (
+	"slices"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// Divergence is a converted bookmark whose Karakeep copy differs from it.
+type Divergence struct {
+	Bookmark  converter.Bookmark
+	RemoteURL string   // URL of the Karakeep copy
+	Fields    []string // "url" if found by provenance tag, "note" if not part of Karakeep's, "createdAt"
+}
+
+// Diff is the comparison of converted bookmarks with the bookmarks in Karakeep.
+type Diff struct {
+	Missing  []converter.Bookmark // converted but not in Karakeep
+	Diverged []Divergence         // in Karakeep with a different URL, note, or timestamp
+	Matching int                  // in Karakeep as converted
+	Stale    []string             // URLs of imports in Karakeep that weren't converted, sorted
+}
+
+// DiffBookmarks compares the converted bookmarks with the existing Karakeep bookmarks, looked up by URL
+// like NewKarakeepTarget, or else by provenance tag (see remoteIndex.lookup). A note diverges when a sync
+// would add it (see mergeNotes), so notes edited in Karakeep around the imported one still match.
+// Existing bookmarks matching no converted one are reported as stale if imported tells they were
+// imported, e.g., by their tags.
+func DiffBookmarks(bookmarks []converter.Bookmark, existing map[string]karakeep.ExistingBookmark,
+	imported func(url string, remote karakeep.ExistingBookmark) bool,
+) Diff {
+	var diff Diff
+	index := newRemoteIndex(existing)
+	matched := make(map[string]bool, len(bookmarks))
+	for _, bm := range bookmarks {
+		url, remote, found := index.lookup(bm)
+		if !found {
+			diff.Missing = append(diff.Missing, bm)
+			continue
+		}
+		matched[url] = true
+
+		var fields []string
+		if url != bm.Content.URL {
+			fields = append(fields, "url")
+		}
+		if _, changed := mergeNotes(remote.Note, bm.Note); changed {
+			fields = append(fields, "note")
+		}
+		if remote.CreatedAt != bm.CreatedAt {
+			fields = append(fields, "createdAt")
+		}
+		if len(fields) == 0 {
+			diff.Matching++
+			continue
+		}
+		diff.Diverged = append(diff.Diverged, Divergence{Bookmark: bm, RemoteURL: url, Fields: fields})
+	}
+
+	for url, remote := range existing {
+		if !matched[url] && imported(url, remote) {
+			diff.Stale = append(diff.Stale, url)
+		}
+	}
+	slices.Sort(diff.Stale)
+	return diff
+}
+
+// remoteIndex looks up the existing Karakeep bookmarks of converted ones.
+type remoteIndex struct {
+	existing map[string]karakeep.ExistingBookmark
+	byID     map[int]string // HN ID of the provenance tags -> URL
+}
+
+// newRemoteIndex indexes the existing bookmarks, keyed by URL, by their provenance tags.
+func newRemoteIndex(existing map[string]karakeep.ExistingBookmark) remoteIndex {
+	byID := make(map[int]string)
+	for url, remote := range existing {
+		for _, id := range converter.ProvenanceIDs(remote.Tags) {
+			byID[id] = url
+		}
+	}
+	return remoteIndex{existing: existing, byID: byID}
+}
+
+// lookup returns the existing bookmark of the converted one and its URL: the bookmark of the same URL,
+// or else the one sharing a provenance tag (see converter.ProvenanceTag), e.g., after its URL changed.
+func (idx remoteIndex) lookup(bm converter.Bookmark) (string, karakeep.ExistingBookmark, bool) {
+	if remote, found := idx.existing[bm.Content.URL]; found {
+		return bm.Content.URL, remote, true
+	}
+	for _, id := range converter.ProvenanceIDs(bm.Tags) {
+		if url, found := idx.byID[id]; found {
+			return url, idx.existing[url], true
+		}
+	}
+	return "", karakeep.ExistingBookmark{}, false
+}
@@ -0,0 +1,77 @@
+package syncer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+func TestDiffBookmarks(t *testing.T) {
+	bookmark := func(url string, createdAt int64, note string) converter.Bookmark {
+		return converter.Bookmark{CreatedAt: createdAt, Content: converter.BookmarkContent{URL: url}, Note: ptr(note)}
+	}
+	bookmarks := []converter.Bookmark{
+		bookmark("https://a.com", 100, "hn a"),
+		bookmark("https://b.com", 200, "hn b"),
+		bookmark("https://c.com", 300, "hn c"),
+		bookmark("https://d.com", 400, "hn d"),
+	}
+	existing := map[string]karakeep.ExistingBookmark{
+		"https://a.com": {CreatedAt: 100, Note: ptr("my thoughts\n\nhn a")}, // edited around the imported note
+		"https://b.com": {CreatedAt: 250, Note: ptr("hn b")},
+		"https://c.com": {CreatedAt: 300, Note: ptr("rewritten")},
+		"https://y.com": {Tags: []string{"hnkeep:20260101"}},
+		"https://x.com": {Tags: []string{"hnkeep:20250101"}},
+		"https://z.com": {Tags: []string{"reading"}},
+	}
+	imported := func(_ string, remote karakeep.ExistingBookmark) bool {
+		return slices.ContainsFunc(remote.Tags, func(tag string) bool { return strings.HasPrefix(tag, "hnkeep:") })
+	}
+
+	diff := DiffBookmarks(bookmarks, existing, imported)
+
+	if len(diff.Missing) != 1 || diff.Missing[0].Content.URL != "https://d.com" {
+		t.Errorf("Missing = %v, want only https://d.com", diff.Missing)
+	}
+	if diff.Matching != 1 {
+		t.Errorf("Matching = %d, want 1", diff.Matching)
+	}
+	want := map[string][]string{"https://b.com": {"createdAt"}, "https://c.com": {"note"}}
+	if len(diff.Diverged) != len(want) {
+		t.Fatalf("Diverged = %v, want %v", diff.Diverged, want)
+	}
+	for _, d := range diff.Diverged {
+		if !slices.Equal(d.Fields, want[d.Bookmark.Content.URL]) {
+			t.Errorf("Diverged %s fields = %v, want %v", d.Bookmark.Content.URL, d.Fields, want[d.Bookmark.Content.URL])
+		}
+	}
+	if wantStale := []string{"https://x.com", "https://y.com"}; !slices.Equal(diff.Stale, wantStale) {
+		t.Errorf("Stale = %v, want %v", diff.Stale, wantStale)
+	}
+}
+
+func TestDiffBookmarks_Provenance(t *testing.T) {
+	bookmarks := []converter.Bookmark{{
+		CreatedAt: 100,
+		Tags:      converter.BookmarkTags{converter.ProvenanceTag(1)},
+		Content:   converter.BookmarkContent{URL: "https://a.com/old"},
+	}}
+	existing := map[string]karakeep.ExistingBookmark{
+		"https://a.com/new": {CreatedAt: 100, Tags: []string{converter.ProvenanceTag(1)}},
+	}
+
+	diff := DiffBookmarks(bookmarks, existing, func(string, karakeep.ExistingBookmark) bool { return true })
+
+	if len(diff.Missing) != 0 || len(diff.Stale) != 0 {
+		t.Errorf("Missing = %v, Stale = %v, want none as the provenance tag matches", diff.Missing, diff.Stale)
+	}
+	if len(diff.Diverged) != 1 || diff.Diverged[0].RemoteURL != "https://a.com/new" || !slices.Equal(diff.Diverged[0].Fields, []string{"url"}) {
+		t.Errorf("Diverged = %+v, want https://a.com/new diverging by url", diff.Diverged)
+	}
+	if got := VerifyBookmarks(bookmarks, existing); len(got) != 0 {
+		t.Errorf("VerifyBookmarks() = %+v, want none", got)
+	}
+}
@@ -1,2 +1,3 @@
-// Package syncer provides functionality for syncing converted bookmarks to Karakeep using its API.
+// Package syncer provides functionality for syncing converted bookmarks to a bookmark service
+// such as Karakeep, through the Target interface.
 package syncer
@@ -0,0 +1,186 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// karakeepTarget is the Target for the Karakeep API.
+type karakeepTarget struct {
+	client *karakeep.Client
+	index  remoteIndex
+	search bool // look up each URL with Karakeep's search instead of in index
+}
+
+// NewKarakeepTarget returns a Target for the Karakeep API. Exists looks up the given bookmarks
+// pre-fetched by URL (nil = none), which also covers asset bookmarks that Karakeep's create
+// endpoint doesn't deduplicate against, or else by provenance tag like Diff (see remoteIndex.lookup).
+func NewKarakeepTarget(client *karakeep.Client, existing map[string]karakeep.ExistingBookmark) Target {
+	return &karakeepTarget{client: client, index: newRemoteIndex(existing)}
+}
+
+// NewKarakeepSearchTarget is like NewKarakeepTarget, but Exists looks up each URL with Karakeep's search
+// (see karakeep.Client.FindBookmarkByURL), one request per bookmark instead of pre-fetching the whole
+// library, which is faster for a few bookmarks synced to a large library. It doesn't fall back to
+// provenance tags.
+func NewKarakeepSearchTarget(client *karakeep.Client) Target {
+	return &karakeepTarget{client: client, search: true}
+}
+
+func (t *karakeepTarget) Exists(ctx context.Context, bm converter.Bookmark) (Remote, bool, error) {
+	_, existing, found := t.index.lookup(bm)
+	if t.search {
+		var err error
+		if existing, found, err = t.client.FindBookmarkByURL(ctx, bm.Content.URL); err != nil {
+			return Remote{}, false, err
+		}
+	}
+	if !found {
+		return Remote{}, false, nil
+	}
+	return Remote{
+		ID:         existing.ID,
+		CreatedAt:  existing.CreatedAt,
+		Title:      existing.Title,
+		Note:       existing.Note,
+		Summary:    existing.Summary,
+		Archived:   existing.Archived,
+		Favourited: existing.Favourited,
+		Tags:       existing.Tags,
+	}, true, nil
+}
+
+func (t *karakeepTarget) Create(ctx context.Context, bm converter.Bookmark) (Remote, bool, error) {
+	resp, exists, err := t.client.CreateBookmark(ctx, createRequest(bm))
+	if err != nil {
+		return Remote{}, false, noteLimitError(err)
+	}
+	remote, err := createdRemote(resp)
+	if err != nil {
+		return Remote{}, false, err
+	}
+	return remote, exists, nil
+}
+
+func (t *karakeepTarget) CreateBatch(ctx context.Context, bms []converter.Bookmark) ([]Created, error) {
+	reqs := make([]*karakeep.CreateBookmarkRequest, len(bms))
+	for i, bm := range bms {
+		reqs[i] = createRequest(bm)
+	}
+	results, err := t.client.CreateBookmarks(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]Created, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			created[i].Err = noteLimitError(result.Err)
+			continue
+		}
+		created[i].Remote, created[i].Err = createdRemote(result.Bookmark)
+		created[i].Exists = result.Exists
+	}
+	return created, nil
+}
+
+// createRequest returns the request creating the converted bookmark.
+func createRequest(bm converter.Bookmark) *karakeep.CreateBookmarkRequest {
+	createdAt := unixToISO8601(bm.CreatedAt)
+	req := karakeep.NewCreateBookmarkRequest(bm.Content.URL, createdAt, bm.Title, bm.Note)
+	if bm.Content.Type == "text" {
+		req = karakeep.NewCreateTextBookmarkRequest(bm.Content.Text, bm.Content.URL, createdAt, bm.Title, bm.Note)
+	}
+	req.Summary = bm.Summary
+	if bm.Archived {
+		req.Archived = &bm.Archived
+	}
+	if bm.Favourited {
+		req.Favourited = &bm.Favourited
+	}
+	return req
+}
+
+// createdRemote returns the Remote of a created bookmark, or of the existing one returned instead.
+func createdRemote(resp *karakeep.CreateBookmarkResponse) (Remote, error) {
+	created, err := iso8601ToUnix(resp.CreatedAt)
+	if err != nil {
+		return Remote{}, fmt.Errorf("parsing existing createdAt: %w", err)
+	}
+	return Remote{
+		ID:         resp.ID,
+		CreatedAt:  created,
+		Title:      resp.Title,
+		Note:       resp.Note,
+		Summary:    resp.Summary,
+		Archived:   resp.Archived,
+		Favourited: resp.Favourited,
+	}, nil
+}
+
+func (t *karakeepTarget) Update(ctx context.Context, id string, changes Changes) error {
+	req := karakeep.UpdateBookmarkRequest{
+		Title:      changes.Title,
+		Note:       changes.Note,
+		Summary:    changes.Summary,
+		Archived:   changes.Archived,
+		Favourited: changes.Favourited,
+	}
+	if changes.CreatedAt != nil {
+		createdAt := unixToISO8601(*changes.CreatedAt)
+		req.CreatedAt = &createdAt
+	}
+	return noteLimitError(t.client.UpdateBookmark(ctx, id, req))
+}
+
+// noteLimitError wraps err in a NoteLimitError if Karakeep rejected the note as too long.
+func noteLimitError(err error) error {
+	var httpErr karakeep.HTTPError
+	if errors.As(err, &httpErr) {
+		if limit, ok := httpErr.FieldLimit("note"); ok {
+			return &NoteLimitError{Limit: limit, Err: err}
+		}
+	}
+	return err
+}
+
+func (t *karakeepTarget) AttachTags(ctx context.Context, id string, tags []string) error {
+	return t.client.AttachTags(ctx, id, tags)
+}
+
+func (t *karakeepTarget) DetachTags(ctx context.Context, id string, tags []string) error {
+	return t.client.DetachTags(ctx, id, tags)
+}
+
+// CreateHighlights creates the highlights unanchored, as their text is usually not part of the bookmarked page.
+func (t *karakeepTarget) CreateHighlights(ctx context.Context, id string, highlights []converter.Highlight) error {
+	for _, h := range highlights {
+		req := karakeep.CreateHighlightRequest{BookmarkID: id, Text: &h.Text, Note: &h.Note}
+		if err := t.client.CreateHighlight(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *karakeepTarget) Crawl(ctx context.Context, id string) error {
+	return t.client.RecrawlBookmark(ctx, id)
+}
+
+// unixToISO8601 converts a Unix timestamp (in seconds) to an ISO8601 date string.
+func unixToISO8601(ts int64) string {
+	return time.Unix(ts, 0).Format(time.RFC3339)
+}
+
+// iso8601ToUnix converts an ISO8601 date string to a Unix timestamp (in seconds).
+func iso8601ToUnix(iso string) (int64, error) {
+	t, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		return 0, fmt.Errorf("parsing ISO8601 date %q: %w", iso, err)
+	}
+	return t.Unix(), nil
+}
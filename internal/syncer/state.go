@@ -0,0 +1,141 @@
+package syncer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// State tracks, per bookmark URL, what hnkeep pushed to Karakeep and what it last saw there.
+//
+// It records which bookmarks have been synced, and enables two-way sync: edits made in Karakeep
+// are pulled into the state, and a note or tag that hnkeep pushed before but the user has since
+// removed is not pushed again on later runs.
+type State struct {
+	mu        sync.Mutex
+	Bookmarks map[string]*StateEntry `json:"bookmarks"`
+}
+
+// StateEntry is the sync state of a single bookmark.
+type StateEntry struct {
+	ID         string   `json:"id"`
+	PushedNote string   `json:"pushedNote,omitempty"` // note hnkeep merged into the bookmark
+	PushedTags []string `json:"pushedTags,omitempty"` // tags hnkeep attached to the bookmark
+	Note       *string  `json:"note,omitempty"`       // note last seen in Karakeep
+	Tags       []string `json:"tags,omitempty"`       // tags last seen in Karakeep
+}
+
+// NewState creates an empty State.
+func NewState() *State {
+	return &State{Bookmarks: make(map[string]*StateEntry)}
+}
+
+// LoadState reads the state from the given path. A missing file yields an empty State.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st := NewState()
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	if st.Bookmarks == nil { // e.g., file contains "{}"
+		st.Bookmarks = make(map[string]*StateEntry)
+	}
+	return st, nil
+}
+
+// Save writes the state to the given path, creating parent directories as needed.
+// The file is written to a temporary path first so a crash never leaves a truncated state.
+func (st *State) Save(path string) error {
+	st.mu.Lock()
+	data, err := json.Marshal(st)
+	st.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Pull records the current note and tags of tracked bookmarks as last seen in Karakeep.
+// Bookmarks not tracked yet are ignored, since hnkeep has not pushed anything to them.
+func (st *State) Pull(existing map[string]karakeep.ExistingBookmark) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for url, entry := range st.Bookmarks {
+		bm, ok := existing[url]
+		if !ok || bm.ID != entry.ID {
+			continue // deleted (or recreated) in Karakeep, keep the last known state
+		}
+		entry.Note = bm.Note
+		entry.Tags = bm.Tags
+	}
+}
+
+// reconcile drops the incoming note and tags that were pushed before to the bookmark with the given
+// URL and ID but have since been removed by the user. currentTags are the bookmark's tags in Karakeep.
+func (st *State) reconcile(url, id string, currentTags []string, note *string, tags []string) (*string, []string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	entry, ok := st.Bookmarks[url]
+	if !ok || entry.ID != id {
+		return note, tags // never pushed to this bookmark
+	}
+
+	// a note pushed before is either still there (merge is a no-op) or was deleted on purpose
+	if note != nil && *note == entry.PushedNote {
+		note = nil
+	}
+
+	kept := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if slices.Contains(entry.PushedTags, tag) && !slices.Contains(currentTags, tag) {
+			continue // removed by the user
+		}
+		kept = append(kept, tag)
+	}
+	return note, kept
+}
+
+// Synced reports whether the bookmark with the given URL has been synced before.
+func (st *State) Synced(url string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.Bookmarks[url]
+	return ok
+}
+
+// record marks the note and tags as pushed to the bookmark with the given URL and ID.
+func (st *State) record(url, id string, note *string, tags []string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	entry, ok := st.Bookmarks[url]
+	if !ok || entry.ID != id {
+		entry = &StateEntry{ID: id} // new, or recreated after the user deleted it
+		st.Bookmarks[url] = entry
+	}
+	if note != nil && *note != "" {
+		entry.PushedNote = *note
+	}
+	for _, tag := range tags {
+		if !slices.Contains(entry.PushedTags, tag) {
+			entry.PushedTags = append(entry.PushedTags, tag)
+		}
+	}
+}
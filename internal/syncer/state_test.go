@@ -0,0 +1,95 @@
+package syncer
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+func TestStateReconcile(t *testing.T) {
+	const url = "https://example.com"
+
+	t.Run("untracked bookmark passes through", func(t *testing.T) {
+		st := NewState()
+		note, tags := st.reconcile(url, "bm-1", nil, ptr("hn note"), []string{"hn"})
+		if note == nil || *note != "hn note" {
+			t.Errorf("note = %v, want %q", note, "hn note")
+		}
+		if !slices.Equal(tags, []string{"hn"}) {
+			t.Errorf("tags = %v, want [hn]", tags)
+		}
+	})
+
+	t.Run("removed note and tag are not pushed again", func(t *testing.T) {
+		st := NewState()
+		st.record(url, "bm-1", ptr("hn note"), []string{"hn", "news"})
+
+		// user deleted the note and the "news" tag in Karakeep
+		note, tags := st.reconcile(url, "bm-1", []string{"hn"}, ptr("hn note"), []string{"hn", "news", "new"})
+		if note != nil {
+			t.Errorf("note = %q, want nil", *note)
+		}
+		if want := []string{"hn", "new"}; !slices.Equal(tags, want) {
+			t.Errorf("tags = %v, want %v", tags, want)
+		}
+	})
+
+	t.Run("changed note is pushed", func(t *testing.T) {
+		st := NewState()
+		st.record(url, "bm-1", ptr("old note"), nil)
+		note, _ := st.reconcile(url, "bm-1", nil, ptr("new note"), nil)
+		if note == nil || *note != "new note" {
+			t.Errorf("note = %v, want %q", note, "new note")
+		}
+	})
+
+	t.Run("recreated bookmark starts fresh", func(t *testing.T) {
+		st := NewState()
+		st.record(url, "bm-1", ptr("hn note"), []string{"hn"})
+		note, tags := st.reconcile(url, "bm-2", nil, ptr("hn note"), []string{"hn"})
+		if note == nil || !slices.Equal(tags, []string{"hn"}) {
+			t.Errorf("got note=%v tags=%v, want both passed through", note, tags)
+		}
+	})
+}
+
+func TestStateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "state.json")
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState on missing file: %v", err)
+	}
+	if len(loaded.Bookmarks) != 0 {
+		t.Fatalf("expected empty state, got %d entries", len(loaded.Bookmarks))
+	}
+
+	st := NewState()
+	st.record("https://a.com", "bm-1", ptr("note"), []string{"hn"})
+	st.Pull(map[string]karakeep.ExistingBookmark{
+		"https://a.com": {ID: "bm-1", Note: ptr("edited"), Tags: []string{"hn", "mine"}},
+	})
+	if !st.Synced("https://a.com") || st.Synced("https://b.com") {
+		t.Error("Synced should report only recorded URLs")
+	}
+	if err := st.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err = LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	entry := loaded.Bookmarks["https://a.com"]
+	if entry == nil {
+		t.Fatal("entry missing after reload")
+	}
+	if entry.ID != "bm-1" || entry.PushedNote != "note" || !slices.Equal(entry.PushedTags, []string{"hn"}) {
+		t.Errorf("unexpected pushed state: %+v", entry)
+	}
+	if entry.Note == nil || *entry.Note != "edited" || !slices.Equal(entry.Tags, []string{"hn", "mine"}) {
+		t.Errorf("unexpected last-seen state: %+v", entry)
+	}
+}
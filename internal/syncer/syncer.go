@@ -2,18 +2,22 @@ package syncer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
-	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 
+	"github.com/akhdanfadh/hnkeep/internal/adaptive"
 	"github.com/akhdanfadh/hnkeep/internal/converter"
-	"github.com/akhdanfadh/hnkeep/internal/karakeep"
 	"github.com/akhdanfadh/hnkeep/internal/logger"
 )
 
-// noteSeparator is used to join notes when merging with existing Karakeep notes.
+// noteSeparator is used to join notes when merging with existing notes.
 const (
 	noteSeparator      = "\n\n---\n\n"
 	defaultConcurrency = 5
@@ -21,26 +25,43 @@ const (
 
 // Syncer represents the syncer pipeline orchestrator.
 type Syncer struct {
-	client            *karakeep.Client
-	concurrency       int
-	logger            logger.Logger
-	progresser        logger.Progresser
-	existingBookmarks map[string]karakeep.ExistingBookmark
+	target      Target
+	concurrency int
+	limiter     *adaptive.Limiter // bounds parallel syncs, defaults to a limiter of concurrency workers
+	logger      logger.Logger
+	progresser  logger.Progresser
+	onResult    ResultFunc
+	state       *State
+	twoWay      bool
+	removeTags  []string
+	titles      bool         // update differing titles of existing bookmarks
+	crawl       bool         // trigger a crawl of created link bookmarks
+	batchSize   int          // create new bookmarks in batches of this size up front (0 = one by one)
+	notes       NoteStrategy // how notes of existing bookmarks are updated
+	resolve     ConflictFunc
+	resolveMu   sync.Mutex   // serializes resolve calls, e.g., interactive prompts
+	maxFailures int          // failures tolerated before aborting the sync (-1 = unlimited)
+	noteLimit   atomic.Int64 // maximum note length learned from a NoteLimitError (0 = unknown)
 }
 
 // Option configures the Syncer.
 type Option func(s *Syncer)
 
-// New creates a new Syncer with the given client and options.
-func New(client *karakeep.Client, opts ...Option) *Syncer {
+// New creates a new Syncer pushing to the given target with the given options.
+func New(target Target, opts ...Option) *Syncer {
 	s := &Syncer{
-		client:      client,
+		target:      target,
 		concurrency: defaultConcurrency,
 		logger:      logger.Noop(),
+		maxFailures: -1,
+		notes:       NotesMerge,
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.limiter == nil {
+		s.limiter = adaptive.NewLimiter(s.concurrency)
+	}
 	return s
 }
 
@@ -51,6 +72,14 @@ func WithConcurrency(n int) Option {
 	}
 }
 
+// WithLimiter sets the limiter bounding parallel syncs, overriding WithConcurrency.
+// Sharing it with the target client's rate limit hook lets the concurrency adapt to 429 responses.
+func WithLimiter(l *adaptive.Limiter) Option {
+	return func(s *Syncer) {
+		s.limiter = l
+	}
+}
+
 // WithLogger sets the logger for info/warn/error messages.
 func WithLogger(l logger.Logger) Option {
 	return func(s *Syncer) {
@@ -65,10 +94,117 @@ func WithProgress(p logger.Progresser) Option {
 	}
 }
 
-// WithExistingBookmarks sets pre-fetched bookmarks for client-side deduplication.
-func WithExistingBookmarks(e map[string]karakeep.ExistingBookmark) Option {
+// WithState records the notes and tags pushed to each bookmark in the given state (see State).
+func WithState(st *State) Option {
+	return func(s *Syncer) {
+		s.state = st
+	}
+}
+
+// WithTwoWay enables two-way sync: notes and tags recorded in the state (see WithState)
+// but since removed from a bookmark the target already has (see Target.Exists) are not pushed again.
+// The state should have pulled the target's bookmarks (see State.Pull) before syncing.
+func WithTwoWay() Option {
+	return func(s *Syncer) {
+		s.twoWay = true
+	}
+}
+
+// WithRemoveTags sets tags to detach from bookmarks that already exist in the target,
+// e.g., to clean up tags of previous imports. Newly created bookmarks are not affected.
+func WithRemoveTags(tags []string) Option {
+	return func(s *Syncer) {
+		s.removeTags = tags
+	}
+}
+
+// NoteStrategy is how the note of an existing bookmark is updated with the converted note.
+// Whatever the strategy, an empty converted note never clears an existing one.
+type NoteStrategy string
+
+const (
+	NotesMerge        NoteStrategy = "merge"         // append the converted note unless already part of the existing one (see mergeNotes)
+	NotesReplace      NoteStrategy = "replace"       // overwrite the existing note with the converted one
+	NotesKeepExisting NoteStrategy = "keep-existing" // set the converted note only on bookmarks without a note
+	NotesSkip         NoteStrategy = "skip"          // never touch the notes of existing bookmarks
+)
+
+// WithNoteStrategy sets how notes of existing bookmarks are updated, NotesMerge by default.
+// New bookmarks are always created with the converted note.
+func WithNoteStrategy(strategy NoteStrategy) Option {
+	return func(s *Syncer) {
+		s.notes = strategy
+	}
+}
+
+// WithUpdateTitles updates the title of existing bookmarks whose title differs from the converted one,
+// e.g., a generic page title crawled by Karakeep. Without it, titles of existing bookmarks are left as is.
+func WithUpdateTitles() Option {
+	return func(s *Syncer) {
+		s.titles = true
+	}
+}
+
+// WithTriggerCrawl asks the target to crawl each newly created link bookmark (see Target.Crawl), e.g., so
+// Karakeep archives and tags it right away. A failed crawl request is logged as a warning only.
+func WithTriggerCrawl() Option {
+	return func(s *Syncer) {
+		s.crawl = true
+	}
+}
+
+// WithBatchCreate creates the bookmarks the target doesn't have in batches of up to n (see Target.CreateBatch)
+// before syncing them, instead of one request each. Bookmarks failing in a batch are created one by one
+// as usual, as are all remaining ones once the target fails a whole batch, e.g., as it doesn't support them.
+func WithBatchCreate(n int) Option {
+	return func(s *Syncer) {
+		s.batchSize = n
+	}
+}
+
+// WithMaxFailures aborts the sync once more than n bookmarks failed: no more bookmarks are pushed,
+// and those in flight are cancelled and left out of the result, like on context cancellation.
+func WithMaxFailures(n int) Option {
+	return func(s *Syncer) {
+		s.maxFailures = n
+	}
+}
+
+// Resolution is how a conflict between an existing bookmark and the converted one is resolved.
+type Resolution int
+
+const (
+	ResolveMerge   Resolution = iota // apply the default update, e.g., append the note (see mergeNotes)
+	ResolveReplace                   // overwrite the existing note and timestamp with the converted ones
+	ResolveSkip                      // leave the existing bookmark untouched, including its tags
+)
+
+// Conflict is an existing bookmark whose note or timestamp differs from the converted one.
+type Conflict struct {
+	Remote   Remote
+	Bookmark converter.Bookmark
+}
+
+// ConflictFunc decides how to resolve a conflict. Calls are serialized.
+type ConflictFunc func(Conflict) Resolution
+
+// WithConflictResolver sets a function deciding per conflict (see Conflict) whether to merge,
+// replace, or skip. Without it, conflicts are always merged. Dry runs (see Plan) ignore it.
+func WithConflictResolver(fn ConflictFunc) Option {
+	return func(s *Syncer) {
+		s.resolve = fn
+	}
+}
+
+// ResultFunc is called once per processed bookmark with its sync status and error (if failed).
+type ResultFunc func(bookmark converter.Bookmark, status SyncStatus, err error)
+
+// WithOnResult sets a callback invoked for every processed bookmark.
+// Callbacks run sequentially from the result loop, so no extra synchronization is needed.
+// Bookmarks still in flight when the context is cancelled are not reported.
+func WithOnResult(fn ResultFunc) Option {
 	return func(s *Syncer) {
-		s.existingBookmarks = e
+		s.onResult = fn
 	}
 }
 
@@ -82,41 +218,117 @@ const (
 	SyncSkipped
 )
 
-// Sync synchronizes the given converted bookmarks to Karakeep.
+// String returns the lowercase name of the sync status.
+func (s SyncStatus) String() string {
+	switch s {
+	case SyncCreated:
+		return "created"
+	case SyncUpdated:
+		return "updated"
+	case SyncSkipped:
+		return "skipped"
+	default:
+		return "failed"
+	}
+}
+
+// PlanEntry describes the action Sync would take for a single bookmark.
+type PlanEntry struct {
+	URL     string
+	Status  SyncStatus
+	Changes []string // fields that would be updated (only for SyncUpdated)
+	Err     error    // only for SyncFailed
+}
+
+// Plan returns the actions Sync would take for the given bookmarks without writing anything.
+//
+// Decisions are based solely on the bookmarks the target already knows of (see Target.Exists):
+// URLs not found there would be created, and found ones go through the same update logic as Sync.
+// Attached tags are not part of the plan since attaching them is idempotent, but removed ones are
+// (see WithRemoveTags).
+func (s *Syncer) Plan(ctx context.Context, bookmarks []converter.Bookmark) []PlanEntry {
+	plan := make([]PlanEntry, 0, len(bookmarks))
+	for _, bm := range bookmarks {
+		entry := PlanEntry{URL: bm.Content.URL}
+
+		existing, found, err := s.target.Exists(ctx, bm)
+		if err != nil {
+			entry.Status, entry.Err = SyncFailed, fmt.Errorf("looking up bookmark: %w", err)
+			plan = append(plan, entry)
+			continue
+		}
+		if !found {
+			entry.Status = SyncCreated
+			plan = append(plan, entry)
+			continue
+		}
+		if s.twoWay && s.state != nil {
+			bm.Note, bm.Tags = s.state.reconcile(bm.Content.URL, existing.ID, existing.Tags, bm.Note, bm.Tags)
+		}
+
+		changes, needsUpdate := planUpdate(existing, bm, s.notes)
+		if s.titles && planTitle(existing, bm, &changes) {
+			needsUpdate = true
+		}
+		removed := s.tagsToRemove(existing.Tags, true)
+		switch {
+		case needsUpdate || len(removed) > 0:
+			entry.Status, entry.Changes = SyncUpdated, updateFields(changes)
+			for _, tag := range removed {
+				entry.Changes = append(entry.Changes, "-tag "+tag)
+			}
+		default:
+			entry.Status = SyncSkipped
+		}
+		plan = append(plan, entry)
+	}
+	return plan
+}
+
+// Sync synchronizes the given converted bookmarks to the target.
 // Errors are logged inline via the logger; the returned map contains counts per status.
 func (s *Syncer) Sync(ctx context.Context, bookmarks []converter.Bookmark) map[SyncStatus]int {
+	ctx, abort := context.WithCancel(ctx) // for WithMaxFailures
+	defer abort()
+
 	type syncTaskResult struct {
-		url    string
-		status SyncStatus
-		err    error
+		bookmark converter.Bookmark
+		status   SyncStatus
+		err      error
 	}
 	syncTaskCh := make(chan syncTaskResult, len(bookmarks))
-	semaphoreCh := make(chan struct{}, s.concurrency)
 
 	total := len(bookmarks)
 	var counter atomic.Int32 // for logging progress
 
-	// sync bookmarks with semaphore
+	var created []*Created // for WithBatchCreate
+	if s.batchSize > 0 {
+		created = s.createBatches(ctx, bookmarks)
+	}
+
+	// sync bookmarks, bounded by the limiter
 	var wg sync.WaitGroup
-	for _, bm := range bookmarks {
+	for i, bm := range bookmarks {
+		var batched *Created
+		if created != nil {
+			batched = created[i]
+		}
 		wg.Add(1)
 		go func(bookmark converter.Bookmark) {
 			defer wg.Done()
 
 			// check for cancellation before acquiring
-			select {
-			case <-ctx.Done():
+			if s.limiter.Acquire(ctx) != nil {
 				return
-			case semaphoreCh <- struct{}{}: // acquire
 			}
-			defer func() { <-semaphoreCh }() // release
+			defer s.limiter.Release()
 
 			// check again after acquiring (in case cancelled while waiting)
 			if ctx.Err() != nil {
 				return
 			}
 
-			status, err := s.syncTask(ctx, bookmark)
+			status, err := s.syncTask(ctx, bookmark, batched)
 			// skip sending result after cancellation
 			if ctx.Err() != nil {
 				return
@@ -127,7 +339,7 @@ func (s *Syncer) Sync(ctx context.Context, bookmarks []converter.Bookmark) map[S
 				s.progresser.Update(int(n), total)
 			}
 			s.logger.Info("pushed %d/%d", n, total)
-			syncTaskCh <- syncTaskResult{url: bookmark.Content.URL, status: status, err: err}
+			syncTaskCh <- syncTaskResult{bookmark: bookmark, status: status, err: err}
 		}(bm)
 	}
 
@@ -141,7 +353,14 @@ func (s *Syncer) Sync(ctx context.Context, bookmarks []converter.Bookmark) map[S
 	for r := range syncTaskCh {
 		status[r.status]++
 		if r.status == SyncFailed {
-			s.logger.Warn("failed to push %s: %v", r.url, r.err)
+			s.logger.Warn("failed to push %s: %v", r.bookmark.Content.URL, r.err)
+		}
+		if s.onResult != nil {
+			s.onResult(r.bookmark, r.status, r.err)
+		}
+		if s.maxFailures >= 0 && status[SyncFailed] > s.maxFailures {
+			s.logger.Error("aborting sync after %d failure(s)", status[SyncFailed])
+			abort()
 		}
 
 		// check for cancellation after processing
@@ -152,83 +371,350 @@ func (s *Syncer) Sync(ctx context.Context, bookmarks []converter.Bookmark) map[S
 	return status
 }
 
-// syncTask performs the sync operation for a single bookmark.
+// createBatches creates the bookmarks the target doesn't have in batches (see WithBatchCreate) and returns
+// each one's result, nil for those left to syncTask: existing ones, ones with a note over the learned limit,
+// and failed ones, which are tried again one by one.
+func (s *Syncer) createBatches(ctx context.Context, bookmarks []converter.Bookmark) []*Created {
+	created := make([]*Created, len(bookmarks))
+	var batch []int // indices of the bookmarks to create
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		bms := make([]converter.Bookmark, len(batch))
+		for j, i := range batch {
+			bms[j] = bookmarks[i]
+		}
+		results, err := s.target.CreateBatch(ctx, bms)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("creating bookmarks in batches failed, creating them one by one: %v", err)
+			}
+			return false
+		}
+		for j := range results {
+			if err := results[j].Err; err != nil {
+				s.learnNoteLimit(err)
+				continue
+			}
+			created[batch[j]] = &results[j]
+		}
+		s.logger.Info("created a batch of %d bookmarks", len(batch))
+		batch = batch[:0]
+		return true
+	}
+
+	for i, bm := range bookmarks {
+		if ctx.Err() != nil {
+			return created
+		}
+		if !s.noteFits(bm.Note) {
+			continue
+		}
+		if _, found, err := s.target.Exists(ctx, bm); err != nil || found {
+			continue
+		}
+		batch = append(batch, i)
+		if len(batch) == s.batchSize && !flush() {
+			return created
+		}
+	}
+	flush()
+	return created
+}
+
+// syncTask performs the sync operation for a single bookmark, created in a batch up front unless nil.
 //
 // The following business logic is made:
-//  1. Check pre-fetched map first (client-side dedup for asset URLs).
-//  2. Create the bookmark (or get existing) by passing url, createdAt, title, and note.
+//  1. Check whether the target already knows the bookmark (see Target.Exists).
+//  2. Otherwise create the bookmark (or get existing) by passing url, createdAt, title, and note.
+//     A bookmark created in a batch (see WithBatchCreate) skips both.
 //  3. Since attaching tags is idempotent, always attach tags if converted has any.
-//  4. If it is newly created, we're done.
-//  5. If the (unedited) existing is returned, we check whether to update createdAt (by earliest) and/or note (see mergeNotes).
-func (s *Syncer) syncTask(ctx context.Context, convertedBM converter.Bookmark) (SyncStatus, error) {
-	var karakeepBM *karakeep.CreateBookmarkResponse
+//  4. If it is newly created, create its highlights, if any, trigger its crawl (see WithTriggerCrawl), and
+//     we're done. Highlights are never added to existing bookmarks, as they can't be matched up with ones
+//     created by earlier runs.
+//  5. If the (unedited) existing is returned, we check whether to update createdAt (by earliest), note (see WithNoteStrategy),
+//     and/or archived/favourited state (only set, never cleared), and detach tags to remove (see WithRemoveTags).
+//     With WithUpdateTitles, a differing title is updated too.
+//
+// With a conflict resolver (see WithConflictResolver), existing bookmarks with a differing note or timestamp
+// are resolved before step 3, so skipped ones are left untouched.
+//
+// Once the target rejects a note as too long (see NoteLimitError), the bookmark is tried again with the note
+// truncated to the limit, and notes of later bookmarks are truncated up front, each with a warning.
+//
+// With a state (see WithState), what was pushed is recorded on success. With two-way sync (see WithTwoWay),
+// notes and tags removed by the user from a known bookmark are not pushed again.
+func (s *Syncer) syncTask(ctx context.Context, convertedBM converter.Bookmark, batched *Created) (status SyncStatus, err error) {
+	var remote Remote
 	var alreadyExists bool
-
-	// client-side dedup: check pre-fetched map first
-	if s.existingBookmarks != nil {
-		if existing, found := s.existingBookmarks[convertedBM.Content.URL]; found {
-			karakeepBM = &karakeep.CreateBookmarkResponse{
-				ID:        existing.ID,
-				CreatedAt: unixToISO8601(existing.CreatedAt),
-				Note:      existing.Note,
+	tagsKnown := false // only for bookmarks found by Exists
+	declined := false  // conflict resolved by skipping, nothing pushed
+	convertedBM.Note = s.fitNote(convertedBM.Content.URL, convertedBM.Note)
+
+	if s.state != nil {
+		pushedNote, pushedTags := convertedBM.Note, convertedBM.Tags
+		defer func() {
+			if status != SyncFailed && !declined {
+				s.state.record(convertedBM.Content.URL, remote.ID, pushedNote, pushedTags)
 			}
-			alreadyExists = true
-		}
+		}()
 	}
 
-	// only call api if not found in pre-fetched
-	if karakeepBM == nil {
-		var err error
+	// client-side dedup: check known bookmarks first
+	if batched != nil {
+		remote, alreadyExists = batched.Remote, batched.Exists
+	} else if remote, alreadyExists, err = s.target.Exists(ctx, convertedBM); err != nil {
+		return SyncFailed, fmt.Errorf("looking up bookmark: %w", err)
+	} else if alreadyExists {
+		tagsKnown = true
+		if s.twoWay && s.state != nil {
+			convertedBM.Note, convertedBM.Tags = s.state.reconcile(convertedBM.Content.URL,
+				remote.ID, remote.Tags, convertedBM.Note, convertedBM.Tags)
+		}
+	} else {
 		// create or get existing bookmark
-		karakeepBM, alreadyExists, err = s.client.CreateBookmark(ctx,
-			convertedBM.Content.URL,
-			unixToISO8601(convertedBM.CreatedAt),
-			convertedBM.Title,
-			convertedBM.Note,
-		)
+		remote, alreadyExists, err = s.target.Create(ctx, convertedBM)
+		if s.learnNoteLimit(err) {
+			convertedBM.Note = s.fitNote(convertedBM.Content.URL, convertedBM.Note)
+			remote, alreadyExists, err = s.target.Create(ctx, convertedBM)
+		}
 		if err != nil {
 			return SyncFailed, fmt.Errorf("creating bookmark: %w", err)
 		}
 	}
 
+	var changes Changes
+	var needsUpdate bool
+	if alreadyExists {
+		changes, needsUpdate = planUpdate(remote, convertedBM, s.notes)
+		if s.resolve != nil && isConflict(remote, convertedBM, changes) {
+			switch s.resolveConflict(Conflict{Remote: remote, Bookmark: convertedBM}) {
+			case ResolveSkip:
+				declined = true
+				s.logger.Info("skipped (conflict): %s", convertedBM.Content.URL)
+				return SyncSkipped, nil
+			case ResolveReplace:
+				changes, needsUpdate = planReplace(remote, convertedBM)
+			}
+		}
+		if s.titles && planTitle(remote, convertedBM, &changes) {
+			needsUpdate = true
+		}
+	}
+
 	// attach tags if any
 	if len(convertedBM.Tags) > 0 {
-		if err := s.client.AttachTags(ctx, karakeepBM.ID, convertedBM.Tags); err != nil {
+		if err := s.target.AttachTags(ctx, remote.ID, convertedBM.Tags); err != nil {
 			return SyncFailed, fmt.Errorf("attaching tags: %w", err)
 		}
 	}
 
 	if !alreadyExists {
+		if len(convertedBM.Highlights) > 0 {
+			// the bookmark is there either way, so a failure doesn't fail it
+			if err := s.target.CreateHighlights(ctx, remote.ID, convertedBM.Highlights); err != nil {
+				s.logger.Warn("creating highlights of %s: %v", convertedBM.Content.URL, err)
+			}
+		}
+		if s.crawl && convertedBM.Content.Type == "link" {
+			if err := s.target.Crawl(ctx, remote.ID); err != nil {
+				s.logger.Warn("triggering crawl of %s: %v", convertedBM.Content.URL, err)
+			}
+		}
 		s.logger.Info("created: %s", convertedBM.Content.URL)
 		return SyncCreated, nil
 	}
 
+	removed := s.tagsToRemove(remote.Tags, tagsKnown)
+	if !needsUpdate && len(removed) == 0 {
+		s.logger.Info("skipped: %s", convertedBM.Content.URL)
+		return SyncSkipped, nil
+	}
+	if len(removed) > 0 {
+		if err := s.target.DetachTags(ctx, remote.ID, removed); err != nil {
+			return SyncFailed, fmt.Errorf("detaching tags: %w", err)
+		}
+	}
+	if needsUpdate {
+		updated, err := s.update(ctx, remote, convertedBM.Content.URL, changes)
+		if err != nil {
+			return SyncFailed, fmt.Errorf("updating bookmark: %w", err)
+		}
+		if !updated && len(removed) == 0 {
+			s.logger.Info("skipped: %s", convertedBM.Content.URL)
+			return SyncSkipped, nil
+		}
+	}
+	s.logger.Info("updated: %s", convertedBM.Content.URL)
+	return SyncUpdated, nil
+}
+
+// update applies the changes to the existing bookmark, cutting a note, e.g., a merged one, to the
+// target's note limit. Returns false if nothing was left to update since the note was cut back to the
+// existing one, as happens to notes merged into one truncated by an earlier sync.
+func (s *Syncer) update(ctx context.Context, remote Remote, url string, changes Changes) (bool, error) {
+	for retried := false; ; retried = true {
+		changes.Note = s.fitNote(url, changes.Note)
+		if changes.Note != nil && remote.Note != nil && *changes.Note == *remote.Note {
+			changes.Note = nil
+		}
+		if changes == (Changes{}) {
+			return false, nil
+		}
+		err := s.target.Update(ctx, remote.ID, changes)
+		if retried || !s.learnNoteLimit(err) || changes.Note == nil {
+			return true, err
+		}
+	}
+}
+
+// planUpdate computes the changes needed to bring an existing bookmark
+// in line with the converted one, updating its note by the given strategy. Returns whether any field needs updating.
+func planUpdate(remote Remote, convertedBM converter.Bookmark, notes NoteStrategy) (Changes, bool) {
+	var changes Changes
+	needsUpdate := false
+
 	// handle timestamp update: use the earlier
-	var updatedCreatedAt *string
-	var timestampChanged bool
-	karakeepCreatedAtUnix, err := iso8601ToUnix(karakeepBM.CreatedAt)
-	if err != nil {
-		return SyncFailed, fmt.Errorf("parsing existing createdAt: %w", err)
+	if convertedBM.CreatedAt < remote.CreatedAt {
+		changes.CreatedAt = &convertedBM.CreatedAt
+		needsUpdate = true
 	}
-	if convertedBM.CreatedAt < karakeepCreatedAtUnix {
-		earlierCreatedAt := unixToISO8601(convertedBM.CreatedAt)
-		updatedCreatedAt = &earlierCreatedAt
-		timestampChanged = true
+
+	// handle note update by the note strategy
+	if note, changed := updateNote(notes, remote.Note, convertedBM.Note); changed {
+		changes.Note = note
+		needsUpdate = true
 	}
 
-	// handle note update: merge if needed
-	updatedNote, noteChanged := mergeNotes(karakeepBM.Note, convertedBM.Note)
+	// handle summary update: only fill in a missing one, never overwrite one written by the user or Karakeep's AI
+	if convertedBM.Summary != nil && (remote.Summary == nil || strings.TrimSpace(*remote.Summary) == "") {
+		changes.Summary = convertedBM.Summary
+		needsUpdate = true
+	}
 
-	// decide update or skip
-	if !timestampChanged && !noteChanged {
-		s.logger.Info("skipped: %s", convertedBM.Content.URL)
-		return SyncSkipped, nil
+	// handle archive update: only archive, never unarchive what the user restored
+	if convertedBM.Archived && !remote.Archived {
+		changes.Archived = &convertedBM.Archived
+		needsUpdate = true
 	}
-	if err := s.client.UpdateBookmark(ctx, karakeepBM.ID, updatedCreatedAt, updatedNote); err != nil {
-		return SyncFailed, fmt.Errorf("updating bookmark: %w", err)
+
+	// handle favourite update: same one-way rule as archive
+	if convertedBM.Favourited && !remote.Favourited {
+		changes.Favourited = &convertedBM.Favourited
+		needsUpdate = true
+	}
+
+	return changes, needsUpdate
+}
+
+// planTitle sets the title change of an existing bookmark whose title differs from the converted one
+// (see WithUpdateTitles). Returns whether the title needs updating. Bookmarks converted without a title,
+// e.g., of deleted items left for Karakeep to crawl, keep theirs.
+func planTitle(remote Remote, convertedBM converter.Bookmark, changes *Changes) bool {
+	if convertedBM.Title == nil || *convertedBM.Title == "" {
+		return false
+	}
+	if remote.Title != nil && *remote.Title == *convertedBM.Title {
+		return false
+	}
+	changes.Title = convertedBM.Title
+	return true
+}
+
+// isConflict reports whether the existing bookmark has a note or timestamp differing from the converted one,
+// i.e., an existing note the converted note would be merged into, or a different save time.
+func isConflict(remote Remote, convertedBM converter.Bookmark, changes Changes) bool {
+	noteConflict := changes.Note != nil && remote.Note != nil && strings.TrimSpace(*remote.Note) != ""
+	return noteConflict || remote.CreatedAt != convertedBM.CreatedAt
+}
+
+// planReplace is like planUpdate, but overwrites the note and timestamp with the converted ones.
+func planReplace(remote Remote, convertedBM converter.Bookmark) (Changes, bool) {
+	changes, _ := planUpdate(remote, convertedBM, NotesSkip)
+	changes.CreatedAt, changes.Note = nil, nil
+
+	if convertedBM.CreatedAt != remote.CreatedAt {
+		changes.CreatedAt = &convertedBM.CreatedAt
+	}
+	existing, note := "", ""
+	if remote.Note != nil {
+		existing = *remote.Note
+	}
+	if convertedBM.Note != nil {
+		note = *convertedBM.Note
+	}
+	if existing != note {
+		changes.Note = &note
+	}
+	return changes, updateFields(changes) != nil
+}
+
+// resolveConflict asks the conflict resolver, one conflict at a time.
+func (s *Syncer) resolveConflict(c Conflict) Resolution {
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	return s.resolve(c)
+}
+
+// updateFields returns the names of the fields set in the changes.
+func updateFields(changes Changes) []string {
+	var fields []string
+	if changes.CreatedAt != nil {
+		fields = append(fields, "createdAt")
+	}
+	if changes.Title != nil {
+		fields = append(fields, "title")
+	}
+	if changes.Note != nil {
+		fields = append(fields, "note")
+	}
+	if changes.Summary != nil {
+		fields = append(fields, "summary")
+	}
+	if changes.Archived != nil {
+		fields = append(fields, "archived")
+	}
+	if changes.Favourited != nil {
+		fields = append(fields, "favourited")
+	}
+	return fields
+}
+
+// tagsToRemove returns the tags to detach from an existing bookmark (see WithRemoveTags).
+// If the bookmark's current tags are known, only the ones actually attached are returned.
+func (s *Syncer) tagsToRemove(current []string, known bool) []string {
+	if !known {
+		return s.removeTags
+	}
+	var tags []string
+	for _, tag := range s.removeTags {
+		if slices.Contains(current, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// updateNote returns the existing note updated with the incoming note by the strategy (see NoteStrategy),
+// and whether it changed.
+func updateNote(strategy NoteStrategy, existing, incoming *string) (*string, bool) {
+	switch strategy {
+	case NotesReplace:
+		if incoming == nil || *incoming == "" || (existing != nil && *existing == *incoming) {
+			return existing, false
+		}
+		return incoming, true
+	case NotesKeepExisting:
+		if existing != nil && strings.TrimSpace(*existing) != "" {
+			return existing, false
+		}
+		return mergeNotes(existing, incoming) // sets the incoming note
+	case NotesSkip:
+		return existing, false
+	default:
+		return mergeNotes(existing, incoming)
 	}
-	s.logger.Info("updated: %s", convertedBM.Content.URL)
-	return SyncUpdated, nil
 }
 
 // mergeNotes merges a new note into an existing note.
@@ -237,6 +723,7 @@ func (s *Syncer) syncTask(ctx context.Context, convertedBM converter.Bookmark) (
 // Update logic:
 //   - If the incoming note is nil or empty, no update is needed.
 //   - If the existing note already contains the incoming note, skip (idempotent).
+//   - If the existing note was truncated from the incoming note, or from a merge ending with it, skip.
 //   - If the existing note is empty, use the incoming note directly.
 //   - If the existing note is non-empty, append with noteSeparator.
 func mergeNotes(existing, incoming *string) (merged *string, needsUpdate bool) {
@@ -249,7 +736,7 @@ func mergeNotes(existing, incoming *string) (merged *string, needsUpdate bool) {
 		return existing, false
 	}
 
-	if strings.Contains(existingNote, *incoming) { // idempotency here
+	if strings.Contains(existingNote, *incoming) || truncatedFrom(existingNote, *incoming) { // idempotency here
 		return existing, false
 	}
 
@@ -265,16 +752,76 @@ func mergeNotes(existing, incoming *string) (merged *string, needsUpdate bool) {
 	return &result, true
 }
 
-// unixToISO8601 converts a Unix timestamp (in seconds) to an ISO8601 date string.
-func unixToISO8601(ts int64) string {
-	return time.Unix(ts, 0).Format(time.RFC3339)
+// truncatedFrom reports whether note was cut to the target's note limit by fitNote from incoming, or
+// from a merge ending with it. Merging incoming again would only be cut back to the same note.
+func truncatedFrom(note, incoming string) bool {
+	kept, ok := strings.CutSuffix(note, noteTruncation)
+	if !ok || kept == "" {
+		return false
+	}
+	if strings.HasPrefix(incoming, kept) {
+		return true
+	}
+	// the cut ends in the separator before incoming, or in incoming itself
+	merged := noteSeparator + incoming
+	for i := range len(kept) {
+		if kept[i] == noteSeparator[0] && strings.HasPrefix(merged, kept[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// learnNoteLimit records the note limit if err is a NoteLimitError, reporting whether to try again.
+func (s *Syncer) learnNoteLimit(err error) bool {
+	var limitErr *NoteLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit <= 0 {
+		return false
+	}
+	if s.noteLimit.Swap(int64(limitErr.Limit)) != int64(limitErr.Limit) {
+		s.logger.Warn("the target accepts notes of at most %d characters, longer notes are truncated", limitErr.Limit)
+	}
+	return true
+}
+
+// noteFits reports whether the note is within the learned note limit, if any (see fitNote).
+func (s *Syncer) noteFits(note *string) bool {
+	limit := int(s.noteLimit.Load())
+	return note == nil || limit <= 0 || utf16Len(*note) <= limit
+}
+
+// noteTruncation marks the end of a truncated note.
+const noteTruncation = "…"
+
+// fitNote returns the note cut to the learned note limit, if any, with a warning naming the bookmark.
+// The limit is in UTF-16 code units, as Karakeep's request validation counts JavaScript string lengths.
+func (s *Syncer) fitNote(url string, note *string) *string {
+	limit := int(s.noteLimit.Load())
+	if note == nil || limit <= 0 {
+		return note
+	}
+	length := utf16Len(*note)
+	if length <= limit {
+		return note
+	}
+	budget := limit - utf16Len(noteTruncation)
+	end := 0
+	for i, r := range *note {
+		if budget -= utf16.RuneLen(r); budget < 0 {
+			break
+		}
+		end = i + utf8.RuneLen(r)
+	}
+	cut := strings.TrimRightFunc((*note)[:end], unicode.IsSpace) + noteTruncation
+	s.logger.Warn("note of %s truncated from %d to %d characters", url, length, limit)
+	return &cut
 }
 
-// iso8601ToUnix converts an ISO8601 date string to a Unix timestamp (in seconds).
-func iso8601ToUnix(iso string) (int64, error) {
-	t, err := time.Parse(time.RFC3339, iso)
-	if err != nil {
-		return 0, fmt.Errorf("parsing ISO8601 date %q: %w", iso, err)
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
 	}
-	return t.Unix(), nil
+	return n
 }
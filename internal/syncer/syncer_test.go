@@ -3,14 +3,18 @@ package syncer
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/akhdanfadh/hnkeep/internal/converter"
-	"github.com/akhdanfadh/hnkeep/internal/karakeep"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
 )
 
 // ptr returns a pointer to the given string.
@@ -41,6 +45,24 @@ func TestMergeNotes(t *testing.T) {
 			wantMerged:  ptr("my note with content"),
 			wantUpdated: false,
 		},
+		"truncated from incoming (idempotent)": {
+			existing:    ptr("a long no…"),
+			incoming:    ptr("a long note here"),
+			wantMerged:  ptr("a long no…"),
+			wantUpdated: false,
+		},
+		"truncated from a merge ending with incoming (idempotent)": {
+			existing:    ptr("mine\n\n---\n\nthe…"),
+			incoming:    ptr("theirs"),
+			wantMerged:  ptr("mine\n\n---\n\nthe…"),
+			wantUpdated: false,
+		},
+		"truncated from another note appends incoming": {
+			existing:    ptr("mine\n\n---\n\nold…"),
+			incoming:    ptr("theirs"),
+			wantMerged:  ptr("mine\n\n---\n\nold…\n\n---\n\ntheirs"),
+			wantUpdated: true,
+		},
 		"nil existing replaced by incoming": {
 			existing:    nil,
 			incoming:    ptr("new note"),
@@ -81,6 +103,36 @@ func TestMergeNotes(t *testing.T) {
 	}
 }
 
+func TestUpdateNote(t *testing.T) {
+	tests := map[string]struct {
+		strategy    NoteStrategy
+		existing    *string
+		incoming    *string
+		wantNote    *string
+		wantChanged bool
+	}{
+		"merge appends":                  {NotesMerge, ptr("mine"), ptr("hn"), ptr("mine" + noteSeparator + "hn"), true},
+		"replace overwrites":             {NotesReplace, ptr("mine"), ptr("hn"), ptr("hn"), true},
+		"replace same note":              {NotesReplace, ptr("hn"), ptr("hn"), ptr("hn"), false},
+		"replace never clears":           {NotesReplace, ptr("mine"), ptr(""), ptr("mine"), false},
+		"keep-existing keeps":            {NotesKeepExisting, ptr("mine"), ptr("hn"), ptr("mine"), false},
+		"keep-existing fills empty note": {NotesKeepExisting, nil, ptr("hn"), ptr("hn"), true},
+		"skip never touches":             {NotesSkip, nil, ptr("hn"), nil, false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, changed := updateNote(tc.strategy, tc.existing, tc.incoming)
+			if changed != tc.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tc.wantChanged)
+			}
+			if (got == nil) != (tc.wantNote == nil) || (got != nil && *got != *tc.wantNote) {
+				t.Errorf("note = %v, want %v", got, tc.wantNote)
+			}
+		})
+	}
+}
+
 func TestTimestampConversion(t *testing.T) {
 	t.Run("unixToISO8601", func(t *testing.T) {
 		// 2024-01-01 00:00:00 UTC
@@ -183,7 +235,7 @@ func TestSync(t *testing.T) {
 			karakeep.WithRetryWait(0),
 		)
 
-		syncer := New(client, WithConcurrency(2))
+		syncer := New(NewKarakeepTarget(client, nil), WithConcurrency(2))
 
 		bookmarks := []converter.Bookmark{
 			{
@@ -239,7 +291,7 @@ func TestSync(t *testing.T) {
 			karakeep.WithRetryWait(0),
 		)
 
-		syncer := New(client, WithConcurrency(1))
+		syncer := New(NewKarakeepTarget(client, nil), WithConcurrency(1))
 
 		bookmarks := []converter.Bookmark{
 			{
@@ -280,7 +332,7 @@ func TestSync(t *testing.T) {
 			karakeep.WithRetryWait(0),
 		)
 
-		syncer := New(client, WithConcurrency(1))
+		syncer := New(NewKarakeepTarget(client, nil), WithConcurrency(1))
 
 		bookmarks := []converter.Bookmark{
 			{
@@ -322,7 +374,7 @@ func TestSync(t *testing.T) {
 			karakeep.WithRetryWait(0),
 		)
 
-		syncer := New(client, WithConcurrency(1))
+		syncer := New(NewKarakeepTarget(client, nil), WithConcurrency(1))
 
 		bookmarks := []converter.Bookmark{
 			{
@@ -359,7 +411,7 @@ func TestSync(t *testing.T) {
 			karakeep.WithRetryWait(0),
 		)
 
-		syncer := New(client, WithConcurrency(1))
+		syncer := New(NewKarakeepTarget(client, nil), WithConcurrency(1))
 
 		bookmarks := []converter.Bookmark{
 			{
@@ -376,6 +428,35 @@ func TestSync(t *testing.T) {
 		}
 	})
 
+	t.Run("creates text bookmarks", func(t *testing.T) {
+		var got karakeep.CreateBookmarkRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/bookmarks" && r.Method == http.MethodPost {
+				_ = json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(karakeep.CreateBookmarkResponse{ID: "bm-1", CreatedAt: "2024-01-01T00:00:00Z"})
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := karakeep.NewClient(server.URL, "test-key", karakeep.WithHTTPClient(server.Client()))
+		syncer := New(NewKarakeepTarget(client, nil))
+		status := syncer.Sync(context.Background(), []converter.Bookmark{{
+			CreatedAt: 1704067200,
+			Title:     ptr("Ask HN: Why?"),
+			Content:   converter.NewTextBookmarkContent("Just wondering", "https://news.ycombinator.com/item?id=1"),
+		}})
+
+		if status[SyncCreated] != 1 {
+			t.Fatalf("SyncCreated = %d, want 1", status[SyncCreated])
+		}
+		if got.Type != "text" || got.Text != "Just wondering" || got.SourceURL != "https://news.ycombinator.com/item?id=1" || got.URL != "" {
+			t.Errorf("create request = %+v, want a text bookmark with the HN source URL", got)
+		}
+	})
+
 	t.Run("respects context cancellation", func(t *testing.T) {
 		requestCount := 0
 		var mu sync.Mutex
@@ -394,7 +475,7 @@ func TestSync(t *testing.T) {
 			karakeep.WithRetryWait(0),
 		)
 
-		syncer := New(client, WithConcurrency(1))
+		syncer := New(NewKarakeepTarget(client, nil), WithConcurrency(1))
 
 		// create many bookmarks
 		var bookmarks []converter.Bookmark
@@ -480,9 +561,8 @@ func TestSync(t *testing.T) {
 			},
 		}
 
-		syncer := New(client,
+		syncer := New(NewKarakeepTarget(client, existingBookmarks),
 			WithConcurrency(1),
-			WithExistingBookmarks(existingBookmarks),
 		)
 
 		bookmarks := []converter.Bookmark{
@@ -540,4 +620,689 @@ func TestSync(t *testing.T) {
 			t.Errorf("SyncSkipped = %d, want 1", status[SyncSkipped])
 		}
 	})
+
+	t.Run("archives created and existing bookmarks when requested", func(t *testing.T) {
+		var mu sync.Mutex
+		var createArchived []bool
+		var updateArchived []bool
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if r.Method == http.MethodPost && r.URL.Path == "/bookmarks" {
+				var req karakeep.CreateBookmarkRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				createArchived = append(createArchived, req.Archived != nil && *req.Archived)
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(karakeep.CreateBookmarkResponse{
+					ID:        "bm-new",
+					CreatedAt: "2024-01-01T00:00:00Z",
+					Archived:  true,
+				})
+				return
+			}
+
+			if r.Method == http.MethodPatch {
+				var req karakeep.UpdateBookmarkRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				updateArchived = append(updateArchived, req.Archived != nil && *req.Archived)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := karakeep.NewClient(server.URL, "test-key",
+			karakeep.WithHTTPClient(server.Client()),
+			karakeep.WithMaxRetries(1),
+			karakeep.WithRetryWait(0),
+		)
+
+		existingBookmarks := map[string]karakeep.ExistingBookmark{
+			"https://unarchived.com": {ID: "bm-1", CreatedAt: 1704067200},
+			"https://archived.com":   {ID: "bm-2", CreatedAt: 1704067200, Archived: true},
+		}
+
+		syncer := New(NewKarakeepTarget(client, existingBookmarks),
+			WithConcurrency(1),
+		)
+
+		bookmarks := []converter.Bookmark{
+			{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://new.com"), Archived: true},
+			{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://unarchived.com"), Archived: true},
+			{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://archived.com"), Archived: true},
+		}
+
+		status := syncer.Sync(context.Background(), bookmarks)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(createArchived) != 1 || !createArchived[0] {
+			t.Errorf("create archived = %v, want [true]", createArchived)
+		}
+		if len(updateArchived) != 1 || !updateArchived[0] {
+			t.Errorf("update archived = %v, want [true]", updateArchived)
+		}
+		if status[SyncCreated] != 1 || status[SyncUpdated] != 1 || status[SyncSkipped] != 1 {
+			t.Errorf("status = %v, want 1 created, 1 updated, 1 skipped", status)
+		}
+	})
+
+	t.Run("detaches removed tags from existing bookmarks only", func(t *testing.T) {
+		var mu sync.Mutex
+		detached := make(map[string][]string) // bookmark id -> detached tag names
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/bookmarks":
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(karakeep.CreateBookmarkResponse{ID: "bm-new", CreatedAt: "2024-01-01T00:00:00Z"})
+			case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/tags"):
+				w.WriteHeader(http.StatusOK)
+			case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/tags"):
+				var req karakeep.AttachTagsRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				id := strings.Split(r.URL.Path, "/")[2]
+				for _, tag := range req.Tags {
+					detached[id] = append(detached[id], tag.TagName)
+				}
+				w.WriteHeader(http.StatusOK)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		client := karakeep.NewClient(server.URL, "test-key",
+			karakeep.WithHTTPClient(server.Client()),
+			karakeep.WithMaxRetries(1),
+			karakeep.WithRetryWait(0),
+		)
+
+		existingBookmarks := map[string]karakeep.ExistingBookmark{
+			"https://old-tag.com": {ID: "bm-1", CreatedAt: 1704067200, Tags: []string{"hn", "old:tag"}},
+			"https://clean.com":   {ID: "bm-2", CreatedAt: 1704067200, Tags: []string{"hn"}},
+		}
+
+		syncer := New(NewKarakeepTarget(client, existingBookmarks),
+			WithConcurrency(1),
+			WithRemoveTags([]string{"old:tag"}),
+		)
+
+		bookmarks := []converter.Bookmark{
+			{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://new.com"), Tags: []string{"hn"}},
+			{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://old-tag.com"), Tags: []string{"hn"}},
+			{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://clean.com"), Tags: []string{"hn"}},
+		}
+
+		plan := syncer.Plan(context.Background(), bookmarks)
+		if plan[1].Status != SyncUpdated || strings.Join(plan[1].Changes, ",") != "-tag old:tag" {
+			t.Errorf("plan[1] = %+v, want update removing old:tag", plan[1])
+		}
+
+		status := syncer.Sync(context.Background(), bookmarks)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(detached) != 1 || !slices.Equal(detached["bm-1"], []string{"old:tag"}) {
+			t.Errorf("detached = %v, want only old:tag from bm-1", detached)
+		}
+		if status[SyncCreated] != 1 || status[SyncUpdated] != 1 || status[SyncSkipped] != 1 {
+			t.Errorf("status = %v, want 1 created, 1 updated, 1 skipped", status)
+		}
+	})
+}
+
+func TestPlan(t *testing.T) {
+	existingBookmarks := map[string]karakeep.ExistingBookmark{
+		"https://up-to-date.com": {ID: "bm-1", CreatedAt: 1704067200, Note: ptr("same note")},
+		"https://needs-note.com": {ID: "bm-2", CreatedAt: 1704067200},
+		"https://needs-both.com": {ID: "bm-3", CreatedAt: 1735689600}, // 2025-01-01
+		"https://no-summary.com": {ID: "bm-4", CreatedAt: 1704067200},
+		"https://ai-summary.com": {ID: "bm-5", CreatedAt: 1704067200, Summary: ptr("written by AI")},
+	}
+
+	// client is never called by Plan, so a dummy URL is fine
+	client := karakeep.NewClient("http://unused.invalid", "test-key")
+	syncer := New(NewKarakeepTarget(client, existingBookmarks))
+
+	bookmarks := []converter.Bookmark{
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://new.com")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://up-to-date.com"), Note: ptr("same note")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://needs-note.com"), Note: ptr("new note")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://needs-both.com"), Note: ptr("new note")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://no-summary.com"), Summary: ptr("10 points")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://ai-summary.com"), Summary: ptr("10 points")},
+	}
+
+	plan := syncer.Plan(context.Background(), bookmarks)
+
+	want := []struct {
+		status  SyncStatus
+		changes string
+	}{
+		{SyncCreated, ""},
+		{SyncSkipped, ""},
+		{SyncUpdated, "note"},
+		{SyncUpdated, "createdAt,note"},
+		{SyncUpdated, "summary"},
+		{SyncSkipped, ""}, // existing summary is kept
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("len(plan) = %d, want %d", len(plan), len(want))
+	}
+	for i, w := range want {
+		if plan[i].URL != bookmarks[i].Content.URL {
+			t.Errorf("plan[%d].URL = %q, want %q", i, plan[i].URL, bookmarks[i].Content.URL)
+		}
+		if plan[i].Status != w.status {
+			t.Errorf("plan[%d].Status = %v, want %v", i, plan[i].Status, w.status)
+		}
+		if got := strings.Join(plan[i].Changes, ","); got != w.changes {
+			t.Errorf("plan[%d].Changes = %q, want %q", i, got, w.changes)
+		}
+	}
+}
+
+// memTarget is an in-memory Target for testing the sync logic independently of Karakeep.
+type memTarget struct {
+	mu         sync.Mutex
+	known      map[string]Remote // returned by Exists
+	created    []converter.Bookmark
+	updates    map[string]Changes
+	attached   map[string][]string
+	highlights map[string][]converter.Highlight
+	crawled    []string
+	createErr  error
+	batches    [][]string // URLs of each CreateBatch call
+	batchErr   error      // returned by CreateBatch
+	batchFail  string     // URL failing in CreateBatch only
+}
+
+func (m *memTarget) Exists(_ context.Context, bm converter.Bookmark) (Remote, bool, error) {
+	remote, found := m.known[bm.Content.URL]
+	return remote, found, nil
+}
+
+func (m *memTarget) Create(_ context.Context, bm converter.Bookmark) (Remote, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.createErr != nil {
+		return Remote{}, false, m.createErr
+	}
+	m.created = append(m.created, bm)
+	return Remote{ID: fmt.Sprintf("new-%d", len(m.created)), CreatedAt: bm.CreatedAt}, false, nil
+}
+
+func (m *memTarget) CreateBatch(ctx context.Context, bms []converter.Bookmark) ([]Created, error) {
+	m.mu.Lock()
+	urls := make([]string, len(bms))
+	for i, bm := range bms {
+		urls[i] = bm.Content.URL
+	}
+	m.batches = append(m.batches, urls)
+	m.mu.Unlock()
+	if m.batchErr != nil {
+		return nil, m.batchErr
+	}
+	created := make([]Created, len(bms))
+	for i, bm := range bms {
+		if bm.Content.URL == m.batchFail {
+			created[i].Err = errors.New("boom")
+			continue
+		}
+		created[i].Remote, created[i].Exists, created[i].Err = m.Create(ctx, bm)
+	}
+	return created, nil
+}
+
+func (m *memTarget) Update(_ context.Context, id string, changes Changes) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updates[id] = changes
+	return nil
+}
+
+func (m *memTarget) AttachTags(_ context.Context, id string, tags []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attached[id] = append(m.attached[id], tags...)
+	return nil
+}
+
+func (m *memTarget) DetachTags(context.Context, string, []string) error { return nil }
+
+func (m *memTarget) CreateHighlights(_ context.Context, id string, highlights []converter.Highlight) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.highlights == nil {
+		m.highlights = make(map[string][]converter.Highlight)
+	}
+	m.highlights[id] = append(m.highlights[id], highlights...)
+	return nil
+}
+
+func (m *memTarget) Crawl(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.crawled = append(m.crawled, id)
+	return nil
+}
+
+func TestSync_Target(t *testing.T) {
+	target := &memTarget{
+		known: map[string]Remote{
+			"https://known.com": {ID: "bm-1", CreatedAt: 1735689600, Note: ptr("mine")},
+		},
+		updates:  make(map[string]Changes),
+		attached: make(map[string][]string),
+	}
+	highlights := []converter.Highlight{{Text: "Great take", Note: "alice on HN"}}
+	bookmarks := []converter.Bookmark{
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://new.com"), Tags: []string{"hn"},
+			Highlights: highlights},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://known.com"), Note: ptr("theirs"),
+			Highlights: highlights},
+	}
+
+	status := New(target, WithConcurrency(1)).Sync(context.Background(), bookmarks)
+
+	if status[SyncCreated] != 1 || status[SyncUpdated] != 1 {
+		t.Errorf("status = %v, want 1 created, 1 updated", status)
+	}
+	if len(target.created) != 1 || target.created[0].Content.URL != "https://new.com" {
+		t.Errorf("created = %v, want only https://new.com", target.created)
+	}
+	if !slices.Equal(target.attached["new-1"], []string{"hn"}) {
+		t.Errorf("attached = %v, want hn on new-1", target.attached)
+	}
+	if len(target.highlights) != 1 || !slices.Equal(target.highlights["new-1"], highlights) {
+		t.Errorf("highlights = %v, want them on new-1 only", target.highlights)
+	}
+	changes := target.updates["bm-1"]
+	if changes.CreatedAt == nil || *changes.CreatedAt != 1704067200 {
+		t.Errorf("CreatedAt change = %v, want the earlier 1704067200", changes.CreatedAt)
+	}
+	if changes.Note == nil || *changes.Note != "mine"+noteSeparator+"theirs" {
+		t.Errorf("Note change = %v, want the merged note", changes.Note)
+	}
+
+	target.createErr = errors.New("boom")
+	status = New(target).Sync(context.Background(), bookmarks[:1])
+	if status[SyncFailed] != 1 {
+		t.Errorf("status = %v, want 1 failed", status)
+	}
+}
+
+func TestSync_Provenance(t *testing.T) {
+	var mu sync.Mutex
+	var creates int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/bookmarks" {
+			mu.Lock()
+			creates++
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(karakeep.CreateBookmarkResponse{ID: "bm-new", CreatedAt: "2024-01-01T00:00:00Z"})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	client := karakeep.NewClient(server.URL, "test-key", karakeep.WithHTTPClient(server.Client()), karakeep.WithMaxRetries(1))
+
+	// the bookmark's URL was changed in Karakeep after it was imported with its provenance tag
+	existing := map[string]karakeep.ExistingBookmark{
+		"https://a.com/new": {ID: "bm-1", CreatedAt: 1704067200, Tags: []string{converter.ProvenanceTag(1)}},
+	}
+	bookmarks := []converter.Bookmark{{
+		CreatedAt: 1704067200,
+		Tags:      converter.BookmarkTags{converter.ProvenanceTag(1)},
+		Content:   converter.NewBookmarkContent("https://a.com/old"),
+	}}
+
+	status := New(NewKarakeepTarget(client, existing), WithConcurrency(1)).Sync(context.Background(), bookmarks)
+
+	if status[SyncCreated] != 0 || status[SyncFailed] != 0 || creates != 0 {
+		t.Errorf("status = %v, creates = %d, want the bookmark found by its provenance tag", status, creates)
+	}
+
+	// without the provenance tag, the old URL is created again
+	bookmarks[0].Tags = nil
+	status = New(NewKarakeepTarget(client, existing), WithConcurrency(1)).Sync(context.Background(), bookmarks)
+	if status[SyncCreated] != 1 {
+		t.Errorf("status = %v without a provenance tag, want 1 created", status)
+	}
+}
+
+func TestSync_BatchCreate(t *testing.T) {
+	bookmarks := []converter.Bookmark{
+		{Content: converter.NewBookmarkContent("https://a.com"), Tags: []string{"hn"}},
+		{Content: converter.NewBookmarkContent("https://known.com")},
+		{Content: converter.NewBookmarkContent("https://b.com")},
+		{Content: converter.NewBookmarkContent("https://c.com")},
+	}
+	tests := map[string]struct {
+		batchErr    error
+		batchFail   string
+		wantBatches [][]string
+		wantCreated []string
+	}{
+		"batches": {
+			wantBatches: [][]string{{"https://a.com", "https://b.com"}, {"https://c.com"}},
+			wantCreated: []string{"https://a.com", "https://b.com", "https://c.com"},
+		},
+		"failed bookmark one by one": {
+			batchFail:   "https://b.com",
+			wantBatches: [][]string{{"https://a.com", "https://b.com"}, {"https://c.com"}},
+			wantCreated: []string{"https://a.com", "https://b.com", "https://c.com"},
+		},
+		"all one by one once a batch fails": {
+			batchErr:    errors.ErrUnsupported,
+			wantBatches: [][]string{{"https://a.com", "https://b.com"}},
+			wantCreated: []string{"https://a.com", "https://b.com", "https://c.com"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			target := &memTarget{
+				known:     map[string]Remote{"https://known.com": {ID: "bm-1"}},
+				updates:   make(map[string]Changes),
+				attached:  make(map[string][]string),
+				batchErr:  tc.batchErr,
+				batchFail: tc.batchFail,
+			}
+
+			status := New(target, WithConcurrency(1), WithBatchCreate(2)).Sync(context.Background(), bookmarks)
+
+			if status[SyncCreated] != 3 || status[SyncSkipped] != 1 {
+				t.Errorf("status = %v, want 3 created, 1 skipped", status)
+			}
+			if !slices.EqualFunc(target.batches, tc.wantBatches, slices.Equal) {
+				t.Errorf("batches = %v, want %v", target.batches, tc.wantBatches)
+			}
+			var created []string
+			for _, bm := range target.created {
+				created = append(created, bm.Content.URL)
+			}
+			idA := fmt.Sprintf("new-%d", slices.Index(created, "https://a.com")+1)
+			if slices.Sort(created); !slices.Equal(created, tc.wantCreated) {
+				t.Errorf("created = %v, want %v", created, tc.wantCreated)
+			}
+			if !slices.Equal(target.attached[idA], []string{"hn"}) {
+				t.Errorf("attached = %v, want hn on %s", target.attached, idA)
+			}
+		})
+	}
+}
+
+func TestSync_TriggerCrawl(t *testing.T) {
+	target := &memTarget{
+		known:    map[string]Remote{"https://known.com": {ID: "bm-1"}},
+		updates:  make(map[string]Changes),
+		attached: make(map[string][]string),
+	}
+	bookmarks := []converter.Bookmark{
+		{Content: converter.NewBookmarkContent("https://new.com")},
+		{Content: converter.NewTextBookmarkContent("Ask HN text", "https://news.ycombinator.com/item?id=1")},
+		{Content: converter.NewBookmarkContent("https://known.com")},
+	}
+
+	New(target, WithConcurrency(1)).Sync(context.Background(), bookmarks)
+	if len(target.crawled) != 0 {
+		t.Errorf("crawled = %v without WithTriggerCrawl, want none", target.crawled)
+	}
+
+	target.created = nil
+	New(target, WithConcurrency(1), WithTriggerCrawl()).Sync(context.Background(), bookmarks)
+	if !slices.Equal(target.crawled, []string{"new-1"}) {
+		t.Errorf("crawled = %v, want only the created link bookmark new-1", target.crawled)
+	}
+}
+
+func TestSync_MaxFailures(t *testing.T) {
+	target := &memTarget{createErr: errors.New("boom")}
+	var bookmarks []converter.Bookmark
+	for i := range 10 {
+		bookmarks = append(bookmarks, converter.Bookmark{
+			Content: converter.NewBookmarkContent(fmt.Sprintf("https://example.com/%d", i)),
+		})
+	}
+
+	status := New(target, WithMaxFailures(2)).Sync(context.Background(), bookmarks)
+	if status[SyncFailed] != 3 {
+		t.Errorf("status = %v, want abort at the 3rd failure", status)
+	}
+
+	status = New(target, WithMaxFailures(10)).Sync(context.Background(), bookmarks)
+	if status[SyncFailed] != 10 {
+		t.Errorf("status = %v, want all 10 failed within the limit", status)
+	}
+}
+
+func TestSync_ConflictResolver(t *testing.T) {
+	target := &memTarget{
+		known: map[string]Remote{
+			"https://merge.com":   {ID: "bm-merge", CreatedAt: 1735689600, Note: ptr("mine")},
+			"https://replace.com": {ID: "bm-replace", CreatedAt: 1735689600, Note: ptr("mine")},
+			"https://skip.com":    {ID: "bm-skip", CreatedAt: 1735689600, Note: ptr("mine")},
+			"https://same.com":    {ID: "bm-same", CreatedAt: 1704067200, Note: ptr("theirs")},
+		},
+		updates:  make(map[string]Changes),
+		attached: make(map[string][]string),
+	}
+	var bookmarks []converter.Bookmark
+	for _, url := range []string{"https://merge.com", "https://replace.com", "https://skip.com", "https://same.com"} {
+		bookmarks = append(bookmarks, converter.Bookmark{
+			CreatedAt: 1704067200, Content: converter.NewBookmarkContent(url), Note: ptr("theirs"), Tags: []string{"hn"},
+		})
+	}
+
+	var asked []string
+	resolver := func(c Conflict) Resolution {
+		asked = append(asked, c.Bookmark.Content.URL)
+		switch c.Remote.ID {
+		case "bm-replace":
+			return ResolveReplace
+		case "bm-skip":
+			return ResolveSkip
+		}
+		return ResolveMerge
+	}
+	status := New(target, WithConcurrency(1), WithConflictResolver(resolver)).Sync(context.Background(), bookmarks)
+
+	if status[SyncUpdated] != 2 || status[SyncSkipped] != 2 {
+		t.Errorf("status = %v, want 2 updated, 2 skipped", status)
+	}
+	slices.Sort(asked)
+	if want := []string{"https://merge.com", "https://replace.com", "https://skip.com"}; !slices.Equal(asked, want) {
+		t.Errorf("asked for %v, want %v (no conflict for same.com)", asked, want)
+	}
+	if note := target.updates["bm-merge"].Note; note == nil || *note != "mine"+noteSeparator+"theirs" {
+		t.Errorf("merge note = %v, want the merged note", note)
+	}
+	replaced := target.updates["bm-replace"]
+	if replaced.Note == nil || *replaced.Note != "theirs" {
+		t.Errorf("replace note = %v, want %q", replaced.Note, "theirs")
+	}
+	if replaced.CreatedAt == nil || *replaced.CreatedAt != 1704067200 {
+		t.Errorf("replace CreatedAt = %v, want 1704067200", replaced.CreatedAt)
+	}
+	if _, ok := target.updates["bm-skip"]; ok {
+		t.Error("skipped bookmark was updated")
+	}
+	if _, ok := target.attached["bm-skip"]; ok {
+		t.Error("skipped bookmark got tags attached")
+	}
+}
+
+func TestSync_UpdateTitles(t *testing.T) {
+	newTarget := func() *memTarget {
+		return &memTarget{
+			known: map[string]Remote{
+				"https://generic.com":  {ID: "bm-generic", CreatedAt: 1704067200, Title: ptr("Home")},
+				"https://untitled.com": {ID: "bm-untitled", CreatedAt: 1704067200},
+				"https://same.com":     {ID: "bm-same", CreatedAt: 1704067200, Title: ptr("Same")},
+				"https://dead.com":     {ID: "bm-dead", CreatedAt: 1704067200, Title: ptr("Crawled")},
+			},
+			updates:  make(map[string]Changes),
+			attached: make(map[string][]string),
+		}
+	}
+	bookmarks := []converter.Bookmark{
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://generic.com"), Title: ptr("A Real Title")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://untitled.com"), Title: ptr("Untitled")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://same.com"), Title: ptr("Same")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://dead.com")}, // no title to set
+	}
+
+	target := newTarget()
+	status := New(target, WithUpdateTitles()).Sync(context.Background(), bookmarks)
+	if status[SyncUpdated] != 2 || status[SyncSkipped] != 2 {
+		t.Errorf("status = %v, want 2 updated, 2 skipped", status)
+	}
+	for id, want := range map[string]string{"bm-generic": "A Real Title", "bm-untitled": "Untitled"} {
+		if title := target.updates[id].Title; title == nil || *title != want {
+			t.Errorf("%s title change = %v, want %q", id, title, want)
+		}
+	}
+
+	target = newTarget()
+	status = New(target).Sync(context.Background(), bookmarks)
+	if status[SyncSkipped] != 4 {
+		t.Errorf("status = %v without WithUpdateTitles, want all skipped", status)
+	}
+}
+
+func TestSync_NoteStrategy(t *testing.T) {
+	target := &memTarget{
+		known: map[string]Remote{
+			"https://known.com": {ID: "bm-1", CreatedAt: 1704067200, Note: ptr("mine")},
+		},
+		updates:  make(map[string]Changes),
+		attached: make(map[string][]string),
+	}
+	bookmarks := []converter.Bookmark{
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://known.com"), Note: ptr("theirs")},
+	}
+
+	status := New(target, WithNoteStrategy(NotesReplace)).Sync(context.Background(), bookmarks)
+	if note := target.updates["bm-1"].Note; status[SyncUpdated] != 1 || note == nil || *note != "theirs" {
+		t.Errorf("status = %v, note = %v, want updated to the converted note", status, note)
+	}
+
+	status = New(target, WithNoteStrategy(NotesSkip)).Sync(context.Background(), bookmarks)
+	if status[SyncSkipped] != 1 {
+		t.Errorf("status = %v, want skipped with the note left alone", status)
+	}
+}
+
+// limitTarget is a memTarget rejecting notes longer than limit, like Karakeep's request validation.
+type limitTarget struct {
+	*memTarget
+	limit    int
+	rejected atomic.Int32
+}
+
+func (l *limitTarget) tooLong(note *string) bool {
+	if note != nil && utf16Len(*note) > l.limit {
+		l.rejected.Add(1)
+		return true
+	}
+	return false
+}
+
+func (l *limitTarget) Create(ctx context.Context, bm converter.Bookmark) (Remote, bool, error) {
+	if l.tooLong(bm.Note) {
+		return Remote{}, false, &NoteLimitError{Limit: l.limit, Err: errors.New("HTTP 400")}
+	}
+	return l.memTarget.Create(ctx, bm)
+}
+
+func (l *limitTarget) Update(ctx context.Context, id string, changes Changes) error {
+	if l.tooLong(changes.Note) {
+		return &NoteLimitError{Limit: l.limit, Err: errors.New("HTTP 400")}
+	}
+	return l.memTarget.Update(ctx, id, changes)
+}
+
+func TestSync_NoteLimit(t *testing.T) {
+	target := &limitTarget{
+		memTarget: &memTarget{
+			known:    map[string]Remote{"https://known.com": {ID: "bm-1", CreatedAt: 1704067200, Note: ptr("mine")}},
+			updates:  make(map[string]Changes),
+			attached: make(map[string][]string),
+		},
+		limit: 10,
+	}
+	bookmarks := []converter.Bookmark{
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://a.com"), Note: ptr("a long note here")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://b.com"), Note: ptr("short")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://c.com"), Note: ptr("ünïcode 😀 emoji")},
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://known.com"), Note: ptr("theirs")},
+	}
+
+	status := New(target, WithConcurrency(1)).Sync(context.Background(), bookmarks)
+	if status[SyncCreated] != 3 || status[SyncUpdated] != 1 {
+		t.Fatalf("status = %v, want 3 created, 1 updated", status)
+	}
+	// the first long note is rejected once, later ones are truncated up front
+	if got := target.rejected.Load(); got != 1 {
+		t.Errorf("rejected %d notes, want 1", got)
+	}
+	for i, want := range []string{"a long no…", "short", "ünïcode…"} {
+		if got := *target.created[i].Note; got != want {
+			t.Errorf("created note %d = %q, want %q", i, got, want)
+		}
+	}
+	// the merged note "mine\n\n---\n\ntheirs" is too long as well
+	if note := target.updates["bm-1"].Note; note == nil || utf16Len(*note) > 10 {
+		t.Errorf("merged note = %v, want it truncated to 10 characters", note)
+	}
+
+	// syncing again with the truncated notes in the target changes nothing
+	known := map[string]Remote{"https://known.com": {ID: "bm-1", CreatedAt: 1704067200, Note: target.updates["bm-1"].Note}}
+	for i, bm := range target.created {
+		known[bm.Content.URL] = Remote{ID: fmt.Sprintf("new-%d", i+1), CreatedAt: bm.CreatedAt, Note: bm.Note}
+	}
+	again := &limitTarget{
+		memTarget: &memTarget{known: known, updates: make(map[string]Changes), attached: make(map[string][]string)},
+		limit:     10,
+	}
+	status = New(again, WithConcurrency(1)).Sync(context.Background(), bookmarks)
+	if status[SyncSkipped] != 4 || len(again.updates) != 0 || again.rejected.Load() != 0 {
+		t.Errorf("second sync status = %v with updates %v and %d rejected notes, want 4 skipped and nothing sent",
+			status, again.updates, again.rejected.Load())
+	}
+}
+
+func TestSync_NoteLimit_CutBackToExisting(t *testing.T) {
+	// "mine" merged with "theirs" was cut to "mine…", which truncatedFrom can't tell apart from a
+	// user's note, so the merged note is only recognized after the target rejected it
+	target := &limitTarget{
+		memTarget: &memTarget{
+			known:    map[string]Remote{"https://known.com": {ID: "bm-1", CreatedAt: 1704067200, Note: ptr("mine…")}},
+			updates:  make(map[string]Changes),
+			attached: make(map[string][]string),
+		},
+		limit: 5,
+	}
+	bookmarks := []converter.Bookmark{
+		{CreatedAt: 1704067200, Content: converter.NewBookmarkContent("https://known.com"), Note: ptr("theirs")},
+	}
+
+	status := New(target, WithConcurrency(1)).Sync(context.Background(), bookmarks)
+	if status[SyncSkipped] != 1 || len(target.updates) != 0 {
+		t.Errorf("status = %v with updates %v, want skipped without an update", status, target.updates)
+	}
 }
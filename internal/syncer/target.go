@@ -0,0 +1,77 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+)
+
+// Target is a bookmark service the Syncer pushes converted bookmarks to, e.g., Karakeep (see NewKarakeepTarget).
+// The Syncer handles concurrency, merging, and state, so a Target only maps these calls to the service's API.
+// Methods are called from several goroutines at once.
+type Target interface {
+	// Exists returns the bookmark the service has for the converted one, if known without creating one,
+	// usually the one with its URL. The returned bookmark's tags must be set, so tags to remove can be checked.
+	Exists(ctx context.Context, bm converter.Bookmark) (remote Remote, found bool, err error)
+	// Create creates the bookmark. If the service already has one for its URL,
+	// that one is returned instead with exists set. The returned bookmark's tags may be unknown.
+	Create(ctx context.Context, bm converter.Bookmark) (created Remote, exists bool, err error)
+	// CreateBatch creates the bookmarks like Create, but in one request (see WithBatchCreate), and returns
+	// a result per bookmark in order. An error means none was created, e.g., errors.ErrUnsupported.
+	CreateBatch(ctx context.Context, bms []converter.Bookmark) ([]Created, error)
+	// Update applies the changes to the bookmark with the given ID.
+	Update(ctx context.Context, id string, changes Changes) error
+	// AttachTags attaches the tags to the bookmark with the given ID. Attaching an attached tag is a no-op.
+	AttachTags(ctx context.Context, id string, tags []string) error
+	// DetachTags detaches the tags from the bookmark with the given ID.
+	DetachTags(ctx context.Context, id string, tags []string) error
+	// CreateHighlights adds the highlights to the bookmark with the given ID.
+	CreateHighlights(ctx context.Context, id string, highlights []converter.Highlight) error
+	// Crawl asks the service to fetch the page of the link bookmark with the given ID now (see WithTriggerCrawl).
+	Crawl(ctx context.Context, id string) error
+}
+
+// Remote represents a bookmark as stored by a Target.
+type Remote struct {
+	ID         string
+	CreatedAt  int64 // Unix timestamp
+	Title      *string
+	Note       *string
+	Summary    *string
+	Archived   bool
+	Favourited bool
+	Tags       []string // tag names
+}
+
+// Created is the result of creating one bookmark of a batch (see Target.CreateBatch).
+type Created struct {
+	Remote Remote
+	Exists bool  // the service already had a bookmark for the URL, which Remote is
+	Err    error // the bookmark wasn't created
+}
+
+// NoteLimitError is returned by a Target whose service rejected a note as too long.
+// The Syncer then truncates this and later notes to the limit and tries again.
+type NoteLimitError struct {
+	Limit int   // maximum note length, in characters
+	Err   error // the service's error
+}
+
+func (e *NoteLimitError) Error() string {
+	return fmt.Sprintf("note longer than %d characters: %v", e.Limit, e.Err)
+}
+
+func (e *NoteLimitError) Unwrap() error {
+	return e.Err
+}
+
+// Changes represents the fields to update on a Remote bookmark. Nil fields are left untouched.
+type Changes struct {
+	CreatedAt  *int64 // Unix timestamp
+	Title      *string
+	Note       *string
+	Summary    *string
+	Archived   *bool
+	Favourited *bool
+}
@@ -0,0 +1,41 @@
+package syncer
+
+import (
+	"slices"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+// Discrepancy is a converted bookmark that isn't in Karakeep as expected after a sync.
+type Discrepancy struct {
+	Bookmark    converter.Bookmark
+	Missing     bool     // no Karakeep bookmark has its URL or provenance tag
+	RemoteURL   string   // URL of the Karakeep bookmark, unless missing
+	MissingTags []string // tags of the bookmark the Karakeep one lacks
+}
+
+// VerifyBookmarks checks that every converted bookmark exists among the existing Karakeep bookmarks,
+// by URL or else by provenance tag like DiffBookmarks, with all its tags. Notes aren't checked, as a sync merges them
+// with the notes in Karakeep (see DiffBookmarks to compare them).
+func VerifyBookmarks(bookmarks []converter.Bookmark, existing map[string]karakeep.ExistingBookmark) []Discrepancy {
+	var discrepancies []Discrepancy
+	index := newRemoteIndex(existing)
+	for _, bm := range bookmarks {
+		url, remote, found := index.lookup(bm)
+		if !found {
+			discrepancies = append(discrepancies, Discrepancy{Bookmark: bm, Missing: true})
+			continue
+		}
+		var missingTags []string
+		for _, tag := range bm.Tags {
+			if !slices.Contains(remote.Tags, tag) {
+				missingTags = append(missingTags, tag)
+			}
+		}
+		if len(missingTags) > 0 {
+			discrepancies = append(discrepancies, Discrepancy{Bookmark: bm, RemoteURL: url, MissingTags: missingTags})
+		}
+	}
+	return discrepancies
+}
@@ -0,0 +1,36 @@
+package syncer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/pkg/karakeep"
+)
+
+func TestVerifyBookmarks(t *testing.T) {
+	bookmark := func(url string, tags ...string) converter.Bookmark {
+		return converter.Bookmark{Tags: tags, Content: converter.BookmarkContent{URL: url}}
+	}
+	bookmarks := []converter.Bookmark{
+		bookmark("https://a.com", "src:hackernews", "hn:ask"),
+		bookmark("https://b.com", "src:hackernews", "hn:show"),
+		bookmark("https://c.com", "src:hackernews"),
+	}
+	existing := map[string]karakeep.ExistingBookmark{
+		"https://a.com": {Tags: []string{"hn:ask", "reading", "src:hackernews"}}, // extra tags are fine
+		"https://b.com": {Tags: []string{"src:hackernews"}},
+	}
+
+	got := VerifyBookmarks(bookmarks, existing)
+
+	if len(got) != 2 {
+		t.Fatalf("got %d discrepancies, want 2: %v", len(got), got)
+	}
+	if got[0].Bookmark.Content.URL != "https://b.com" || got[0].Missing || !slices.Equal(got[0].MissingTags, []string{"hn:show"}) {
+		t.Errorf("got[0] = %+v, want https://b.com lacking hn:show", got[0])
+	}
+	if got[1].Bookmark.Content.URL != "https://c.com" || !got[1].Missing {
+		t.Errorf("got[1] = %+v, want https://c.com missing", got[1])
+	}
+}
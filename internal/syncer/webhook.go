@@ -0,0 +1,74 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/webhook"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// webhookTarget is the Target for a generic webhook endpoint.
+type webhookTarget struct {
+	client *webhook.Client
+}
+
+// NewWebhookTarget returns a Target posting every bookmark, tags included, to the webhook endpoint.
+// The endpoint can't be queried, so every bookmark counts as created and is never updated.
+func NewWebhookTarget(client *webhook.Client) Target {
+	return &webhookTarget{client: client}
+}
+
+func (t *webhookTarget) Exists(context.Context, converter.Bookmark) (Remote, bool, error) {
+	return Remote{}, false, nil
+}
+
+func (t *webhookTarget) Create(ctx context.Context, bm converter.Bookmark) (Remote, bool, error) {
+	payload := webhook.Bookmark{
+		URL:        bm.Content.URL,
+		Type:       bm.Content.Type,
+		Text:       bm.Content.Text,
+		Title:      bm.Title,
+		Note:       bm.Note,
+		Tags:       bm.Tags,
+		CreatedAt:  unixToISO8601(bm.CreatedAt),
+		Archived:   bm.Archived,
+		Favourited: bm.Favourited,
+		HNID:       bm.Origin.ID,
+		Author:     bm.Origin.Author,
+		Score:      bm.Origin.Score,
+	}
+	if payload.Tags == nil {
+		payload.Tags = []string{}
+	}
+	if bm.Origin.ID != 0 {
+		payload.HNURL = hackernews.DiscussionURL(bm.Origin.ID)
+	}
+	if err := t.client.Post(ctx, payload); err != nil {
+		return Remote{}, false, err
+	}
+	return Remote{ID: bm.Content.URL, CreatedAt: bm.CreatedAt}, false, nil
+}
+
+// CreateBatch is unsupported, since the webhook payload is a single bookmark.
+func (t *webhookTarget) CreateBatch(context.Context, []converter.Bookmark) ([]Created, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// AttachTags is a no-op, since the tags are part of the posted bookmark.
+func (t *webhookTarget) AttachTags(context.Context, string, []string) error { return nil }
+
+// CreateHighlights is a no-op, since the webhook payload has no highlights.
+func (t *webhookTarget) CreateHighlights(context.Context, string, []converter.Highlight) error {
+	return nil
+}
+
+// Crawl is a no-op, since the receiving side fetches whatever it wants.
+func (t *webhookTarget) Crawl(context.Context, string) error {
+	return nil
+}
+
+// Update and DetachTags are never called, since bookmarks never exist (see Exists).
+func (t *webhookTarget) Update(context.Context, string, Changes) error      { return nil }
+func (t *webhookTarget) DetachTags(context.Context, string, []string) error { return nil }
@@ -0,0 +1,3 @@
+// Package transform runs a user-supplied Starlark script that rewrites or drops bookmarks during conversion,
+// for per-user logic that the tag rules and note templates can't express.
+package transform
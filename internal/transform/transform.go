@@ -0,0 +1,263 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// funcName is the function a transform script must define.
+const funcName = "transform"
+
+// defaultTimeout bounds a single call of the script, so an endless loop fails the run instead of hanging it.
+const defaultTimeout = 10 * time.Second
+
+// Script is a loaded Starlark transform script. It defines a function
+//
+//	def transform(bookmark, item):
+//
+// called once per bookmark with the converted bookmark as a dict of id, timestamp, url, title, tags,
+// and note, and the HN item as a dict as returned by the HN API. The function may change title, tags,
+// and note of the bookmark dict in place, and returns False to drop the bookmark or None to keep it.
+type Script struct {
+	path    string
+	fn      starlark.Callable
+	timeout time.Duration
+
+	mu      sync.Mutex
+	dropped int
+}
+
+// Load reads and runs the script at path, which must define the transform function.
+func Load(path string) (*Script, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading transform script: %w", err)
+	}
+	thread := newThread(path)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("loading transform script %s: %w", path, scriptError(err))
+	}
+	fn, ok := globals[funcName].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("transform script %s must define a function %s(bookmark, item)", path, funcName)
+	}
+	globals.Freeze() // calls may then share the globals safely
+	return &Script{path: path, fn: fn, timeout: defaultTimeout}, nil
+}
+
+// newThread returns a Starlark thread whose print goes to stderr, e.g., for debugging a script.
+func newThread(path string) *starlark.Thread {
+	return &starlark.Thread{
+		Name: path,
+		Print: func(_ *starlark.Thread, msg string) {
+			_, _ = fmt.Fprintln(os.Stderr, msg)
+		},
+	}
+}
+
+// Transform calls the script's function with the bookmark and applies its changes, implementing
+// converter.Transformer. On error, the bookmark is left unchanged.
+func (s *Script) Transform(bm harmonic.Bookmark, item *hackernews.Item, b *converter.Bookmark) (bool, error) {
+	itemValue, err := itemDict(item)
+	if err != nil {
+		return true, err
+	}
+	tags := make([]starlark.Value, len(b.Tags))
+	for i, tag := range b.Tags {
+		tags[i] = starlark.String(tag)
+	}
+	bookmark := starlark.NewDict(6)
+	for key, value := range map[string]starlark.Value{
+		"id":        starlark.MakeInt(bm.ID),
+		"timestamp": starlark.MakeInt64(bm.Timestamp),
+		"url":       starlark.String(b.Content.URL),
+		"title":     optionalString(b.Title),
+		"tags":      starlark.NewList(tags),
+		"note":      optionalString(b.Note),
+	} {
+		if err := bookmark.SetKey(starlark.String(key), value); err != nil {
+			return true, err
+		}
+	}
+
+	thread := newThread(s.path)
+	timer := time.AfterFunc(s.timeout, func() {
+		thread.Cancel(fmt.Sprintf("timed out after %s", s.timeout))
+	})
+	result, err := starlark.Call(thread, s.fn, starlark.Tuple{bookmark, itemValue}, nil)
+	timer.Stop()
+	if err != nil {
+		return true, scriptError(err)
+	}
+
+	switch result {
+	case starlark.None, starlark.True:
+	case starlark.False:
+		s.mu.Lock()
+		s.dropped++
+		s.mu.Unlock()
+		return false, nil
+	default:
+		return true, fmt.Errorf("%s() returned %s, want None to keep or False to drop the bookmark", funcName, result.Type())
+	}
+
+	title, err := stringField(bookmark, "title")
+	if err != nil {
+		return true, err
+	}
+	note, err := stringField(bookmark, "note")
+	if err != nil {
+		return true, err
+	}
+	newTags, err := tagsField(bookmark)
+	if err != nil {
+		return true, err
+	}
+	b.Title, b.Tags, b.Note = title, newTags, note
+	if b.Note != nil && *b.Note == "" {
+		b.Note = nil // same as an empty rendered note
+	}
+	return true, nil
+}
+
+// Dropped returns the number of bookmarks the script dropped.
+func (s *Script) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// scriptError adds the Starlark backtrace to errors raised by the script, which names the failing line.
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+// optionalString returns s as a Starlark string, or None if s is nil.
+func optionalString(s *string) starlark.Value {
+	if s == nil {
+		return starlark.None
+	}
+	return starlark.String(*s)
+}
+
+// stringField returns the string or None value of key in the bookmark dict.
+func stringField(bookmark *starlark.Dict, key string) (*string, error) {
+	v, _, err := bookmark.Get(starlark.String(key))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+	s, ok := starlark.AsString(v)
+	if !ok {
+		return nil, fmt.Errorf("bookmark %s is %s, want a string or None", key, v.Type())
+	}
+	return &s, nil
+}
+
+// tagsField returns the list of strings in the tags of the bookmark dict.
+func tagsField(bookmark *starlark.Dict) ([]string, error) {
+	v, _, err := bookmark.Get(starlark.String("tags"))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+	iterable, ok := v.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("bookmark tags is %s, want a list of strings", v.Type())
+	}
+	var tags []string
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var elem starlark.Value
+	for iter.Next(&elem) {
+		tag, ok := starlark.AsString(elem)
+		if !ok {
+			return nil, fmt.Errorf("bookmark tag %s is %s, want a string", elem, elem.Type())
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// itemDict converts the HN item to a Starlark dict with the fields of the HN API.
+func itemDict(item *hackernews.Item) (starlark.Value, error) {
+	if item == nil {
+		return starlark.None, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return toStarlark(v)
+}
+
+// toStarlark converts a decoded JSON value to a Starlark value.
+func toStarlark(v any) (starlark.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return starlark.MakeInt64(i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Float(f), nil
+	case []any:
+		elems := make([]starlark.Value, len(v))
+		for i, elem := range v {
+			sv, err := toStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = sv
+		}
+		return starlark.NewList(elems), nil
+	case map[string]any:
+		dict := starlark.NewDict(len(v))
+		for key, elem := range v {
+			sv, err := toStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(key), sv); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+	return nil, fmt.Errorf("unsupported JSON value %v", v)
+}
@@ -0,0 +1,151 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/converter"
+	"github.com/akhdanfadh/hnkeep/internal/harmonic"
+	"github.com/akhdanfadh/hnkeep/pkg/hackernews"
+)
+
+// loadScript loads a transform script with the given source.
+func loadScript(t *testing.T, src string) *Script {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transform.star")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	return s
+}
+
+func newBookmark(title string) *converter.Bookmark {
+	note := "a note"
+	return &converter.Bookmark{
+		Title:   &title,
+		Content: converter.NewBookmarkContent("https://example.com"),
+		Tags:    []string{"hn"},
+		Note:    &note,
+	}
+}
+
+func TestScript_Transform(t *testing.T) {
+	// drops item 2, retitles and retags item 1 after checking the arguments, keeps the rest
+	s := loadScript(t, `
+def transform(bookmark, item):
+    if bookmark["id"] == 2:
+        return False
+    if bookmark["id"] == 1 and bookmark["url"] == "https://example.com" and item["score"] == 42 and bookmark["timestamp"] == 1700000000:
+        bookmark["title"] = bookmark["title"].replace("Old", "New")
+        bookmark["tags"].append("score:" + str(item["score"]))
+        bookmark["note"] = ""
+`)
+
+	b := newBookmark("Old")
+	keep, err := s.Transform(harmonic.Bookmark{ID: 1, Timestamp: 1700000000}, &hackernews.Item{ID: 1, Score: 42}, b)
+	if err != nil || !keep {
+		t.Fatalf("Transform() = %v, %v, want true, nil", keep, err)
+	}
+	if *b.Title != "New" || !slices.Equal(b.Tags, []string{"hn", "score:42"}) || b.Note != nil {
+		t.Errorf("Transform() bookmark = %q %v %v, want New [hn score:42] with no note", *b.Title, b.Tags, b.Note)
+	}
+
+	if keep, err := s.Transform(harmonic.Bookmark{ID: 2}, &hackernews.Item{ID: 2}, newBookmark("Drop")); err != nil || keep {
+		t.Errorf("Transform() = %v, %v, want false, nil", keep, err)
+	}
+
+	b = newBookmark("Same")
+	if keep, err := s.Transform(harmonic.Bookmark{ID: 3}, &hackernews.Item{ID: 3}, b); err != nil || !keep {
+		t.Errorf("Transform() = %v, %v, want true, nil", keep, err)
+	}
+	if *b.Title != "Same" || *b.Note != "a note" || !slices.Equal(b.Tags, []string{"hn"}) {
+		t.Errorf("Transform() without changes changed the bookmark: %q %q %v", *b.Title, *b.Note, b.Tags)
+	}
+
+	if got := s.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
+
+func TestScript_Transform_Errors(t *testing.T) {
+	tests := map[string]struct {
+		src     string
+		wantErr string
+	}{
+		"runtime error": {
+			src:     "def transform(bookmark, item):\n    return 1 // 0\n",
+			wantErr: "division by zero",
+		},
+		"bad return value": {
+			src:     "def transform(bookmark, item):\n    return \"yes\"\n",
+			wantErr: "returned string",
+		},
+		"bad tags": {
+			src:     "def transform(bookmark, item):\n    bookmark[\"tags\"] = [1]\n",
+			wantErr: "want a string",
+		},
+		"bad title": {
+			src:     "def transform(bookmark, item):\n    bookmark[\"title\"] = 1\n",
+			wantErr: "want a string or None",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := loadScript(t, tc.src)
+			b := newBookmark("Old")
+			keep, err := s.Transform(harmonic.Bookmark{ID: 1}, &hackernews.Item{ID: 1}, b)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Transform() error = %v, want containing %q", err, tc.wantErr)
+			}
+			if !keep || *b.Title != "Old" || !slices.Equal(b.Tags, []string{"hn"}) {
+				t.Errorf("Transform() = %v with %q %v, want the bookmark kept unchanged", keep, *b.Title, b.Tags)
+			}
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		s := loadScript(t, "def transform(bookmark, item):\n    for i in range(1 << 40):\n        pass\n")
+		s.timeout = 10 * time.Millisecond
+		if _, err := s.Transform(harmonic.Bookmark{ID: 1}, &hackernews.Item{ID: 1}, newBookmark("x")); err == nil ||
+			!strings.Contains(err.Error(), "timed out") {
+			t.Errorf("Transform() error = %v, want a timeout", err)
+		}
+	})
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]struct {
+		src     string
+		wantErr string
+	}{
+		"syntax error":  {"def transform(:\n", "loading transform script"},
+		"no function":   {"x = 1\n", "must define a function transform"},
+		"not callable":  {"transform = 1\n", "must define a function transform"},
+		"top-level err": {"fail(\"boom\")\n", "boom"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "transform.star")
+			if err := os.WriteFile(path, []byte(tc.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.star")); err == nil {
+		t.Error("Load() of a missing file succeeded, want an error")
+	}
+}
@@ -0,0 +1,103 @@
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://archive.org"
+	defaultTimeout = 30 * time.Second
+)
+
+// Client looks up archived snapshots in the Wayback Machine.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// ClientOption configures the Client.
+type ClientOption func(*Client)
+
+// NewClient creates a new Wayback Machine client with the given options.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    defaultBaseURL,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithBaseURL sets a custom base URL for the availability API.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithTimeout sets the timeout for HTTP requests.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = d
+	}
+}
+
+// availabilityResponse is the response of the availability API.
+// Refer to https://archive.org/help/wayback_api.php
+type availabilityResponse struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Closest returns the URL of the snapshot of the given URL closest to the given time,
+// or "" if the Wayback Machine has no snapshot of it.
+func (c *Client) Closest(ctx context.Context, rawURL string, at time.Time) (string, error) {
+	query := url.Values{}
+	query.Set("url", rawURL)
+	query.Set("timestamp", at.UTC().Format("20060102150405"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/wayback/available?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("availability API returned HTTP %d", resp.StatusCode)
+	}
+	var avail availabilityResponse
+	if err := json.NewDecoder(resp.Body).Decode(&avail); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+
+	closest := avail.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return "", nil
+	}
+	// snapshots are served over https too, the API just returns http URLs
+	return strings.Replace(closest.URL, "http://", "https://", 1), nil
+}
@@ -0,0 +1,64 @@
+package wayback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Closest(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		status  int
+		want    string
+		wantErr bool
+	}{
+		"snapshot": {
+			body: `{"url": "example.com/post", "archived_snapshots": {"closest": {"status": "200", "available": true,
+				"url": "http://web.archive.org/web/20130919044612/http://example.com/post", "timestamp": "20130919044612"}}}`,
+			want: "https://web.archive.org/web/20130919044612/http://example.com/post",
+		},
+		"no snapshot": {
+			body: `{"url": "example.com/post", "archived_snapshots": {}}`,
+		},
+		"api error": {
+			status:  http.StatusServiceUnavailable,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotQuery = r.URL.RawQuery
+				if tc.status != 0 {
+					w.WriteHeader(tc.status)
+					return
+				}
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			c := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+			got, err := c.Closest(context.Background(), "http://example.com/post", time.Unix(1379566000, 0))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Closest() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Closest() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Closest() = %q, want %q", got, tc.want)
+			}
+			if want := "timestamp=20130919044640&url=http%3A%2F%2Fexample.com%2Fpost"; gotQuery != want {
+				t.Errorf("query = %q, want %q", gotQuery, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package wayback provides a client for the Wayback Machine availability API of archive.org.
+package wayback
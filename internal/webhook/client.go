@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akhdanfadh/hnkeep/internal/logger"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultRetryWait  = time.Second
+)
+
+// maxErrorBody caps how much of an error response body is kept in HTTPError.
+const maxErrorBody = 512
+
+// Client posts bookmarks to a webhook endpoint.
+type Client struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+	logger     logger.Logger
+	onLimited  func()
+}
+
+// ClientOption configures the Client.
+type ClientOption func(*Client)
+
+// NewClient creates a new webhook client posting to the given URL with the given options.
+func NewClient(url string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:        url,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		retryWait:  defaultRetryWait,
+		logger:     logger.Noop(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithHeader sets a header sent with every request, e.g., for authentication.
+func WithHeader(name, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(map[string]string)
+		}
+		c.headers[name] = value
+	}
+}
+
+// WithMaxRetries sets the maximum number of attempts per request.
+func WithMaxRetries(n int) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = n
+	}
+}
+
+// WithRetryWait sets the base wait duration between retries, doubled after each attempt.
+func WithRetryWait(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryWait = d
+	}
+}
+
+// WithLogger sets the logger for retry visibility.
+func WithLogger(l logger.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithRateLimitHook sets a function called whenever the endpoint responds with HTTP 429,
+// e.g., to lower the number of concurrent requests (see adaptive.Limiter).
+func WithRateLimitHook(fn func()) ClientOption {
+	return func(c *Client) {
+		c.onLimited = fn
+	}
+}
+
+// WithTimeout sets the timeout for HTTP requests.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = d
+	}
+}
+
+// Post posts the bookmark as JSON. Any 2xx response is a success. Network errors, rate limiting,
+// and server errors are retried with exponential backoff; other responses fail immediately.
+// Since a retried request may have been processed already, endpoints should tolerate duplicates.
+func (c *Client) Post(ctx context.Context, bm Bookmark) error {
+	body, err := json.Marshal(bm)
+	if err != nil {
+		return fmt.Errorf("encoding bookmark: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < c.maxRetries; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		var httpErr HTTPError
+		if errors.Is(err, ErrUnauthorized) || (errors.As(err, &httpErr) && !httpErr.retryable()) {
+			return err
+		}
+		if httpErr.StatusCode == http.StatusTooManyRequests && c.onLimited != nil {
+			c.onLimited()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err() // user cancellation
+		}
+		lastErr = err
+		if attempt == c.maxRetries-1 {
+			break // no point waiting after the last attempt
+		}
+
+		backoff := min(c.retryWait*time.Duration(1<<attempt), 30*time.Second)
+		c.logger.Warn("webhook request failed (attempt %d/%d): %v, retrying in %s...", attempt+1, c.maxRetries, err, backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", c.maxRetries, lastErr)
+}
+
+// post performs a single POST request.
+func (c *Client) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hnkeep")
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Debug("POST %s failed after %s: %v", c.url, time.Since(start).Round(time.Millisecond), err)
+		return fmt.Errorf("request failed: %w", err)
+	}
+	c.logger.Debug("POST %s -> %d (%s)", c.url, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	defer func() { _ = resp.Body.Close() }() // close error not actionable after body is read
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		_, _ = io.Copy(io.Discard, resp.Body) // allow connection reuse
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return HTTPError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+}
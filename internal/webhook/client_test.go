@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_Post(t *testing.T) {
+	t.Run("posts the bookmark as JSON", func(t *testing.T) {
+		var got Bookmark
+		var token string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("got %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+			}
+			token = r.Header.Get("X-Token")
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithHTTPClient(server.Client()), WithHeader("X-Token", "secret"))
+		bm := Bookmark{URL: "https://example.com", Type: "link", Tags: []string{"go"}, HNID: 1}
+		if err := client.Post(context.Background(), bm); err != nil {
+			t.Fatalf("Post() unexpected error: %v", err)
+		}
+		if got.URL != bm.URL || got.HNID != 1 || len(got.Tags) != 1 {
+			t.Errorf("posted %+v, want %+v", got, bm)
+		}
+		if token != "secret" {
+			t.Errorf("X-Token = %q, want %q", token, "secret")
+		}
+	})
+
+	testCases := []struct {
+		name      string
+		status    int
+		wantCalls int32
+		wantErr   error
+	}{
+		{"retries server errors", http.StatusBadGateway, 3, nil},
+		{"retries rate limiting", http.StatusTooManyRequests, 3, nil},
+		{"fails on client errors", http.StatusBadRequest, 1, nil},
+		{"fails on auth errors", http.StatusForbidden, 1, ErrUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, WithHTTPClient(server.Client()), WithRetryWait(0))
+			err := client.Post(context.Background(), Bookmark{URL: "https://example.com"})
+			if err == nil {
+				t.Fatal("Post() expected error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Post() error = %v, want %v", err, tc.wantErr)
+			}
+			var httpErr HTTPError
+			if tc.wantErr == nil && (!errors.As(err, &httpErr) || httpErr.StatusCode != tc.status) {
+				t.Errorf("Post() error = %v, want HTTP %d", err, tc.status)
+			}
+			if got := calls.Load(); got != tc.wantCalls {
+				t.Errorf("got %d calls, want %d", got, tc.wantCalls)
+			}
+		})
+	}
+}
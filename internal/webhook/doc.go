@@ -0,0 +1,2 @@
+// Package webhook provides a client posting converted bookmarks as JSON to a user-supplied endpoint.
+package webhook
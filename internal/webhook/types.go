@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Bookmark represents the JSON body posted for each bookmark.
+type Bookmark struct {
+	URL        string   `json:"url"`
+	Type       string   `json:"type"`           // "link" or "text"
+	Text       string   `json:"text,omitempty"` // post text of text bookmarks
+	Title      *string  `json:"title"`          // nullable
+	Note       *string  `json:"note"`           // nullable
+	Tags       []string `json:"tags"`           // empty array if no tags
+	CreatedAt  string   `json:"createdAt"`      // when it was saved (ISO8601)
+	Archived   bool     `json:"archived"`
+	Favourited bool     `json:"favourited"`
+	HNID       int      `json:"hnId,omitempty"` // HN item the bookmark was converted from
+	HNURL      string   `json:"hnUrl,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	Score      int      `json:"score,omitempty"`
+}
+
+// ErrUnauthorized is returned when the endpoint rejects the request with HTTP 401 or 403.
+var ErrUnauthorized = errors.New("unauthorized by webhook endpoint")
+
+// HTTPError represents an unexpected HTTP response from the endpoint.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("webhook endpoint returned HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("webhook endpoint returned HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable reports whether the request may succeed when repeated, i.e., on rate limiting and server errors.
+func (e HTTPError) retryable() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
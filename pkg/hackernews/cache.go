@@ -0,0 +1,363 @@
+package hackernews
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache permanent-error states for negative caching.
+const (
+	cacheErrDeleted = "deleted"
+	cacheErrDead    = "dead"
+)
+
+// cacheEntry wraps an item with optional error state for negative caching.
+type cacheEntry struct {
+	Item  *Item  `json:"item,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// inflightCall deduplicates concurrent fetches for the same item (singleflight pattern).
+type inflightCall struct {
+	wg   sync.WaitGroup
+	item *Item
+	err  error
+}
+
+// CachedClient wraps a Client with caching capabilities.
+type CachedClient struct {
+	client   *Client
+	cacheDir string
+	ttl      time.Duration // zero means entries never expire
+	memory   *lruCache     // recently used entries, to spare the disk on repeated lookups
+	compress bool          // gzip new entries
+	logger   Logger
+
+	mu        sync.Mutex
+	inflight  map[int]*inflightCall
+	cacheHits atomic.Int32
+}
+
+// CacheOption configures the CachedClient.
+type CacheOption func(*CachedClient)
+
+// WithCacheLogger sets a custom Logger for the CachedClient.
+func WithCacheLogger(l Logger) CacheOption {
+	return func(c *CachedClient) {
+		c.logger = l
+	}
+}
+
+// WithCacheTTL sets the maximum age of cache entries. Older entries are refetched from the API,
+// falling back to the cached copy if the refetch fails transiently. Zero means entries never expire.
+func WithCacheTTL(d time.Duration) CacheOption {
+	return func(c *CachedClient) {
+		c.ttl = d
+	}
+}
+
+// WithCacheCompression gzips new cache entries, which shrinks them to roughly a third.
+// Reading is transparent either way, so compressed and plain entries can share a cache directory.
+func WithCacheCompression(enabled bool) CacheOption {
+	return func(c *CachedClient) {
+		c.compress = enabled
+	}
+}
+
+// WithMemoryCacheSize sets how many recently used entries are also kept in memory (default 1024).
+// Zero disables the in-memory layer, so every lookup reads the disk cache.
+func WithMemoryCacheSize(n int) CacheOption {
+	return func(c *CachedClient) {
+		c.memory = newLRUCache(n)
+	}
+}
+
+// NewCachedClient creates a client that caches responses in the given directory.
+func NewCachedClient(client *Client, cacheDir string, opts ...CacheOption) (*CachedClient, error) {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &CachedClient{
+		client:   client,
+		cacheDir: cacheDir,
+		memory:   newLRUCache(defaultMemoryCacheSize),
+		logger:   noopLogger{},
+		inflight: make(map[int]*inflightCall),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// GetItem retrieves an item by ID, using the cache if available.
+func (c *CachedClient) GetItem(ctx context.Context, id int) (*Item, error) {
+	// check for early cancellation
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// the in-memory layer mirrors the disk cache, whose file age stays the source of truth for the TTL
+	expired := c.expired(id)
+	if !expired {
+		if e, ok := c.memory.get(id); ok {
+			c.cacheHits.Add(1)
+			c.logger.Info("cache hit for item %d (memory)", id)
+			return e.item, e.err
+		}
+	}
+
+	// try read from cache (includes negative cache hits), unless the entry has expired
+	item, err := c.readCache(id)
+	var stale *Item // expired item, used if the refetch fails transiently
+	switch {
+	case expired:
+		c.logger.Info("cache expired for item %d", id)
+		stale = item
+	case err == nil:
+		c.cacheHits.Add(1)
+		c.logger.Info("cache hit for item %d", id)
+		c.memory.put(id, item, nil)
+		return item, nil
+	case errors.Is(err, ErrItemDeleted) || errors.Is(err, ErrItemDead):
+		c.cacheHits.Add(1)
+		c.logger.Info("cache hit for item %d (negative)", id)
+		c.memory.put(id, nil, err)
+		return nil, err // cached error state
+	}
+
+	// cache miss, try to deduplicate concurrent fetches
+	c.mu.Lock()
+	if call, ok := c.inflight[id]; ok {
+		// another goroutine is already fetching this item, wait for it
+		c.mu.Unlock()
+		call.wg.Wait() // block until fetch is done
+		return call.item, call.err
+	}
+
+	// otherwise, we are the first so create an inflightCall
+	call := &inflightCall{}
+	call.wg.Add(1)
+	c.inflight[id] = call
+	c.mu.Unlock()
+
+	// fetch from API and cache result (best-effort), outside lock
+	call.item, call.err = c.client.GetItem(ctx, id)
+	switch {
+	case ctx.Err() != nil: // don't cache incomplete results
+	case call.err != nil && stale != nil && !isPermanentError(call.err):
+		// keep the expired entry on disk so it is refetched again next time
+		c.logger.Warn("refetching expired item %d failed, using cached copy: %v", id, call.err)
+		call.item, call.err = stale, nil
+	default:
+		_ = c.writeCache(id, call.item, call.err)
+		if call.err == nil || isPermanentError(call.err) && !errors.Is(call.err, ErrItemNotFound) {
+			c.memory.put(id, call.item, call.err)
+		}
+	}
+
+	// signal waiting goroutines and cleanup
+	c.mu.Lock()
+	delete(c.inflight, id)
+	c.mu.Unlock()
+	call.wg.Done()
+
+	return call.item, call.err
+}
+
+// CachedItem returns the item with the given ID from the cache only, without calling the API.
+// Returns an error wrapping os.ErrNotExist on a cache miss, or the cached error for deleted/dead items.
+func (c *CachedClient) CachedItem(id int) (*Item, error) {
+	return c.readCache(id)
+}
+
+// CacheHits returns the number of cache hits (both positive and negative).
+func (c *CachedClient) CacheHits() int {
+	return int(c.cacheHits.Load())
+}
+
+// expired reports whether the cache entry of the given item is older than the TTL.
+// Missing entries and a zero TTL never expire.
+func (c *CachedClient) expired(id int) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+	info, err := os.Stat(c.getCachePath(id))
+	if err != nil {
+		return false
+	}
+	return time.Since(info.ModTime()) > c.ttl
+}
+
+// isPermanentError reports whether the item is gone for good rather than temporarily unavailable.
+func isPermanentError(err error) bool {
+	return errors.Is(err, ErrItemNotFound) || errors.Is(err, ErrItemDeleted) || errors.Is(err, ErrItemDead)
+}
+
+// getCachePath returns the file path for the cached item with the given ID.
+func (c *CachedClient) getCachePath(id int) string {
+	return filepath.Join(c.cacheDir, fmt.Sprintf("%d.json", id))
+}
+
+// writeCache writes an item or error state to the cache.
+// Caches the item on success, or the error state for permanent errors (deleted/dead).
+func (c *CachedClient) writeCache(id int, item *Item, err error) error {
+	var entry cacheEntry
+
+	switch {
+	case err == nil && item != nil:
+		entry.Item = item
+	case errors.Is(err, ErrItemDeleted):
+		entry.Error = cacheErrDeleted
+	case errors.Is(err, ErrItemDead):
+		entry.Error = cacheErrDead
+	default:
+		return nil // don't cache unknown errors or nil results
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if c.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	return os.WriteFile(c.getCachePath(id), data, 0o644)
+}
+
+// ClearCache removes all cached items.
+func (c *CachedClient) ClearCache() error {
+	c.memory.clear()
+	if err := os.RemoveAll(c.cacheDir); err != nil {
+		return err
+	}
+	// recreate cacheDir so subsequent writeCache calls don't fail
+	return os.MkdirAll(c.cacheDir, 0o755)
+}
+
+// PruneOptions selects the cache entries removed by Prune. Zero values match all entries.
+type PruneOptions struct {
+	OlderThan    time.Duration // only entries older than this
+	NegativeOnly bool          // only negative entries (deleted/dead items)
+	DryRun       bool          // count matching entries without removing them
+}
+
+// Prune removes the cache entries matching the options and returns how many matched.
+// Files that are not cache entries are left alone.
+func (c *CachedClient) Prune(opts PruneOptions) (int, error) {
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue // not a cache entry
+		}
+
+		if opts.OlderThan > 0 {
+			info, err := e.Info()
+			if err != nil || time.Since(info.ModTime()) <= opts.OlderThan {
+				continue
+			}
+		}
+		if opts.NegativeOnly {
+			_, err := c.readCache(id)
+			if !errors.Is(err, ErrItemDeleted) && !errors.Is(err, ErrItemDead) {
+				continue
+			}
+		}
+
+		if !opts.DryRun {
+			if err := os.Remove(filepath.Join(c.cacheDir, name)); err != nil {
+				return removed, err
+			}
+			c.memory.remove(id)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// readCache reads the item with the given ID from the cache.
+// Returns the cached error if a negative cache entry exists.
+func (c *CachedClient) readCache(id int) (*Item, error) {
+	data, err := os.ReadFile(c.getCachePath(id))
+	if err != nil {
+		return nil, err
+	}
+	if data, err = decompress(data); err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	// both fields set is invalid as per the writeCache logic
+	if entry.Item != nil && entry.Error != "" {
+		return nil, os.ErrNotExist
+	}
+
+	// check for cached error state
+	if entry.Error != "" {
+		switch entry.Error {
+		case cacheErrDeleted:
+			return nil, ErrItemDeleted
+		case cacheErrDead:
+			return nil, ErrItemDead
+			// default: ignore unknown error states
+		}
+	}
+
+	// handle invalid/corrupted cache entries
+	// otherwise returning (nil, nil) would cause nil pointer dereference
+	if entry.Item == nil {
+		return nil, os.ErrNotExist
+	}
+
+	return entry.Item, nil
+}
+
+// gzipMagic is the header of gzip streams, used to tell compressed cache entries from plain JSON.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompress returns the JSON of a cache entry, gunzipping it if compressed.
+func decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+	return io.ReadAll(zr)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -428,3 +429,264 @@ func TestCachedClient_GetItem_ConcurrentSameID(t *testing.T) {
 		t.Errorf("expected 1 API call with concurrent requests, got %d", apiCalls.Load())
 	}
 }
+
+func TestCachedClient_GetItem_TTL(t *testing.T) {
+	var apiCalls atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Item{ID: 1, Title: fmt.Sprintf("Title v%d", apiCalls.Load())})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithHTTPClient(server.Client()),
+		WithBaseURL(server.URL),
+		WithRetries(1),
+		WithRetryWait(0),
+	)
+	cacheDir := t.TempDir()
+	cached, err := NewCachedClient(client, cacheDir, WithCacheTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create cached client: %v", err)
+	}
+	age := func(d time.Duration) {
+		old := time.Now().Add(-d)
+		if err := os.Chtimes(cached.getCachePath(1), old, old); err != nil {
+			t.Fatalf("failed to age cache entry: %v", err)
+		}
+	}
+
+	if _, err := cached.GetItem(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// fresh entry is served from cache
+	age(30 * time.Minute)
+	item, err := cached.GetItem(context.Background(), 1)
+	if err != nil || item.Title != "Title v1" || apiCalls.Load() != 1 {
+		t.Fatalf("expected cache hit, got item=%v err=%v calls=%d", item, err, apiCalls.Load())
+	}
+
+	// expired entry is refetched and rewritten
+	age(2 * time.Hour)
+	item, err = cached.GetItem(context.Background(), 1)
+	if err != nil || item.Title != "Title v2" || apiCalls.Load() != 2 {
+		t.Fatalf("expected refetch, got item=%v err=%v calls=%d", item, err, apiCalls.Load())
+	}
+	if item, err := cached.GetItem(context.Background(), 1); err != nil || item.Title != "Title v2" {
+		t.Fatalf("expected refreshed entry from cache, got item=%v err=%v", item, err)
+	}
+
+	// failed refetch of an expired entry falls back to the cached copy
+	age(2 * time.Hour)
+	failing.Store(true)
+	item, err = cached.GetItem(context.Background(), 1)
+	if err != nil || item.Title != "Title v2" {
+		t.Fatalf("expected stale fallback, got item=%v err=%v", item, err)
+	}
+	if !cached.expired(1) {
+		t.Error("expected entry to stay expired after a failed refetch")
+	}
+}
+
+func TestCachedClient_CachedItem(t *testing.T) {
+	var apiCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalls.Add(1)
+		_ = json.NewEncoder(w).Encode(Item{ID: 1, Title: "Cached"})
+	}))
+	defer server.Close()
+
+	cached, err := NewCachedClient(NewClient(WithBaseURL(server.URL)), t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// miss never calls the API
+	if _, err := cached.CachedItem(1); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected os.ErrNotExist on miss, got %v", err)
+	}
+	if apiCalls.Load() != 0 {
+		t.Fatalf("expected no API calls, got %d", apiCalls.Load())
+	}
+
+	if _, err := cached.GetItem(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	item, err := cached.CachedItem(1)
+	if err != nil {
+		t.Fatalf("unexpected error after fetch: %v", err)
+	}
+	if item.Title != "Cached" {
+		t.Errorf("expected title %q, got %q", "Cached", item.Title)
+	}
+	if apiCalls.Load() != 1 {
+		t.Errorf("expected 1 API call, got %d", apiCalls.Load())
+	}
+}
+
+func TestCachedClient_Prune(t *testing.T) {
+	setup := func(t *testing.T) *CachedClient {
+		t.Helper()
+		cached, err := NewCachedClient(NewClient(), t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to create cached client: %v", err)
+		}
+		old := time.Now().Add(-100 * 24 * time.Hour)
+		entries := map[int]error{1: nil, 2: ErrItemDeleted, 3: nil, 4: ErrItemDead}
+		for id, itemErr := range entries {
+			var item *Item
+			if itemErr == nil {
+				item = &Item{ID: id, Title: "Item"}
+			}
+			if err := cached.writeCache(id, item, itemErr); err != nil {
+				t.Fatalf("writeCache: %v", err)
+			}
+			if id <= 2 { // items 1 and 2 are old
+				if err := os.Chtimes(cached.getCachePath(id), old, old); err != nil {
+					t.Fatalf("Chtimes: %v", err)
+				}
+			}
+		}
+		// unrelated file must survive
+		if err := os.WriteFile(filepath.Join(cached.cacheDir, "notes.json"), []byte("{}"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		return cached
+	}
+	remaining := func(t *testing.T, c *CachedClient) []int {
+		t.Helper()
+		var ids []int
+		for id := 1; id <= 4; id++ {
+			if _, err := os.Stat(c.getCachePath(id)); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	tests := map[string]struct {
+		opts        PruneOptions
+		wantRemoved int
+		wantLeft    []int
+	}{
+		"all":                {PruneOptions{}, 4, nil},
+		"older than":         {PruneOptions{OlderThan: 90 * 24 * time.Hour}, 2, []int{3, 4}},
+		"negative only":      {PruneOptions{NegativeOnly: true}, 2, []int{1, 3}},
+		"old negative only":  {PruneOptions{OlderThan: 90 * 24 * time.Hour, NegativeOnly: true}, 1, []int{1, 3, 4}},
+		"dry run keeps data": {PruneOptions{DryRun: true}, 4, []int{1, 2, 3, 4}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cached := setup(t)
+			removed, err := cached.Prune(tc.opts)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			if removed != tc.wantRemoved {
+				t.Errorf("Prune() removed = %d, want %d", removed, tc.wantRemoved)
+			}
+			if got := remaining(t, cached); fmt.Sprint(got) != fmt.Sprint(tc.wantLeft) {
+				t.Errorf("remaining entries = %v, want %v", got, tc.wantLeft)
+			}
+			if _, err := os.Stat(filepath.Join(cached.cacheDir, "notes.json")); err != nil {
+				t.Errorf("unrelated file was removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCachedClient_GetItem_MemoryLayer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Item{ID: 1, Title: "From API"})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithHTTPClient(server.Client()), WithBaseURL(server.URL), WithRetries(1), WithRetryWait(0))
+
+	for _, tc := range []struct {
+		name string
+		opts []CacheOption
+		want string
+	}{
+		{"memory serves repeated lookups", nil, "From API"},
+		{"disabled memory reads disk", []CacheOption{WithMemoryCacheSize(0)}, "From disk"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cached, err := NewCachedClient(client, t.TempDir(), tc.opts...)
+			if err != nil {
+				t.Fatalf("failed to create cached client: %v", err)
+			}
+			if _, err := cached.GetItem(context.Background(), 1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// rewrite the disk entry behind the client's back to see which layer answers
+			if err := cached.writeCache(1, &Item{ID: 1, Title: "From disk"}, nil); err != nil {
+				t.Fatalf("failed to rewrite cache entry: %v", err)
+			}
+			item, err := cached.GetItem(context.Background(), 1)
+			if err != nil || item.Title != tc.want {
+				t.Errorf("expected %q, got item=%v err=%v", tc.want, item, err)
+			}
+			if cached.CacheHits() != 1 {
+				t.Errorf("expected 1 cache hit, got %d", cached.CacheHits())
+			}
+		})
+	}
+}
+
+func TestCachedClient_Compression(t *testing.T) {
+	item := &Item{ID: 1, Title: "Compressed", URL: "https://example.com"}
+	cacheDir := t.TempDir()
+
+	compressed, err := NewCachedClient(NewClient(), cacheDir, WithCacheCompression(true))
+	if err != nil {
+		t.Fatalf("failed to create cached client: %v", err)
+	}
+	if err := compressed.writeCache(1, item, nil); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	if err := compressed.writeCache(2, nil, ErrItemDead); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	data, err := os.ReadFile(compressed.getCachePath(1))
+	if err != nil {
+		t.Fatalf("failed to read cache file: %v", err)
+	}
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		t.Fatalf("expected gzip data, got %q", data)
+	}
+
+	// a client without compression reads compressed entries, and the other way around
+	plain, err := NewCachedClient(NewClient(), cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create cached client: %v", err)
+	}
+	if got, err := plain.readCache(1); err != nil || got.Title != item.Title {
+		t.Errorf("expected compressed item, got item=%v err=%v", got, err)
+	}
+	if _, err := plain.readCache(2); !errors.Is(err, ErrItemDead) {
+		t.Errorf("expected compressed negative entry, got %v", err)
+	}
+	if err := plain.writeCache(3, item, nil); err != nil {
+		t.Fatalf("failed to write cache: %v", err)
+	}
+	if got, err := compressed.readCache(3); err != nil || got.Title != item.Title {
+		t.Errorf("expected plain item, got item=%v err=%v", got, err)
+	}
+
+	// a truncated gzip entry is a cache miss, not a crash
+	if err := os.WriteFile(compressed.getCachePath(4), data[:len(data)/2], 0o644); err != nil {
+		t.Fatalf("failed to write truncated entry: %v", err)
+	}
+	if _, err := compressed.readCache(4); err == nil {
+		t.Error("expected error for truncated gzip entry")
+	}
+}
@@ -6,10 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
-
-	"github.com/akhdanfadh/hnkeep/internal/logger"
 )
 
 const (
@@ -25,7 +24,9 @@ type Client struct {
 	baseURL    string
 	maxRetries int
 	retryWait  time.Duration
-	logger     logger.Logger
+	limiter    *rateLimiter // nil means unlimited
+	logger     Logger
+	onLimited  func()
 }
 
 // ClientOption configures the Client.
@@ -38,7 +39,7 @@ func NewClient(opts ...ClientOption) *Client {
 		baseURL:    defaultBaseURL,
 		maxRetries: defaultMaxRetries,
 		retryWait:  defaultRetryWait,
-		logger:     logger.Noop(),
+		logger:     noopLogger{},
 	}
 
 	for _, opt := range opts {
@@ -75,13 +76,63 @@ func WithHTTPClient(client *http.Client) ClientOption {
 	}
 }
 
+// WithProxy sends the requests through the proxy at proxyURL (http, https, or socks5) instead of
+// the one of the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables. A nil proxyURL keeps those.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(c *Client) {
+		if proxyURL == nil {
+			return
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithRateLimit limits requests to rps per second across all goroutines using the client.
+// A non-positive rps disables rate limiting.
+func WithRateLimit(rps float64) ClientOption {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(rps)
+	}
+}
+
+// Logger receives the messages of the Client and CachedClient: failed attempts being retried as
+// warnings, and requests and cache activity as debug messages. Arguments are fmt.Printf style.
+type Logger interface {
+	Debug(format string, args ...any)
+	Info(format string, args ...any)
+	Warn(format string, args ...any)
+	Error(format string, args ...any)
+}
+
+// noopLogger is the default Logger, discarding all messages.
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...any) {}
+func (noopLogger) Info(string, ...any)  {}
+func (noopLogger) Warn(string, ...any)  {}
+func (noopLogger) Error(string, ...any) {}
+
 // WithLogger sets the logger for retry and rate limit visibility.
-func WithLogger(l logger.Logger) ClientOption {
+func WithLogger(l Logger) ClientOption {
 	return func(c *Client) {
 		c.logger = l
 	}
 }
 
+// WithRateLimitHook sets a function called whenever the API responds with HTTP 429,
+// e.g., to lower the number of concurrent requests.
+func WithRateLimitHook(fn func()) ClientOption {
+	return func(c *Client) {
+		c.onLimited = fn
+	}
+}
+
 // waitWithContext waits for the specified duration or until context is cancelled.
 // Uses NewTimer instead of time.After to avoid memory leak before Go 1.23 for explicitness.
 func waitWithContext(ctx context.Context, d time.Duration) error {
@@ -95,6 +146,42 @@ func waitWithContext(ctx context.Context, d time.Duration) error {
 	}
 }
 
+// maxRetryAfter caps the server-requested Retry-After delay so a bogus header can't stall a run.
+const maxRetryAfter = 5 * time.Minute
+
+// rateLimitError is returned on HTTP 429 and carries the delay requested via Retry-After (zero if absent).
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string { return ErrRateLimited.Error() }
+func (e *rateLimitError) Unwrap() error { return ErrRateLimited }
+
+// parseRetryAfter parses a Retry-After header value, given either as delay seconds or as an HTTP date.
+// Returns zero if the value is empty, invalid, or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
+
+// retryDelay returns how long to wait before the next attempt: the server's Retry-After delay
+// if the error carries one, otherwise exponential backoff capped at 30s.
+func retryDelay(err error, base time.Duration, attempt int) time.Duration {
+	var rlErr *rateLimitError
+	if errors.As(err, &rlErr) && rlErr.retryAfter > 0 {
+		return rlErr.retryAfter
+	}
+	return min(base*time.Duration(1<<attempt), 30*time.Second)
+}
+
 // GetItem fetches an item by its ID with retry logic.
 func (c *Client) GetItem(ctx context.Context, id int) (*Item, error) {
 	url := fmt.Sprintf("%s/item/%d.json", c.baseURL, id)
@@ -106,6 +193,13 @@ func (c *Client) GetItem(ctx context.Context, id int) (*Item, error) {
 			return nil, ctx.Err()
 		}
 
+		// every attempt, including retries, counts against the rate limit
+		if c.limiter != nil {
+			if err := c.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
 		item, err := c.fetchItem(ctx, url)
 		if err == nil {
 			return item, nil // immediate return on success
@@ -121,9 +215,12 @@ func (c *Client) GetItem(ctx context.Context, id int) (*Item, error) {
 			return nil, ctx.Err() // user cancelled
 		}
 
-		// exponential backoff capped at 30s for all retryable errors
-		backoff := min(c.retryWait*time.Duration(1<<attempt), 30*time.Second)
+		// honor Retry-After on 429, otherwise exponential backoff for all retryable errors
+		backoff := retryDelay(err, c.retryWait, attempt)
 		if errors.Is(err, ErrRateLimited) {
+			if c.onLimited != nil {
+				c.onLimited()
+			}
 			c.logger.Warn("rate limited, retrying in %s...", backoff)
 		} else {
 			c.logger.Warn("request failed (attempt %d/%d): %v, retrying in %s...", attempt+1, c.maxRetries, err, backoff)
@@ -145,14 +242,17 @@ func (c *Client) fetchItem(ctx context.Context, url string) (*Item, error) {
 		return nil, fmt.Errorf("create request failed: %w", err)
 	}
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.logger.Debug("GET %s failed after %s: %v", url, time.Since(start).Round(time.Millisecond), err)
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	c.logger.Debug("GET %s -> %d (%s)", url, resp.StatusCode, time.Since(start).Round(time.Millisecond))
 	defer func() { _ = resp.Body.Close() }() // close error not actionable after read
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
+		return nil, &rateLimitError{retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
 	}
 
 	var item Item
@@ -3,10 +3,13 @@ package hackernews
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestClient_GetItem(t *testing.T) {
@@ -170,6 +173,29 @@ func TestClient_GetItem_NetworkError(t *testing.T) {
 	}
 }
 
+func TestClient_GetItem_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String() // a proxy is sent the absolute URL
+		_ = json.NewEncoder(w).Encode(Item{ID: 1, Type: "story"})
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	client := NewClient(
+		WithBaseURL("http://hn.invalid/v0"), // unresolvable, only reachable through the proxy
+		WithProxy(proxyURL),
+		WithRetries(1),
+	)
+
+	if _, err := client.GetItem(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxied != "http://hn.invalid/v0/item/1.json" {
+		t.Errorf("proxied URL = %q, want %q", proxied, "http://hn.invalid/v0/item/1.json")
+	}
+}
+
 func TestClient_GetItem_RateLimited(t *testing.T) {
 	attempts := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -178,24 +204,63 @@ func TestClient_GetItem_RateLimited(t *testing.T) {
 	}))
 	defer server.Close()
 
+	limited := 0
 	client := NewClient(
 		WithBaseURL(server.URL),
 		WithRetries(3),
 		WithRetryWait(0), // no wait for test speed
+		WithRateLimitHook(func() { limited++ }),
 	)
 
 	_, err := client.GetItem(context.Background(), 3742902)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
-	if !strings.Contains(err.Error(), "rate limited") {
-		t.Errorf("expected error to contain 'rate limited', got %q", err.Error())
+	if limited != attempts {
+		t.Errorf("expected the rate limit hook to be called on each of %d attempts, got %d", attempts, limited)
+	}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %q", err.Error())
 	}
 	if attempts != 3 {
 		t.Errorf("expected 3 attempts with exponential backoff, got %d", attempts)
 	}
 }
 
+func TestRetryDelay(t *testing.T) {
+	tests := map[string]struct {
+		err     error
+		attempt int
+		want    time.Duration
+	}{
+		"generic error backs off":       {errors.New("boom"), 2, 4 * time.Second},
+		"backoff is capped":             {errors.New("boom"), 10, 30 * time.Second},
+		"rate limited without header":   {&rateLimitError{}, 1, 2 * time.Second},
+		"rate limited with Retry-After": {&rateLimitError{retryAfter: 7 * time.Second}, 0, 7 * time.Second},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := retryDelay(tc.err, time.Second, tc.attempt); got != tc.want {
+				t.Errorf("retryDelay() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 17, 12, 0, 0, 0, time.UTC)
+	if got := parseRetryAfter("3", now); got != 3*time.Second {
+		t.Errorf("seconds: got %s, want 3s", got)
+	}
+	if got := parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now); got != time.Minute {
+		t.Errorf("http date: got %s, want 1m", got)
+	}
+	if got := parseRetryAfter("later", now); got != 0 {
+		t.Errorf("invalid: got %s, want 0", got)
+	}
+}
+
 func TestDiscussionURL(t *testing.T) {
 	got := DiscussionURL(3742902)
 	want := "https://news.ycombinator.com/item?id=3742902"
@@ -0,0 +1,16 @@
+// Package hackernews provides a client for interacting with the Hacker News API.
+//
+// It is hnkeep's Hacker News client, usable by other Go tools as is. Client fetches items from
+// the official Firebase API with retries and an optional rate limit; CachedClient wraps it with
+// an on-disk cache of the items, so repeated runs only fetch what they have not seen:
+//
+//	client := hackernews.NewClient(hackernews.WithRateLimit(10))
+//	cached, err := hackernews.NewCachedClient(client, cacheDir, hackernews.WithCacheTTL(24*time.Hour))
+//	item, err := cached.GetItem(ctx, 8863)
+//	if errors.Is(err, hackernews.ErrItemDeleted) || errors.Is(err, hackernews.ErrItemDead) {
+//		// the item exists but was removed by its author or the moderators
+//	}
+//
+// Both honor the cancellation of the context given to GetItem. Their exported API follows semantic
+// versioning with hnkeep's releases: breaking changes only come with a new major version.
+package hackernews
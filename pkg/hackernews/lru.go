@@ -0,0 +1,83 @@
+package hackernews
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultMemoryCacheSize is the default number of entries kept in memory by CachedClient.
+const defaultMemoryCacheSize = 1024
+
+// lruEntry is an item or permanent error state kept in the in-memory cache.
+type lruEntry struct {
+	id   int
+	item *Item
+	err  error // ErrItemDeleted or ErrItemDead for negative entries
+}
+
+// lruCache is a bounded, concurrency-safe least-recently-used cache of items by ID.
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List            // front is the most recently used
+	entries map[int]*list.Element // id -> element in order
+}
+
+// newLRUCache creates an LRU cache holding up to size entries. A size of zero or less disables it.
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[int]*list.Element),
+	}
+}
+
+// get returns the cached entry for the given ID, and whether it was found.
+func (c *lruCache) get(id int) (lruEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[id]
+	if !ok {
+		return lruEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	return *el.Value.(*lruEntry), true
+}
+
+// put stores the item or error state for the given ID, evicting the least recently used entry if full.
+func (c *lruCache) put(id int, item *Item, err error) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[id]; ok {
+		el.Value = &lruEntry{id: id, item: item, err: err}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[id] = c.order.PushFront(&lruEntry{id: id, item: item, err: err})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).id)
+	}
+}
+
+// remove drops the entry for the given ID, if any.
+func (c *lruCache) remove(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[id]; ok {
+		c.order.Remove(el)
+		delete(c.entries, id)
+	}
+}
+
+// clear drops all entries.
+func (c *lruCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
@@ -0,0 +1,64 @@
+package hackernews
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLRUCache(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		c := newLRUCache(2)
+		c.put(1, &Item{ID: 1}, nil)
+		c.put(2, &Item{ID: 2}, nil)
+		c.get(1) // 2 is now the least recently used
+		c.put(3, &Item{ID: 3}, nil)
+
+		if _, ok := c.get(2); ok {
+			t.Error("expected item 2 to be evicted")
+		}
+		for _, id := range []int{1, 3} {
+			if e, ok := c.get(id); !ok || e.item.ID != id {
+				t.Errorf("expected item %d to be cached, got %+v ok=%v", id, e, ok)
+			}
+		}
+	})
+
+	t.Run("keeps negative entries", func(t *testing.T) {
+		c := newLRUCache(1)
+		c.put(1, nil, ErrItemDeleted)
+		if e, ok := c.get(1); !ok || !errors.Is(e.err, ErrItemDeleted) {
+			t.Errorf("expected deleted entry, got %+v ok=%v", e, ok)
+		}
+	})
+
+	t.Run("put replaces existing entry", func(t *testing.T) {
+		c := newLRUCache(2)
+		c.put(1, &Item{ID: 1, Title: "old"}, nil)
+		c.put(1, &Item{ID: 1, Title: "new"}, nil)
+		if e, _ := c.get(1); e.item.Title != "new" || c.order.Len() != 1 {
+			t.Errorf("expected single replaced entry, got %+v (len %d)", e.item, c.order.Len())
+		}
+	})
+
+	t.Run("remove and clear", func(t *testing.T) {
+		c := newLRUCache(3)
+		c.put(1, &Item{ID: 1}, nil)
+		c.put(2, &Item{ID: 2}, nil)
+		c.remove(1)
+		if _, ok := c.get(1); ok {
+			t.Error("expected item 1 to be removed")
+		}
+		c.clear()
+		if _, ok := c.get(2); ok {
+			t.Error("expected cache to be empty after clear")
+		}
+	})
+
+	t.Run("zero size disables cache", func(t *testing.T) {
+		c := newLRUCache(0)
+		c.put(1, &Item{ID: 1}, nil)
+		if _, ok := c.get(1); ok {
+			t.Error("expected nothing cached with zero size")
+		}
+	})
+}
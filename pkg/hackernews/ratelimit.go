@@ -0,0 +1,56 @@
+package hackernews
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket limiting the request rate across all goroutines sharing it.
+// The bucket holds at most burst tokens and refills at rps tokens per second.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time // for testing
+}
+
+// newRateLimiter creates a rate limiter allowing rps requests per second, starting with a full bucket.
+// The burst size is one second worth of requests (at least one).
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := max(rps, 1)
+	return &rateLimiter{
+		rps:    rps,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+// Tokens may go negative so that concurrent callers queue up instead of all waking at once.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rps)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rps * float64(time.Second))
+}
+
+// wait blocks until a request is allowed or the context is cancelled.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	d := l.reserve()
+	if d <= 0 {
+		return nil
+	}
+	return waitWithContext(ctx, d)
+}
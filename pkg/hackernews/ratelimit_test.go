@@ -0,0 +1,51 @@
+package hackernews
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2)
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	// full bucket allows a burst of rps requests
+	for i := range 2 {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("request %d: expected no wait, got %v", i, d)
+		}
+	}
+
+	// subsequent requests queue up at 1/rps intervals
+	if d := l.reserve(); d != 500*time.Millisecond {
+		t.Errorf("expected 500ms wait, got %v", d)
+	}
+	if d := l.reserve(); d != time.Second {
+		t.Errorf("expected 1s wait, got %v", d)
+	}
+
+	// the bucket refills over time, but never beyond the burst size
+	now = now.Add(10 * time.Second)
+	for i := range 2 {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("after refill request %d: expected no wait, got %v", i, d)
+		}
+	}
+	if d := l.reserve(); d == 0 {
+		t.Error("expected wait once refilled burst is used up")
+	}
+}
+
+func TestRateLimiter_WaitCancelled(t *testing.T) {
+	l := newRateLimiter(0.001) // one token, then a very long wait
+	l.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,447 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const listBookmarksPageSize = 100
+
+// CreateBookmark creates a new link-type bookmark from the given request.
+//
+// If the URL is new, it creates the bookmark and returns it with exists=false.
+// If the URL already exists, it returns the existing bookmark unedited with exists=true.
+// Refer to https://docs.karakeep.app/api/create-a-new-bookmark and the codebase.
+func (c *Client) CreateBookmark(ctx context.Context, reqBody *CreateBookmarkRequest) (*CreateBookmarkResponse, bool, error) {
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	var karakeepBM CreateBookmarkResponse
+	var alreadyExists bool
+
+	err = c.doRequestWithRetries(ctx, http.MethodPost, "/bookmarks", data, func(resp *http.Response) error {
+		alreadyExists = resp.StatusCode == http.StatusOK
+
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&karakeepBM); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &karakeepBM, alreadyExists, nil
+}
+
+// CreateBookmarks creates the bookmarks of the given requests in one request, each like CreateBookmark,
+// and returns their results in order. An error means none was created, e.g., as the server rejects batches.
+//
+// The REST API has no batch endpoint, so this calls the bookmarks.createBookmark procedure of Karakeep's
+// tRPC API once per bookmark in a single tRPC batch, the procedure behind the REST endpoint.
+// Refer to package/trpc/routers/bookmarks.ts of the codebase.
+func (c *Client) CreateBookmarks(ctx context.Context, reqs []*CreateBookmarkRequest) ([]CreateBookmarkResult, error) {
+	procedures := make([]string, len(reqs))
+	input := make(map[string]trpcRequest, len(reqs))
+	for i, req := range reqs {
+		procedures[i] = "bookmarks.createBookmark"
+		input[strconv.Itoa(i)] = trpcRequest{JSON: req}
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	var results []trpcResult
+	batchURL := c.trpcURL(strings.Join(procedures, ",")) + "?batch=1"
+	err = c.doURLWithRetries(ctx, http.MethodPost, batchURL, data, func(resp *http.Response) error {
+		// the status is shared by all calls, or 207 Multi-Status if only some failed,
+		// so a batch is told apart from an error of the whole request by its body
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading response: %w", err)
+		}
+		if json.Unmarshal(body, &results) != nil || len(results) != len(reqs) {
+			if resp.StatusCode >= 300 {
+				return HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+			}
+			return fmt.Errorf("decoding response: want a batch of %d results, got %.100s", len(reqs), body)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]CreateBookmarkResult, len(results))
+	for i, result := range results {
+		created[i] = decodeCreateResult(result)
+	}
+	return created, nil
+}
+
+// decodeCreateResult reads the result of one bookmarks.createBookmark call of a tRPC batch.
+func decodeCreateResult(result trpcResult) CreateBookmarkResult {
+	if result.Error != nil {
+		var trpcErr trpcError
+		if err := json.Unmarshal(result.Error.JSON, &trpcErr); err != nil || trpcErr.Data.HTTPStatus == 0 {
+			trpcErr.Data.HTTPStatus = http.StatusInternalServerError
+		}
+		return CreateBookmarkResult{Err: HTTPError{StatusCode: trpcErr.Data.HTTPStatus, Body: string(result.Error.JSON)}}
+	}
+	if result.Result == nil {
+		return CreateBookmarkResult{Err: errors.New("decoding response: result without data")}
+	}
+
+	// the output is the bookmark, as the REST endpoint returns it, with whether it already existed
+	var output struct {
+		CreateBookmarkResponse
+		AlreadyExists bool `json:"alreadyExists"`
+	}
+	if err := json.Unmarshal(result.Result.Data.JSON, &output); err != nil {
+		return CreateBookmarkResult{Err: fmt.Errorf("decoding response: %w", err)}
+	}
+	return CreateBookmarkResult{Bookmark: &output.CreateBookmarkResponse, Exists: output.AlreadyExists}
+}
+
+// AttachTags attaches tags to an existing bookmark by its ID.
+//
+// The endpoint is idempotent, meaning existing tags are not duplicated, and new tags are added.
+// Refer to https://docs.karakeep.app/api/attach-tags-to-a-bookmark and the codebase.
+func (c *Client) AttachTags(ctx context.Context, id string, tags []string) error {
+	if len(tags) == 0 {
+		return nil // nothing to do
+	}
+
+	data, err := marshalTagsRequest(tags)
+	if err != nil {
+		return err
+	}
+
+	return c.doRequestWithRetries(ctx, http.MethodPost, "/bookmarks/"+id+"/tags", data, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+
+		return nil
+	})
+}
+
+// DetachTags detaches tags from an existing bookmark by its ID.
+//
+// Tags not attached to the bookmark are ignored by the API.
+// Refer to https://docs.karakeep.app/api/detach-tags-from-a-bookmark and the codebase.
+func (c *Client) DetachTags(ctx context.Context, id string, tags []string) error {
+	if len(tags) == 0 {
+		return nil // nothing to do
+	}
+
+	data, err := marshalTagsRequest(tags)
+	if err != nil {
+		return err
+	}
+
+	return c.doRequestWithRetries(ctx, http.MethodDelete, "/bookmarks/"+id+"/tags", data, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+
+		return nil
+	})
+}
+
+// marshalTagsRequest builds the request body shared by the attach and detach tags endpoints.
+func marshalTagsRequest(tags []string) ([]byte, error) {
+	tagReqs := make([]TagRequest, len(tags))
+	for i, tag := range tags {
+		tagReqs[i] = TagRequest{TagName: tag}
+	}
+
+	data, err := json.Marshal(AttachTagsRequest{Tags: tagReqs})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+	return data, nil
+}
+
+// UpdateBookmark updates the non-nil fields of the request on an existing bookmark.
+// Refer to https://docs.karakeep.app/api/update-a-bookmark and the codebase.
+func (c *Client) UpdateBookmark(ctx context.Context, id string, reqBody UpdateBookmarkRequest) error {
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	return c.doRequestWithRetries(ctx, http.MethodPatch, "/bookmarks/"+id, data, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+
+		return nil
+	})
+}
+
+// RecrawlBookmark queues a crawl of the link bookmark with the given ID, like the web UI's refresh:
+// Karakeep fetches and archives the page again, then tags it with AI if enabled.
+//
+// The REST API has no crawl endpoint, so this calls the bookmarks.recrawlBookmark procedure of Karakeep's
+// tRPC API, which the web UI and apps use and which accepts the API key too.
+// Refer to package/trpc/routers/bookmarks.ts of the codebase.
+func (c *Client) RecrawlBookmark(ctx context.Context, id string) error {
+	data, err := json.Marshal(trpcRequest{JSON: recrawlInput{BookmarkID: id}})
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	return c.doURLWithRetries(ctx, http.MethodPost, c.trpcURL("bookmarks.recrawlBookmark"), data, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return nil
+	})
+}
+
+// trpcURL returns the URL of the tRPC procedure, served next to the REST API at /api/trpc.
+func (c *Client) trpcURL(procedure string) string {
+	return strings.TrimSuffix(c.baseURL, "/v1") + "/trpc/" + procedure
+}
+
+// ListBookmarks fetches all bookmarks and returns a map of URL to ExistingBookmark for deduplication.
+// It handles pagination internally and extracts URLs from both link and asset content types.
+// Refer to https://docs.karakeep.app/api/get-all-bookmarks and the codebase.
+func (c *Client) ListBookmarks(ctx context.Context) (map[string]ExistingBookmark, error) {
+	result := make(map[string]ExistingBookmark)
+	if err := c.listAllBookmarkPages(ctx, existingByURL(result)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListTaggedBookmarks is like ListBookmarks, but only fetches the bookmarks that have the tag with
+// the given name, which is much faster than paginating a large library.
+// Returns ErrTagNotFound if no such tag exists.
+func (c *Client) ListTaggedBookmarks(ctx context.Context, tagName string) (map[string]ExistingBookmark, error) {
+	tag, err := c.GetTagByName(ctx, tagName)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]ExistingBookmark)
+	if err := c.listBookmarkPages(ctx, "/tags/"+tag.ID+"/bookmarks", existingByURL(result)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindBookmarkByURL looks up the bookmark with the given URL using Karakeep's search, without listing
+// the whole library. The url: qualifier matches URLs containing the given one, so the exact URL is
+// picked among the results. Text bookmarks are not found, like in ListBookmarks.
+// Refer to https://docs.karakeep.app/api/search-bookmarks and https://docs.karakeep.app/guides/search-query-language.
+func (c *Client) FindBookmarkByURL(ctx context.Context, bmURL string) (ExistingBookmark, bool, error) {
+	bookmarks, err := c.SearchBookmarks(ctx, `url:"`+strings.ReplaceAll(bmURL, `"`, `\"`)+`"`)
+	if err != nil {
+		return ExistingBookmark{}, false, err
+	}
+	found := make(map[string]ExistingBookmark)
+	existingByURL(found)(bookmarks)
+	bm, ok := found[bmURL]
+	return bm, ok, nil
+}
+
+// SearchBookmarks returns all bookmarks matching the query of Karakeep's search query language,
+// e.g., "is:fav #hackernews".
+// Refer to https://docs.karakeep.app/api/search-bookmarks and https://docs.karakeep.app/guides/search-query-language.
+func (c *Client) SearchBookmarks(ctx context.Context, query string) ([]ListBookmark, error) {
+	var result []ListBookmark
+	err := c.listBookmarkPages(ctx, "/bookmarks/search?q="+url.QueryEscape(query), func(bookmarks []ListBookmark) {
+		result = append(result, bookmarks...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBookmark fetches a single bookmark by its ID.
+// Refer to https://docs.karakeep.app/api/get-a-single-bookmark and the codebase.
+func (c *Client) GetBookmark(ctx context.Context, id string) (*ListBookmark, error) {
+	var bm ListBookmark
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/bookmarks/"+id, nil, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&bm)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &bm, nil
+}
+
+// existingByURL returns a listBookmarkPages callback adding the listed bookmarks to result by URL.
+func existingByURL(result map[string]ExistingBookmark) func([]ListBookmark) {
+	return func(bookmarks []ListBookmark) {
+		for _, bm := range bookmarks {
+			bmURL := bm.Content.GetURL()
+			if bmURL == "" {
+				continue // skip text bookmarks
+			}
+			createdAt, err := iso8601ToUnix(bm.CreatedAt)
+			if err != nil {
+				continue // skip malformed entries
+			}
+			tags := make([]string, len(bm.Tags))
+			for i, tag := range bm.Tags {
+				tags[i] = tag.Name
+			}
+			result[bmURL] = ExistingBookmark{
+				ID:         bm.ID,
+				CreatedAt:  createdAt,
+				Title:      bm.Title,
+				Note:       bm.Note,
+				Summary:    bm.Summary,
+				Archived:   bm.Archived,
+				Favourited: bm.Favourited,
+				Tags:       tags,
+			}
+		}
+	}
+}
+
+// ListAllBookmarks fetches all bookmarks as listed by the API. Unlike ListBookmarks,
+// bookmarks sharing a URL are all returned, which is needed to find duplicates.
+func (c *Client) ListAllBookmarks(ctx context.Context) ([]ListBookmark, error) {
+	var result []ListBookmark
+	err := c.listAllBookmarkPages(ctx, func(bookmarks []ListBookmark) {
+		result = append(result, bookmarks...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListBookmarksByTag fetches all bookmarks that have the tag with the given name.
+// Returns ErrTagNotFound if no such tag exists.
+// Refer to https://docs.karakeep.app/api/get-bookmarks-with-the-tag and the codebase.
+func (c *Client) ListBookmarksByTag(ctx context.Context, tagName string) ([]ListBookmark, error) {
+	tag, err := c.GetTagByName(ctx, tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ListBookmark
+	err = c.listBookmarkPages(ctx, "/tags/"+tag.ID+"/bookmarks", func(bookmarks []ListBookmark) {
+		result = append(result, bookmarks...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// listBookmarkPages walks all pages of a paginated bookmarks endpoint, calling fn for each page.
+func (c *Client) listBookmarkPages(ctx context.Context, basePath string, fn func([]ListBookmark)) error {
+	progress := &listProgress{onPage: c.onListPage}
+	return c.walkBookmarkPages(ctx, basePath, "", progress, func(bookmarks []ListBookmark) bool {
+		fn(bookmarks)
+		return true
+	})
+}
+
+// walkBookmarkPages walks the pages of a paginated bookmarks endpoint from the given cursor ("" for
+// the first page), calling fn for each page until it returns false or the last page.
+func (c *Client) walkBookmarkPages(ctx context.Context, basePath, cursor string, progress *listProgress,
+	fn func([]ListBookmark) bool,
+) error {
+	page := 1
+
+	for {
+		// check for cancellation
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		sep := "?"
+		if strings.Contains(basePath, "?") {
+			sep = "&" // e.g., the search query
+		}
+		path := fmt.Sprintf("%s%slimit=%d", basePath, sep, listBookmarksPageSize)
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor) // if not escaped, may break for special chars
+		}
+
+		var listResp ListBookmarksResponse
+		err := c.doRequestWithRetries(ctx, http.MethodGet, path, nil, func(resp *http.Response) error {
+			if resp.StatusCode != http.StatusOK {
+				return readHTTPError(resp)
+			}
+			return json.NewDecoder(resp.Body).Decode(&listResp)
+		})
+		if err != nil {
+			return fmt.Errorf("listing bookmarks (page %d): %w", page, err)
+		}
+
+		more := fn(listResp.Bookmarks)
+		progress.add(len(listResp.Bookmarks))
+
+		if !more || listResp.NextCursor == nil || *listResp.NextCursor == "" {
+			return nil // no more pages
+		}
+		cursor = *listResp.NextCursor
+		page++
+	}
+}
+
+// DeleteBookmark permanently deletes a bookmark by its ID.
+// Refer to https://docs.karakeep.app/api/delete-a-bookmark and the codebase.
+func (c *Client) DeleteBookmark(ctx context.Context, id string) error {
+	return c.doRequestWithRetries(ctx, http.MethodDelete, "/bookmarks/"+id, nil, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+
+		return nil
+	})
+}
+
+// iso8601ToUnix converts an ISO8601 date string to a Unix timestamp (in seconds).
+func iso8601ToUnix(iso string) (int64, error) {
+	t, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		return 0, fmt.Errorf("parsing ISO8601 date %q: %w", iso, err)
+	}
+	return t.Unix(), nil
+}
@@ -0,0 +1,795 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ptr returns a pointer to the given string.
+func ptr(s string) *string { return &s }
+
+func TestClient_CreateBookmark(t *testing.T) {
+	tests := map[string]struct {
+		statusCode  int
+		response    CreateBookmarkResponse
+		rawResponse string // if set, write this instead of encoding response
+		wantExists  bool
+		wantErr     bool
+		errContain  string
+		errSentinel error
+	}{
+		"new bookmark created (201)": {
+			statusCode: http.StatusCreated,
+			response: CreateBookmarkResponse{
+				ID:        "bm-123",
+				CreatedAt: "2024-01-01T00:00:00Z",
+				Title:     ptr("Test Title"),
+			},
+			wantExists: false,
+		},
+		"existing bookmark returned (200)": {
+			statusCode: http.StatusOK,
+			response: CreateBookmarkResponse{
+				ID:        "bm-existing",
+				CreatedAt: "2023-06-15T12:00:00Z",
+				Title:     ptr("Existing Title"),
+				Note:      ptr("existing note"),
+			},
+			wantExists: true,
+		},
+		"unauthorized (401)": {
+			statusCode:  http.StatusUnauthorized,
+			wantErr:     true,
+			errSentinel: ErrUnauthorized,
+		},
+		"bad request (400)": {
+			statusCode: http.StatusBadRequest,
+			wantErr:    true,
+			errContain: "HTTP 400",
+		},
+		"malformed JSON response": {
+			statusCode:  http.StatusCreated,
+			rawResponse: `{"id": "bm-123", "createdAt": `, // truncated JSON
+			wantErr:     true,
+			errContain:  "decoding response",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// verify request method and path
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST, got %s", r.Method)
+				}
+				if r.URL.Path != "/bookmarks" {
+					t.Errorf("expected /bookmarks, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tc.statusCode)
+				if tc.statusCode == http.StatusCreated || tc.statusCode == http.StatusOK {
+					if tc.rawResponse != "" {
+						_, _ = w.Write([]byte(tc.rawResponse))
+					} else {
+						_ = json.NewEncoder(w).Encode(tc.response)
+					}
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-key",
+				WithHTTPClient(server.Client()),
+				WithMaxRetries(1),
+				WithRetryWait(0),
+			)
+
+			resp, exists, err := client.CreateBookmark(context.Background(), NewCreateBookmarkRequest(
+				"https://example.com",
+				"2024-01-01T00:00:00Z",
+				ptr("Test Title"),
+				nil,
+			))
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tc.errSentinel != nil && !errors.Is(err, tc.errSentinel) {
+					t.Errorf("expected error %v, got %v", tc.errSentinel, err)
+				}
+				if tc.errContain != "" && !strings.Contains(err.Error(), tc.errContain) {
+					t.Errorf("expected error to contain %q, got %q", tc.errContain, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exists != tc.wantExists {
+				t.Errorf("exists = %v, want %v", exists, tc.wantExists)
+			}
+			if resp.ID != tc.response.ID {
+				t.Errorf("response ID = %q, want %q", resp.ID, tc.response.ID)
+			}
+		})
+	}
+}
+
+func TestClient_AttachTags(t *testing.T) {
+	tests := map[string]struct {
+		tags        []string
+		statusCode  int
+		wantErr     bool
+		errSentinel error
+		wantNoCall  bool // expect no HTTP call (empty tags optimization)
+	}{
+		"empty tags no-op": {
+			tags:       []string{},
+			wantNoCall: true,
+		},
+		"nil tags no-op": {
+			tags:       nil,
+			wantNoCall: true,
+		},
+		"success attaching tags": {
+			tags:       []string{"hn", "imported"},
+			statusCode: http.StatusOK,
+		},
+		"bookmark not found (404)": {
+			tags:        []string{"tag1"},
+			statusCode:  http.StatusNotFound,
+			wantErr:     true,
+			errSentinel: ErrBookmarkNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+
+				// verify request
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST, got %s", r.Method)
+				}
+				if !strings.HasSuffix(r.URL.Path, "/tags") {
+					t.Errorf("expected path to end with /tags, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-key",
+				WithHTTPClient(server.Client()),
+				WithMaxRetries(1),
+				WithRetryWait(0),
+			)
+
+			err := client.AttachTags(context.Background(), "bm-123", tc.tags)
+
+			if tc.wantNoCall && called {
+				t.Error("expected no HTTP call for empty tags")
+			}
+			if !tc.wantNoCall && !called && !tc.wantErr {
+				t.Error("expected HTTP call but none was made")
+			}
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tc.errSentinel != nil && !errors.Is(err, tc.errSentinel) {
+					t.Errorf("expected error %v, got %v", tc.errSentinel, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateBookmark(t *testing.T) {
+	tests := map[string]struct {
+		statusCode  int
+		wantErr     bool
+		errSentinel error
+	}{
+		"success": {
+			statusCode: http.StatusOK,
+		},
+		"bookmark not found (404)": {
+			statusCode:  http.StatusNotFound,
+			wantErr:     true,
+			errSentinel: ErrBookmarkNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// verify request
+				if r.Method != http.MethodPatch {
+					t.Errorf("expected PATCH, got %s", r.Method)
+				}
+				if !strings.HasPrefix(r.URL.Path, "/bookmarks/") {
+					t.Errorf("expected path to start with /bookmarks/, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-key",
+				WithHTTPClient(server.Client()),
+				WithMaxRetries(1),
+				WithRetryWait(0),
+			)
+
+			err := client.UpdateBookmark(context.Background(), "bm-123", UpdateBookmarkRequest{
+				CreatedAt: ptr("2024-01-01T00:00:00Z"),
+				Note:      ptr("updated note"),
+			})
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tc.errSentinel != nil && !errors.Is(err, tc.errSentinel) {
+					t.Errorf("expected error %v, got %v", tc.errSentinel, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_ListBookmarks(t *testing.T) {
+	t.Run("fetches all bookmarks with pagination", func(t *testing.T) {
+		pageCount := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				t.Errorf("expected GET, got %s", r.Method)
+			}
+			if !strings.HasPrefix(r.URL.Path, "/bookmarks") {
+				t.Errorf("expected /bookmarks path, got %s", r.URL.Path)
+			}
+
+			pageCount++
+			w.WriteHeader(http.StatusOK)
+
+			// page 1: return link and asset bookmarks with cursor
+			if pageCount == 1 {
+				cursor := "cursor-page-2"
+				_ = json.NewEncoder(w).Encode(ListBookmarksResponse{
+					Bookmarks: []ListBookmark{
+						{
+							ID:        "bm-link",
+							CreatedAt: "2024-01-01T00:00:00Z",
+							Note:      ptr("link note"),
+							Content:   ListBookmarkContent{Type: "link", URL: ptr("https://example.com")},
+						},
+						{
+							ID:        "bm-asset",
+							CreatedAt: "2024-01-02T00:00:00Z",
+							Content:   ListBookmarkContent{Type: "asset", SourceURL: ptr("https://example.com/doc.pdf")},
+						},
+					},
+					NextCursor: &cursor,
+				})
+				return
+			}
+
+			// page 2: return text bookmark (should be skipped) and no cursor
+			_ = json.NewEncoder(w).Encode(ListBookmarksResponse{
+				Bookmarks: []ListBookmark{
+					{
+						ID:        "bm-text",
+						CreatedAt: "2024-01-03T00:00:00Z",
+						Content:   ListBookmarkContent{Type: "text"},
+					},
+					{
+						ID:        "bm-link-2",
+						CreatedAt: "2024-01-04T00:00:00Z",
+						Content:   ListBookmarkContent{Type: "link", URL: ptr("https://another.com")},
+					},
+				},
+				NextCursor: nil,
+			})
+		}))
+		defer server.Close()
+
+		var progress []string
+		client := NewClient(server.URL, "test-key",
+			WithHTTPClient(server.Client()),
+			WithMaxRetries(1),
+			WithRetryWait(0),
+			WithListProgress(func(page, listed int) {
+				progress = append(progress, fmt.Sprintf("%d:%d", page, listed))
+			}),
+		)
+
+		result, err := client.ListBookmarks(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if pageCount != 2 {
+			t.Errorf("expected 2 pages, got %d", pageCount)
+		}
+		if got := strings.Join(progress, ","); got != "1:2,2:4" {
+			t.Errorf("list progress = %q, want %q", got, "1:2,2:4")
+		}
+
+		// should have 3 entries: link, asset, link-2 (text skipped)
+		if len(result) != 3 {
+			t.Errorf("expected 3 bookmarks, got %d", len(result))
+		}
+
+		// verify link bookmark
+		if bm, ok := result["https://example.com"]; !ok {
+			t.Error("missing link bookmark")
+		} else if bm.ID != "bm-link" {
+			t.Errorf("link bookmark ID = %q, want %q", bm.ID, "bm-link")
+		}
+
+		// verify asset bookmark (PDF URL should be keyed)
+		if bm, ok := result["https://example.com/doc.pdf"]; !ok {
+			t.Error("missing asset bookmark")
+		} else if bm.ID != "bm-asset" {
+			t.Errorf("asset bookmark ID = %q, want %q", bm.ID, "bm-asset")
+		}
+
+		// verify text bookmark is NOT present
+		if _, ok := result["bm-text"]; ok {
+			t.Error("text bookmark should not be in result")
+		}
+	})
+
+	t.Run("handles API error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "test-key",
+			WithHTTPClient(server.Client()),
+			WithMaxRetries(1),
+			WithRetryWait(0),
+		)
+
+		_, err := client.ListBookmarks(context.Background())
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "listing bookmarks") {
+			t.Errorf("expected error to mention listing bookmarks, got: %v", err)
+		}
+	})
+
+	t.Run("respects context cancellation", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(ListBookmarksResponse{})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "test-key",
+			WithHTTPClient(server.Client()),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.ListBookmarks(ctx)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestClient_DeleteBookmark(t *testing.T) {
+	tests := map[string]struct {
+		statusCode  int
+		wantErr     bool
+		errSentinel error
+	}{
+		"success (204)": {
+			statusCode: http.StatusNoContent,
+		},
+		"bookmark not found (404)": {
+			statusCode:  http.StatusNotFound,
+			wantErr:     true,
+			errSentinel: ErrBookmarkNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete {
+					t.Errorf("expected DELETE, got %s", r.Method)
+				}
+				if r.URL.Path != "/bookmarks/bm-123" {
+					t.Errorf("expected /bookmarks/bm-123, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-key",
+				WithHTTPClient(server.Client()),
+				WithMaxRetries(1),
+				WithRetryWait(0),
+			)
+
+			err := client.DeleteBookmark(context.Background(), "bm-123")
+
+			if tc.wantErr {
+				if !errors.Is(err, tc.errSentinel) {
+					t.Errorf("expected error %v, got %v", tc.errSentinel, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_ListBookmarksByTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tags":
+			_ = json.NewEncoder(w).Encode(ListTagsResponse{Tags: []Tag{
+				{ID: "tag-1", Name: "other"},
+				{ID: "tag-2", Name: "hnkeep:20260117"},
+			}})
+		case "/tags/tag-2/bookmarks":
+			_ = json.NewEncoder(w).Encode(ListBookmarksResponse{Bookmarks: []ListBookmark{
+				{ID: "bm-1", Content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com")}},
+			}})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	bookmarks, err := client.ListBookmarksByTag(context.Background(), "hnkeep:20260117")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bookmarks) != 1 || bookmarks[0].ID != "bm-1" {
+		t.Errorf("bookmarks = %+v, want [bm-1]", bookmarks)
+	}
+
+	if _, err := client.ListBookmarksByTag(context.Background(), "missing"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+}
+
+func TestClient_ListTaggedBookmarks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tags":
+			_ = json.NewEncoder(w).Encode(ListTagsResponse{Tags: []Tag{{ID: "tag-1", Name: "src:hackernews"}}})
+		case "/tags/tag-1/bookmarks":
+			_ = json.NewEncoder(w).Encode(ListBookmarksResponse{Bookmarks: []ListBookmark{{
+				ID:        "bm-1",
+				CreatedAt: "2024-01-01T00:00:00Z",
+				Tags:      []ListBookmarkTag{{ID: "tag-1", Name: "src:hackernews"}},
+				Content:   ListBookmarkContent{Type: "link", URL: ptr("https://example.com")},
+			}}})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path) // e.g., the whole library at /bookmarks
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	result, err := client.ListTaggedBookmarks(context.Background(), "src:hackernews")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bm, ok := result["https://example.com"]
+	if len(result) != 1 || !ok || bm.ID != "bm-1" || bm.CreatedAt != 1704067200 || !slices.Equal(bm.Tags, []string{"src:hackernews"}) {
+		t.Errorf("result = %+v, want bm-1 by its URL", result)
+	}
+
+	if _, err := client.ListTaggedBookmarks(context.Background(), "missing"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+}
+
+func TestClient_FindBookmarkByURL(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bookmarks/search" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Get("q"))
+		// the url: qualifier matches substrings, so longer URLs come back too
+		_ = json.NewEncoder(w).Encode(ListBookmarksResponse{Bookmarks: []ListBookmark{
+			{ID: "bm-long", CreatedAt: "2024-01-01T00:00:00Z", Content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com/a?page=2")}},
+			{ID: "bm-exact", CreatedAt: "2024-01-01T00:00:00Z", Content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com/a")}},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	bm, found, err := client.FindBookmarkByURL(context.Background(), "https://example.com/a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found || bm.ID != "bm-exact" {
+		t.Errorf("FindBookmarkByURL() = %+v, %v, want bm-exact", bm, found)
+	}
+	if want := `url:"https://example.com/a"`; len(queries) != 1 || queries[0] != want {
+		t.Errorf("queries = %q, want [%q]", queries, want)
+	}
+
+	if _, found, err := client.FindBookmarkByURL(context.Background(), "https://example.com"); err != nil || found {
+		t.Errorf("FindBookmarkByURL() found = %v, err = %v, want not found", found, err)
+	}
+}
+
+func TestClient_ListAllBookmarks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bookmarks" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		// two bookmarks with the same URL must both be returned
+		_ = json.NewEncoder(w).Encode(ListBookmarksResponse{Bookmarks: []ListBookmark{
+			{ID: "bm-1", Content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com")}},
+			{ID: "bm-2", Content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com")}},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	bookmarks, err := client.ListAllBookmarks(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bookmarks) != 2 {
+		t.Errorf("got %d bookmarks, want 2", len(bookmarks))
+	}
+}
+
+func TestClient_DetachTags(t *testing.T) {
+	var gotBody AttachTagsRequest
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected DELETE, got %s", r.Method)
+		}
+		if r.URL.Path != "/bookmarks/bm-1/tags" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	if err := client.DetachTags(context.Background(), "bm-1", nil); err != nil || calls != 0 {
+		t.Fatalf("expected no-op for empty tags, got err=%v calls=%d", err, calls)
+	}
+	if err := client.DetachTags(context.Background(), "bm-1", []string{"old:tag"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gotBody.Tags) != 1 || gotBody.Tags[0].TagName != "old:tag" {
+		t.Errorf("unexpected request body: %+v", gotBody)
+	}
+}
+
+func TestClient_CreateHighlight(t *testing.T) {
+	var got CreateHighlightRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/highlights" {
+			t.Errorf("expected POST /highlights, got %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if got.BookmarkID == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	req := CreateHighlightRequest{BookmarkID: "bm-123", Text: ptr("a comment"), Note: ptr("by pg")}
+	if err := client.CreateHighlight(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.BookmarkID != "bm-123" || got.Text == nil || *got.Text != "a comment" {
+		t.Errorf("request = %+v, want bookmark bm-123 with the text", got)
+	}
+
+	req.BookmarkID = "missing"
+	if err := client.CreateHighlight(context.Background(), req); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("expected ErrBookmarkNotFound, got %v", err)
+	}
+}
+
+func TestClient_GetBookmark(t *testing.T) {
+	client := newMuxClient(t, map[string]http.HandlerFunc{
+		"GET /bookmarks/bm-1": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(ListBookmark{
+				ID:      "bm-1",
+				Title:   ptr("Show HN"),
+				Content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com")},
+			})
+		},
+		"GET /bookmarks/missing": func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	})
+
+	bm, err := client.GetBookmark(context.Background(), "bm-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bm.Content.GetURL() != "https://example.com" || bm.Title == nil || *bm.Title != "Show HN" {
+		t.Errorf("GetBookmark() = %+v, want the Show HN link", bm)
+	}
+	if _, err := client.GetBookmark(context.Background(), "missing"); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("expected ErrBookmarkNotFound, got %v", err)
+	}
+}
+
+func TestClient_RecrawlBookmark(t *testing.T) {
+	var got struct {
+		JSON recrawlInput `json:"json"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/trpc/bookmarks.recrawlBookmark" {
+			t.Errorf("expected POST /api/trpc/bookmarks.recrawlBookmark, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Authorization = %q, want the API key", r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if got.JSON.BookmarkID == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result": {"data": {"json": null}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v1", "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+
+	if err := client.RecrawlBookmark(context.Background(), "bm-123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.JSON.BookmarkID != "bm-123" {
+		t.Errorf("request = %+v, want bookmark bm-123", got)
+	}
+	var httpErr HTTPError
+	if err := client.RecrawlBookmark(context.Background(), "missing"); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected HTTP 404 error, got %v", err)
+	}
+}
+
+func TestClient_CreateBookmarks(t *testing.T) {
+	var got map[string]struct {
+		JSON CreateBookmarkRequest `json:"json"`
+	}
+	reject := false // answer like a server without the tRPC API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const batch = "/api/trpc/bookmarks.createBookmark,bookmarks.createBookmark,bookmarks.createBookmark"
+		if r.Method != http.MethodPost || r.URL.Path != batch || r.URL.Query().Get("batch") != "1" {
+			t.Errorf("expected POST %s?batch=1, got %s %s", batch, r.Method, r.URL)
+		}
+		if reject {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("Not Found"))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(`[
+			{"result": {"data": {"json": {"id": "bm-1", "createdAt": "2024-01-01T00:00:00.000Z", "alreadyExists": false}, "meta": {}}}},
+			{"result": {"data": {"json": {"id": "bm-2", "createdAt": "2023-01-01T00:00:00.000Z", "alreadyExists": true}}}},
+			{"error": {"json": {"message": "[{\"code\":\"too_big\",\"maximum\":5,\"path\":[\"note\"]}]", "data": {"httpStatus": 400}}}}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/v1", "test-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(1),
+		WithRetryWait(0),
+	)
+	reqs := []*CreateBookmarkRequest{
+		NewCreateBookmarkRequest("https://a.com", "2024-01-01T00:00:00Z", nil, nil),
+		NewCreateBookmarkRequest("https://b.com", "2024-01-01T00:00:00Z", nil, nil),
+		NewCreateTextBookmarkRequest("Ask HN", "https://c.com", "2024-01-01T00:00:00Z", nil, ptr("too long")),
+	}
+
+	results, err := client.CreateBookmarks(context.Background(), reqs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 || got["0"].JSON.URL != "https://a.com" || got["2"].JSON.Type != "text" || got["2"].JSON.Source != "api" {
+		t.Errorf("request = %+v, want the three requests keyed by index", got)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if r := results[0]; r.Err != nil || r.Exists || r.Bookmark.ID != "bm-1" {
+		t.Errorf("results[0] = %+v, want bm-1 created", r)
+	}
+	if r := results[1]; r.Err != nil || !r.Exists || r.Bookmark.ID != "bm-2" {
+		t.Errorf("results[1] = %+v, want bm-2 existing", r)
+	}
+	var httpErr HTTPError
+	if !errors.As(results[2].Err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("results[2].Err = %v, want HTTP 400", results[2].Err)
+	}
+	if limit, ok := httpErr.FieldLimit("note"); !ok || limit != 5 {
+		t.Errorf("FieldLimit(note) = %d, %v, want 5, true", limit, ok)
+	}
+
+	reject = true
+	if _, err := client.CreateBookmarks(context.Background(), reqs); !errors.As(err, &httpErr) || !httpErr.Incompatible() {
+		t.Errorf("expected an incompatible HTTP 404 error for the whole batch, got %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package karakeep
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultRetryWait  = time.Second
+	defaultUserAgent  = "hnkeep"
+)
+
+// Client is a Karakeep API client.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+	logger     Logger
+	onListPage func(page, listed int)
+	onLimited  func()
+	listRanges int
+	userAgent  string
+	headers    map[string]string
+}
+
+// ClientOption configures the Client.
+type ClientOption func(*Client)
+
+// NewClient creates a new Karakeep API client with the given base URL, API key, and options.
+func NewClient(baseURL, apiKey string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"), // ensure no trailing slash
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		retryWait:  defaultRetryWait,
+		logger:     noopLogger{},
+		userAgent:  defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries for requests.
+func WithMaxRetries(n int) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = n
+	}
+}
+
+// WithRetryWait sets the wait duration between retries.
+func WithRetryWait(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryWait = d
+	}
+}
+
+// Logger receives the client's messages: failed attempts being retried as warnings, and every
+// request with its status and duration as debug messages. Arguments are fmt.Printf style.
+type Logger interface {
+	Debug(format string, args ...any)
+	Info(format string, args ...any)
+	Warn(format string, args ...any)
+	Error(format string, args ...any)
+}
+
+// noopLogger is the default Logger, discarding all messages.
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...any) {}
+func (noopLogger) Info(string, ...any)  {}
+func (noopLogger) Warn(string, ...any)  {}
+func (noopLogger) Error(string, ...any) {}
+
+// WithLogger sets the logger for retry and rate limit visibility.
+func WithLogger(l Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithListProgress sets a callback invoked after each page of a bookmark listing
+// with the page number and the number of bookmarks listed so far.
+func WithListProgress(fn func(page, listed int)) ClientOption {
+	return func(c *Client) {
+		c.onListPage = fn
+	}
+}
+
+// WithListConcurrency lists all bookmarks (ListBookmarks, ListAllBookmarks) as n ranges of creation
+// time walked concurrently instead of one cursor walk, see listAllBookmarkPages. n <= 1 is sequential,
+// the default. The ranges start at cursors built after Karakeep's undocumented cursor format.
+func WithListConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		c.listRanges = n
+	}
+}
+
+// WithRateLimitHook sets a function called whenever the server responds with HTTP 429,
+// e.g., to lower the number of concurrent requests.
+func WithRateLimitHook(fn func()) ClientOption {
+	return func(c *Client) {
+		c.onLimited = fn
+	}
+}
+
+// WithProxy sends the requests through the proxy at proxyURL (http, https, or socks5) instead of
+// the one of the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables. A nil proxyURL keeps those.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(c *Client) {
+		if proxyURL != nil {
+			c.transport().Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the requests, e.g., to trust the private CA or
+// self-signed certificate of a self-hosted instance. A nil config keeps the default one.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) {
+		if config != nil {
+			c.transport().TLSClientConfig = config
+		}
+	}
+}
+
+// transport returns the HTTP transport of the client for options to configure,
+// replacing the shared default transport by a copy first.
+func (c *Client) transport() *http.Transport {
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	c.httpClient.Transport = t
+	return t
+}
+
+// WithUserAgent sets the User-Agent header of the requests.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithHeader sets a header sent with every request, e.g., the service token of an auth proxy
+// like Cloudflare Access in front of the instance.
+func WithHeader(name, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(map[string]string)
+		}
+		c.headers[name] = value
+	}
+}
+
+// WithTimeout sets the timeout for HTTP requests.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = d
+	}
+}
+
+// waitWithContext waits for the specified duration or until context is cancelled.
+func waitWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// maxRetryAfter caps the server-requested Retry-After delay so a bogus header can't stall a run.
+const maxRetryAfter = 5 * time.Minute
+
+// rateLimitError is returned on HTTP 429 and carries the delay requested via Retry-After (zero if absent).
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string { return ErrRateLimited.Error() }
+func (e *rateLimitError) Unwrap() error { return ErrRateLimited }
+
+// parseRetryAfter parses a Retry-After header value, given either as delay seconds or as an HTTP date.
+// Returns zero if the value is empty, invalid, or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
+
+// retryDelay returns how long to wait before the next attempt: the server's Retry-After delay
+// if the error carries one, otherwise exponential backoff capped at 30s.
+func retryDelay(err error, base time.Duration, attempt int) time.Duration {
+	var rlErr *rateLimitError
+	if errors.As(err, &rlErr) && rlErr.retryAfter > 0 {
+		return rlErr.retryAfter
+	}
+	return min(base*time.Duration(1<<attempt), 30*time.Second)
+}
+
+// doRequestWithRetries performs the HTTP request with retries on failure.
+//
+// We implement exponential backoff for all retryable errors (rate limiting,
+// network errors, server errors). There is no documentation for rate limiting
+// in Karakeep API, but they do document it in practice for self-hosters.
+// Refer to https://docs.karakeep.app/administration/security-considerations/.
+func (c *Client) doRequestWithRetries(ctx context.Context, method, path string, body []byte, handleResp func(*http.Response) error) error {
+	return c.doURLWithRetries(ctx, method, c.baseURL+path, body, handleResp)
+}
+
+// doURLWithRetries is like doRequestWithRetries, but for a full URL, e.g., outside the REST API (see trpcURL).
+func (c *Client) doURLWithRetries(ctx context.Context, method, url string, body []byte, handleResp func(*http.Response) error) error {
+	var lastErr error
+	for attempt := 0; attempt < c.maxRetries; attempt++ {
+		// check for cancellation before each attempt
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// do request and immediate return on non-retryable errors
+		err := c.doRequest(ctx, method, url, body, handleResp)
+		if err == nil {
+			return nil // success
+		}
+		if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrReadOnly) || errors.Is(err, ErrBookmarkNotFound) ||
+			errors.Is(err, ErrTagNotFound) || errors.Is(err, ErrListNotFound) || errors.Is(err, ErrHighlightNotFound) {
+			return err // known errors
+		}
+		var httpErr HTTPError
+		if errors.As(err, &httpErr) && httpErr.IsClientError() {
+			return err // client error
+		}
+		if ctx.Err() != nil {
+			return ctx.Err() // user cancellation
+		}
+
+		// honor Retry-After on 429, otherwise exponential backoff for all retryable errors
+		backoff := retryDelay(err, c.retryWait, attempt)
+		if errors.Is(err, ErrRateLimited) {
+			if c.onLimited != nil {
+				c.onLimited()
+			}
+			c.logger.Warn("rate limited, retrying in %s...", backoff)
+		} else {
+			c.logger.Warn("request failed (attempt %d/%d): %v, retrying in %s...", attempt+1, c.maxRetries, err, backoff)
+		}
+
+		if err := waitWithContext(ctx, backoff); err != nil {
+			return err
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", c.maxRetries, lastErr)
+}
+
+// CheckConnectivity verifies the API is reachable and the API key is valid.
+// This is a lightweight pre-flight check that calls GET /users/me and returns the key's owner.
+func (c *Client) CheckConnectivity(ctx context.Context) (*User, error) {
+	var user User
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/users/me", nil, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CheckCompatibility reports whether the server looks recent enough for this client (see SupportedVersion).
+//
+// Karakeep does not report its version over the API, so we probe GET /users/me/stats, a route of recent
+// releases this client reads: an older server answers with the router's 404 (see HTTPError.Incompatible).
+func (c *Client) CheckCompatibility(ctx context.Context) (bool, error) {
+	_, err := c.GetUserStats(ctx)
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) && httpErr.Incompatible() {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// writeProbeID is a bookmark ID that never exists, used to probe write access.
+const writeProbeID = "hnkeep-write-probe"
+
+// CheckWriteAccess verifies the API key is allowed to modify bookmarks.
+//
+// Karakeep does not expose key scopes, so we send an empty PATCH to a bookmark that does not exist.
+// This is a no-op write: an authorized key gets 404 (passed auth, bookmark missing), while a
+// restricted key is rejected with 401/403 before the lookup happens.
+func (c *Client) CheckWriteAccess(ctx context.Context) error {
+	return c.doRequestWithRetries(ctx, http.MethodPatch, "/bookmarks/"+writeProbeID, []byte("{}"), func(resp *http.Response) error {
+		switch {
+		case resp.StatusCode == http.StatusForbidden:
+			return ErrReadOnly
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode < 300:
+			return nil
+		default:
+			return readHTTPError(resp)
+		}
+	})
+}
+
+// doRequest performs a single HTTP request.
+func (c *Client) doRequest(ctx context.Context, method, url string, body []byte, handleResp func(*http.Response) error) error {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	// karakeep API expects JSON request/response (see JOURNAL.md)
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Debug("%s %s failed after %s: %v", method, url, time.Since(start).Round(time.Millisecond), err)
+		return fmt.Errorf("request failed: %w", err)
+	}
+	c.logger.Debug("%s %s -> %d (%s)", method, url, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	defer func() { _ = resp.Body.Close() }() // close error not actionable after body is read
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &rateLimitError{retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	}
+
+	return handleResp(resp)
+}
@@ -2,6 +2,9 @@ package karakeep
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -125,6 +128,69 @@ func TestClient_doRequestWithRetries_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestClient_doRequestWithRetries_RetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limited := 0
+	client := NewClient(server.URL, "test-api-key",
+		WithHTTPClient(server.Client()),
+		WithMaxRetries(2),
+		WithRetryWait(time.Hour), // would time out the test if Retry-After were ignored
+		WithRateLimitHook(func() { limited++ }),
+	)
+
+	start := time.Now()
+	err := client.doRequestWithRetries(context.Background(), http.MethodGet, "/test", nil, func(resp *http.Response) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if limited != 1 {
+		t.Errorf("expected the rate limit hook to be called once, got %d", limited)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 10*time.Second {
+		t.Errorf("expected to wait about 1s as per Retry-After, waited %s", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 17, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		value string
+		want  time.Duration
+	}{
+		"empty":         {"", 0},
+		"seconds":       {"5", 5 * time.Second},
+		"negative":      {"-5", 0},
+		"http date":     {now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		"date in past":  {now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		"invalid":       {"soon", 0},
+		"capped at max": {"86400", maxRetryAfter},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := parseRetryAfter(tc.value, now); got != tc.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestClient_doRequest_Headers(t *testing.T) {
 	var capturedHeaders http.Header
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -135,6 +201,8 @@ func TestClient_doRequest_Headers(t *testing.T) {
 
 	client := NewClient(server.URL, "my-secret-key",
 		WithHTTPClient(server.Client()),
+		WithUserAgent("hnkeep/1.2.3"),
+		WithHeader("CF-Access-Client-Id", "client-id"),
 	)
 
 	err := client.doRequest(context.Background(), http.MethodPost, server.URL+"/test", []byte(`{"test":true}`), func(resp *http.Response) error {
@@ -161,6 +229,14 @@ func TestClient_doRequest_Headers(t *testing.T) {
 	if acceptHeader != "application/json" {
 		t.Errorf("Accept header = %q, want %q", acceptHeader, "application/json")
 	}
+
+	// verify user agent and extra headers
+	if ua := capturedHeaders.Get("User-Agent"); ua != "hnkeep/1.2.3" {
+		t.Errorf("User-Agent header = %q, want %q", ua, "hnkeep/1.2.3")
+	}
+	if id := capturedHeaders.Get("CF-Access-Client-Id"); id != "client-id" {
+		t.Errorf("CF-Access-Client-Id header = %q, want %q", id, "client-id")
+	}
 }
 
 func TestNewClient_TrimsTrailingSlash(t *testing.T) {
@@ -170,6 +246,62 @@ func TestNewClient_TrimsTrailingSlash(t *testing.T) {
 	}
 }
 
+func TestClient_CheckCompatibility(t *testing.T) {
+	tests := map[string]struct {
+		statusCode int
+		body       string
+		want       bool
+		wantErr    bool
+	}{
+		"recent server":        {statusCode: http.StatusOK, body: `{"numBookmarks":1}`, want: true},
+		"older server":         {statusCode: http.StatusNotFound, body: "Not Found", want: false},
+		"api 404 is not older": {statusCode: http.StatusNotFound, body: `{"code":"NOT_FOUND"}`, wantErr: true},
+		"server error":         {statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/users/me/stats" {
+					t.Errorf("unexpected path: %s, want /users/me/stats", r.URL.Path)
+				}
+				w.WriteHeader(tc.statusCode)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-api-key", WithHTTPClient(server.Client()), WithMaxRetries(1))
+			got, err := client.CheckCompatibility(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("CheckCompatibility() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("CheckCompatibility() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClient_WithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"user-1"}`))
+	}))
+	defer server.Close()
+
+	// the test server's certificate is self-signed, so the default config rejects it
+	client := NewClient(server.URL, "key", WithMaxRetries(1))
+	if _, err := client.CheckConnectivity(context.Background()); err == nil {
+		t.Fatal("expected a certificate error with the default TLS config")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client = NewClient(server.URL, "key", WithMaxRetries(1), WithTLSConfig(&tls.Config{RootCAs: roots}))
+	if _, err := client.CheckConnectivity(context.Background()); err != nil {
+		t.Errorf("unexpected error trusting the server's certificate: %v", err)
+	}
+}
+
 func TestClient_CheckConnectivity(t *testing.T) {
 	tests := map[string]struct {
 		statusCode int
@@ -202,6 +334,9 @@ func TestClient_CheckConnectivity(t *testing.T) {
 					t.Errorf("unexpected method: %s, want GET", r.Method)
 				}
 				w.WriteHeader(tc.statusCode)
+				if tc.statusCode == http.StatusOK {
+					_, _ = w.Write([]byte(`{"id":"user-1","name":"Jane","email":"jane@example.com"}`))
+				}
 			}))
 			defer server.Close()
 
@@ -211,7 +346,7 @@ func TestClient_CheckConnectivity(t *testing.T) {
 				WithRetryWait(0),
 			)
 
-			err := client.CheckConnectivity(context.Background())
+			user, err := client.CheckConnectivity(context.Background())
 
 			if tc.wantErr {
 				if err == nil {
@@ -224,6 +359,64 @@ func TestClient_CheckConnectivity(t *testing.T) {
 				if err != nil {
 					t.Fatalf("unexpected error: %v", err)
 				}
+				if got, want := user.String(), "Jane <jane@example.com>"; got != want {
+					t.Errorf("user = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_CheckWriteAccess(t *testing.T) {
+	tests := map[string]struct {
+		statusCode  int
+		wantErr     bool
+		errSentinel error
+	}{
+		"authorized key hits missing bookmark (404)": {
+			statusCode: http.StatusNotFound,
+		},
+		"read-only key (403)": {
+			statusCode:  http.StatusForbidden,
+			wantErr:     true,
+			errSentinel: ErrReadOnly,
+		},
+		"invalid key (401)": {
+			statusCode:  http.StatusUnauthorized,
+			wantErr:     true,
+			errSentinel: ErrUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPatch {
+					t.Errorf("unexpected method: %s, want PATCH", r.Method)
+				}
+				if r.URL.Path != "/bookmarks/"+writeProbeID {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-api-key",
+				WithHTTPClient(server.Client()),
+				WithMaxRetries(1),
+				WithRetryWait(0),
+			)
+
+			err := client.CheckWriteAccess(context.Background())
+
+			if tc.wantErr {
+				if !errors.Is(err, tc.errSentinel) {
+					t.Errorf("expected error %v, got %v", tc.errSentinel, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
 		})
 	}
@@ -0,0 +1,26 @@
+// Package karakeep provides a client for interacting with the Karakeep API.
+//
+// It is hnkeep's Karakeep client, usable by other Go tools as is:
+//
+//	client := karakeep.NewClient("https://karakeep.example.com/api/v1", apiKey,
+//		karakeep.WithTimeout(10*time.Second),
+//	)
+//	bookmarks, err := client.SearchBookmarks(ctx, "is:fav")
+//
+// Its exported API follows semantic versioning with hnkeep's releases: breaking changes only come
+// with a new major version. Errors of the API are returned as the sentinel errors (ErrUnauthorized,
+// ErrBookmarkNotFound, ...) where they have a meaning, else as HTTPError; failed requests are retried
+// with exponential backoff, honoring Retry-After on HTTP 429.
+//
+// This package was built against Karakeep v0.30.0.
+// Most of the code references the following files in the Karakeep codebase:
+//   - Commit hash: https://github.com/karakeep-app/karakeep/tree/v0.30.0
+//   - API routes (http layer): packages/api/routes/bookmarks.ts
+//   - TRPC routers (business logic): package/trpc/routers/bookmarks.ts
+//   - Shared types: packages/shared/types/bookmarks.ts
+//
+// The client covers the endpoints of bookmarks (including search), tags, lists, highlights, and the
+// current user, grouped by file; lists.go and users.go follow packages/api/routes/lists.ts and users.ts.
+// Types are hand-written after the OpenAPI spec (packages/open-api/karakeep-openapi-spec.json) and only
+// hold the fields of use so far, so new fields of the responses are ignored rather than breaking decoding.
+package karakeep
@@ -0,0 +1,62 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CreateHighlight creates a highlight on an existing bookmark.
+// Refer to the highlights endpoints at https://docs.karakeep.app/api and the codebase.
+func (c *Client) CreateHighlight(ctx context.Context, req CreateHighlightRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	return c.doRequestWithRetries(ctx, http.MethodPost, "/highlights", data, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+
+		return nil
+	})
+}
+
+// ListHighlights fetches the highlights of a bookmark.
+// Refer to https://docs.karakeep.app/api/get-highlights-of-a-bookmark and the codebase.
+func (c *Client) ListHighlights(ctx context.Context, bookmarkID string) ([]Highlight, error) {
+	var listResp ListHighlightsResponse
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/bookmarks/"+bookmarkID+"/highlights", nil, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrBookmarkNotFound
+		}
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&listResp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listResp.Highlights, nil
+}
+
+// DeleteHighlight deletes a highlight by its ID.
+// Refer to https://docs.karakeep.app/api/delete-a-highlight and the codebase.
+func (c *Client) DeleteHighlight(ctx context.Context, id string) error {
+	return c.doRequestWithRetries(ctx, http.MethodDelete, "/highlights/"+id, nil, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrHighlightNotFound
+		}
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return nil
+	})
+}
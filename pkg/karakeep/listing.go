@@ -0,0 +1,184 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// listProgress counts the pages and bookmarks listed so far for WithListProgress,
+// shared by the concurrent walks of listAllBookmarkPages.
+type listProgress struct {
+	mu     sync.Mutex
+	page   int
+	listed int
+	onPage func(page, listed int)
+}
+
+// add records a listed page of n bookmarks.
+func (p *listProgress) add(n int) {
+	if p.onPage == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.page++
+	p.listed += n
+	p.onPage(p.page, p.listed)
+}
+
+// listCursor is the pagination cursor of GET /bookmarks: the base64url-encoded JSON of the ID and
+// creation time of the bookmark starting the next page. Bookmarks are listed newest first, so the
+// cursor of an empty ID and a time starts the listing at the bookmarks created strictly before it.
+type listCursor struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// encode returns the cursor as passed in the cursor query parameter.
+func (lc listCursor) encode() string {
+	data, _ := json.Marshal(lc) // cannot fail for this struct
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// listAllBookmarkPages walks all pages of GET /bookmarks like listBookmarkPages. With
+// WithListConcurrency, the cursor walk, whose every page waits for the previous one, is split
+// into ranges of creation time walked concurrently, each starting at a cursor built for its upper
+// bound. Pages are then passed to fn once all ranges are listed, newest first like a single walk.
+//
+// The cursor format is not documented, so the ranges are checked against the bookmark count of
+// GetUserStats. If the server rejects a built cursor, or the ranges miss or repeat bookmarks, e.g.,
+// after a change of the format, the bookmarks are listed again with a single walk.
+func (c *Client) listAllBookmarkPages(ctx context.Context, fn func([]ListBookmark)) error {
+	if c.listRanges <= 1 {
+		return c.listBookmarkPages(ctx, "/bookmarks", fn)
+	}
+	stats, err := c.GetUserStats(ctx)
+	if err != nil {
+		c.logger.Warn("counting bookmarks failed: %v, listing them sequentially...", err)
+		return c.listBookmarkPages(ctx, "/bookmarks", fn)
+	}
+	bounds, err := c.creationBounds(ctx, c.listRanges)
+	if err != nil {
+		return err
+	}
+	if len(bounds) == 0 {
+		return c.listBookmarkPages(ctx, "/bookmarks", fn) // too few or same-time bookmarks to split
+	}
+
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	progress := &listProgress{onPage: c.onListPage}
+	ranges := make([][]ListBookmark, len(bounds)+1)
+	errs := make([]error, len(bounds)+1)
+	var wg sync.WaitGroup
+	for i := range ranges {
+		// range i holds the bookmarks created in [bounds[i], bounds[i-1]), unbounded at the ends
+		var cursor string
+		if i > 0 {
+			cursor = listCursor{CreatedAt: bounds[i-1]}.encode()
+		}
+		var lower time.Time
+		if i < len(bounds) {
+			lower = bounds[i]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.walkBookmarkPages(listCtx, "/bookmarks", cursor, progress, func(bookmarks []ListBookmark) bool {
+				for j, bm := range bookmarks {
+					if createdAt, err := time.Parse(time.RFC3339, bm.CreatedAt); err == nil && createdAt.Before(lower) {
+						ranges[i] = append(ranges[i], bookmarks[:j]...)
+						return false // reached the next range
+					}
+				}
+				ranges[i] = append(ranges[i], bookmarks...)
+				return true
+			})
+			if errs[i] != nil {
+				cancel() // no use listing the other ranges
+			}
+		}()
+	}
+	wg.Wait()
+
+	var httpErr HTTPError
+	for _, err := range errs {
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusBadRequest {
+			c.logger.Warn("listing bookmarks concurrently failed: %v, listing them sequentially...", err)
+			return c.listBookmarkPages(ctx, "/bookmarks", fn)
+		}
+	}
+	for _, err := range errs {
+		if err != nil && (!errors.Is(err, context.Canceled) || ctx.Err() != nil) {
+			return err // not merely canceled by the failure of another range
+		}
+	}
+	listed := 0
+	for _, bookmarks := range ranges {
+		listed += len(bookmarks)
+	}
+	if listed != stats.NumBookmarks {
+		// also if bookmarks were added or deleted meanwhile, which a single walk copes with
+		c.logger.Warn("listing bookmarks concurrently found %d of %d bookmarks, listing them sequentially...",
+			listed, stats.NumBookmarks)
+		return c.listBookmarkPages(ctx, "/bookmarks", fn)
+	}
+	for _, bookmarks := range ranges {
+		fn(bookmarks)
+	}
+	return nil
+}
+
+// creationBounds splits the creation times of the bookmarks, from the oldest to the newest, into
+// n equal ranges and returns the n-1 bounds between them, newest first. It returns none if the
+// bookmarks span less than n seconds, the precision of Karakeep's timestamps.
+func (c *Client) creationBounds(ctx context.Context, n int) ([]time.Time, error) {
+	newest, err := c.firstCreated(ctx, "desc")
+	if err != nil {
+		return nil, err
+	}
+	oldest, err := c.firstCreated(ctx, "asc")
+	if err != nil {
+		return nil, err
+	}
+	span := newest.Sub(oldest)
+	if newest.IsZero() || oldest.IsZero() || span < time.Duration(n)*time.Second {
+		return nil, nil // also if the server ignored the sort order
+	}
+
+	bounds := make([]time.Time, n-1)
+	for i := range bounds {
+		bounds[i] = newest.Add(-span * time.Duration(i+1) / time.Duration(n)).Truncate(time.Second)
+	}
+	return bounds, nil
+}
+
+// firstCreated returns the creation time of the first bookmark listed in the given sort order,
+// "desc" for the newest or "asc" for the oldest, or the zero time if there are no bookmarks.
+func (c *Client) firstCreated(ctx context.Context, sortOrder string) (time.Time, error) {
+	var listResp ListBookmarksResponse
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/bookmarks?limit=1&sortOrder="+sortOrder, nil,
+		func(resp *http.Response) error {
+			if resp.StatusCode != http.StatusOK {
+				return readHTTPError(resp)
+			}
+			return json.NewDecoder(resp.Body).Decode(&listResp)
+		})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("listing bookmarks (%s): %w", sortOrder, err)
+	}
+	if len(listResp.Bookmarks) == 0 {
+		return time.Time{}, nil
+	}
+	createdAt, err := time.Parse(time.RFC3339, listResp.Bookmarks[0].CreatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing bookmark creation time: %w", err)
+	}
+	return createdAt, nil
+}
@@ -0,0 +1,143 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newListServer serves GET /bookmarks over the given bookmarks, sorted newest first, with
+// Karakeep's cursor semantics, and GET /users/me/stats counting numBookmarks.
+// If rejectBuilt, cursors it didn't hand out get a 400.
+func newListServer(t *testing.T, bookmarks []ListBookmark, numBookmarks int, rejectBuilt bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/users/me/stats" {
+			_ = json.NewEncoder(w).Encode(UserStats{NumBookmarks: numBookmarks})
+			return
+		}
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		list := bookmarks
+		if q.Get("sortOrder") == "asc" {
+			list = slices.Clone(bookmarks)
+			slices.Reverse(list)
+		}
+
+		start := 0
+		if raw := q.Get("cursor"); raw != "" {
+			data, err := base64.RawURLEncoding.DecodeString(raw)
+			var cursor listCursor
+			if err == nil {
+				err = json.Unmarshal(data, &cursor)
+			}
+			if err != nil || (rejectBuilt && cursor.ID == "") {
+				http.Error(w, "invalid cursor", http.StatusBadRequest)
+				return
+			}
+			// first bookmark created before the cursor's, or at the same time with a lower or equal ID
+			start = slices.IndexFunc(list, func(bm ListBookmark) bool {
+				createdAt, _ := time.Parse(time.RFC3339, bm.CreatedAt)
+				return createdAt.Before(cursor.CreatedAt) || (createdAt.Equal(cursor.CreatedAt) && bm.ID <= cursor.ID)
+			})
+			if start < 0 {
+				start = len(list)
+			}
+		}
+
+		end := min(start+limit, len(list))
+		resp := ListBookmarksResponse{Bookmarks: list[start:end]}
+		if end < len(list) {
+			first := list[end] // of the next page
+			createdAt, _ := time.Parse(time.RFC3339, first.CreatedAt)
+			next := listCursor{ID: first.ID, CreatedAt: createdAt}.encode()
+			resp.NextCursor = &next
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_ListAllBookmarks_Concurrent(t *testing.T) {
+	// 450 bookmarks, a few created at the same second, newest first
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bookmarks := make([]ListBookmark, 450)
+	for i := range bookmarks {
+		bookmarks[i] = ListBookmark{
+			ID:        fmt.Sprintf("bm-%03d", len(bookmarks)-i),
+			CreatedAt: base.Add(time.Duration(len(bookmarks)-i) / 3 * time.Hour).Format(time.RFC3339),
+			Content:   ListBookmarkContent{Type: "link", URL: ptr(fmt.Sprintf("https://example.com/%d", i))},
+		}
+	}
+	ids := func(list []ListBookmark) []string {
+		out := make([]string, len(list))
+		for i, bm := range list {
+			out[i] = bm.ID
+		}
+		return out
+	}
+
+	t.Run("lists every bookmark once in order", func(t *testing.T) {
+		server, requests := newListServer(t, bookmarks, len(bookmarks), false)
+		var pages int
+		client := NewClient(server.URL, "test-key",
+			WithHTTPClient(server.Client()),
+			WithListConcurrency(4),
+			WithListProgress(func(page, _ int) { pages = page }),
+		)
+
+		got, err := client.ListAllBookmarks(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(ids(got), ids(bookmarks)) {
+			t.Errorf("listed %d bookmarks, want all %d newest first", len(got), len(bookmarks))
+		}
+		// 1 request for the count, 2 for the bounds, then 4 ranges of about 113 bookmarks take 2 pages each
+		if n := requests.Load(); n != 11 || pages != 8 {
+			t.Errorf("requests = %d, pages = %d, want 11 and 8", n, pages)
+		}
+	})
+
+	t.Run("falls back to a single walk if built cursors are rejected", func(t *testing.T) {
+		server, _ := newListServer(t, bookmarks, len(bookmarks), true)
+		client := NewClient(server.URL, "test-key", WithHTTPClient(server.Client()), WithListConcurrency(4))
+
+		got, err := client.ListBookmarks(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != len(bookmarks) {
+			t.Errorf("listed %d bookmarks, want %d", len(got), len(bookmarks))
+		}
+	})
+
+	t.Run("falls back to a single walk if the count doesn't match", func(t *testing.T) {
+		// e.g., a cursor format whose built cursors skip bookmarks, here one added meanwhile
+		server, requests := newListServer(t, bookmarks, len(bookmarks)+1, false)
+		client := NewClient(server.URL, "test-key", WithHTTPClient(server.Client()), WithListConcurrency(4))
+
+		got, err := client.ListAllBookmarks(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(ids(got), ids(bookmarks)) {
+			t.Errorf("listed %d bookmarks, want all %d newest first", len(got), len(bookmarks))
+		}
+		// 11 requests of the ranges, then 5 pages of a single walk
+		if n := requests.Load(); n != 16 {
+			t.Errorf("requests = %d, want 16", n)
+		}
+	})
+}
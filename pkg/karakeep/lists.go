@@ -0,0 +1,103 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ListLists fetches all lists of the user, manual and smart.
+// Refer to https://docs.karakeep.app/api/get-all-lists and the codebase.
+func (c *Client) ListLists(ctx context.Context) ([]List, error) {
+	var listResp ListListsResponse
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/lists", nil, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&listResp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listResp.Lists, nil
+}
+
+// GetListByName returns the list with the given name, or ErrListNotFound if it does not exist.
+// Like GetTagByName, this lists all lists and matches client-side.
+func (c *Client) GetListByName(ctx context.Context, name string) (*List, error) {
+	lists, err := c.ListLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if list.Name == name {
+			return &list, nil
+		}
+	}
+	return nil, ErrListNotFound
+}
+
+// CreateList creates a list and returns it.
+// Refer to https://docs.karakeep.app/api/create-a-new-list and the codebase.
+func (c *Client) CreateList(ctx context.Context, req CreateListRequest) (*List, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	var list List
+	err = c.doRequestWithRetries(ctx, http.MethodPost, "/lists", data, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&list)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// DeleteList deletes a list; its bookmarks are kept.
+// Refer to https://docs.karakeep.app/api/delete-a-list and the codebase.
+func (c *Client) DeleteList(ctx context.Context, id string) error {
+	return c.doRequestWithRetries(ctx, http.MethodDelete, "/lists/"+id, nil, listStatus)
+}
+
+// AddBookmarkToList adds a bookmark to a manual list. Adding a bookmark already in the list is a no-op.
+// Refer to https://docs.karakeep.app/api/add-a-bookmark-to-a-list and the codebase.
+func (c *Client) AddBookmarkToList(ctx context.Context, listID, bookmarkID string) error {
+	return c.doRequestWithRetries(ctx, http.MethodPut, "/lists/"+listID+"/bookmarks/"+bookmarkID, nil, listStatus)
+}
+
+// RemoveBookmarkFromList removes a bookmark from a manual list.
+// Refer to https://docs.karakeep.app/api/remove-a-bookmark-from-a-list and the codebase.
+func (c *Client) RemoveBookmarkFromList(ctx context.Context, listID, bookmarkID string) error {
+	return c.doRequestWithRetries(ctx, http.MethodDelete, "/lists/"+listID+"/bookmarks/"+bookmarkID, nil, listStatus)
+}
+
+// ListListBookmarks fetches all bookmarks of a list, like ListAllBookmarks.
+// Refer to https://docs.karakeep.app/api/get-bookmarks-in-the-list and the codebase.
+func (c *Client) ListListBookmarks(ctx context.Context, listID string) ([]ListBookmark, error) {
+	var result []ListBookmark
+	err := c.listBookmarkPages(ctx, "/lists/"+listID+"/bookmarks", func(bookmarks []ListBookmark) {
+		result = append(result, bookmarks...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// listStatus handles the response of a list write without a response body.
+// A 404 is reported as ErrListNotFound, though Karakeep also returns it for a missing bookmark.
+func listStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrListNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return readHTTPError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newMuxClient returns a client of a test server serving the given routes, e.g., "GET /lists".
+func newMuxClient(t *testing.T, routes map[string]http.HandlerFunc) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	for pattern, handler := range routes {
+		mux.HandleFunc(pattern, handler)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, "test-key", WithHTTPClient(server.Client()), WithMaxRetries(1), WithRetryWait(0))
+}
+
+func TestClient_Lists(t *testing.T) {
+	var created CreateListRequest
+	members := map[string]bool{}
+	client := newMuxClient(t, map[string]http.HandlerFunc{
+		"GET /lists": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(ListListsResponse{Lists: []List{
+				{ID: "list-1", Name: "Reading", Icon: "📚", Type: "manual"},
+				{ID: "list-2", Name: "Hacker News", Icon: "🟧", Type: "manual"},
+			}})
+		},
+		"POST /lists": func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(List{ID: "list-3", Name: created.Name, Icon: created.Icon, Type: "manual"})
+		},
+		"PUT /lists/list-2/bookmarks/{id}": func(w http.ResponseWriter, r *http.Request) {
+			members[r.PathValue("id")] = true
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"DELETE /lists/list-2/bookmarks/{id}": func(w http.ResponseWriter, r *http.Request) {
+			delete(members, r.PathValue("id"))
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"GET /lists/list-2/bookmarks": func(w http.ResponseWriter, _ *http.Request) {
+			var bookmarks []ListBookmark
+			for id := range members {
+				bookmarks = append(bookmarks, ListBookmark{ID: id})
+			}
+			_ = json.NewEncoder(w).Encode(ListBookmarksResponse{Bookmarks: bookmarks})
+		},
+		"DELETE /lists/missing": func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	})
+	ctx := context.Background()
+
+	list, err := client.GetListByName(ctx, "Hacker News")
+	if err != nil || list.ID != "list-2" {
+		t.Fatalf("GetListByName() = %+v, %v, want list-2", list, err)
+	}
+	if _, err := client.GetListByName(ctx, "Unknown"); !errors.Is(err, ErrListNotFound) {
+		t.Errorf("GetListByName(unknown) error = %v, want ErrListNotFound", err)
+	}
+
+	newList, err := client.CreateList(ctx, CreateListRequest{Name: "Later", Icon: "⏳"})
+	if err != nil || newList.ID != "list-3" || created.Name != "Later" {
+		t.Errorf("CreateList() = %+v, %v, sent %+v, want list-3 named Later", newList, err, created)
+	}
+
+	for _, id := range []string{"bm-1", "bm-2"} {
+		if err := client.AddBookmarkToList(ctx, "list-2", id); err != nil {
+			t.Fatalf("AddBookmarkToList(%s) error: %v", id, err)
+		}
+	}
+	if err := client.RemoveBookmarkFromList(ctx, "list-2", "bm-1"); err != nil {
+		t.Fatalf("RemoveBookmarkFromList() error: %v", err)
+	}
+	bookmarks, err := client.ListListBookmarks(ctx, "list-2")
+	if err != nil || len(bookmarks) != 1 || bookmarks[0].ID != "bm-2" {
+		t.Errorf("ListListBookmarks() = %+v, %v, want only bm-2", bookmarks, err)
+	}
+
+	if err := client.DeleteList(ctx, "missing"); !errors.Is(err, ErrListNotFound) {
+		t.Errorf("DeleteList(missing) error = %v, want ErrListNotFound", err)
+	}
+}
+
+func TestClient_TagsHighlightsAndStats(t *testing.T) {
+	var renamed string
+	client := newMuxClient(t, map[string]http.HandlerFunc{
+		"PATCH /tags/tag-1": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			renamed = body["name"]
+			_ = json.NewEncoder(w).Encode(Tag{ID: "tag-1", Name: renamed})
+		},
+		"DELETE /tags/missing": func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+		"GET /bookmarks/bm-1/highlights": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(ListHighlightsResponse{Highlights: []Highlight{{ID: "hl-1", BookmarkID: "bm-1"}}})
+		},
+		"DELETE /highlights/hl-1": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(Highlight{ID: "hl-1"})
+		},
+		"GET /users/me/stats": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(UserStats{NumBookmarks: 42, NumTags: 7})
+		},
+	})
+	ctx := context.Background()
+
+	if err := client.RenameTag(ctx, "tag-1", "src:hn"); err != nil || renamed != "src:hn" {
+		t.Errorf("RenameTag() error = %v, renamed to %q, want src:hn", err, renamed)
+	}
+	if err := client.DeleteTag(ctx, "missing"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("DeleteTag(missing) error = %v, want ErrTagNotFound", err)
+	}
+
+	highlights, err := client.ListHighlights(ctx, "bm-1")
+	if err != nil || len(highlights) != 1 || highlights[0].ID != "hl-1" {
+		t.Errorf("ListHighlights() = %+v, %v, want hl-1", highlights, err)
+	}
+	if err := client.DeleteHighlight(ctx, "hl-1"); err != nil {
+		t.Errorf("DeleteHighlight() error: %v", err)
+	}
+	if err := client.DeleteHighlight(ctx, "hl-2"); !errors.Is(err, ErrHighlightNotFound) {
+		t.Errorf("DeleteHighlight(missing) error = %v, want ErrHighlightNotFound", err)
+	}
+
+	stats, err := client.GetUserStats(ctx)
+	if err != nil || stats.NumBookmarks != 42 || stats.NumTags != 7 {
+		t.Errorf("GetUserStats() = %+v, %v, want 42 bookmarks and 7 tags", stats, err)
+	}
+}
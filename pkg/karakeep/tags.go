@@ -0,0 +1,66 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ListTags fetches all tags of the user.
+// Refer to https://docs.karakeep.app/api/get-all-tags and the codebase.
+func (c *Client) ListTags(ctx context.Context) ([]Tag, error) {
+	var listResp ListTagsResponse
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/tags", nil, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&listResp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listResp.Tags, nil
+}
+
+// GetTagByName returns the tag with the given name, or ErrTagNotFound if it does not exist.
+// Karakeep has no lookup-by-name endpoint, so this lists all tags and matches client-side.
+func (c *Client) GetTagByName(ctx context.Context, name string) (*Tag, error) {
+	tags, err := c.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tag.Name == name {
+			return &tag, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+// RenameTag renames the tag with the given ID, on every bookmark it is attached to.
+// Refer to https://docs.karakeep.app/api/update-a-tag and the codebase.
+func (c *Client) RenameTag(ctx context.Context, id, name string) error {
+	data, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+	return c.doRequestWithRetries(ctx, http.MethodPatch, "/tags/"+id, data, tagStatus)
+}
+
+// DeleteTag deletes the tag with the given ID, detaching it from every bookmark.
+// Refer to https://docs.karakeep.app/api/delete-a-tag and the codebase.
+func (c *Client) DeleteTag(ctx context.Context, id string) error {
+	return c.doRequestWithRetries(ctx, http.MethodDelete, "/tags/"+id, nil, tagStatus)
+}
+
+// tagStatus handles the response of a tag write, reporting a 404 as ErrTagNotFound.
+func tagStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrTagNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return readHTTPError(resp)
+	}
+	return nil
+}
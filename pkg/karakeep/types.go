@@ -0,0 +1,355 @@
+package karakeep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors for specific API conditions.
+var (
+	ErrUnauthorized      = errors.New("unauthorized: invalid or missing API key")
+	ErrReadOnly          = errors.New("forbidden: API key lacks write access")
+	ErrBookmarkNotFound  = errors.New("bookmark not found")
+	ErrTagNotFound       = errors.New("tag not found")
+	ErrListNotFound      = errors.New("list not found")
+	ErrHighlightNotFound = errors.New("highlight not found")
+	ErrRateLimited       = errors.New("rate limited: too many requests")
+)
+
+// SupportedVersion is the Karakeep release this package is built and tested against. Karakeep does not
+// report its version over the API, so it is only probed for (see Client.CheckCompatibility) and hinted
+// at in errors (see HTTPError.Incompatible).
+const SupportedVersion = "v0.30.0"
+
+// HTTPError represents an HTTP error from the API with status code and response body.
+// Body is raw string since Karakeep error formats vary (see JOURNALS.md).
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface for HTTPError.
+func (e HTTPError) Error() string {
+	msg := fmt.Sprintf("karakeep API error (HTTP %d): %s", e.StatusCode, e.Body)
+	if e.Incompatible() {
+		msg += " (the Karakeep server may be older than " + SupportedVersion + ", which this client requires)"
+	}
+	return msg
+}
+
+// Incompatible reports whether the error is for a route the server doesn't have, as an older Karakeep
+// lacks routes added since. Such 404s come from the router with a plain-text body, unlike the JSON error
+// of the API for a missing bookmark or tag, so other 404s and all other errors don't count.
+func (e HTTPError) Incompatible() bool {
+	return e.StatusCode == http.StatusNotFound && !strings.HasPrefix(strings.TrimSpace(e.Body), "{")
+}
+
+// FieldLimit returns the maximum length of the named request field, e.g., "note", if the error is
+// Karakeep's request validation rejecting the field as too long. Karakeep doesn't publish its limits,
+// so this is how they are detected. The validation issue may be JSON inside the error message.
+func (e HTTPError) FieldLimit(field string) (int, bool) {
+	if e.StatusCode != http.StatusBadRequest {
+		return 0, false
+	}
+	for _, m := range fieldLimitRe.FindAllStringSubmatch(e.Body, -1) {
+		if m[2] != field {
+			continue
+		}
+		limit, err := strconv.Atoi(m[1])
+		if err != nil || limit <= 0 {
+			return 0, false
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+// fieldLimitRe matches a too_big issue of Karakeep's request validation, capturing the maximum length and
+// the first path element, i.e., the field name. Quotes may be escaped when the issue is JSON inside a message.
+var fieldLimitRe = regexp.MustCompile(`too_big\\?"[^{}]*?"?maximum\\?"?:\s*(\d+)[^{}]*?"?path\\?"?:\s*\[\s*\\?"([^"\\]+)\\?"`)
+
+// IsClientError returns true for 4xx HTTP status codes.
+func (e HTTPError) IsClientError() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// readHTTPError reads the response body and returns an HTTPError.
+// It adds useful debug info of this rare edge case; body is not structured anyway.
+func readHTTPError(resp *http.Response) HTTPError {
+	body, readErr := io.ReadAll(resp.Body)
+	bodyStr := string(body)
+	if readErr != nil {
+		bodyStr += fmt.Sprintf(" (body read error: %v)", readErr)
+	}
+	return HTTPError{StatusCode: resp.StatusCode, Body: bodyStr}
+}
+
+// User represents the owner of the API key as returned by GET /users/me.
+type User struct {
+	ID    string  `json:"id"`
+	Name  *string `json:"name"`  // nullable
+	Email *string `json:"email"` // nullable
+}
+
+// String returns a human-readable identifier for the user.
+func (u User) String() string {
+	switch {
+	case u.Name != nil && u.Email != nil:
+		return fmt.Sprintf("%s <%s>", *u.Name, *u.Email)
+	case u.Email != nil:
+		return *u.Email
+	case u.Name != nil:
+		return *u.Name
+	}
+	return u.ID
+}
+
+// trpcRequest represents the request body of a tRPC mutation, whose input Karakeep wraps for superjson.
+type trpcRequest struct {
+	JSON any `json:"json"`
+}
+
+// trpcResult represents the result of one call of a tRPC batch: the output wrapped for superjson, or the error.
+type trpcResult struct {
+	Result *struct {
+		Data struct {
+			JSON json.RawMessage `json:"json"`
+		} `json:"data"`
+	} `json:"result"`
+	Error *struct {
+		JSON json.RawMessage `json:"json"` // message and data.httpStatus, kept raw for HTTPError.Body
+	} `json:"error"`
+}
+
+// trpcError represents the fields of a tRPC error read from trpcResult.Error.
+type trpcError struct {
+	Message string `json:"message"`
+	Data    struct {
+		HTTPStatus int `json:"httpStatus"`
+	} `json:"data"`
+}
+
+// recrawlInput represents the input of the bookmarks.recrawlBookmark tRPC procedure.
+type recrawlInput struct {
+	BookmarkID string `json:"bookmarkId"`
+}
+
+// CreateBookmarkRequest represents the request body to create a link-type or text-type bookmark.
+type CreateBookmarkRequest struct {
+	Type       string  `json:"type"`                // "link" or "text"
+	Source     string  `json:"source"`              // set to "api"
+	URL        string  `json:"url,omitempty"`       // required for links
+	Text       string  `json:"text,omitempty"`      // required for texts
+	SourceURL  string  `json:"sourceUrl,omitempty"` // page a text was taken from
+	CreatedAt  string  `json:"createdAt"`           // ISO8601, the save time to keep
+	Title      *string `json:"title,omitempty"`     // nullable
+	Note       *string `json:"note,omitempty"`      // converted's note nullable
+	Summary    *string `json:"summary,omitempty"`   // shown on the card apart from the note, nullable
+	Archived   *bool   `json:"archived,omitempty"`
+	Favourited *bool   `json:"favourited,omitempty"`
+}
+
+// NewCreateBookmarkRequest creates a link-type CreateBookmarkRequest with the source pre-set.
+func NewCreateBookmarkRequest(url, createdAt string, title, note *string) *CreateBookmarkRequest {
+	return &CreateBookmarkRequest{
+		Type:      "link",
+		Source:    "api",
+		URL:       url,
+		CreatedAt: createdAt,
+		Title:     title,
+		Note:      note,
+	}
+}
+
+// NewCreateTextBookmarkRequest creates a text-type CreateBookmarkRequest with the source pre-set.
+func NewCreateTextBookmarkRequest(text, sourceURL, createdAt string, title, note *string) *CreateBookmarkRequest {
+	return &CreateBookmarkRequest{
+		Type:      "text",
+		Source:    "api",
+		Text:      text,
+		SourceURL: sourceURL,
+		CreatedAt: createdAt,
+		Title:     title,
+		Note:      note,
+	}
+}
+
+// CreateBookmarkResponse represents a successful response body when creating or retrieving a bookmark.
+type CreateBookmarkResponse struct {
+	ID         string  `json:"id"`
+	CreatedAt  string  `json:"createdAt"` // ISO8601
+	Title      *string `json:"title"`     // nullable
+	Note       *string `json:"note"`      // nullable
+	Summary    *string `json:"summary"`   // nullable
+	Archived   bool    `json:"archived"`
+	Favourited bool    `json:"favourited"`
+}
+
+// CreateBookmarkResult represents the result of one bookmark created in a batch (see Client.CreateBookmarks).
+type CreateBookmarkResult struct {
+	Bookmark *CreateBookmarkResponse // nil if Err is set
+	Exists   bool                    // the URL already existed, and Bookmark is the existing one
+	Err      error                   // an HTTPError with the status of the failed call
+}
+
+// AttachTagsRequest represents the request body to attach tags to (or detach them from) a bookmark.
+type AttachTagsRequest struct {
+	Tags []TagRequest `json:"tags"`
+}
+
+// TagRequest represents a tag to attach to (or detach from) a bookmark.
+type TagRequest struct {
+	TagName string `json:"tagName"`
+}
+
+// UpdateBookmarkRequest represents the request body to update a bookmark's fields.
+// Nil fields are omitted so the server leaves them untouched.
+type UpdateBookmarkRequest struct {
+	CreatedAt  *string `json:"createdAt,omitempty"`  // nullable, ISO8601
+	Title      *string `json:"title,omitempty"`      // nullable
+	Note       *string `json:"note,omitempty"`       // nullable
+	Summary    *string `json:"summary,omitempty"`    // nullable
+	Archived   *bool   `json:"archived,omitempty"`   // nullable
+	Favourited *bool   `json:"favourited,omitempty"` // nullable
+}
+
+// CreateHighlightRequest represents the request body to create a highlight on a bookmark.
+// Offsets locate the highlighted text in the bookmark's content; equal offsets anchor nothing,
+// so the highlight only shows in the bookmark's list of highlights.
+type CreateHighlightRequest struct {
+	BookmarkID  string  `json:"bookmarkId"`
+	StartOffset int     `json:"startOffset"`
+	EndOffset   int     `json:"endOffset"`
+	Color       string  `json:"color,omitempty"` // yellow (default), red, green, or blue
+	Text        *string `json:"text"`            // nullable
+	Note        *string `json:"note"`            // nullable
+}
+
+// Highlight represents a highlight on a bookmark.
+type Highlight struct {
+	ID          string  `json:"id"`
+	BookmarkID  string  `json:"bookmarkId"`
+	StartOffset int     `json:"startOffset"`
+	EndOffset   int     `json:"endOffset"`
+	Color       string  `json:"color"`
+	Text        *string `json:"text"` // nullable
+	Note        *string `json:"note"` // nullable
+	CreatedAt   string  `json:"createdAt"`
+}
+
+// ListHighlightsResponse represents the response body when listing the highlights of a bookmark.
+type ListHighlightsResponse struct {
+	Highlights []Highlight `json:"highlights"`
+}
+
+// ExistingBookmark represents a pre-fetched bookmark data for deduplication.
+type ExistingBookmark struct {
+	ID         string
+	CreatedAt  int64 // Unix timestamp
+	Title      *string
+	Note       *string
+	Summary    *string
+	Archived   bool
+	Favourited bool
+	Tags       []string // tag names
+}
+
+// ListBookmarksResponse represents the paginated response body when listing bookmarks.
+type ListBookmarksResponse struct {
+	Bookmarks  []ListBookmark `json:"bookmarks"`
+	NextCursor *string        `json:"nextCursor"`
+}
+
+// ListBookmark represents a bookmark in the list bookmarks response.
+type ListBookmark struct {
+	ID         string              `json:"id"`
+	CreatedAt  string              `json:"createdAt"`
+	Title      *string             `json:"title"`
+	Source     *string             `json:"source"` // "api", "web", "extension", "import", etc.
+	Note       *string             `json:"note"`
+	Summary    *string             `json:"summary"`
+	Archived   bool                `json:"archived"`
+	Favourited bool                `json:"favourited"`
+	Tags       []ListBookmarkTag   `json:"tags"`
+	Content    ListBookmarkContent `json:"content"`
+}
+
+// ListBookmarkTag represents a tag attached to a bookmark in the list bookmarks response.
+type ListBookmarkTag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListBookmarkContent handles discriminated union of bookmark content types.
+type ListBookmarkContent struct {
+	Type      string  `json:"type"`      // "link", "assetL", "text"
+	URL       *string `json:"url"`       // present when type="link"
+	SourceURL *string `json:"sourceUrl"` // present when type="asset", optional when type="text"
+}
+
+// GetURL extracts the bookmark	URL based on its content type.
+func (c ListBookmarkContent) GetURL() string {
+	switch c.Type {
+	case "link":
+		if c.URL != nil {
+			return *c.URL
+		}
+	case "asset", "text":
+		if c.SourceURL != nil {
+			return *c.SourceURL
+		}
+	}
+	return ""
+}
+
+// Tag represents a tag in the list tags response.
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListTagsResponse represents the response body when listing tags.
+type ListTagsResponse struct {
+	Tags []Tag `json:"tags"`
+}
+
+// List represents a bookmark list. Manual lists hold the bookmarks added to them, smart lists
+// the bookmarks matching their search query.
+type List struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Icon     string  `json:"icon"`     // emoji
+	Type     string  `json:"type"`     // "manual" or "smart"
+	Query    *string `json:"query"`    // search query of smart lists, nullable
+	ParentID *string `json:"parentId"` // nullable
+}
+
+// CreateListRequest represents the request body to create a list.
+type CreateListRequest struct {
+	Name     string  `json:"name"`
+	Icon     string  `json:"icon"`
+	Type     string  `json:"type,omitempty"`     // "manual" (default) or "smart"
+	Query    *string `json:"query,omitempty"`    // required for smart lists
+	ParentID *string `json:"parentId,omitempty"` // nullable
+}
+
+// ListListsResponse represents the response body when listing lists.
+type ListListsResponse struct {
+	Lists []List `json:"lists"`
+}
+
+// UserStats represents the counts of the user's content as returned by GET /users/me/stats.
+type UserStats struct {
+	NumBookmarks  int `json:"numBookmarks"`
+	NumFavorites  int `json:"numFavorites"`
+	NumArchived   int `json:"numArchived"`
+	NumTags       int `json:"numTags"`
+	NumLists      int `json:"numLists"`
+	NumHighlights int `json:"numHighlights"`
+}
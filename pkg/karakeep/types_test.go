@@ -0,0 +1,138 @@
+package karakeep
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListBookmarkContent_GetURL(t *testing.T) {
+	tests := map[string]struct {
+		content ListBookmarkContent
+		want    string
+	}{
+		"link type returns URL": {
+			content: ListBookmarkContent{Type: "link", URL: ptr("https://example.com")},
+			want:    "https://example.com",
+		},
+		"link type with nil URL returns empty": {
+			content: ListBookmarkContent{Type: "link", URL: nil},
+			want:    "",
+		},
+		"asset type returns sourceUrl": {
+			content: ListBookmarkContent{Type: "asset", SourceURL: ptr("https://example.com/doc.pdf")},
+			want:    "https://example.com/doc.pdf",
+		},
+		"asset type with nil sourceUrl returns empty": {
+			content: ListBookmarkContent{Type: "asset", SourceURL: nil},
+			want:    "",
+		},
+		"text type returns sourceUrl": {
+			content: ListBookmarkContent{Type: "text", SourceURL: ptr("https://news.ycombinator.com/item?id=1")},
+			want:    "https://news.ycombinator.com/item?id=1",
+		},
+		"text type without sourceUrl returns empty": {
+			content: ListBookmarkContent{Type: "text"},
+			want:    "",
+		},
+		"unknown type returns empty": {
+			content: ListBookmarkContent{Type: "unknown"},
+			want:    "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.content.GetURL()
+			if got != tc.want {
+				t.Errorf("GetURL() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHTTPError_Error(t *testing.T) {
+	err := HTTPError{StatusCode: 500, Body: "internal server error"}
+	got := err.Error()
+	want := "karakeep API error (HTTP 500): internal server error"
+	if got != want {
+		t.Errorf("HTTPError.Error() = %q, want %q", got, want)
+	}
+}
+
+func TestHTTPError_Incompatible(t *testing.T) {
+	tests := map[string]struct {
+		err  HTTPError
+		want bool
+	}{
+		"missing route":         {err: HTTPError{StatusCode: 404, Body: "404 Not Found"}, want: true},
+		"missing resource":      {err: HTTPError{StatusCode: 404, Body: `{"code":"NOT_FOUND","message":"Bookmark not found"}`}, want: false},
+		"unknown request field": {err: HTTPError{StatusCode: 400, Body: `{"code":"unrecognized_keys","keys":["title"]}`}, want: false},
+		"server error":          {err: HTTPError{StatusCode: 500, Body: "internal server error"}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.err.Incompatible(); got != tc.want {
+				t.Errorf("Incompatible() = %v, want %v", got, tc.want)
+			}
+			if got := strings.Contains(tc.err.Error(), SupportedVersion); got != tc.want {
+				t.Errorf("Error() = %q, mentions %s: %v, want %v", tc.err.Error(), SupportedVersion, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHTTPError_FieldLimit(t *testing.T) {
+	tests := map[string]struct {
+		err       HTTPError
+		wantLimit int
+		wantOK    bool
+	}{
+		"zod issue": {
+			err:       HTTPError{StatusCode: 400, Body: `[{"code":"too_big","maximum":1000,"type":"string","inclusive":true,"exact":false,"message":"String must contain at most 1000 character(s)","path":["note"]}]`},
+			wantLimit: 1000, wantOK: true,
+		},
+		"issue in message": {
+			err:       HTTPError{StatusCode: 400, Body: `{"code":"BAD_REQUEST","message":"[{\"origin\":\"string\",\"code\":\"too_big\",\"maximum\": 5000,\"inclusive\":true,\"path\":[\"note\"],\"message\":\"Too big\"}]"}`},
+			wantLimit: 5000, wantOK: true,
+		},
+		"other field": {
+			err: HTTPError{StatusCode: 400, Body: `[{"code":"too_big","maximum":1000,"type":"string","path":["title"]},{"code":"invalid_type","path":["note"]}]`},
+		},
+		"other error": {err: HTTPError{StatusCode: 400, Body: `{"code":"invalid_string","path":["note"]}`}},
+		"server error": {
+			err: HTTPError{StatusCode: 500, Body: `[{"code":"too_big","maximum":1000,"path":["note"]}]`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limit, ok := tc.err.FieldLimit("note")
+			if limit != tc.wantLimit || ok != tc.wantOK {
+				t.Errorf("FieldLimit() = %d, %v, want %d, %v", limit, ok, tc.wantLimit, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestHTTPError_IsClientError(t *testing.T) {
+	tests := map[string]struct {
+		statusCode int
+		want       bool
+	}{
+		"399 is not client error": {statusCode: 399, want: false},
+		"400 is client error":     {statusCode: 400, want: true},
+		"404 is client error":     {statusCode: 404, want: true},
+		"499 is client error":     {statusCode: 499, want: true},
+		"500 is not client error": {statusCode: 500, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := HTTPError{StatusCode: tc.statusCode}
+			if got := err.IsClientError(); got != tc.want {
+				t.Errorf("HTTPError{%d}.IsClientError() = %v, want %v", tc.statusCode, got, tc.want)
+			}
+		})
+	}
+}
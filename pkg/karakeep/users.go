@@ -0,0 +1,23 @@
+package karakeep
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// GetUserStats fetches the counts of bookmarks, tags, lists, and highlights of the API key's owner.
+// Refer to https://docs.karakeep.app/api/get-current-user-stats and the codebase.
+func (c *Client) GetUserStats(ctx context.Context) (*UserStats, error) {
+	var stats UserStats
+	err := c.doRequestWithRetries(ctx, http.MethodGet, "/users/me/stats", nil, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return readHTTPError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&stats)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}